@@ -0,0 +1,67 @@
+// Command driftcheck compares the articles in the SQLite database with the
+// documents in the public articles Meilisearch index, and with -repair
+// re-indexes missing and stale articles and deletes orphaned documents.
+//
+//	DATABASE_PATH=file:/data/articles.db go run ./cmd/driftcheck -repair
+//
+// The report is logged. Without -repair the command exits with status 2
+// when drift was found. The server runs the same check on a schedule; see
+// DRIFT_CHECK_INTERVAL.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"mini-search-platform/internal/adapters"
+	"mini-search-platform/internal/drift"
+	"mini-search-platform/pkg/logging"
+	"mini-search-platform/pkg/sqlite"
+)
+
+func main() {
+	logging.Init()
+
+	dbPath := flag.String("db", os.Getenv("DATABASE_PATH"), "SQLite database to read articles from (default $DATABASE_PATH)")
+	repair := flag.Bool("repair", false, "re-index missing and stale articles and delete orphaned documents")
+	pageSize := flag.Int("page-size", 1000, "articles and documents read per page")
+	flag.Parse()
+
+	if *dbPath == "" {
+		log.Fatalf("no database: set DATABASE_PATH or -db (the server's default in-memory database cannot be read from another process)")
+	}
+	if *pageSize <= 0 {
+		log.Fatalf("-page-size must be positive")
+	}
+
+	db, err := sqlite.Init(*dbPath)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer sqlite.Close(db)
+
+	meilisearchHost := os.Getenv("MEILISEARCH_HOST")
+	if meilisearchHost == "" {
+		meilisearchHost = "http://localhost:7700"
+	}
+	engine := adapters.Init(meilisearchHost, os.Getenv("MEILISEARCH_API_KEY"))
+
+	checker := drift.NewChecker(adapters.NewSQLliteArticleRepository(db), engine)
+	checker.PageSize = *pageSize
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	report, err := checker.Run(ctx, *repair)
+	if err != nil {
+		logging.Error("drift check failed", "error", err)
+		os.Exit(1)
+	}
+	if !report.Consistent() && !report.Repaired {
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,90 @@
+// Command reindex rebuilds the public articles Meilisearch index from the
+// SQLite database, e.g. after the index was wiped or its settings changed.
+//
+//	DATABASE_PATH=file:/data/articles.db go run ./cmd/reindex
+//
+// Progress is checkpointed in the database after every page, so an
+// interrupted run (Ctrl-C, crash) picks up where it stopped when started
+// again. The command exits with status 2 if, once Meilisearch has processed
+// every batch, the index does not hold exactly one document per article.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"mini-search-platform/internal/adapters"
+	"mini-search-platform/internal/database"
+	"mini-search-platform/internal/reindex"
+	"mini-search-platform/pkg/logging"
+	"mini-search-platform/pkg/sqlite"
+)
+
+func main() {
+	logging.Init()
+
+	dbPath := flag.String("db", os.Getenv("DATABASE_PATH"), "SQLite database to read articles from (default $DATABASE_PATH)")
+	pageSize := flag.Int("page-size", 1000, "articles read from SQLite per page (and per checkpoint)")
+	batchSize := flag.Int("batch-size", 250, "articles sent to Meilisearch per request")
+	flag.Parse()
+
+	if *dbPath == "" {
+		log.Fatalf("no database: set DATABASE_PATH or -db (the server's default in-memory database cannot be read from another process)")
+	}
+	if *pageSize <= 0 || *batchSize <= 0 {
+		log.Fatalf("-page-size and -batch-size must be positive")
+	}
+
+	db, err := sqlite.Init(*dbPath)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer sqlite.Close(db)
+
+	// Creates the checkpoint table when the database predates it.
+	if err := database.Create(db); err != nil {
+		log.Fatalf("Failed to create database schema: %v", err)
+	}
+
+	meilisearchHost := os.Getenv("MEILISEARCH_HOST")
+	if meilisearchHost == "" {
+		meilisearchHost = "http://localhost:7700"
+	}
+	engine := adapters.Init(meilisearchHost, os.Getenv("MEILISEARCH_API_KEY"))
+
+	reindexer := reindex.New(
+		adapters.NewSQLliteArticleRepository(db),
+		engine,
+		adapters.NewSQLiteReindexCheckpointRepository(db),
+	)
+	reindexer.PageSize = *pageSize
+	reindexer.BatchSize = *batchSize
+	reindexer.OnProgress = func(p reindex.Progress) {
+		logging.Info("reindex progress",
+			"indexed", p.Indexed, "total", p.Total, "last_article_id", p.LastArticleID, "elapsed", p.Elapsed.Round(time.Millisecond).String())
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	result, err := reindexer.Run(ctx)
+	if err != nil {
+		logging.Error("reindex stopped; run again to resume from the last checkpoint",
+			"indexed", result.Indexed, "error", err)
+		os.Exit(1)
+	}
+
+	logging.Info("reindex finished",
+		"indexed", result.Indexed, "resumed_after_article_id", result.ResumedAfter,
+		"articles", result.ArticleCount, "index_documents", result.IndexCount)
+	if !result.CountsMatch() {
+		logging.Warn("index document count does not match the article count",
+			"articles", result.ArticleCount, "index_documents", result.IndexCount)
+		os.Exit(2)
+	}
+}
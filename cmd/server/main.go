@@ -4,10 +4,19 @@ import (
 	"log"
 	"mini-search-platform/config"
 	"mini-search-platform/internal/adapters"
+	"mini-search-platform/internal/analytics"
 	"mini-search-platform/internal/database"
+	"mini-search-platform/internal/drift"
 	"mini-search-platform/internal/handlers"
+	"mini-search-platform/internal/merchandising"
 	"mini-search-platform/internal/middleware"
+	"mini-search-platform/internal/models"
+	"mini-search-platform/internal/popularity"
+	"mini-search-platform/internal/savedsearch"
 	"mini-search-platform/internal/search"
+	"mini-search-platform/internal/spelling"
+	"mini-search-platform/internal/usage"
+	"mini-search-platform/internal/webhooks"
 	"mini-search-platform/pkg/logging"
 	"mini-search-platform/pkg/security"
 	"mini-search-platform/pkg/sqlite"
@@ -43,8 +52,26 @@ func main() {
 	users := adapters.NewSQLiteUserRepository(db)
 	tenants := adapters.NewSQLiteTenantRepository(db)
 	memberships := adapters.NewSQLiteMembershipRepository(db)
+	invitations := adapters.NewSQLiteInvitationRepository(db)
+	customRoles := adapters.NewSQLiteCustomRoleRepository(db)
+	refreshTokens := adapters.NewSQLiteRefreshTokenRepository(db)
+	sessions := adapters.NewSQLiteSessionRepository(db)
+	projects := adapters.NewSQLiteProjectRepository(db)
+	deletionReceipts := adapters.NewSQLiteTenantDeletionReceiptRepository(db)
+	usageRollups := adapters.NewSQLiteUsageRepository(db)
+	searchLogs := adapters.NewSQLiteSearchLogRepository(db)
+	searchEvents := adapters.NewSQLiteSearchEventRepository(db)
+	documentPopularity := adapters.NewSQLiteDocumentPopularityRepository(db)
+	queryRules := adapters.NewSQLiteQueryRuleRepository(db)
+	vocabulary := spelling.NewVocabulary(adapters.NewSQLiteVocabularyRepository(db), cfg.Spelling.MaxTenants)
+	savedSearches := adapters.NewSQLiteSavedSearchRepository(db)
+	webhookDeliveries := adapters.NewSQLiteWebhookDeliveryRepository(db)
+	webhookSubscriptions := adapters.NewSQLiteWebhookSubscriptionRepository(db)
+	indexSyncOutbox := adapters.NewSQLiteIndexSyncOutboxRepository(db)
 
 	jwtSvc := security.NewJWTService(cfg.JWT.SecretKey, cfg.JWT.Issuer, cfg.JWT.AccessTTL)
+	revocations := security.NewRevocationList(sessions, cfg.Sessions.RevocationCacheTTL)
+	security.StartSessionPruning(sessions, cfg.Sessions.PruneInterval)
 
 	meilisearchAPIKey := os.Getenv("MEILISEARCH_API_KEY")
 	meilisearchHost := os.Getenv("MEILISEARCH_HOST")
@@ -52,13 +79,80 @@ func main() {
 		meilisearchHost = "http://localhost:7700"
 	}
 	engine := adapters.Init(meilisearchHost, meilisearchAPIKey)
+	if cfg.Popularity.Ranking {
+		engine.EnablePopularityRanking()
+	}
+
+	meter := usage.NewMeter(usageRollups)
+	meter.Start(10 * time.Second)
+	// Metering wraps the cache so searches served from it are still billed;
+	// the cache wraps spelling and merchandising so cached results already
+	// carry suggestions and the tenant's rules, and auto-corrected searches
+	// have the rules of the corrected query applied. Indexed documents keep
+	// their popularity scores, whichever path indexes them.
+	merchandisedEngine := merchandising.NewEngine(popularity.NewEngine(engine, documentPopularity), queryRules)
+	spellingEngine := spelling.NewEngine(merchandisedEngine, vocabulary, cfg.Spelling.MinHits, cfg.Spelling.AutoCorrect)
+	searchCache := search.NewCachingEngine(spellingEngine, cfg.SearchCache.TTL, cfg.SearchCache.MaxBytes)
+
+	webhookDispatcher := webhooks.NewDispatcher(webhookDeliveries, cfg.Webhooks.Timeout, cfg.Webhooks.MaxAttempts, cfg.Webhooks.Workers)
+	if cfg.Webhooks.AllowPrivateNetworks {
+		webhookDispatcher.AllowPrivateNetworks()
+	}
+	webhookDispatcher.Start(10 * time.Second)
+
+	// Lifecycle webhooks: engine tasks enqueued from here on are reported to
+	// the tenant's subscriptions once Meilisearch finishes them.
+	webhookPublisher := webhooks.NewPublisher(webhookSubscriptions, webhookDispatcher)
+	engine.WatchTasks(2*time.Second, webhookPublisher.HandleTask)
+	// Saved searches are matched against the raw engine: buyers are told
+	// about documents matching their query, not about merchandising.
+	savedSearchNotifier := savedsearch.NewNotifier(engine, savedSearches, webhookDispatcher, 1000)
+	savedSearchNotifier.Start()
+	tenantEngine := usage.NewMeteredEngine(savedsearch.NewNotifyingEngine(searchCache, savedSearchNotifier), meter)
+
+	// Tenants' articles are indexed through the tenant engine, so their
+	// indexing is metered, drops their cached results and reaches their
+	// saved searches like any other tenant document.
+	sync := search.NewIndexSyncManager(engine, tenantEngine, engine, articles, tags, indexSyncOutbox, cfg.IndexSync.MaxAttempts)
+	sync.Start(cfg.IndexSync.Interval)
+	if cfg.Drift.Interval > 0 {
+		drift.NewChecker(articles, engine).Start(cfg.Drift.Interval, cfg.Drift.Repair)
+	}
+
+	searchRecorder := analytics.NewRecorder(searchLogs, 10000)
+	searchRecorder.Start(5 * time.Second)
+	searchRecorder.StartRetention(time.Hour, cfg.Analytics.Retention, cfg.Analytics.MaxRows)
+
+	// Popularity writes bypass metering (they are not tenant traffic) but go
+	// through the cache so patched tenants' cached results are dropped.
+	popularityScorer := popularity.NewScorer(searchEvents, documentPopularity, searchCache, cfg.Popularity.HalfLife, cfg.Popularity.Window)
+	popularityScorer.Start(cfg.Popularity.Interval)
 
-	sync := search.NewIndexSyncManager(engine, articles, tags)
+	var rateLimitStore middleware.RateLimitStore = middleware.NewMemoryRateLimitStore()
+	if cfg.RateLimit.Store == "redis" {
+		rateLimitStore, err = middleware.NewRedisRateLimitStoreFromURL(cfg.RateLimit.RedisURL)
+		if err != nil {
+			log.Fatalf("Failed to configure rate limit store: %v", err)
+		}
+	}
 
-	rateLimiter := middleware.NewRateLimiter(cfg.RateLimit.SearchLimit)
+	rateLimiter := middleware.NewSharedRateLimiter(cfg.RateLimit.SearchLimit, rateLimitStore, cfg.RateLimit.FailOpen)
 	rateLimiter.Cleanup(5 * time.Minute)
 
-	authMiddleware := middleware.NewAuthMiddleware(jwtSvc, users)
+	tenantLimits := cfg.RateLimit.Tenant
+	tenantRateLimiter := middleware.NewTenantRateLimiter(
+		rateLimitStore,
+		cfg.RateLimit.FailOpen,
+		middleware.NewProjectPlanResolver(projects),
+		map[models.ProjectTier]middleware.TenantLimits{
+			models.TierFree:    {Search: tenantLimits.FreeSearchLimit, Index: tenantLimits.FreeIndexLimit, Events: tenantLimits.FreeEventsLimit},
+			models.TierPremium: {Search: tenantLimits.ProSearchLimit, Index: tenantLimits.ProIndexLimit, Events: tenantLimits.ProEventsLimit},
+		},
+	)
+	tenantRateLimiter.Cleanup(5 * time.Minute)
+
+	authMiddleware := middleware.NewAuthMiddleware(jwtSvc, users, memberships, sessions, revocations)
+	requireTenant := authMiddleware.RequireTenant(customRoles)
 
 	r := gin.New()
 
@@ -79,31 +173,92 @@ func main() {
 
 	handlers.SetupSwagger(r)
 
-	ttlaccess := int64(cfg.JWT.AccessTTL.Seconds())
+	tokenIssuer := handlers.NewTokenIssuer(jwtSvc, refreshTokens, sessions, revocations, cfg.JWT.AccessTTL, cfg.JWT.RefreshTTL)
 
 	// resource: auth (public endpoints)
-	r.POST("/auth/register", handlers.Register(users, tenants, memberships, jwtSvc, ttlaccess))
-	r.POST("/auth/login", handlers.Login(users, jwtSvc, ttlaccess))
-	r.POST("/auth/refresh", handlers.RefreshToken(jwtSvc, ttlaccess))
+	r.POST("/auth/register", handlers.Register(users, tenants, memberships, invitations, tokenIssuer))
+	r.POST("/auth/login", handlers.Login(users, memberships, invitations, tokenIssuer))
+	r.POST("/auth/refresh", handlers.RefreshToken(users, memberships, tokenIssuer))
+	r.POST("/auth/logout", handlers.Logout(tokenIssuer))
 
 	// resource: logged user (protected)
 	r.GET("/api/me", authMiddleware.RequireAuth(), handlers.GetCurrentUser(users))
+	r.POST("/auth/switch-tenant", authMiddleware.RequireAuth(), handlers.SwitchTenant(memberships, tokenIssuer))
+	r.GET("/auth/sessions", authMiddleware.RequireAuth(), handlers.ListSessions(sessions))
+	r.DELETE("/auth/sessions", authMiddleware.RequireAuth(), handlers.RevokeAllSessions(sessions, revocations))
+	r.DELETE("/auth/sessions/:id", authMiddleware.RequireAuth(), handlers.RevokeSession(sessions, revocations))
+
+	// resource: articles (protected, scoped to the token's tenant)
+	r.POST("/articles", authMiddleware.RequireAuth(), requireTenant, middleware.RequirePermission(models.PermDocumentsWrite), handlers.AddArticle(articles, authors, tags, projects, sync))
+	r.POST("/articles/batch", authMiddleware.RequireAuth(), requireTenant, middleware.RequirePermission(models.PermDocumentsWrite), handlers.AddArticles(articles, authors, tags, projects, sync))
+	r.GET("/articles", authMiddleware.RequireAuth(), requireTenant, middleware.RequirePermission(models.PermDocumentsRead), handlers.ListArticles(articles))
+	r.GET("/articles/:id", authMiddleware.RequireAuth(), requireTenant, middleware.RequirePermission(models.PermDocumentsRead), handlers.GetArticle(articles))
+	r.PUT("/articles/:id", authMiddleware.RequireAuth(), requireTenant, middleware.RequirePermission(models.PermDocumentsWrite), handlers.UpdateArticle(articles, authors, tags, sync))
+	r.PATCH("/articles/:id", authMiddleware.RequireAuth(), requireTenant, middleware.RequirePermission(models.PermDocumentsWrite), handlers.PatchArticle(articles, authors, tags, sync))
+	r.DELETE("/articles/:id", authMiddleware.RequireAuth(), requireTenant, middleware.RequirePermission(models.PermDocumentsWrite), handlers.DeleteArticle(articles, sync))
+
+	// resource: projects (protected, scoped to the token's tenant)
+	projectLimits := handlers.ProjectLimits{
+		models.TierFree:    cfg.Projects.FreeLimit,
+		models.TierPremium: cfg.Projects.PremiumLimit,
+	}
+	r.POST("/projects", authMiddleware.RequireAuth(), requireTenant, middleware.RequirePermission(models.PermProjectsManage), handlers.CreateProject(projects, middleware.NewProjectPlanResolver(projects), projectLimits))
+	r.GET("/projects", authMiddleware.RequireAuth(), requireTenant, middleware.RequirePermission(models.PermDocumentsRead), handlers.ListProjects(projects))
+	r.GET("/projects/:id", authMiddleware.RequireAuth(), requireTenant, middleware.RequirePermission(models.PermDocumentsRead), handlers.GetProject(projects))
+	r.PATCH("/projects/:id", authMiddleware.RequireAuth(), requireTenant, middleware.RequirePermission(models.PermProjectsManage), handlers.RenameProject(projects))
+	r.PUT("/projects/:id/tier", authMiddleware.RequireAuth(), requireTenant, middleware.RequirePermission(models.PermBillingUpdate), handlers.UpdateProjectTier(projects, projectLimits))
+	r.GET("/projects/:id/search", authMiddleware.RequireAuth(), requireTenant, middleware.RequirePermission(models.PermDocumentsRead), handlers.SearchProject(projects, engine))
+	r.DELETE("/projects/:id", authMiddleware.RequireAuth(), requireTenant, middleware.RequirePermission(models.PermProjectsManage), handlers.DeleteProject(projects, sync))
 
-	// resource: articles
-	r.POST("/articles", handlers.AddArticle(articles, authors, tags, sync))
-	r.POST("/articles/batch", handlers.AddArticles(articles, authors, tags, sync))
+	// resource: members and invitations (protected, scoped to the token's
+	// tenant)
+	r.GET("/members", authMiddleware.RequireAuth(), requireTenant, middleware.RequirePermission(models.PermMembersRead), handlers.ListMembers(memberships, users))
+	r.PUT("/members/:id/role", authMiddleware.RequireAuth(), requireTenant, middleware.RequirePermission(models.PermMembersManage), handlers.UpdateMemberRole(memberships, customRoles))
+	r.DELETE("/members/:id", authMiddleware.RequireAuth(), requireTenant, handlers.RemoveMember(memberships, customRoles))
+	r.POST("/invitations", authMiddleware.RequireAuth(), requireTenant, middleware.RequirePermission(models.PermMembersManage), handlers.InviteMember(invitations, memberships, users, customRoles, cfg.Invitations.TTL))
+	r.GET("/invitations", authMiddleware.RequireAuth(), requireTenant, middleware.RequirePermission(models.PermMembersManage), handlers.ListInvitations(invitations))
+	r.DELETE("/invitations/:id", authMiddleware.RequireAuth(), requireTenant, middleware.RequirePermission(models.PermMembersManage), handlers.RevokeInvitation(invitations))
+	r.POST("/invitations/accept", authMiddleware.RequireAuth(), handlers.AcceptInvitation(invitations))
+
+	// resource: custom roles (protected, scoped to the token's tenant)
+	r.GET("/roles", authMiddleware.RequireAuth(), requireTenant, middleware.RequirePermission(models.PermMembersRead), handlers.ListRoles(customRoles))
+	r.POST("/roles", authMiddleware.RequireAuth(), requireTenant, middleware.RequirePermission(models.PermMembersManage), handlers.CreateRole(customRoles))
+	r.PUT("/roles/:id", authMiddleware.RequireAuth(), requireTenant, middleware.RequirePermission(models.PermMembersManage), handlers.UpdateRole(customRoles))
+	r.DELETE("/roles/:id", authMiddleware.RequireAuth(), requireTenant, middleware.RequirePermission(models.PermMembersManage), handlers.DeleteRole(customRoles))
+
+	// Changes to the shared catalog of authors and tags (see
+	// RequireOperator) are reserved to the platform's operators, and so are
+	// signing a user out of every tenant and inspecting the index sync of
+	// every tenant's articles.
+	requireOperator := middleware.RequireOperator(cfg.Operators.Emails)
+
+	// resource: users (protected, platform operators only)
+	r.DELETE("/users/:id/sessions", authMiddleware.RequireAuth(), requireOperator, handlers.RevokeUserSessions(users, sessions, revocations))
 
 	// resource: authors
 	r.POST("/authors", handlers.AddAuthor(authors))
 	r.POST("/authors/batch", handlers.AddAuthors(authors))
+	r.GET("/authors", handlers.ListAuthors(authors))
+	r.GET("/authors/:id", handlers.GetAuthor(authors))
+	r.GET("/authors/:id/articles", authMiddleware.RequireAuth(), requireTenant, middleware.RequirePermission(models.PermDocumentsRead), handlers.ListAuthorArticles(authors, articles))
+	r.PATCH("/authors/:id", authMiddleware.RequireAuth(), requireOperator, handlers.UpdateAuthor(authors, sync))
 
 	// resource: tags
 	r.POST("/tags", handlers.AddTag(tags))
-	r.PATCH("/tags/:label", handlers.UpdateTagWithLabel(tags, sync))
+	r.PATCH("/tags/:label", authMiddleware.RequireAuth(), requireOperator, handlers.UpdateTagWithLabel(tags, sync))
 	r.POST("/tags/batch", handlers.AddTagsInBatch(tags))
 	r.GET("/tags", handlers.ListAllTags(tags))
 	r.GET("/tags/:label", handlers.GetTagByLabel(tags))
-	r.GET("/tags/:label/articles", handlers.FindArticlesByLabels(articles, tags))
+	r.GET("/tags/:label/articles", authMiddleware.RequireAuth(), requireTenant, middleware.RequirePermission(models.PermDocumentsRead), handlers.FindArticlesByLabels(articles, tags))
+	r.DELETE("/tags/:label", authMiddleware.RequireAuth(), requireOperator, handlers.DeleteTag(tags, sync))
+	r.POST("/tags/:label/merge", authMiddleware.RequireAuth(), requireOperator, handlers.MergeTag(tags, sync))
+	r.POST("/tags/:label/move", authMiddleware.RequireAuth(), requireOperator, handlers.MoveTag(tags, sync))
+	r.GET("/tags/:label/ancestors", handlers.GetTagAncestors(tags))
+	r.GET("/tags/:label/descendants", handlers.GetTagDescendants(tags))
+
+	// resource: articles index sync outbox (protected, platform operators
+	// only: it spans every tenant)
+	r.GET("/index-sync", authMiddleware.RequireAuth(), requireOperator, handlers.GetIndexSyncStatus(indexSyncOutbox))
 
 	// resource: search (with rate limiting)
 	r.GET("/search", rateLimiter.Middleware(), handlers.SearchArticles(engine))
@@ -111,9 +266,31 @@ func main() {
 	// resource: internal, tenant-scoped search API (called only by the
 	// Fastify control plane; never exposed through Ingress). Trust boundary
 	// and tenant resolution are documented in CONTRACT.md §2 and §4.
-	r.GET("/internal/search", handlers.InternalSearch(engine))
-	r.GET("/internal/documents", handlers.InternalListDocuments(engine))
-	r.POST("/internal/documents/batch", handlers.InternalIndexDocumentsBatch(engine))
+	r.GET("/internal/search", tenantRateLimiter.Middleware(middleware.ScopeSearch), handlers.InternalSearch(tenantEngine, searchRecorder))
+	r.GET("/internal/search/cache-stats", handlers.InternalSearchCacheStats(searchCache))
+	r.GET("/internal/documents", handlers.InternalListDocuments(tenantEngine))
+	r.POST("/internal/documents/batch", tenantRateLimiter.Middleware(middleware.ScopeIndex), handlers.InternalIndexDocumentsBatch(tenantEngine, sync, webhookPublisher))
+	r.DELETE("/internal/tenant", handlers.InternalDeleteTenant(tenants, deletionReceipts, tenantEngine, projects, engine, searchRecorder, meter))
+	r.GET("/internal/tenant/deletion-receipts", handlers.InternalListTenantDeletionReceipts(deletionReceipts))
+	r.GET("/internal/usage", handlers.InternalUsage(meter))
+	r.GET("/internal/analytics/searches", handlers.InternalSearchAnalytics(searchRecorder))
+	r.POST("/internal/events", tenantRateLimiter.Middleware(middleware.ScopeEvents), handlers.InternalRecordEvents(searchEvents))
+	r.GET("/internal/rules", handlers.InternalListQueryRules(queryRules))
+	r.POST("/internal/rules", handlers.InternalCreateQueryRule(queryRules, searchCache))
+	r.PUT("/internal/rules/:id", handlers.InternalUpdateQueryRule(queryRules, searchCache))
+	r.DELETE("/internal/rules/:id", handlers.InternalDeleteQueryRule(queryRules, searchCache))
+	r.GET("/internal/saved-searches", handlers.InternalListSavedSearches(savedSearches))
+	r.POST("/internal/saved-searches", handlers.InternalCreateSavedSearch(savedSearches, webhookDispatcher))
+	r.PUT("/internal/saved-searches/:id", handlers.InternalUpdateSavedSearch(savedSearches, webhookDispatcher))
+	r.DELETE("/internal/saved-searches/:id", handlers.InternalDeleteSavedSearch(savedSearches))
+	r.GET("/internal/saved-searches/:id/deliveries", handlers.InternalListSavedSearchDeliveries(savedSearches, webhookDeliveries))
+	r.GET("/internal/webhooks", handlers.InternalListWebhookSubscriptions(webhookSubscriptions))
+	r.POST("/internal/webhooks", handlers.InternalCreateWebhookSubscription(webhookSubscriptions, webhookDispatcher))
+	r.PUT("/internal/webhooks/:id", handlers.InternalUpdateWebhookSubscription(webhookSubscriptions, webhookDispatcher))
+	r.DELETE("/internal/webhooks/:id", handlers.InternalDeleteWebhookSubscription(webhookSubscriptions))
+	r.GET("/internal/webhooks/:id/deliveries", handlers.InternalListWebhookSubscriptionDeliveries(webhookSubscriptions, webhookDeliveries))
+	r.GET("/internal/webhooks/dead-letters", handlers.InternalListWebhookDeadLetters(webhookDeliveries))
+	r.POST("/internal/webhooks/dead-letters/:id/redeliver", handlers.InternalRedeliverWebhook(webhookDispatcher))
 
 	logging.Info("starting server", "port", cfg.Server.Port)
 	if err := r.Run(":" + cfg.Server.Port); err != nil {
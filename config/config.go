@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -13,6 +14,17 @@ type Config struct {
 	Meilisearch MeilisearchConfig
 	JWT         JWTConfig
 	RateLimit   RateLimitConfig
+	SearchCache SearchCacheConfig
+	Analytics   AnalyticsConfig
+	Popularity  PopularityConfig
+	Spelling    SpellingConfig
+	Webhooks    WebhooksConfig
+	IndexSync   IndexSyncConfig
+	Drift       DriftConfig
+	Projects    ProjectsConfig
+	Invitations InvitationsConfig
+	Sessions    SessionsConfig
+	Operators   OperatorsConfig
 }
 
 type ServerConfig struct {
@@ -28,6 +40,93 @@ type MeilisearchConfig struct {
 	APIKey string
 }
 
+// SearchCacheConfig bounds the tenant search result cache.
+type SearchCacheConfig struct {
+	TTL      time.Duration
+	MaxBytes int64
+}
+
+// AnalyticsConfig bounds the search analytics log: entries older than
+// Retention are pruned, and the table never keeps more than MaxRows.
+type AnalyticsConfig struct {
+	Retention time.Duration
+	MaxRows   int
+}
+
+// PopularityConfig controls the event-driven popularity score: how often it
+// is recomputed, how fast events lose weight (HalfLife), how long they count
+// at all (Window), and whether it is used as a custom ranking rule.
+type PopularityConfig struct {
+	Interval time.Duration
+	HalfLife time.Duration
+	Window   time.Duration
+	Ranking  bool
+}
+
+// SpellingConfig controls "did you mean" suggestions: searches returning
+// fewer than MinHits hits get one, and with AutoCorrect the suggestion is
+// searched instead when it finds more. The vocabularies of at most
+// MaxTenants tenants are kept in memory.
+type SpellingConfig struct {
+	MinHits     int
+	AutoCorrect bool
+	MaxTenants  int
+}
+
+// WebhooksConfig bounds outgoing webhook deliveries: each attempt waits at
+// most Timeout for the receiver, at most Workers attempts run at once, and
+// a delivery is given up after MaxAttempts failed attempts. Receivers on
+// private networks are refused unless AllowPrivateNetworks is set.
+type WebhooksConfig struct {
+	Timeout              time.Duration
+	MaxAttempts          int
+	Workers              int
+	AllowPrivateNetworks bool
+}
+
+// IndexSyncConfig controls the articles index sync outbox: it is drained
+// every Interval, and an intent is dead-lettered after MaxAttempts failed
+// attempts.
+type IndexSyncConfig struct {
+	Interval    time.Duration
+	MaxAttempts int
+}
+
+// DriftConfig schedules the check comparing SQLite articles with the
+// articles index: every Interval (0 disables it), repairing the drift found
+// when Repair is set.
+type DriftConfig struct {
+	Interval time.Duration
+	Repair   bool
+}
+
+// ProjectsConfig caps how many projects a tenant may have, by plan (see
+// middleware.ProjectPlanResolver).
+type ProjectsConfig struct {
+	FreeLimit    int
+	PremiumLimit int
+}
+
+// InvitationsConfig sets how long a tenant invitation can be accepted.
+type InvitationsConfig struct {
+	TTL time.Duration
+}
+
+// SessionsConfig sets how long the cached list of revoked access tokens is
+// trusted before it is reloaded, which bounds how late a revocation made
+// through another server takes effect, and how often expired sessions and
+// tokens are deleted.
+type SessionsConfig struct {
+	RevocationCacheTTL time.Duration
+	PruneInterval      time.Duration
+}
+
+// OperatorsConfig lists the emails of the platform's operators, who alone
+// may change the shared author and tag catalog.
+type OperatorsConfig struct {
+	Emails []string
+}
+
 type JWTConfig struct {
 	SecretKey  string
 	Issuer     string
@@ -37,6 +136,24 @@ type JWTConfig struct {
 
 type RateLimitConfig struct {
 	SearchLimit int
+	Tenant      TenantRateLimitConfig
+	// Store selects where limiter state lives: "memory" (per process) or
+	// "redis" (shared across replicas, at RedisURL). FailOpen decides whether
+	// requests pass while a shared store is unreachable.
+	Store    string
+	RedisURL string
+	FailOpen bool
+}
+
+// TenantRateLimitConfig holds the per-tenant, per-minute limits applied to
+// the internal API, by plan. Names follow CONTRACT.md §6 (FREE/PRO).
+type TenantRateLimitConfig struct {
+	FreeSearchLimit int
+	ProSearchLimit  int
+	FreeIndexLimit  int
+	ProIndexLimit   int
+	FreeEventsLimit int
+	ProEventsLimit  int
 }
 
 func Load() (*Config, error) {
@@ -50,6 +167,11 @@ func Load() (*Config, error) {
 
 	searchLimit := parseInt(os.Getenv("SEARCH_RATE_LIMIT"), 60)
 
+	rateLimitStore := getEnv("RATE_LIMIT_STORE", "memory")
+	if rateLimitStore != "memory" && rateLimitStore != "redis" {
+		return nil, fmt.Errorf("RATE_LIMIT_STORE must be \"memory\" or \"redis\", got %q", rateLimitStore)
+	}
+
 	return &Config{
 		Server: ServerConfig{
 			Port: getEnv("SERVER_PORT", "8081"),
@@ -69,6 +191,64 @@ func Load() (*Config, error) {
 		},
 		RateLimit: RateLimitConfig{
 			SearchLimit: searchLimit,
+			Tenant: TenantRateLimitConfig{
+				FreeSearchLimit: parseInt(os.Getenv("FREE_SEARCH_LIMIT"), 30),
+				ProSearchLimit:  parseInt(os.Getenv("PRO_SEARCH_LIMIT"), 300),
+				FreeIndexLimit:  parseInt(os.Getenv("FREE_INDEX_LIMIT"), 10),
+				ProIndexLimit:   parseInt(os.Getenv("PRO_INDEX_LIMIT"), 100),
+				FreeEventsLimit: parseInt(os.Getenv("FREE_EVENTS_LIMIT"), 30),
+				ProEventsLimit:  parseInt(os.Getenv("PRO_EVENTS_LIMIT"), 300),
+			},
+			Store:    rateLimitStore,
+			RedisURL: getEnv("REDIS_URL", "redis://localhost:6379/0"),
+			FailOpen: parseBool(os.Getenv("RATE_LIMIT_FAIL_OPEN"), true),
+		},
+		SearchCache: SearchCacheConfig{
+			TTL:      parseDuration(os.Getenv("SEARCH_CACHE_TTL"), 30*time.Second),
+			MaxBytes: int64(parseInt(os.Getenv("SEARCH_CACHE_MAX_BYTES"), 64<<20)),
+		},
+		Analytics: AnalyticsConfig{
+			Retention: parseDuration(os.Getenv("SEARCH_ANALYTICS_RETENTION"), 30*24*time.Hour),
+			MaxRows:   parseInt(os.Getenv("SEARCH_ANALYTICS_MAX_ROWS"), 1_000_000),
+		},
+		Popularity: PopularityConfig{
+			Interval: parseDuration(os.Getenv("POPULARITY_INTERVAL"), 5*time.Minute),
+			HalfLife: parseDuration(os.Getenv("POPULARITY_HALF_LIFE"), 7*24*time.Hour),
+			Window:   parseDuration(os.Getenv("POPULARITY_WINDOW"), 30*24*time.Hour),
+			Ranking:  parseBool(os.Getenv("POPULARITY_RANKING"), false),
+		},
+		Spelling: SpellingConfig{
+			MinHits:     parseInt(os.Getenv("SPELLING_MIN_HITS"), 3),
+			AutoCorrect: parseBool(os.Getenv("SPELLING_AUTOCORRECT"), false),
+			MaxTenants:  parseInt(os.Getenv("SPELLING_MAX_TENANTS"), 1000),
+		},
+		Webhooks: WebhooksConfig{
+			Timeout:              parseDuration(os.Getenv("WEBHOOK_TIMEOUT"), 10*time.Second),
+			MaxAttempts:          parseInt(os.Getenv("WEBHOOK_MAX_ATTEMPTS"), 8),
+			Workers:              parseInt(os.Getenv("WEBHOOK_WORKERS"), 8),
+			AllowPrivateNetworks: parseBool(os.Getenv("WEBHOOK_ALLOW_PRIVATE_NETWORKS"), false),
+		},
+		IndexSync: IndexSyncConfig{
+			Interval:    parseDuration(os.Getenv("INDEX_SYNC_INTERVAL"), 5*time.Second),
+			MaxAttempts: parseInt(os.Getenv("INDEX_SYNC_MAX_ATTEMPTS"), 10),
+		},
+		Drift: DriftConfig{
+			Interval: parseDuration(os.Getenv("DRIFT_CHECK_INTERVAL"), 6*time.Hour),
+			Repair:   parseBool(os.Getenv("DRIFT_REPAIR"), false),
+		},
+		Projects: ProjectsConfig{
+			FreeLimit:    parseInt(os.Getenv("FREE_PROJECT_LIMIT"), 3),
+			PremiumLimit: parseInt(os.Getenv("PREMIUM_PROJECT_LIMIT"), 25),
+		},
+		Invitations: InvitationsConfig{
+			TTL: parseDuration(os.Getenv("INVITATION_TTL"), 7*24*time.Hour),
+		},
+		Sessions: SessionsConfig{
+			RevocationCacheTTL: parseDuration(os.Getenv("REVOCATION_CACHE_TTL"), 30*time.Second),
+			PruneInterval:      parseDuration(os.Getenv("SESSION_PRUNE_INTERVAL"), time.Hour),
+		},
+		Operators: OperatorsConfig{
+			Emails: parseList(os.Getenv("OPERATOR_EMAILS")),
 		},
 	}, nil
 }
@@ -90,6 +270,27 @@ func parseInt(value string, defaultValue int) int {
 	return defaultValue
 }
 
+func parseBool(value string, defaultValue bool) bool {
+	if value == "" {
+		return defaultValue
+	}
+	if parsed, err := strconv.ParseBool(value); err == nil {
+		return parsed
+	}
+	return defaultValue
+}
+
+// parseList splits a comma-separated value, dropping empty items.
+func parseList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func parseDuration(value string, defaultValue time.Duration) time.Duration {
 	if value == "" {
 		return defaultValue
@@ -0,0 +1,127 @@
+package adapters
+
+import (
+	"database/sql"
+	"encoding/json"
+	"mini-search-platform/internal/models"
+)
+
+type SQLiteCustomRoleRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteCustomRoleRepository(db *sql.DB) *SQLiteCustomRoleRepository {
+	return &SQLiteCustomRoleRepository{db: db}
+}
+
+const customRoleColumns = `id, tenant_id, name, permissions, created_at, updated_at`
+
+func (r *SQLiteCustomRoleRepository) Save(role *models.CustomRole) error {
+	permissions, err := json.Marshal(role.Permissions)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO custom_roles (` + customRoleColumns + `) VALUES (?, ?, ?, ?, ?, ?)`
+	_, err = r.db.Exec(query, role.ID, role.TenantID, role.Name, string(permissions), role.CreatedAt, role.UpdatedAt)
+	return err
+}
+
+func (r *SQLiteCustomRoleRepository) Update(role *models.CustomRole) error {
+	permissions, err := json.Marshal(role.Permissions)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE custom_roles SET permissions = ?, updated_at = ? WHERE tenant_id = ? AND id = ?`
+	_, err = r.db.Exec(query, string(permissions), role.UpdatedAt, role.TenantID, role.ID)
+	return err
+}
+
+func (r *SQLiteCustomRoleRepository) Delete(tenantID, id string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var name models.Role
+	err = tx.QueryRow(`SELECT name FROM custom_roles WHERE tenant_id = ? AND id = ?`, tenantID, id).Scan(&name)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var assigned int
+	query := `
+		SELECT (SELECT COUNT(*) FROM memberships WHERE tenant_id = ? AND role = ?)
+			+ (SELECT COUNT(*) FROM invitations WHERE tenant_id = ? AND role = ? AND accepted_at IS NULL)
+	`
+	if err := tx.QueryRow(query, tenantID, name, tenantID, name).Scan(&assigned); err != nil {
+		return err
+	}
+	if assigned > 0 {
+		return models.ErrRoleInUse
+	}
+
+	if _, err := tx.Exec(`DELETE FROM custom_roles WHERE tenant_id = ? AND id = ?`, tenantID, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func scanCustomRole(row rowScanner) (*models.CustomRole, error) {
+	var permissions string
+	role := &models.CustomRole{}
+	if err := row.Scan(&role.ID, &role.TenantID, &role.Name, &permissions, &role.CreatedAt, &role.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(permissions), &role.Permissions); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+func (r *SQLiteCustomRoleRepository) FindByID(tenantID, id string) (*models.CustomRole, error) {
+	query := `SELECT ` + customRoleColumns + ` FROM custom_roles WHERE tenant_id = ? AND id = ?`
+	return r.findOne(query, tenantID, id)
+}
+
+func (r *SQLiteCustomRoleRepository) FindByName(tenantID string, name models.Role) (*models.CustomRole, error) {
+	query := `SELECT ` + customRoleColumns + ` FROM custom_roles WHERE tenant_id = ? AND name = ?`
+	return r.findOne(query, tenantID, string(name))
+}
+
+func (r *SQLiteCustomRoleRepository) findOne(query, tenantID, arg string) (*models.CustomRole, error) {
+	role, err := scanCustomRole(r.db.QueryRow(query, tenantID, arg))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+func (r *SQLiteCustomRoleRepository) ListByTenant(tenantID string) ([]*models.CustomRole, error) {
+	query := `SELECT ` + customRoleColumns + ` FROM custom_roles WHERE tenant_id = ? ORDER BY name ASC`
+	rows, err := r.db.Query(query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []*models.CustomRole{}
+	for rows.Next() {
+		role, err := scanCustomRole(rows)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+
+	return roles, rows.Err()
+}
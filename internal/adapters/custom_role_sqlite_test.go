@@ -0,0 +1,55 @@
+package adapters
+
+import (
+	"testing"
+	"time"
+
+	"mini-search-platform/internal/models"
+)
+
+func TestSQLiteCustomRoleRepository_Delete_RefusesAssignedRoles(t *testing.T) {
+	db := newTestDB(t)
+	roles := NewSQLiteCustomRoleRepository(db)
+	memberships := NewSQLiteMembershipRepository(db)
+	invitations := NewSQLiteInvitationRepository(db)
+
+	editor := models.NewCustomRole("role-editor", "acme", "editor", []models.Permission{models.PermDocumentsRead, models.PermDocumentsWrite})
+	if err := roles.Save(editor); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	found, err := roles.FindByName("acme", "editor")
+	if err != nil || found == nil || len(found.Permissions) != 2 || found.Permissions[1] != models.PermDocumentsWrite {
+		t.Fatalf("expected the role with its permissions, got %+v, %v", found, err)
+	}
+	if other, _ := roles.FindByName("globex", "editor"); other != nil {
+		t.Errorf("expected another tenant not to see the role, got %+v", other)
+	}
+
+	if err := memberships.Save(models.NewMembership("m-ada", "ada", "acme", "editor")); err != nil {
+		t.Fatalf("save membership: %v", err)
+	}
+	invitation := models.NewInvitation("inv-1", "acme", "grace@example.com", "editor", "hash", "ada", time.Now().Add(time.Hour))
+	if err := invitations.Save(invitation); err != nil {
+		t.Fatalf("save invitation: %v", err)
+	}
+
+	if err := roles.Delete("acme", editor.ID); err != models.ErrRoleInUse {
+		t.Errorf("expected ErrRoleInUse while a member holds the role, got %v", err)
+	}
+	if err := memberships.UpdateRole("m-ada", models.RoleMember); err != nil {
+		t.Fatalf("UpdateRole failed: %v", err)
+	}
+	if err := roles.Delete("acme", editor.ID); err != models.ErrRoleInUse {
+		t.Errorf("expected ErrRoleInUse while an invitation carries the role, got %v", err)
+	}
+	if err := invitations.Delete("inv-1"); err != nil {
+		t.Fatalf("Delete invitation failed: %v", err)
+	}
+
+	if err := roles.Delete("acme", editor.ID); err != nil {
+		t.Fatalf("expected an unassigned role to be deleted, got %v", err)
+	}
+	if found, _ := roles.FindByID("acme", editor.ID); found != nil {
+		t.Errorf("expected the role gone, got %+v", found)
+	}
+}
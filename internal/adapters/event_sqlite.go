@@ -0,0 +1,168 @@
+package adapters
+
+import (
+	"database/sql"
+	"mini-search-platform/internal/models"
+	"time"
+)
+
+type SQLiteSearchEventRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteSearchEventRepository(db *sql.DB) *SQLiteSearchEventRepository {
+	return &SQLiteSearchEventRepository{db: db}
+}
+
+func (r *SQLiteSearchEventRepository) SaveBatch(events []*models.SearchEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO search_events (
+			id, tenant_id, type, document_id, search_id, query, session_id, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, event := range events {
+		if _, err := stmt.Exec(
+			event.ID,
+			event.TenantID,
+			string(event.Type),
+			event.DocumentID,
+			event.SearchID,
+			event.Query,
+			event.SessionID,
+			event.CreatedAt.UTC().Unix(),
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *SQLiteSearchEventRepository) DailyCounts(since time.Time) ([]*models.DocumentEventCount, error) {
+	query := `
+		SELECT tenant_id, document_id, type, (created_at / 86400) * 86400 AS day, COUNT(*)
+		FROM search_events
+		WHERE created_at >= ?
+		GROUP BY tenant_id, document_id, type, day
+	`
+	rows, err := r.db.Query(query, since.UTC().Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var counts []*models.DocumentEventCount
+	for rows.Next() {
+		var eventType string
+		var day int64
+		count := &models.DocumentEventCount{}
+		if err := rows.Scan(&count.TenantID, &count.DocumentID, &eventType, &day, &count.Count); err != nil {
+			return nil, err
+		}
+		count.Type = models.SearchEventType(eventType)
+		count.Day = time.Unix(day, 0).UTC()
+		counts = append(counts, count)
+	}
+
+	return counts, rows.Err()
+}
+
+func (r *SQLiteSearchEventRepository) Prune(olderThan time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM search_events WHERE created_at < ?`, olderThan.UTC().Unix())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+type SQLiteDocumentPopularityRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteDocumentPopularityRepository(db *sql.DB) *SQLiteDocumentPopularityRepository {
+	return &SQLiteDocumentPopularityRepository{db: db}
+}
+
+func (r *SQLiteDocumentPopularityRepository) ListScored() ([]*models.DocumentPopularity, error) {
+	return r.listScored(`
+		SELECT tenant_id, document_id, score, updated_at
+		FROM document_popularity
+		WHERE score > 0
+	`)
+}
+
+func (r *SQLiteDocumentPopularityRepository) ListScoredByTenant(tenantID string) ([]*models.DocumentPopularity, error) {
+	return r.listScored(`
+		SELECT tenant_id, document_id, score, updated_at
+		FROM document_popularity
+		WHERE tenant_id = ? AND score > 0
+	`, tenantID)
+}
+
+func (r *SQLiteDocumentPopularityRepository) listScored(query string, args ...interface{}) ([]*models.DocumentPopularity, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var scores []*models.DocumentPopularity
+	for rows.Next() {
+		var updatedAt int64
+		score := &models.DocumentPopularity{}
+		if err := rows.Scan(&score.TenantID, &score.DocumentID, &score.Score, &updatedAt); err != nil {
+			return nil, err
+		}
+		score.UpdatedAt = time.Unix(updatedAt, 0).UTC()
+		scores = append(scores, score)
+	}
+
+	return scores, rows.Err()
+}
+
+func (r *SQLiteDocumentPopularityRepository) SaveBatch(scores []*models.DocumentPopularity) error {
+	if len(scores) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, score := range scores {
+		if score.Score <= 0 {
+			_, err = tx.Exec(`DELETE FROM document_popularity WHERE tenant_id = ? AND document_id = ?`,
+				score.TenantID, score.DocumentID)
+		} else {
+			_, err = tx.Exec(`
+				INSERT INTO document_popularity (tenant_id, document_id, score, updated_at)
+				VALUES (?, ?, ?, ?)
+				ON CONFLICT(tenant_id, document_id) DO UPDATE SET
+					score = excluded.score,
+					updated_at = excluded.updated_at
+			`, score.TenantID, score.DocumentID, score.Score, score.UpdatedAt.UTC().Unix())
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,202 @@
+package adapters
+
+import (
+	"database/sql"
+	"fmt"
+	"mini-search-platform/internal/models"
+	"strings"
+	"time"
+)
+
+type SQLiteIndexSyncOutboxRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteIndexSyncOutboxRepository(db *sql.DB) *SQLiteIndexSyncOutboxRepository {
+	return &SQLiteIndexSyncOutboxRepository{db: db}
+}
+
+// enqueueIndexSync records an intent that is due right away. Repositories
+// call it with their transaction, so the intent commits with the change.
+func enqueueIndexSync(db execer, kind models.IndexSyncKind, entityID int) error {
+	now := time.Now().UTC().Unix()
+	_, err := db.Exec(`
+		INSERT INTO index_sync_outbox (kind, entity_id, next_attempt_at, created_at)
+		VALUES (?, ?, ?, ?)
+	`, string(kind), entityID, now, now)
+	return err
+}
+
+// enqueueArticleIndexSync records an intent for an article of the tenant and
+// project, both empty for a shared article.
+func enqueueArticleIndexSync(db execer, articleID int, tenantID, projectID string) error {
+	now := time.Now().UTC().Unix()
+	_, err := db.Exec(`
+		INSERT INTO index_sync_outbox (kind, entity_id, tenant_id, project_id, next_attempt_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, string(models.IndexSyncArticle), articleID, tenantID, projectID, now, now)
+	return err
+}
+
+// enqueueProjectIndexDrop records an intent to drop the index of the
+// tenant's deleted project.
+func enqueueProjectIndexDrop(db execer, tenantID, projectID string) error {
+	now := time.Now().UTC().Unix()
+	_, err := db.Exec(`
+		INSERT INTO index_sync_outbox (kind, entity_id, tenant_id, project_id, next_attempt_at, created_at)
+		VALUES (?, 0, ?, ?, ?, ?)
+	`, string(models.IndexSyncProject), tenantID, projectID, now, now)
+	return err
+}
+
+func (r *SQLiteIndexSyncOutboxRepository) Enqueue(kind models.IndexSyncKind, entityID int) error {
+	return enqueueIndexSync(r.db, kind, entityID)
+}
+
+func (r *SQLiteIndexSyncOutboxRepository) EnqueueTenantArticles(tenantID string) error {
+	now := time.Now().UTC().Unix()
+	_, err := r.db.Exec(`
+		INSERT INTO index_sync_outbox (kind, entity_id, tenant_id, project_id, next_attempt_at, created_at)
+		SELECT ?, id, tenant_id, COALESCE(project_id, ''), ?, ?
+		FROM articles
+		WHERE tenant_id = ?
+	`, string(models.IndexSyncArticle), now, now, tenantID)
+	return err
+}
+
+func (r *SQLiteIndexSyncOutboxRepository) ListDue(now time.Time, limit int) ([]*models.IndexSyncIntent, error) {
+	query := `
+		SELECT id, kind, entity_id, tenant_id, project_id, attempts, last_error, next_attempt_at, created_at
+		FROM index_sync_outbox
+		WHERE next_attempt_at <= ?
+		ORDER BY next_attempt_at, id
+		LIMIT ?
+	`
+	rows, err := r.db.Query(query, now.UTC().Unix(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var intents []*models.IndexSyncIntent
+	for rows.Next() {
+		var kind string
+		var nextAttemptAt, createdAt int64
+		intent := &models.IndexSyncIntent{}
+		if err := rows.Scan(&intent.ID, &kind, &intent.EntityID, &intent.TenantID, &intent.ProjectID, &intent.Attempts, &intent.LastError, &nextAttemptAt, &createdAt); err != nil {
+			return nil, err
+		}
+		intent.Kind = models.IndexSyncKind(kind)
+		intent.NextAttemptAt = time.Unix(nextAttemptAt, 0).UTC()
+		intent.CreatedAt = time.Unix(createdAt, 0).UTC()
+		intents = append(intents, intent)
+	}
+	return intents, rows.Err()
+}
+
+func (r *SQLiteIndexSyncOutboxRepository) Complete(ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	placeholders := strings.Repeat("?,", len(ids)-1) + "?"
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+
+	_, err := r.db.Exec(fmt.Sprintf(`DELETE FROM index_sync_outbox WHERE id IN (%s)`, placeholders), args...)
+	return err
+}
+
+func (r *SQLiteIndexSyncOutboxRepository) Reschedule(intent *models.IndexSyncIntent) error {
+	query := `
+		UPDATE index_sync_outbox
+		SET attempts = ?, last_error = ?, next_attempt_at = ?
+		WHERE id = ?
+	`
+	_, err := r.db.Exec(query, intent.Attempts, intent.LastError, intent.NextAttemptAt.UTC().Unix(), intent.ID)
+	return err
+}
+
+func (r *SQLiteIndexSyncOutboxRepository) DeadLetter(intent *models.IndexSyncIntent) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO index_sync_dead_letters (intent_id, kind, entity_id, tenant_id, project_id, attempts, last_error, created_at, failed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err = tx.Exec(query,
+		intent.ID,
+		string(intent.Kind),
+		intent.EntityID,
+		intent.TenantID,
+		intent.ProjectID,
+		intent.Attempts,
+		intent.LastError,
+		intent.CreatedAt.UTC().Unix(),
+		time.Now().UTC().Unix(),
+	)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM index_sync_outbox WHERE id = ?`, intent.ID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *SQLiteIndexSyncOutboxRepository) ListDeadLetters(limit int) ([]*models.IndexSyncDeadLetter, error) {
+	query := `
+		SELECT id, intent_id, kind, entity_id, tenant_id, project_id, attempts, last_error, created_at, failed_at
+		FROM index_sync_dead_letters
+		ORDER BY failed_at DESC, id DESC
+		LIMIT ?
+	`
+	rows, err := r.db.Query(query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deadLetters := []*models.IndexSyncDeadLetter{}
+	for rows.Next() {
+		var kind string
+		var createdAt, failedAt int64
+		dl := &models.IndexSyncDeadLetter{}
+		if err := rows.Scan(&dl.ID, &dl.IntentID, &kind, &dl.EntityID, &dl.TenantID, &dl.ProjectID, &dl.Attempts, &dl.LastError, &createdAt, &failedAt); err != nil {
+			return nil, err
+		}
+		dl.Kind = models.IndexSyncKind(kind)
+		dl.CreatedAt = time.Unix(createdAt, 0).UTC()
+		dl.FailedAt = time.Unix(failedAt, 0).UTC()
+		deadLetters = append(deadLetters, dl)
+	}
+	return deadLetters, rows.Err()
+}
+
+func (r *SQLiteIndexSyncOutboxRepository) Stats() (models.IndexSyncStats, error) {
+	var stats models.IndexSyncStats
+	var oldest sql.NullInt64
+
+	err := r.db.QueryRow(`
+		SELECT COUNT(*), COALESCE(SUM(attempts > 0), 0), MIN(created_at)
+		FROM index_sync_outbox
+	`).Scan(&stats.Pending, &stats.Retrying, &oldest)
+	if err != nil {
+		return stats, err
+	}
+	if oldest.Valid {
+		t := time.Unix(oldest.Int64, 0).UTC()
+		stats.OldestPendingAt = &t
+	}
+
+	err = r.db.QueryRow(`SELECT COUNT(*) FROM index_sync_dead_letters`).Scan(&stats.DeadLetters)
+	return stats, err
+}
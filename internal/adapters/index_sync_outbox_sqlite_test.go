@@ -0,0 +1,172 @@
+package adapters
+
+import (
+	"testing"
+	"time"
+
+	"mini-search-platform/internal/models"
+)
+
+func TestSQLliteArticleRepository_SaveQueuesIndexSyncIntent(t *testing.T) {
+	db := newTestDB(t)
+	authors := NewSQLliteAuthorsRepository(db)
+	tags := NewSQLliteTagsRepository(db)
+	articles := NewSQLliteArticleRepository(db)
+	outbox := NewSQLiteIndexSyncOutboxRepository(db)
+
+	authorID, err := authors.Save(&models.Author{Name: "Ada"})
+	if err != nil {
+		t.Fatalf("failed to save author: %v", err)
+	}
+	tagID, err := tags.Save(models.NewTag("go"))
+	if err != nil {
+		t.Fatalf("failed to save tag: %v", err)
+	}
+
+	tagged, err := articles.Save(models.NewArticle("Tagged", "body", &models.Author{ID: authorID, Name: "Ada"}, []*models.Tag{{ID: tagID}}))
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	untagged, err := articles.Save(models.NewArticle("Untagged", "body", &models.Author{ID: authorID, Name: "Ada"}, nil))
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	due, err := outbox.ListDue(time.Now().Add(time.Second), 10)
+	if err != nil {
+		t.Fatalf("ListDue failed: %v", err)
+	}
+	if len(due) != 2 || due[0].Kind != models.IndexSyncArticle || due[0].EntityID != tagged || due[1].EntityID != untagged {
+		t.Fatalf("expected an intent per saved article, got %+v", due)
+	}
+
+	found, err := articles.FindByIDs([]int{untagged, tagged, 999})
+	if err != nil {
+		t.Fatalf("FindByIDs failed: %v", err)
+	}
+	if len(found) != 2 || found[0].ID != tagged || len(found[0].Tags) != 1 || found[0].Tags[0].Label != "go" ||
+		found[1].ID != untagged || len(found[1].Tags) != 0 || found[1].Author != "Ada" {
+		t.Errorf("unexpected articles %+v", found)
+	}
+
+	// A failed insert rolls the intent back with the article.
+	if _, err := articles.Save(models.NewArticle("Bad tag", "body", &models.Author{ID: authorID}, []*models.Tag{{ID: tagID}, {ID: tagID}})); err == nil {
+		t.Fatal("expected a duplicate tag to fail the save")
+	}
+	if stats, _ := outbox.Stats(); stats.Pending != 2 {
+		t.Errorf("a rolled back save must not leave an intent, got %d pending", stats.Pending)
+	}
+}
+
+func TestSQLliteTagsRepository_UpdateQueuesIndexSyncIntent(t *testing.T) {
+	db := newTestDB(t)
+	tags := NewSQLliteTagsRepository(db)
+	outbox := NewSQLiteIndexSyncOutboxRepository(db)
+
+	tagID, _ := tags.Save(models.NewTag("go"))
+	otherID, _ := tags.Save(models.NewTag("rust"))
+
+	tag, _ := tags.FindById(tagID)
+	tag.Update("golang")
+	if err := tags.Update(tag); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if renamed, _ := tags.FindById(tagID); renamed.Label != "golang" {
+		t.Errorf("expected the tag renamed in place, got %+v", renamed)
+	}
+
+	due, _ := outbox.ListDue(time.Now().Add(time.Second), 10)
+	if len(due) != 1 || due[0].Kind != models.IndexSyncTag || due[0].EntityID != tagID {
+		t.Fatalf("expected an intent for the renamed tag, got %+v", due)
+	}
+
+	// A rename onto another tag's label rolls the intent back.
+	tag.Update("rust")
+	if err := tags.Update(tag); err == nil {
+		t.Fatalf("expected renaming onto tag %d's label to fail", otherID)
+	}
+	if stats, _ := outbox.Stats(); stats.Pending != 1 {
+		t.Errorf("a rolled back rename must not leave an intent, got %d pending", stats.Pending)
+	}
+}
+
+func TestIndexSyncOutboxRepository_EnqueueTenantArticles(t *testing.T) {
+	db := newTestDB(t)
+	outbox := NewSQLiteIndexSyncOutboxRepository(db)
+
+	for _, article := range [][]interface{}{{1, "acme", nil}, {2, "acme", "docs"}, {3, "globex", nil}, {4, nil, nil}} {
+		if _, err := db.Exec(`INSERT INTO articles (id, title, body, author_id, tenant_id, project_id) VALUES (?, 't', 'b', 1, ?, ?)`, article...); err != nil {
+			t.Fatalf("failed to insert article: %v", err)
+		}
+	}
+
+	if err := outbox.EnqueueTenantArticles("acme"); err != nil {
+		t.Fatalf("EnqueueTenantArticles failed: %v", err)
+	}
+
+	due, err := outbox.ListDue(time.Now().Add(time.Second), 10)
+	if err != nil {
+		t.Fatalf("ListDue failed: %v", err)
+	}
+	if len(due) != 2 || due[0].EntityID != 1 || due[0].TenantID != "acme" || due[0].ProjectID != "" ||
+		due[1].EntityID != 2 || due[1].ProjectID != "docs" || due[1].Kind != models.IndexSyncArticle {
+		t.Errorf("expected an intent per article of the tenant, got %+v", due)
+	}
+}
+
+func TestIndexSyncOutboxRepository_RescheduleAndDeadLetter(t *testing.T) {
+	db := newTestDB(t)
+	outbox := NewSQLiteIndexSyncOutboxRepository(db)
+
+	if err := outbox.Enqueue(models.IndexSyncTag, 7); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	if err := outbox.Enqueue(models.IndexSyncArticle, 1); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+
+	now := time.Now().Add(time.Second)
+	due, _ := outbox.ListDue(now, 10)
+	if len(due) != 2 {
+		t.Fatalf("expected 2 due intents, got %d", len(due))
+	}
+
+	retrying := due[0]
+	retrying.Attempts = 1
+	retrying.LastError = "meilisearch unavailable"
+	retrying.NextAttemptAt = now.Add(time.Minute)
+	if err := outbox.Reschedule(retrying); err != nil {
+		t.Fatalf("Reschedule failed: %v", err)
+	}
+	if waiting, _ := outbox.ListDue(now, 10); len(waiting) != 1 || waiting[0].ID != due[1].ID {
+		t.Fatalf("a rescheduled intent must wait, got %+v", waiting)
+	}
+
+	stats, err := outbox.Stats()
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	if stats.Pending != 2 || stats.Retrying != 1 || stats.OldestPendingAt == nil || stats.DeadLetters != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	retrying.Attempts = 2
+	if err := outbox.DeadLetter(retrying); err != nil {
+		t.Fatalf("DeadLetter failed: %v", err)
+	}
+	deadLetters, err := outbox.ListDeadLetters(10)
+	if err != nil {
+		t.Fatalf("ListDeadLetters failed: %v", err)
+	}
+	if len(deadLetters) != 1 || deadLetters[0].IntentID != retrying.ID || deadLetters[0].Kind != models.IndexSyncTag ||
+		deadLetters[0].EntityID != 7 || deadLetters[0].Attempts != 2 || deadLetters[0].LastError != "meilisearch unavailable" {
+		t.Errorf("unexpected dead letters %+v", deadLetters)
+	}
+
+	if err := outbox.Complete([]int64{due[1].ID}); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if stats, _ := outbox.Stats(); stats.Pending != 0 || stats.OldestPendingAt != nil || stats.DeadLetters != 1 {
+		t.Errorf("unexpected stats after draining %+v", stats)
+	}
+}
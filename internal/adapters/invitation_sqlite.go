@@ -0,0 +1,147 @@
+package adapters
+
+import (
+	"database/sql"
+	"mini-search-platform/internal/models"
+	"time"
+)
+
+type SQLiteInvitationRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteInvitationRepository(db *sql.DB) *SQLiteInvitationRepository {
+	return &SQLiteInvitationRepository{db: db}
+}
+
+const invitationColumns = `id, tenant_id, email, role, token_hash, invited_by, expires_at, accepted_at, created_at`
+
+func scanInvitation(row rowScanner) (*models.Invitation, error) {
+	invitation := &models.Invitation{}
+	var acceptedAt sql.NullTime
+	err := row.Scan(&invitation.ID, &invitation.TenantID, &invitation.Email, &invitation.Role,
+		&invitation.TokenHash, &invitation.InvitedBy, &invitation.ExpiresAt, &acceptedAt, &invitation.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if acceptedAt.Valid {
+		invitation.AcceptedAt = &acceptedAt.Time
+	}
+	return invitation, nil
+}
+
+func (r *SQLiteInvitationRepository) Save(invitation *models.Invitation) error {
+	query := `INSERT INTO invitations (` + invitationColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := r.db.Exec(query, invitation.ID, invitation.TenantID, invitation.Email, invitation.Role,
+		invitation.TokenHash, invitation.InvitedBy, invitation.ExpiresAt, invitation.AcceptedAt, invitation.CreatedAt)
+	return err
+}
+
+func (r *SQLiteInvitationRepository) FindByID(id string) (*models.Invitation, error) {
+	return r.findOne(`SELECT `+invitationColumns+` FROM invitations WHERE id = ?`, id)
+}
+
+func (r *SQLiteInvitationRepository) FindByTokenHash(tokenHash string) (*models.Invitation, error) {
+	return r.findOne(`SELECT `+invitationColumns+` FROM invitations WHERE token_hash = ?`, tokenHash)
+}
+
+func (r *SQLiteInvitationRepository) findOne(query string, arg string) (*models.Invitation, error) {
+	invitation, err := scanInvitation(r.db.QueryRow(query, arg))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+func (r *SQLiteInvitationRepository) ListPendingByTenant(tenantID string) ([]*models.Invitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM invitations
+		WHERE tenant_id = ? AND accepted_at IS NULL
+		ORDER BY created_at DESC`
+	rows, err := r.db.Query(query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invitations []*models.Invitation
+	for rows.Next() {
+		invitation, err := scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, invitation)
+	}
+
+	return invitations, rows.Err()
+}
+
+func (r *SQLiteInvitationRepository) Delete(id string) error {
+	query := `DELETE FROM invitations WHERE id = ?`
+	_, err := r.db.Exec(query, id)
+	return err
+}
+
+func (r *SQLiteInvitationRepository) Accept(id string, membership *models.Membership) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := accept(tx, id, membership); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *SQLiteInvitationRepository) AcceptAsNewUser(id string, user *models.User, membership *models.Membership) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO users (id, email, password_hash, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`
+	if _, err := tx.Exec(query, user.ID, user.Email, user.PasswordHash, user.CreatedAt, user.UpdatedAt); err != nil {
+		return err
+	}
+	if err := accept(tx, id, membership); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// accept claims the invitation and saves the membership within tx.
+func accept(tx *sql.Tx, id string, membership *models.Membership) error {
+	// Claiming the invitation first makes it single-use: of two concurrent
+	// accepts, only one updates the row.
+	result, err := tx.Exec(`UPDATE invitations SET accepted_at = ? WHERE id = ? AND accepted_at IS NULL`, time.Now(), id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return models.ErrInvitationAccepted
+	}
+
+	var existing int
+	query := `SELECT COUNT(*) FROM memberships WHERE user_id = ? AND tenant_id = ?`
+	if err := tx.QueryRow(query, membership.UserID, membership.TenantID).Scan(&existing); err != nil {
+		return err
+	}
+	if existing > 0 {
+		return models.ErrAlreadyMember
+	}
+
+	query = `INSERT INTO memberships (id, user_id, tenant_id, role, created_at) VALUES (?, ?, ?, ?, ?)`
+	_, err = tx.Exec(query, membership.ID, membership.UserID, membership.TenantID, membership.Role, membership.CreatedAt)
+	return err
+}
@@ -0,0 +1,137 @@
+package adapters
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"mini-search-platform/internal/models"
+)
+
+func TestSQLiteInvitationRepository_Accept_IsSingleUse(t *testing.T) {
+	db := newTestDB(t)
+	invitations := NewSQLiteInvitationRepository(db)
+	memberships := NewSQLiteMembershipRepository(db)
+
+	invitation := models.NewInvitation("inv-1", "acme", "ada@example.com", models.RoleBilling, "hash", "owner", time.Now().Add(time.Hour))
+	if err := invitations.Save(invitation); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	found, err := invitations.FindByTokenHash("hash")
+	if err != nil || found == nil || found.ID != "inv-1" || found.AcceptedAt != nil {
+		t.Fatalf("expected the pending invitation by its token hash, got %+v, %v", found, err)
+	}
+
+	if err := invitations.Accept("inv-1", models.NewMembership("m-1", "ada", "acme", models.RoleBilling)); err != nil {
+		t.Fatalf("Accept failed: %v", err)
+	}
+	membership, err := memberships.FindByUserAndTenant("ada", "acme")
+	if err != nil || membership == nil || membership.Role != models.RoleBilling {
+		t.Errorf("expected ada to be a billing member of acme, got %+v, %v", membership, err)
+	}
+	if found, _ := invitations.FindByID("inv-1"); found == nil || found.AcceptedAt == nil {
+		t.Errorf("expected the invitation to be marked accepted, got %+v", found)
+	}
+	if pending, _ := invitations.ListPendingByTenant("acme"); len(pending) != 0 {
+		t.Errorf("expected no pending invitations, got %+v", pending)
+	}
+
+	err = invitations.Accept("inv-1", models.NewMembership("m-2", "grace", "acme", models.RoleBilling))
+	if err != models.ErrInvitationAccepted {
+		t.Errorf("expected ErrInvitationAccepted reusing the invitation, got %v", err)
+	}
+	if membership, _ := memberships.FindByUserAndTenant("grace", "acme"); membership != nil {
+		t.Errorf("expected no membership from a reused invitation, got %+v", membership)
+	}
+}
+
+func TestSQLiteInvitationRepository_Accept_RefusesExistingMembers(t *testing.T) {
+	db := newTestDB(t)
+	invitations := NewSQLiteInvitationRepository(db)
+	memberships := NewSQLiteMembershipRepository(db)
+
+	if err := memberships.Save(models.NewMembership("m-1", "ada", "acme", models.RoleMember)); err != nil {
+		t.Fatalf("save membership: %v", err)
+	}
+	invitation := models.NewInvitation("inv-1", "acme", "ada@example.com", models.RoleAdmin, "hash", "owner", time.Now().Add(time.Hour))
+	if err := invitations.Save(invitation); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	err := invitations.Accept("inv-1", models.NewMembership("m-2", "ada", "acme", models.RoleAdmin))
+	if err != models.ErrAlreadyMember {
+		t.Fatalf("expected ErrAlreadyMember, got %v", err)
+	}
+	// The failed accept rolled back, leaving the invitation usable.
+	if found, _ := invitations.FindByID("inv-1"); found == nil || found.AcceptedAt != nil {
+		t.Errorf("expected the invitation still pending, got %+v", found)
+	}
+}
+
+func TestSQLiteMembershipRepository_KeepsTheLastAdmin(t *testing.T) {
+	db := newTestDB(t)
+	memberships := NewSQLiteMembershipRepository(db)
+
+	for _, m := range []*models.Membership{
+		models.NewMembership("m-ada", "ada", "acme", models.RoleAdmin),
+		models.NewMembership("m-grace", "grace", "acme", models.RoleAdmin),
+		models.NewMembership("m-linus", "linus", "acme", models.RoleMember),
+		models.NewMembership("m-other", "ada", "globex", models.RoleAdmin),
+	} {
+		if err := memberships.Save(m); err != nil {
+			t.Fatalf("save membership: %v", err)
+		}
+	}
+
+	if err := memberships.UpdateRole("m-grace", models.RoleMember); err != nil {
+		t.Fatalf("expected demoting one of two admins to succeed, got %v", err)
+	}
+	if err := memberships.UpdateRole("m-ada", models.RoleBilling); err != models.ErrLastAdmin {
+		t.Errorf("expected ErrLastAdmin demoting the last admin, got %v", err)
+	}
+	if err := memberships.Delete("m-ada"); err != models.ErrLastAdmin {
+		t.Errorf("expected ErrLastAdmin removing the last admin, got %v", err)
+	}
+	if m, _ := memberships.FindByID("m-ada"); m == nil || m.Role != models.RoleAdmin {
+		t.Errorf("expected the last admin untouched, got %+v", m)
+	}
+
+	if err := memberships.UpdateRole("m-ada", models.RoleAdmin); err != nil {
+		t.Errorf("expected keeping the last admin an admin to succeed, got %v", err)
+	}
+	if err := memberships.Delete("m-linus"); err != nil {
+		t.Errorf("expected removing a member to succeed, got %v", err)
+	}
+	if err := memberships.Delete("m-linus"); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows removing a missing member, got %v", err)
+	}
+}
+
+func TestSQLiteInvitationRepository_AcceptAsNewUser_SavesTheUserOnlyWithTheMembership(t *testing.T) {
+	db := newTestDB(t)
+	invitations := NewSQLiteInvitationRepository(db)
+	users := NewSQLiteUserRepository(db)
+
+	invitation := models.NewInvitation("inv-1", "acme", "ada@example.com", models.RoleMember, "hash", "owner", time.Now().Add(time.Hour))
+	if err := invitations.Save(invitation); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	ada := models.NewUser("ada", "ada@example.com", "secret")
+	if err := invitations.AcceptAsNewUser("inv-1", ada, models.NewMembership("m-1", "ada", "acme", models.RoleMember)); err != nil {
+		t.Fatalf("AcceptAsNewUser failed: %v", err)
+	}
+	if user, _ := users.FindByID("ada"); user == nil {
+		t.Error("expected the new user saved")
+	}
+
+	// A failed accept rolls the new user back.
+	grace := models.NewUser("grace", "grace@example.com", "secret")
+	err := invitations.AcceptAsNewUser("inv-1", grace, models.NewMembership("m-2", "grace", "acme", models.RoleMember))
+	if err != models.ErrInvitationAccepted {
+		t.Fatalf("expected ErrInvitationAccepted, got %v", err)
+	}
+	if user, _ := users.FindByID("grace"); user != nil {
+		t.Errorf("expected no user left from a failed accept, got %+v", user)
+	}
+}
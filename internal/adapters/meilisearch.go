@@ -1,13 +1,16 @@
 package adapters
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"mini-search-platform/internal/models"
 	"mini-search-platform/internal/search"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/meilisearch/meilisearch-go"
 )
@@ -25,14 +28,20 @@ var (
 // by default without being searchable/filterable.
 var (
 	tenantSearchableAttrs = []string{"title", "body", "author", "tags", "brand", "category"}
-	tenantFilterableAttrs = []interface{}{"author", "tags", "brand", "category", "price"}
-	tenantSortableAttrs   = []string{"author", "title", "price"}
+	// id is filterable so merchandising rules can hide and pin documents by
+	// ID (`id IN [...]`).
+	tenantFilterableAttrs = []interface{}{"id", "author", "tags", "brand", "category", "price"}
+	tenantSortableAttrs   = []string{"author", "title", "price", search.PopularityAttribute}
 	// tenantRankingRules move "sort" ahead of the relevancy rules (Meilisearch's
 	// default is words,typo,proximity,attribute,sort,exactness). With "sort"
 	// first, an explicit sort (e.g. price:asc) orders results globally rather
 	// than only breaking ties within equal-relevance groups; queries that don't
 	// request a sort are unaffected (the sort rule is inert without one).
 	tenantRankingRules = []string{"sort", "words", "typo", "proximity", "attribute", "exactness"}
+	// popularityRankingRule is appended to tenantRankingRules when popularity
+	// ranking is enabled: among equally relevant hits, the more popular
+	// document wins. Documents with no score yet rank after scored ones.
+	popularityRankingRule = search.PopularityAttribute + ":desc"
 )
 
 type MeilisearchEngine struct {
@@ -42,6 +51,13 @@ type MeilisearchEngine struct {
 	// that perform the actual initialization — see tenantIndex.
 	mu         sync.Mutex
 	tenantInit map[string]*sync.Once
+
+	// rankingRules overrides tenantRankingRules when set; see
+	// EnablePopularityRanking.
+	rankingRules []string
+
+	// tasks tracks enqueued tenant tasks for WatchTasks.
+	tasks taskTracker
 }
 
 func Init(host string, apiKey string) *MeilisearchEngine {
@@ -88,7 +104,45 @@ func Init(host string, apiKey string) *MeilisearchEngine {
 }
 
 func (e *MeilisearchEngine) IndexArticles(articles []*models.Article) error {
-	_, err := e.Index.AddDocuments(articles, nil)
+	_, err := e.Index.AddDocuments(search.ArticleDocuments(articles), nil)
+	return err
+}
+
+// ListArticleDocuments pages through the articles index in the engine's
+// storage order, returning the page and the index's document count.
+func (e *MeilisearchEngine) ListArticleDocuments(offset, limit int) ([]*models.Article, int64, error) {
+	var result meilisearch.DocumentsResult
+	err := e.Index.GetDocuments(&meilisearch.DocumentsQuery{
+		Offset: int64(offset),
+		Limit:  int64(limit),
+	}, &result)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	docsJSON, err := json.Marshal(result.Results)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var articles []*models.Article
+	if err := json.Unmarshal(docsJSON, &articles); err != nil {
+		return nil, 0, err
+	}
+	return articles, result.Total, nil
+}
+
+// DeleteArticles removes the articles' documents from the articles index.
+func (e *MeilisearchEngine) DeleteArticles(ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	identifiers := make([]string, len(ids))
+	for i, id := range ids {
+		identifiers[i] = strconv.Itoa(id)
+	}
+	_, err := e.Index.DeleteDocuments(identifiers)
 	return err
 }
 
@@ -97,12 +151,17 @@ func NewMeilisearchEngine(index meilisearch.IndexManager) *MeilisearchEngine {
 }
 
 func (e *MeilisearchEngine) Search(query string, options search.SearchOptions) (search.SearchResponse, error) {
-	result, err := e.Index.Search(query, &meilisearch.SearchRequest{
+	req := &meilisearch.SearchRequest{
 		Limit:  int64(options.Limit),
 		Offset: int64(options.Offset),
 		Filter: options.Filter,
 		Sort:   options.Sort,
-	})
+	}
+	if options.Facets != "" {
+		req.Facets = splitAndTrim(options.Facets)
+	}
+
+	result, err := e.Index.Search(query, req)
 	if err != nil {
 		return search.SearchResponse{
 			Query: query,
@@ -125,14 +184,23 @@ func (e *MeilisearchEngine) Search(query string, options search.SearchOptions) (
 	}
 
 	return search.SearchResponse{
-		Hits:   articles,
-		Offset: int(result.Offset),
-		Limit:  int(result.Limit),
-		Total:  int(result.EstimatedTotalHits),
-		Query:  result.Query,
+		Hits:              articles,
+		Offset:            int(result.Offset),
+		Limit:             int(result.Limit),
+		Total:             int(result.EstimatedTotalHits),
+		Query:             result.Query,
+		FacetDistribution: convertFacetDistribution(result.FacetDistribution),
 	}, nil
 }
 
+// EnablePopularityRanking adds the popularity custom ranking rule to every
+// tenant index this engine initializes. Settings are applied on each index's
+// first write per process, so existing indexes pick the rule up after a
+// restart. Call before serving traffic.
+func (e *MeilisearchEngine) EnablePopularityRanking() {
+	e.rankingRules = append(append([]string{}, tenantRankingRules...), popularityRankingRule)
+}
+
 // tenantIndex lazily initializes (searchable/filterable/sortable attributes)
 // and returns the Meilisearch index for a given tenant. Index creation and
 // settings updates are idempotent (see isIndexAlreadyExists), so it is safe
@@ -141,14 +209,27 @@ func (e *MeilisearchEngine) Search(query string, options search.SearchOptions) (
 // requests for the *same* tenant don't race, while requests for *different*
 // tenants are never serialized behind each other's network calls.
 func (e *MeilisearchEngine) tenantIndex(tenantID string) (meilisearch.IndexManager, error) {
-	indexName := search.TenantIndexName(tenantID)
+	return e.lazyIndex(tenantID, search.TenantIndexName(tenantID))
+}
+
+// projectIndex is tenantIndex for one of the tenant's projects' indexes,
+// which are configured like the tenant's.
+func (e *MeilisearchEngine) projectIndex(tenantID, projectID string) (meilisearch.IndexManager, error) {
+	return e.lazyIndex(tenantID, search.ProjectIndexName(tenantID, projectID))
+}
+
+// lazyIndex initializes the tenant's index named indexName once per process,
+// tracking its settings task for the tenant; see tenantIndex.
+func (e *MeilisearchEngine) lazyIndex(tenantID, indexName string) (meilisearch.IndexManager, error) {
 	idx := Client.Index(indexName)
 
 	once := e.tenantOnce(indexName)
 
 	var initErr error
 	once.Do(func() {
-		initErr = initTenantIndex(idx, indexName)
+		var settingsTask *meilisearch.TaskInfo
+		settingsTask, initErr = initTenantIndex(idx, indexName, e.tenantRankingRules())
+		e.trackTask(tenantID, search.TaskSettings, settingsTask)
 	})
 	if initErr != nil {
 		// Allow a future call to retry initialization instead of caching
@@ -182,32 +263,37 @@ func (e *MeilisearchEngine) tenantOnce(indexName string) *sync.Once {
 	return once
 }
 
+func (e *MeilisearchEngine) tenantRankingRules() []string {
+	if e.rankingRules != nil {
+		return e.rankingRules
+	}
+	return tenantRankingRules
+}
+
 // initTenantIndex creates (if needed) and configures a tenant's index.
 // Meilisearch instances backed by persistent storage will return an
 // "index_already_exists" (409) error for CreateIndex after a process
 // restart, since the index survives; that error is expected and non-fatal.
-func initTenantIndex(idx meilisearch.IndexManager, indexName string) error {
+// It returns the last settings task: an index's tasks run in order, so the
+// settings are in place once that one finishes.
+func initTenantIndex(idx meilisearch.IndexManager, indexName string, rankingRules []string) (*meilisearch.TaskInfo, error) {
 	if _, err := Client.CreateIndex(&meilisearch.IndexConfig{
 		Uid:        indexName,
 		PrimaryKey: "id",
 	}); err != nil && !isIndexAlreadyExists(err) {
-		return err
+		return nil, err
 	}
 
 	if _, err := idx.UpdateSearchableAttributes(&tenantSearchableAttrs); err != nil {
-		return err
+		return nil, err
 	}
 	if _, err := idx.UpdateFilterableAttributes(&tenantFilterableAttrs); err != nil {
-		return err
+		return nil, err
 	}
 	if _, err := idx.UpdateSortableAttributes(&tenantSortableAttrs); err != nil {
-		return err
+		return nil, err
 	}
-	if _, err := idx.UpdateRankingRules(&tenantRankingRules); err != nil {
-		return err
-	}
-
-	return nil
+	return idx.UpdateRankingRules(&rankingRules)
 }
 
 // isIndexAlreadyExists reports whether err is Meilisearch's response to
@@ -237,6 +323,16 @@ func isIndexNotFound(err error) bool {
 		meiliErr.MeilisearchApiError.Code == "index_not_found"
 }
 
+// isNotFound reports whether err is any Meilisearch 404, e.g.
+// "document_not_found" as well as "index_not_found".
+func isNotFound(err error) bool {
+	var meiliErr *meilisearch.Error
+	if !errors.As(err, &meiliErr) {
+		return false
+	}
+	return meiliErr.StatusCode == http.StatusNotFound
+}
+
 // IndexTenantDocuments indexes documents into the tenant's isolated index,
 // lazily creating/configuring it on first use.
 func (e *MeilisearchEngine) IndexTenantDocuments(tenantID string, documents []search.TenantDocument) error {
@@ -250,8 +346,12 @@ func (e *MeilisearchEngine) IndexTenantDocuments(tenantID string, documents []se
 		docs[i] = d
 	}
 
-	_, err = idx.AddDocuments(docs, nil)
-	return err
+	task, err := idx.AddDocuments(docs, nil)
+	if err != nil {
+		return err
+	}
+	e.trackTask(tenantID, search.TaskDocuments, task)
+	return nil
 }
 
 // DeleteAllTenantDocuments clears every document from the tenant's isolated
@@ -264,7 +364,179 @@ func (e *MeilisearchEngine) DeleteAllTenantDocuments(tenantID string) error {
 	if err != nil {
 		return err
 	}
-	_, err = idx.DeleteAllDocuments()
+	task, err := idx.DeleteAllDocuments()
+	if err != nil {
+		return err
+	}
+	e.trackTask(tenantID, search.TaskReset, task)
+	return nil
+}
+
+// DeleteTenantIndex drops the tenant's isolated index and forgets its cached
+// initialization, so a tenant re-created under the same ID starts from a
+// freshly configured index instead of assuming one that no longer exists.
+// Deleting an index that was never created is not an error.
+func (e *MeilisearchEngine) DeleteTenantIndex(tenantID string) (int64, error) {
+	indexName := search.TenantIndexName(tenantID)
+
+	task, err := Client.DeleteIndex(indexName)
+	if err != nil && !isIndexNotFound(err) {
+		return 0, err
+	}
+
+	e.mu.Lock()
+	delete(e.tenantInit, indexName)
+	e.mu.Unlock()
+
+	if task == nil {
+		return 0, nil
+	}
+	return task.TaskUID, nil
+}
+
+// DeleteTenantDocuments removes the documents from the tenant's index. For
+// a tenant with no index, the deletion task fails in the engine, which
+// leaves nothing to remove either way.
+func (e *MeilisearchEngine) DeleteTenantDocuments(tenantID string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := Client.Index(search.TenantIndexName(tenantID)).DeleteDocuments(ids)
+	if isIndexNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// IndexProjectDocuments indexes documents into the project's index, lazily
+// creating/configuring it on first use.
+func (e *MeilisearchEngine) IndexProjectDocuments(tenantID, projectID string, documents []search.TenantDocument) error {
+	idx, err := e.projectIndex(tenantID, projectID)
+	if err != nil {
+		return err
+	}
+
+	docs := make([]interface{}, len(documents))
+	for i, d := range documents {
+		docs[i] = d
+	}
+
+	task, err := idx.AddDocuments(docs, nil)
+	if err != nil {
+		return err
+	}
+	e.trackTask(tenantID, search.TaskDocuments, task)
+	return nil
+}
+
+// DeleteProjectDocuments removes the documents from the project's index,
+// like DeleteTenantDocuments.
+func (e *MeilisearchEngine) DeleteProjectDocuments(tenantID, projectID string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := Client.Index(search.ProjectIndexName(tenantID, projectID)).DeleteDocuments(ids)
+	if isIndexNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// DeleteProjectIndex drops the project's index and forgets its cached
+// initialization, like DeleteTenantIndex.
+func (e *MeilisearchEngine) DeleteProjectIndex(tenantID, projectID string) (int64, error) {
+	indexName := search.ProjectIndexName(tenantID, projectID)
+
+	task, err := Client.DeleteIndex(indexName)
+	if err != nil && !isIndexNotFound(err) {
+		return 0, err
+	}
+
+	e.mu.Lock()
+	delete(e.tenantInit, indexName)
+	e.mu.Unlock()
+
+	if task == nil {
+		return 0, nil
+	}
+	return task.TaskUID, nil
+}
+
+// PatchTenantDocuments merges each patch's fields into the existing document
+// with the same id. Meilisearch's partial update would otherwise create a
+// document from a patch whose id is unknown, so each id is looked up first
+// and patches for documents that are gone (or a tenant with no index) are
+// skipped. That costs one lookup per patch, which is acceptable for the
+// background jobs that call this.
+func (e *MeilisearchEngine) PatchTenantDocuments(tenantID string, patches []search.TenantDocument) error {
+	indexName := search.TenantIndexName(tenantID)
+	lookup := Client.Index(indexName)
+
+	existing := make([]interface{}, 0, len(patches))
+	for _, patch := range patches {
+		id, ok := patch["id"]
+		if !ok {
+			continue
+		}
+
+		var doc map[string]interface{}
+		err := lookup.GetDocument(search.DocumentID(id), &meilisearch.DocumentQuery{Fields: []string{"id"}}, &doc)
+		if err != nil {
+			if isNotFound(err) {
+				continue
+			}
+			return err
+		}
+		existing = append(existing, patch)
+	}
+	if len(existing) == 0 {
+		return nil
+	}
+
+	idx, err := e.tenantIndex(tenantID)
+	if err != nil {
+		return err
+	}
+	_, err = idx.UpdateDocuments(existing, nil)
+	return err
+}
+
+// WaitForTenantTasks waits for the newest enqueued or processing task of the
+// tenant's index. Meilisearch processes an index's tasks in order, so every
+// earlier write is done by then too.
+func (e *MeilisearchEngine) WaitForTenantTasks(ctx context.Context, tenantID string) error {
+	return waitForIndexTasks(ctx, search.TenantIndexName(tenantID))
+}
+
+// WaitForArticleTasks is WaitForTenantTasks for the public articles index.
+func (e *MeilisearchEngine) WaitForArticleTasks(ctx context.Context) error {
+	return waitForIndexTasks(ctx, search.ARTICLES_INDEX_NAME)
+}
+
+// CountArticles returns how many documents the articles index holds. Pending
+// writes are not counted until processed; see WaitForArticleTasks.
+func (e *MeilisearchEngine) CountArticles() (int64, error) {
+	stats, err := e.Index.GetStats()
+	if err != nil {
+		return 0, err
+	}
+	return stats.NumberOfDocuments, nil
+}
+
+func waitForIndexTasks(ctx context.Context, indexUID string) error {
+	tasks, err := Client.GetTasksWithContext(ctx, &meilisearch.TasksQuery{
+		IndexUIDS: []string{indexUID},
+		Statuses:  []meilisearch.TaskStatus{meilisearch.TaskStatusEnqueued, meilisearch.TaskStatusProcessing},
+		Limit:     1,
+	})
+	if err != nil {
+		return err
+	}
+	if len(tasks.Results) == 0 {
+		return nil
+	}
+
+	_, err = Client.WaitForTaskWithContext(ctx, tasks.Results[0].UID, 100*time.Millisecond)
 	return err
 }
 
@@ -275,7 +547,18 @@ func (e *MeilisearchEngine) DeleteAllTenantDocuments(tenantID string) error {
 // that must read back as zero results (not an error, and not a
 // side-effecting index creation on a read path).
 func (e *MeilisearchEngine) SearchTenant(tenantID string, query string, options search.SearchOptions) (search.TenantSearchResponse, error) {
-	indexName := search.TenantIndexName(tenantID)
+	return searchIndex(search.TenantIndexName(tenantID), query, options)
+}
+
+// SearchProject searches the project's index like SearchTenant does the
+// tenant's.
+func (e *MeilisearchEngine) SearchProject(tenantID, projectID string, query string, options search.SearchOptions) (search.TenantSearchResponse, error) {
+	return searchIndex(search.ProjectIndexName(tenantID, projectID), query, options)
+}
+
+// searchIndex searches one of the isolated indexes. An index that does not
+// exist yet has no hits.
+func searchIndex(indexName string, query string, options search.SearchOptions) (search.TenantSearchResponse, error) {
 	idx := Client.Index(indexName)
 
 	req := &meilisearch.SearchRequest{
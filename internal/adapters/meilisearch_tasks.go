@@ -0,0 +1,123 @@
+package adapters
+
+import (
+	"sync"
+	"time"
+
+	"mini-search-platform/internal/search"
+	"mini-search-platform/pkg/logging"
+
+	"github.com/meilisearch/meilisearch-go"
+)
+
+const (
+	// maxTrackedTasks bounds the tasks awaiting completion, in case
+	// Meilisearch stops answering task queries for a long time.
+	maxTrackedTasks = 10000
+	taskPollBatch   = 100
+)
+
+// taskTracker remembers the tenant tasks this process enqueued until
+// WatchTasks sees them finish. Nothing is tracked until WatchTasks is
+// called, and tracked tasks are lost on restart.
+type taskTracker struct {
+	mu      sync.Mutex
+	handle  func(search.TaskOutcome)
+	pending map[int64]trackedTask
+}
+
+type trackedTask struct {
+	tenantID string
+	kind     search.TaskKind
+}
+
+// WatchTasks polls Meilisearch every interval for the tenant tasks enqueued
+// since (documents, resets and settings updates) and calls handle once for
+// each that finished, from a single goroutine. Call before serving traffic.
+func (e *MeilisearchEngine) WatchTasks(interval time.Duration, handle func(search.TaskOutcome)) {
+	e.tasks.mu.Lock()
+	e.tasks.handle = handle
+	e.tasks.pending = make(map[int64]trackedTask)
+	e.tasks.mu.Unlock()
+
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			if err := e.pollTasks(); err != nil {
+				logging.Error("failed to poll meilisearch tasks", "error", err)
+			}
+		}
+	}()
+}
+
+func (e *MeilisearchEngine) trackTask(tenantID string, kind search.TaskKind, info *meilisearch.TaskInfo) {
+	if info == nil {
+		return
+	}
+
+	e.tasks.mu.Lock()
+	defer e.tasks.mu.Unlock()
+	if e.tasks.handle == nil {
+		return
+	}
+	if len(e.tasks.pending) >= maxTrackedTasks {
+		logging.Warn("too many meilisearch tasks awaiting completion, not tracking",
+			"tenant_id", tenantID, "task_uid", info.TaskUID)
+		return
+	}
+	e.tasks.pending[info.TaskUID] = trackedTask{tenantID: tenantID, kind: kind}
+}
+
+func (e *MeilisearchEngine) pollTasks() error {
+	e.tasks.mu.Lock()
+	uids := make([]int64, 0, min(len(e.tasks.pending), taskPollBatch))
+	for uid := range e.tasks.pending {
+		if len(uids) == taskPollBatch {
+			break
+		}
+		uids = append(uids, uid)
+	}
+	e.tasks.mu.Unlock()
+	if len(uids) == 0 {
+		return nil
+	}
+
+	result, err := Client.GetTasks(&meilisearch.TasksQuery{
+		UIDS:     uids,
+		Statuses: []meilisearch.TaskStatus{meilisearch.TaskStatusSucceeded, meilisearch.TaskStatusFailed, meilisearch.TaskStatusCanceled},
+		Limit:    int64(len(uids)),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, task := range result.Results {
+		e.tasks.mu.Lock()
+		tracked, ok := e.tasks.pending[task.UID]
+		delete(e.tasks.pending, task.UID)
+		e.tasks.mu.Unlock()
+		if !ok {
+			continue
+		}
+
+		outcome := search.TaskOutcome{
+			TenantID:          tracked.tenantID,
+			TaskUID:           task.UID,
+			Kind:              tracked.kind,
+			Succeeded:         task.Status == meilisearch.TaskStatusSucceeded,
+			ReceivedDocuments: task.Details.ReceivedDocuments,
+			IndexedDocuments:  task.Details.IndexedDocuments,
+			DeletedDocuments:  task.Details.DeletedDocuments,
+			EnqueuedAt:        task.EnqueuedAt,
+			FinishedAt:        task.FinishedAt,
+		}
+		switch task.Status {
+		case meilisearch.TaskStatusFailed:
+			outcome.Error = task.Error.Message
+		case meilisearch.TaskStatusCanceled:
+			outcome.Error = "task was canceled"
+		}
+		e.tasks.handle(outcome)
+	}
+	return nil
+}
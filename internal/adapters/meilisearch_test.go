@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"mini-search-platform/internal/search"
@@ -68,3 +69,91 @@ func TestSearchTenant_MissingIndex_ReturnsEmptyResultNotError(t *testing.T) {
 		t.Fatalf("expected SearchTenant not to create an index as a side effect of a search")
 	}
 }
+
+// TestDeleteTenantIndex_EvictsInitCache asserts that dropping a tenant's
+// index also forgets its cached initialization, so a tenant re-created with
+// the same ID gets its index created and configured again.
+func TestDeleteTenantIndex_EvictsInitCache(t *testing.T) {
+	var deletedPath string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			deletedPath = r.URL.Path
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"taskUid": 42,
+			"status":  "enqueued",
+			"type":    "indexDeletion",
+		})
+	}))
+	defer server.Close()
+
+	Client = meilisearch.New(server.URL)
+
+	tenantID := uuid.NewString()
+	indexName := search.TenantIndexName(tenantID)
+
+	engine := &MeilisearchEngine{}
+	engine.tenantOnce(indexName)
+
+	taskUID, err := engine.DeleteTenantIndex(tenantID)
+	if err != nil {
+		t.Fatalf("DeleteTenantIndex failed: %v", err)
+	}
+	if taskUID != 42 {
+		t.Errorf("expected task uid 42, got %d", taskUID)
+	}
+	if deletedPath != "/indexes/"+indexName {
+		t.Errorf("expected DELETE /indexes/%s, got %q", indexName, deletedPath)
+	}
+	if _, ok := engine.tenantInit[indexName]; ok {
+		t.Error("expected tenant init entry to be evicted")
+	}
+}
+
+// TestIndexProjectDocuments_InitializesTheProjectIndex asserts that a
+// project's index is created and configured on its first write, under its
+// own name, like a tenant's.
+func TestIndexProjectDocuments_InitializesTheProjectIndex(t *testing.T) {
+	var created string
+	var documentsPath string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/indexes":
+			var body map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			created, _ = body["uid"].(string)
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/documents"):
+			documentsPath = r.URL.Path
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"taskUid": 1, "status": "enqueued"})
+	}))
+	defer server.Close()
+
+	Client = meilisearch.New(server.URL)
+
+	tenantID := uuid.NewString()
+	indexName := search.ProjectIndexName(tenantID, "Docs-1")
+	if want := "tenant_" + search.NormalizeTenantID(tenantID) + "_docs_1"; indexName != want {
+		t.Fatalf("expected index %q, got %q", want, indexName)
+	}
+
+	engine := &MeilisearchEngine{}
+	if err := engine.IndexProjectDocuments(tenantID, "Docs-1", []search.TenantDocument{{"id": 1}}); err != nil {
+		t.Fatalf("IndexProjectDocuments failed: %v", err)
+	}
+	if created != indexName {
+		t.Errorf("expected the index %s to be created, got %q", indexName, created)
+	}
+	if documentsPath != "/indexes/"+indexName+"/documents" {
+		t.Errorf("expected the documents in %s, got %q", indexName, documentsPath)
+	}
+	if _, ok := engine.tenantInit[indexName]; !ok {
+		t.Error("expected the project index's initialization to be cached")
+	}
+}
@@ -86,13 +86,62 @@ func (r *SQLiteMembershipRepository) ListByTenant(tenantID string) ([]*models.Me
 }
 
 func (r *SQLiteMembershipRepository) UpdateRole(id string, role models.Role) error {
-	query := `UPDATE memberships SET role = ? WHERE id = ?`
-	_, err := r.db.Exec(query, role, id)
-	return err
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if role != models.RoleAdmin {
+		if err := ensureNotLastAdmin(tx, id); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(`UPDATE memberships SET role = ? WHERE id = ?`, role, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (r *SQLiteMembershipRepository) Delete(id string) error {
-	query := `DELETE FROM memberships WHERE id = ?`
-	_, err := r.db.Exec(query, id)
-	return err
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := ensureNotLastAdmin(tx, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM memberships WHERE id = ?`, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ensureNotLastAdmin fails with models.ErrLastAdmin if the membership is
+// its tenant's only admin, and with sql.ErrNoRows if it does not exist.
+// Run in the transaction making the change, so two admins demoting each
+// other cannot both succeed.
+func ensureNotLastAdmin(tx *sql.Tx, id string) error {
+	var tenantID string
+	var role models.Role
+	if err := tx.QueryRow(`SELECT tenant_id, role FROM memberships WHERE id = ?`, id).Scan(&tenantID, &role); err != nil {
+		return err
+	}
+	if role != models.RoleAdmin {
+		return nil
+	}
+
+	var admins int
+	query := `SELECT COUNT(*) FROM memberships WHERE tenant_id = ? AND role = ?`
+	if err := tx.QueryRow(query, tenantID, models.RoleAdmin).Scan(&admins); err != nil {
+		return err
+	}
+	if admins <= 1 {
+		return models.ErrLastAdmin
+	}
+	return nil
 }
@@ -71,8 +71,57 @@ func (r *SQLiteProjectRepository) Update(project *models.Project) error {
 	return err
 }
 
-func (r *SQLiteProjectRepository) Delete(id string) error {
-	query := `DELETE FROM projects WHERE id = ?`
-	_, err := r.db.Exec(query, id)
-	return err
+func (r *SQLiteProjectRepository) Delete(id string) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var tenantID string
+	if err := tx.QueryRow(`SELECT tenant_id FROM projects WHERE id = ?`, id).Scan(&tenantID); err != nil {
+		return 0, err
+	}
+
+	rows, err := tx.Query(`SELECT id FROM articles WHERE project_id = ? ORDER BY id`, id)
+	if err != nil {
+		return 0, err
+	}
+	var articleIDs []int
+	for rows.Next() {
+		var articleID int
+		if err := rows.Scan(&articleID); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		articleIDs = append(articleIDs, articleID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	// Foreign keys are not enforced, so the articles are detached here
+	// rather than cascaded; their documents lose the project on the next
+	// sync.
+	if _, err := tx.Exec(`UPDATE articles SET project_id = NULL WHERE project_id = ?`, id); err != nil {
+		return 0, err
+	}
+	for _, articleID := range articleIDs {
+		if err := enqueueArticleIndexSync(tx, articleID, tenantID, ""); err != nil {
+			return 0, err
+		}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM projects WHERE id = ?`, id); err != nil {
+		return 0, err
+	}
+	if err := enqueueProjectIndexDrop(tx, tenantID, id); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(articleIDs), nil
 }
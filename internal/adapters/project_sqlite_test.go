@@ -0,0 +1,66 @@
+package adapters
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"mini-search-platform/internal/models"
+)
+
+func TestSQLiteProjectRepository_Delete_DetachesArticles(t *testing.T) {
+	db := newTestDB(t)
+	tenants := NewSQLiteTenantRepository(db)
+	projects := NewSQLiteProjectRepository(db)
+	articles := NewSQLliteArticleRepository(db)
+	outbox := NewSQLiteIndexSyncOutboxRepository(db)
+
+	if err := tenants.Save(models.NewTenant("acme", "Acme")); err != nil {
+		t.Fatalf("save tenant: %v", err)
+	}
+	for _, id := range []string{"docs", "blog"} {
+		if err := projects.Save(models.NewProject(id, "acme", id, models.TierFree)); err != nil {
+			t.Fatalf("save project: %v", err)
+		}
+	}
+	mustExec(t, db, `INSERT INTO authors (id, name) VALUES (1, 'Ada')`)
+	mustExec(t, db, `INSERT INTO articles (id, title, body, author_id, tenant_id, project_id) VALUES (1, 'a', 'b', 1, 'acme', 'docs')`)
+	mustExec(t, db, `INSERT INTO articles (id, title, body, author_id, tenant_id, project_id) VALUES (2, 'a', 'b', 1, 'acme', 'blog')`)
+
+	detached, err := projects.Delete("docs")
+	if err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if detached != 1 {
+		t.Errorf("expected one detached article, got %d", detached)
+	}
+	if project, _ := projects.FindByID("docs"); project != nil {
+		t.Errorf("expected the project to be gone, got %+v", project)
+	}
+
+	article, err := articles.FindByID(1)
+	if err != nil || article == nil || article.TenantID != "acme" || article.ProjectID != "" {
+		t.Errorf("expected the article to stay in the tenant without a project, got %+v, %v", article, err)
+	}
+	if article, _ := articles.FindByID(2); article == nil || article.ProjectID != "blog" {
+		t.Errorf("expected the other project's article untouched, got %+v", article)
+	}
+
+	due, err := outbox.ListDue(time.Now().Add(time.Second), 10)
+	if err != nil {
+		t.Fatalf("ListDue failed: %v", err)
+	}
+	if len(due) != 2 {
+		t.Fatalf("expected two intents, got %d", len(due))
+	}
+	if reindex := due[0]; reindex.Kind != models.IndexSyncArticle || reindex.EntityID != 1 || reindex.TenantID != "acme" || reindex.ProjectID != "" {
+		t.Errorf("expected an intent re-indexing the detached article, got %+v", reindex)
+	}
+	if drop := due[1]; drop.Kind != models.IndexSyncProject || drop.TenantID != "acme" || drop.ProjectID != "docs" {
+		t.Errorf("expected an intent dropping the project's index, got %+v", drop)
+	}
+
+	if _, err := projects.Delete("docs"); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows deleting a missing project, got %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+package adapters
+
+import (
+	"database/sql"
+	"encoding/json"
+	"mini-search-platform/internal/models"
+	"time"
+)
+
+type SQLiteQueryRuleRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteQueryRuleRepository(db *sql.DB) *SQLiteQueryRuleRepository {
+	return &SQLiteQueryRuleRepository{db: db}
+}
+
+const queryRuleColumns = `id, tenant_id, description, enabled, match_type, query, starts_at, ends_at, consequences, created_at, updated_at`
+
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+func (r *SQLiteQueryRuleRepository) Save(rule *models.QueryRule) error {
+	consequences, err := json.Marshal(rule.Consequences)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO query_rules (` + queryRuleColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err = r.db.Exec(query,
+		rule.ID,
+		rule.TenantID,
+		rule.Description,
+		rule.Enabled,
+		string(rule.Conditions.Match),
+		rule.Conditions.Query,
+		nullTime(rule.Conditions.StartsAt),
+		nullTime(rule.Conditions.EndsAt),
+		string(consequences),
+		rule.CreatedAt,
+		rule.UpdatedAt,
+	)
+	return err
+}
+
+func (r *SQLiteQueryRuleRepository) Update(rule *models.QueryRule) error {
+	consequences, err := json.Marshal(rule.Consequences)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE query_rules
+		SET description = ?, enabled = ?, match_type = ?, query = ?,
+			starts_at = ?, ends_at = ?, consequences = ?, updated_at = ?
+		WHERE tenant_id = ? AND id = ?
+	`
+	_, err = r.db.Exec(query,
+		rule.Description,
+		rule.Enabled,
+		string(rule.Conditions.Match),
+		rule.Conditions.Query,
+		nullTime(rule.Conditions.StartsAt),
+		nullTime(rule.Conditions.EndsAt),
+		string(consequences),
+		rule.UpdatedAt,
+		rule.TenantID,
+		rule.ID,
+	)
+	return err
+}
+
+func (r *SQLiteQueryRuleRepository) Delete(tenantID, id string) error {
+	_, err := r.db.Exec(`DELETE FROM query_rules WHERE tenant_id = ? AND id = ?`, tenantID, id)
+	return err
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanQueryRule(row rowScanner) (*models.QueryRule, error) {
+	var matchType, consequences string
+	var startsAt, endsAt sql.NullTime
+	rule := &models.QueryRule{}
+
+	if err := row.Scan(
+		&rule.ID,
+		&rule.TenantID,
+		&rule.Description,
+		&rule.Enabled,
+		&matchType,
+		&rule.Conditions.Query,
+		&startsAt,
+		&endsAt,
+		&consequences,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	rule.Conditions.Match = models.QueryRuleMatch(matchType)
+	if startsAt.Valid {
+		rule.Conditions.StartsAt = &startsAt.Time
+	}
+	if endsAt.Valid {
+		rule.Conditions.EndsAt = &endsAt.Time
+	}
+	if err := json.Unmarshal([]byte(consequences), &rule.Consequences); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (r *SQLiteQueryRuleRepository) FindByID(tenantID, id string) (*models.QueryRule, error) {
+	query := `SELECT ` + queryRuleColumns + ` FROM query_rules WHERE tenant_id = ? AND id = ?`
+	rule, err := scanQueryRule(r.db.QueryRow(query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (r *SQLiteQueryRuleRepository) ListByTenant(tenantID string) ([]*models.QueryRule, error) {
+	query := `SELECT ` + queryRuleColumns + ` FROM query_rules WHERE tenant_id = ? ORDER BY created_at ASC, id ASC`
+	rows, err := r.db.Query(query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []*models.QueryRule{}
+	for rows.Next() {
+		rule, err := scanQueryRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
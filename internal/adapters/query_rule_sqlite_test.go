@@ -0,0 +1,72 @@
+package adapters
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"mini-search-platform/internal/models"
+)
+
+func TestQueryRuleRepository_RoundTripIsTenantScoped(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewSQLiteQueryRuleRepository(db)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	endsAt := now.Add(24 * time.Hour)
+	rule := &models.QueryRule{
+		ID:          "rule-1",
+		TenantID:    "tenant-a",
+		Description: "hero dress",
+		Enabled:     true,
+		Conditions: models.QueryRuleConditions{
+			Match:  models.MatchExact,
+			Query:  "summer dress",
+			EndsAt: &endsAt,
+		},
+		Consequences: models.QueryRuleConsequences{
+			Pins:  []models.PinnedDocument{{DocumentID: "42", Position: 1}},
+			Hide:  []string{"7"},
+			Boost: `brand = "acme"`,
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := repo.Save(rule); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	got, err := repo.FindByID("tenant-a", "rule-1")
+	if err != nil || got == nil {
+		t.Fatalf("FindByID failed: %v (rule=%v)", err, got)
+	}
+	if got.Conditions.StartsAt != nil || got.Conditions.EndsAt == nil || !got.Conditions.EndsAt.Equal(endsAt) {
+		t.Errorf("date range did not round-trip: %+v", got.Conditions)
+	}
+	if !reflect.DeepEqual(got.Consequences, rule.Consequences) {
+		t.Errorf("consequences did not round-trip: %+v", got.Consequences)
+	}
+
+	if other, _ := repo.FindByID("tenant-b", "rule-1"); other != nil {
+		t.Fatal("another tenant must not see the rule")
+	}
+	if err := repo.Delete("tenant-b", "rule-1"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if n := countRows(t, db, `SELECT COUNT(*) FROM query_rules`); n != 1 {
+		t.Fatal("another tenant must not be able to delete the rule")
+	}
+
+	rule.Enabled = false
+	rule.Consequences = models.QueryRuleConsequences{Filter: "stock > 0"}
+	if err := repo.Update(rule); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	rules, err := repo.ListByTenant("tenant-a")
+	if err != nil {
+		t.Fatalf("ListByTenant failed: %v", err)
+	}
+	if len(rules) != 1 || rules[0].Enabled || rules[0].Consequences.Filter != "stock > 0" || len(rules[0].Consequences.Pins) != 0 {
+		t.Errorf("update was not persisted: %+v", rules)
+	}
+}
@@ -0,0 +1,94 @@
+package adapters
+
+import (
+	"database/sql"
+	"mini-search-platform/internal/models"
+	"time"
+)
+
+// SQLiteRefreshTokenRepository stores token times as unix seconds, like
+// SQLiteSessionRepository.
+type SQLiteRefreshTokenRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteRefreshTokenRepository(db *sql.DB) *SQLiteRefreshTokenRepository {
+	return &SQLiteRefreshTokenRepository{db: db}
+}
+
+const refreshTokenColumns = `id, family_id, user_id, tenant_id, token_hash, expires_at, used_at, revoked_at, created_at`
+
+func nullUnix(t *time.Time) sql.NullInt64 {
+	if t == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: t.Unix(), Valid: true}
+}
+
+func unixTime(t sql.NullInt64) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	converted := time.Unix(t.Int64, 0).UTC()
+	return &converted
+}
+
+func saveRefreshToken(db execer, token *models.RefreshToken) error {
+	query := `INSERT INTO refresh_tokens (` + refreshTokenColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := db.Exec(query, token.ID, token.FamilyID, token.UserID, token.TenantID, token.TokenHash,
+		token.ExpiresAt.Unix(), nullUnix(token.UsedAt), nullUnix(token.RevokedAt), token.CreatedAt.Unix())
+	return err
+}
+
+func (r *SQLiteRefreshTokenRepository) Save(token *models.RefreshToken) error {
+	return saveRefreshToken(r.db, token)
+}
+
+func (r *SQLiteRefreshTokenRepository) FindByTokenHash(tokenHash string) (*models.RefreshToken, error) {
+	query := `SELECT ` + refreshTokenColumns + ` FROM refresh_tokens WHERE token_hash = ?`
+	token := &models.RefreshToken{}
+	var expiresAt, createdAt int64
+	var usedAt, revokedAt sql.NullInt64
+	err := r.db.QueryRow(query, tokenHash).Scan(&token.ID, &token.FamilyID, &token.UserID, &token.TenantID,
+		&token.TokenHash, &expiresAt, &usedAt, &revokedAt, &createdAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	token.ExpiresAt = time.Unix(expiresAt, 0).UTC()
+	token.UsedAt = unixTime(usedAt)
+	token.RevokedAt = unixTime(revokedAt)
+	token.CreatedAt = time.Unix(createdAt, 0).UTC()
+	return token, nil
+}
+
+func (r *SQLiteRefreshTokenRepository) Rotate(id string, next *models.RefreshToken) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Of two concurrent rotations of the same token, only one marks it
+	// used; the other sees a reuse.
+	query := `UPDATE refresh_tokens SET used_at = ? WHERE id = ? AND used_at IS NULL AND revoked_at IS NULL`
+	result, err := tx.Exec(query, time.Now().Unix(), id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return models.ErrRefreshTokenReused
+	}
+
+	if err := saveRefreshToken(tx, next); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
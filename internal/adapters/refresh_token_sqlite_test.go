@@ -0,0 +1,50 @@
+package adapters
+
+import (
+	"testing"
+	"time"
+
+	"mini-search-platform/internal/models"
+)
+
+func TestSQLiteRefreshTokenRepository_Rotate_IsSingleUse(t *testing.T) {
+	db := newTestDB(t)
+	tokens := NewSQLiteRefreshTokenRepository(db)
+	expires := time.Now().Add(time.Hour)
+
+	first := models.NewRefreshToken("rt-1", "family", "ada", "acme", "hash-1", expires)
+	if err := tokens.Save(first); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := tokens.Rotate("rt-1", models.NewRefreshToken("rt-2", "family", "ada", "acme", "hash-2", expires)); err != nil {
+		t.Fatalf("Rotate failed: %v", err)
+	}
+
+	used, err := tokens.FindByTokenHash("hash-1")
+	if err != nil || used == nil || used.UsedAt == nil || used.RevokedAt != nil {
+		t.Fatalf("expected the first token marked used, got %+v, %v", used, err)
+	}
+	next, err := tokens.FindByTokenHash("hash-2")
+	if err != nil || next == nil || next.FamilyID != "family" || next.TenantID != "acme" || next.UsedAt != nil {
+		t.Fatalf("expected the next token in the family, got %+v, %v", next, err)
+	}
+
+	err = tokens.Rotate("rt-1", models.NewRefreshToken("rt-3", "family", "ada", "acme", "hash-3", expires))
+	if err != models.ErrRefreshTokenReused {
+		t.Errorf("expected ErrRefreshTokenReused rotating a used token, got %v", err)
+	}
+	if found, _ := tokens.FindByTokenHash("hash-3"); found != nil {
+		t.Errorf("expected no token saved by a failed rotation, got %+v", found)
+	}
+
+	if err := NewSQLiteSessionRepository(db).Revoke("family"); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+	if revoked, _ := tokens.FindByTokenHash("hash-2"); revoked == nil || revoked.RevokedAt == nil {
+		t.Errorf("expected the session's tokens revoked, got %+v", revoked)
+	}
+	err = tokens.Rotate("rt-2", models.NewRefreshToken("rt-4", "family", "ada", "acme", "hash-4", expires))
+	if err != models.ErrRefreshTokenReused {
+		t.Errorf("expected ErrRefreshTokenReused rotating a revoked token, got %v", err)
+	}
+}
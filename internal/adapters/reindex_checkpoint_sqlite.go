@@ -0,0 +1,68 @@
+package adapters
+
+import (
+	"database/sql"
+	"errors"
+	"mini-search-platform/internal/models"
+	"time"
+)
+
+type SQLiteReindexCheckpointRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteReindexCheckpointRepository(db *sql.DB) *SQLiteReindexCheckpointRepository {
+	return &SQLiteReindexCheckpointRepository{db: db}
+}
+
+func (r *SQLiteReindexCheckpointRepository) Load(indexName string) (*models.ReindexCheckpoint, error) {
+	query := `
+		SELECT index_name, last_article_id, indexed, started_at, updated_at
+		FROM reindex_checkpoints
+		WHERE index_name = ?
+	`
+	var startedAt, updatedAt int64
+	checkpoint := &models.ReindexCheckpoint{}
+	err := r.db.QueryRow(query, indexName).Scan(
+		&checkpoint.IndexName,
+		&checkpoint.LastArticleID,
+		&checkpoint.Indexed,
+		&startedAt,
+		&updatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoint.StartedAt = time.Unix(startedAt, 0).UTC()
+	checkpoint.UpdatedAt = time.Unix(updatedAt, 0).UTC()
+	return checkpoint, nil
+}
+
+func (r *SQLiteReindexCheckpointRepository) Save(checkpoint *models.ReindexCheckpoint) error {
+	query := `
+		INSERT INTO reindex_checkpoints (index_name, last_article_id, indexed, started_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(index_name) DO UPDATE SET
+			last_article_id = excluded.last_article_id,
+			indexed = excluded.indexed,
+			started_at = excluded.started_at,
+			updated_at = excluded.updated_at
+	`
+	_, err := r.db.Exec(query,
+		checkpoint.IndexName,
+		checkpoint.LastArticleID,
+		checkpoint.Indexed,
+		checkpoint.StartedAt.UTC().Unix(),
+		checkpoint.UpdatedAt.UTC().Unix(),
+	)
+	return err
+}
+
+func (r *SQLiteReindexCheckpointRepository) Delete(indexName string) error {
+	_, err := r.db.Exec(`DELETE FROM reindex_checkpoints WHERE index_name = ?`, indexName)
+	return err
+}
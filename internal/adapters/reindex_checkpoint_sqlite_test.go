@@ -0,0 +1,85 @@
+package adapters
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"mini-search-platform/internal/models"
+)
+
+func TestReindexCheckpointRepository_SaveLoadDelete(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewSQLiteReindexCheckpointRepository(db)
+
+	if checkpoint, err := repo.Load("articles"); err != nil || checkpoint != nil {
+		t.Fatalf("expected no checkpoint, got %+v, %v", checkpoint, err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	checkpoint := &models.ReindexCheckpoint{IndexName: "articles", LastArticleID: 1000, Indexed: 1000, StartedAt: now, UpdatedAt: now}
+	if err := repo.Save(checkpoint); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	checkpoint.LastArticleID = 2000
+	checkpoint.Indexed = 2000
+	checkpoint.UpdatedAt = now.Add(time.Minute)
+	if err := repo.Save(checkpoint); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	got, err := repo.Load("articles")
+	if err != nil || !reflect.DeepEqual(got, checkpoint) {
+		t.Fatalf("expected the latest checkpoint, got %+v, %v", got, err)
+	}
+
+	if err := repo.Delete("articles"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if got, _ := repo.Load("articles"); got != nil {
+		t.Errorf("expected the checkpoint to be deleted, got %+v", got)
+	}
+}
+
+func TestSQLliteArticleRepository_ListAfterIDPagesInIDOrder(t *testing.T) {
+	db := newTestDB(t)
+	authors := NewSQLliteAuthorsRepository(db)
+	articles := NewSQLliteArticleRepository(db)
+
+	authorID, err := authors.Save(&models.Author{Name: "Ada"})
+	if err != nil {
+		t.Fatalf("failed to save author: %v", err)
+	}
+	author := &models.Author{ID: authorID, Name: "Ada"}
+	var ids []int
+	for _, title := range []string{"one", "two", "three"} {
+		id, err := articles.Save(models.NewArticle(title, "body", author, nil))
+		if err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	// An article whose author row is missing is still listed.
+	if _, err := db.Exec(`INSERT INTO articles (title, body, author_id) VALUES ('orphan', 'body', 999)`); err != nil {
+		t.Fatalf("failed to insert orphan article: %v", err)
+	}
+
+	if count, err := articles.Count(); err != nil || count != 4 {
+		t.Fatalf("expected 4 articles, got %d, %v", count, err)
+	}
+
+	page, err := articles.ListAfterID(0, 2)
+	if err != nil {
+		t.Fatalf("ListAfterID failed: %v", err)
+	}
+	if len(page) != 2 || page[0].ID != ids[0] || page[1].ID != ids[1] || page[0].Author != "Ada" {
+		t.Fatalf("unexpected first page %+v", page)
+	}
+	page, _ = articles.ListAfterID(page[1].ID, 2)
+	if len(page) != 2 || page[0].ID != ids[2] || page[1].Title != "orphan" || page[1].Author != "" {
+		t.Fatalf("unexpected second page %+v", page)
+	}
+	if page, _ = articles.ListAfterID(page[1].ID, 2); len(page) != 0 {
+		t.Errorf("expected no more articles, got %+v", page)
+	}
+}
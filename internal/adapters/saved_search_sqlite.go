@@ -0,0 +1,215 @@
+package adapters
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"mini-search-platform/internal/models"
+	"strings"
+	"time"
+)
+
+type SQLiteSavedSearchRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteSavedSearchRepository(db *sql.DB) *SQLiteSavedSearchRepository {
+	return &SQLiteSavedSearchRepository{db: db}
+}
+
+const savedSearchColumns = `id, tenant_id, name, query, filter, sort, webhook_url, secret, created_at, updated_at`
+
+func (r *SQLiteSavedSearchRepository) Save(savedSearch *models.SavedSearch) error {
+	sort, err := json.Marshal(savedSearch.Sort)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO saved_searches (` + savedSearchColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err = r.db.Exec(query,
+		savedSearch.ID,
+		savedSearch.TenantID,
+		savedSearch.Name,
+		savedSearch.Query,
+		savedSearch.Filter,
+		string(sort),
+		savedSearch.WebhookURL,
+		savedSearch.Secret,
+		savedSearch.CreatedAt,
+		savedSearch.UpdatedAt,
+	)
+	return err
+}
+
+func (r *SQLiteSavedSearchRepository) Update(savedSearch *models.SavedSearch) error {
+	sort, err := json.Marshal(savedSearch.Sort)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE saved_searches
+		SET name = ?, query = ?, filter = ?, sort = ?, webhook_url = ?, updated_at = ?
+		WHERE tenant_id = ? AND id = ?
+	`
+	_, err = r.db.Exec(query,
+		savedSearch.Name,
+		savedSearch.Query,
+		savedSearch.Filter,
+		string(sort),
+		savedSearch.WebhookURL,
+		savedSearch.UpdatedAt,
+		savedSearch.TenantID,
+		savedSearch.ID,
+	)
+	return err
+}
+
+func (r *SQLiteSavedSearchRepository) Delete(tenantID, id string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM saved_searches WHERE tenant_id = ? AND id = ?`, tenantID, id)
+	if err != nil {
+		return err
+	}
+	// Only drop the matches when the saved search really was the tenant's.
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM saved_search_matches WHERE saved_search_id = ?`, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func scanSavedSearch(row rowScanner) (*models.SavedSearch, error) {
+	var sort string
+	savedSearch := &models.SavedSearch{}
+
+	if err := row.Scan(
+		&savedSearch.ID,
+		&savedSearch.TenantID,
+		&savedSearch.Name,
+		&savedSearch.Query,
+		&savedSearch.Filter,
+		&sort,
+		&savedSearch.WebhookURL,
+		&savedSearch.Secret,
+		&savedSearch.CreatedAt,
+		&savedSearch.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(sort), &savedSearch.Sort); err != nil {
+		return nil, err
+	}
+	return savedSearch, nil
+}
+
+func (r *SQLiteSavedSearchRepository) FindByID(tenantID, id string) (*models.SavedSearch, error) {
+	query := `SELECT ` + savedSearchColumns + ` FROM saved_searches WHERE tenant_id = ? AND id = ?`
+	savedSearch, err := scanSavedSearch(r.db.QueryRow(query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return savedSearch, nil
+}
+
+func (r *SQLiteSavedSearchRepository) ListByTenant(tenantID string) ([]*models.SavedSearch, error) {
+	query := `SELECT ` + savedSearchColumns + ` FROM saved_searches WHERE tenant_id = ? ORDER BY created_at ASC, id ASC`
+	rows, err := r.db.Query(query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	savedSearches := []*models.SavedSearch{}
+	for rows.Next() {
+		savedSearch, err := scanSavedSearch(rows)
+		if err != nil {
+			return nil, err
+		}
+		savedSearches = append(savedSearches, savedSearch)
+	}
+
+	return savedSearches, rows.Err()
+}
+
+func (r *SQLiteSavedSearchRepository) UnseenMatches(savedSearchID string, documentIDs []string) ([]string, error) {
+	if len(documentIDs) == 0 {
+		return nil, nil
+	}
+
+	placeholders := strings.Repeat("?,", len(documentIDs)-1) + "?"
+	args := make([]interface{}, 0, len(documentIDs)+1)
+	args = append(args, savedSearchID)
+	for _, id := range documentIDs {
+		args = append(args, id)
+	}
+	rows, err := r.db.Query(fmt.Sprintf(`
+		SELECT document_id FROM saved_search_matches WHERE saved_search_id = ? AND document_id IN (%s)
+	`, placeholders), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seen := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		seen[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var unseen []string
+	for _, id := range documentIDs {
+		if !seen[id] {
+			unseen = append(unseen, id)
+		}
+	}
+	return unseen, nil
+}
+
+func (r *SQLiteSavedSearchRepository) RecordMatches(savedSearchID string, documentIDs []string) error {
+	if len(documentIDs) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO saved_search_matches (saved_search_id, document_id, matched_at) VALUES (?, ?, ?)
+		ON CONFLICT(saved_search_id, document_id) DO NOTHING
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now().UTC().Unix()
+	for _, id := range documentIDs {
+		if _, err := stmt.Exec(savedSearchID, id, now); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
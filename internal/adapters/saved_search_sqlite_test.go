@@ -0,0 +1,72 @@
+package adapters
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"mini-search-platform/internal/models"
+)
+
+func TestSavedSearchRepository_UnseenMatchesAreThoseNotRecordedYet(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewSQLiteSavedSearchRepository(db)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	savedSearch := &models.SavedSearch{
+		ID:         "saved-1",
+		TenantID:   "tenant-a",
+		Query:      "linen blazer",
+		Filter:     "price < 200",
+		Sort:       []string{"price:asc"},
+		WebhookURL: "https://buyer.example.com/hooks",
+		Secret:     "whsec_test",
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := repo.Save(savedSearch); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	got, err := repo.FindByID("tenant-a", "saved-1")
+	if err != nil || got == nil {
+		t.Fatalf("FindByID failed: %v (saved search=%v)", err, got)
+	}
+	if !reflect.DeepEqual(got, savedSearch) {
+		t.Errorf("saved search did not round-trip: %+v", got)
+	}
+	if other, _ := repo.FindByID("tenant-b", "saved-1"); other != nil {
+		t.Fatal("another tenant must not see the saved search")
+	}
+
+	unseen, err := repo.UnseenMatches("saved-1", []string{"3", "1"})
+	if err != nil {
+		t.Fatalf("UnseenMatches failed: %v", err)
+	}
+	if !reflect.DeepEqual(unseen, []string{"3", "1"}) {
+		t.Errorf("expected both documents to be new, got %v", unseen)
+	}
+	if err := repo.RecordMatches("saved-1", unseen); err != nil {
+		t.Fatalf("RecordMatches failed: %v", err)
+	}
+	unseen, _ = repo.UnseenMatches("saved-1", []string{"1", "2", "3"})
+	if !reflect.DeepEqual(unseen, []string{"2"}) {
+		t.Errorf("expected only document 2 to be new, got %v", unseen)
+	}
+	if err := repo.RecordMatches("saved-1", []string{"1", "2", "3"}); err != nil {
+		t.Fatalf("RecordMatches failed: %v", err)
+	}
+
+	if err := repo.Delete("tenant-b", "saved-1"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if n := countRows(t, db, `SELECT COUNT(*) FROM saved_search_matches`); n != 3 {
+		t.Fatalf("another tenant's delete must not drop the matches, got %d", n)
+	}
+	if err := repo.Delete("tenant-a", "saved-1"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if n := countRows(t, db, `SELECT COUNT(*) FROM saved_search_matches`); n != 0 {
+		t.Errorf("expected the matches to be deleted with the saved search, got %d", n)
+	}
+}
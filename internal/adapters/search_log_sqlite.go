@@ -0,0 +1,188 @@
+package adapters
+
+import (
+	"database/sql"
+	"mini-search-platform/internal/models"
+	"time"
+)
+
+type SQLiteSearchLogRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteSearchLogRepository(db *sql.DB) *SQLiteSearchLogRepository {
+	return &SQLiteSearchLogRepository{db: db}
+}
+
+func (r *SQLiteSearchLogRepository) SaveBatch(entries []*models.SearchLogEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO search_logs (
+			id, tenant_id, query, filters, total_hits, latency_ms, session_id, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, entry := range entries {
+		if _, err := stmt.Exec(
+			entry.ID,
+			entry.TenantID,
+			entry.Query,
+			entry.Filters,
+			entry.TotalHits,
+			entry.LatencyMs,
+			entry.SessionID,
+			entry.CreatedAt.UTC().Unix(),
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *SQLiteSearchLogRepository) Summary(tenantID string, from, to time.Time) (models.SearchAnalyticsSummary, error) {
+	query := `
+		SELECT
+			COUNT(*),
+			COALESCE(SUM(total_hits = 0), 0),
+			COUNT(DISTINCT query),
+			COUNT(DISTINCT NULLIF(session_id, '')),
+			COALESCE(AVG(latency_ms), 0)
+		FROM search_logs
+		WHERE tenant_id = ? AND created_at >= ? AND created_at <= ?
+	`
+	var summary models.SearchAnalyticsSummary
+	err := r.db.QueryRow(query, tenantID, from.UTC().Unix(), to.UTC().Unix()).Scan(
+		&summary.Searches,
+		&summary.ZeroResults,
+		&summary.UniqueQueries,
+		&summary.UniqueSessions,
+		&summary.AvgLatencyMs,
+	)
+	if err != nil {
+		return summary, err
+	}
+
+	if summary.Searches > 0 {
+		summary.ZeroResultRate = float64(summary.ZeroResults) / float64(summary.Searches)
+	}
+	return summary, nil
+}
+
+func (r *SQLiteSearchLogRepository) TopQueries(tenantID string, from, to time.Time, limit int) ([]*models.SearchQueryStat, error) {
+	return r.queryStats("", "", tenantID, from, to, limit)
+}
+
+func (r *SQLiteSearchLogRepository) ZeroResultQueries(tenantID string, from, to time.Time, limit int) ([]*models.SearchQueryStat, error) {
+	return r.queryStats("AND total_hits = 0", "", tenantID, from, to, limit)
+}
+
+func (r *SQLiteSearchLogRepository) NoClickQueries(tenantID string, from, to time.Time, limit int) ([]*models.SearchQueryStat, error) {
+	return r.queryStats("AND total_hits > 0", `
+		HAVING SUM(EXISTS (
+			SELECT 1 FROM search_events
+			WHERE search_events.tenant_id = search_logs.tenant_id
+				AND search_events.search_id = search_logs.id
+		)) = 0`, tenantID, from, to, limit)
+}
+
+// queryStats groups the tenant's searches between from and to by query.
+// where and having are fixed SQL fragments supplied by the callers above,
+// never user input.
+func (r *SQLiteSearchLogRepository) queryStats(where, having, tenantID string, from, to time.Time, limit int) ([]*models.SearchQueryStat, error) {
+	query := `
+		SELECT query, COUNT(*) AS searches, AVG(total_hits), MAX(created_at)
+		FROM search_logs
+		WHERE tenant_id = ? AND created_at >= ? AND created_at <= ? ` + where + `
+		GROUP BY query
+		` + having + `
+		ORDER BY searches DESC, query ASC
+		LIMIT ?
+	`
+	rows, err := r.db.Query(query, tenantID, from.UTC().Unix(), to.UTC().Unix(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []*models.SearchQueryStat{}
+	for rows.Next() {
+		var lastQueried int64
+		stat := &models.SearchQueryStat{}
+		if err := rows.Scan(&stat.Query, &stat.Searches, &stat.AvgHits, &lastQueried); err != nil {
+			return nil, err
+		}
+		stat.LastQueried = time.Unix(lastQueried, 0).UTC()
+		stats = append(stats, stat)
+	}
+
+	return stats, rows.Err()
+}
+
+func (r *SQLiteSearchLogRepository) Trend(tenantID string, from, to time.Time, bucket time.Duration) ([]*models.SearchTrendPoint, error) {
+	size := int64(bucket / time.Second)
+	query := `
+		SELECT (created_at / ?) * ? AS bucket, COUNT(*), SUM(total_hits = 0), AVG(latency_ms)
+		FROM search_logs
+		WHERE tenant_id = ? AND created_at >= ? AND created_at <= ?
+		GROUP BY bucket
+		ORDER BY bucket ASC
+	`
+	rows, err := r.db.Query(query, size, size, tenantID, from.UTC().Unix(), to.UTC().Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	points := []*models.SearchTrendPoint{}
+	for rows.Next() {
+		var start int64
+		point := &models.SearchTrendPoint{}
+		if err := rows.Scan(&start, &point.Searches, &point.ZeroResults, &point.AvgLatencyMs); err != nil {
+			return nil, err
+		}
+		point.Bucket = time.Unix(start, 0).UTC()
+		points = append(points, point)
+	}
+
+	return points, rows.Err()
+}
+
+func (r *SQLiteSearchLogRepository) Prune(olderThan time.Time, maxRows int) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM search_logs WHERE created_at < ?`, olderThan.UTC().Unix())
+	if err != nil {
+		return 0, err
+	}
+	expired, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	result, err = r.db.Exec(`
+		DELETE FROM search_logs WHERE rowid IN (
+			SELECT rowid FROM search_logs ORDER BY created_at DESC LIMIT -1 OFFSET ?
+		)
+	`, maxRows)
+	if err != nil {
+		return expired, err
+	}
+	overflow, err := result.RowsAffected()
+	if err != nil {
+		return expired, err
+	}
+
+	return expired + overflow, nil
+}
@@ -0,0 +1,135 @@
+package adapters
+
+import (
+	"testing"
+	"time"
+
+	"mini-search-platform/internal/models"
+
+	"github.com/google/uuid"
+)
+
+func searchLog(tenantID, query string, hits int, at time.Time) *models.SearchLogEntry {
+	return &models.SearchLogEntry{
+		ID:        uuid.NewString(),
+		TenantID:  tenantID,
+		Query:     query,
+		TotalHits: hits,
+		LatencyMs: 10,
+		SessionID: "session-" + query,
+		CreatedAt: at,
+	}
+}
+
+func TestSearchLogRepository_ReportsQueriesPerTenant(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewSQLiteSearchLogRepository(db)
+
+	now := time.Now().UTC()
+	clicked := searchLog("tenant-a", "dress", 12, now)
+	sandals := searchLog("tenant-a", "sandals", 4, now)
+	err := repo.SaveBatch([]*models.SearchLogEntry{
+		clicked,
+		searchLog("tenant-a", "dress", 10, now),
+		searchLog("tenant-a", "dress", 8, now),
+		sandals,
+		searchLog("tenant-a", "sandals", 4, now),
+		searchLog("tenant-a", "velvet cape", 0, now),
+		searchLog("tenant-b", "velvet cape", 0, now),
+		searchLog("tenant-b", "velvet cape", 0, now),
+		// Outside the window.
+		searchLog("tenant-a", "velvet cape", 0, now.Add(-48*time.Hour)),
+	})
+	if err != nil {
+		t.Fatalf("SaveBatch failed: %v", err)
+	}
+
+	// One click makes "dress" a clicked query; an event filed under another
+	// tenant must not count as a click on tenant-a's sandals search.
+	err = NewSQLiteSearchEventRepository(db).SaveBatch([]*models.SearchEvent{
+		{ID: uuid.NewString(), TenantID: "tenant-a", Type: models.EventClick, DocumentID: "1", SearchID: clicked.ID, CreatedAt: now},
+		{ID: uuid.NewString(), TenantID: "tenant-b", Type: models.EventClick, DocumentID: "2", SearchID: sandals.ID, CreatedAt: now},
+	})
+	if err != nil {
+		t.Fatalf("saving events failed: %v", err)
+	}
+
+	from, to := now.Add(-time.Hour), now
+
+	summary, err := repo.Summary("tenant-a", from, to)
+	if err != nil {
+		t.Fatalf("Summary failed: %v", err)
+	}
+	if summary.Searches != 6 || summary.ZeroResults != 1 || summary.UniqueQueries != 3 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+
+	top, err := repo.TopQueries("tenant-a", from, to, 2)
+	if err != nil {
+		t.Fatalf("TopQueries failed: %v", err)
+	}
+	if len(top) != 2 || top[0].Query != "dress" || top[0].Searches != 3 || top[1].Query != "sandals" {
+		t.Errorf("unexpected top queries: %+v", top)
+	}
+	if top[0].AvgHits != 10 {
+		t.Errorf("expected dress to average 10 hits, got %v", top[0].AvgHits)
+	}
+
+	zero, err := repo.ZeroResultQueries("tenant-a", from, to, 10)
+	if err != nil {
+		t.Fatalf("ZeroResultQueries failed: %v", err)
+	}
+	if len(zero) != 1 || zero[0].Query != "velvet cape" || zero[0].Searches != 1 {
+		t.Errorf("zero-result queries must be scoped to the tenant and window, got %+v", zero)
+	}
+
+	noClick, err := repo.NoClickQueries("tenant-a", from, to, 10)
+	if err != nil {
+		t.Fatalf("NoClickQueries failed: %v", err)
+	}
+	if len(noClick) != 1 || noClick[0].Query != "sandals" {
+		t.Errorf("expected only sandals to be a no-click query, got %+v", noClick)
+	}
+
+	trend, err := repo.Trend("tenant-a", now.Add(-72*time.Hour), to, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("Trend failed: %v", err)
+	}
+	var total int64
+	for _, point := range trend {
+		total += point.Searches
+	}
+	if len(trend) < 2 || total != 7 {
+		t.Errorf("expected the trend to cover all 7 tenant-a searches across days, got %d in %d buckets", total, len(trend))
+	}
+}
+
+func TestSearchLogRepository_PruneBoundsTheTable(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewSQLiteSearchLogRepository(db)
+
+	now := time.Now().UTC()
+	entries := []*models.SearchLogEntry{
+		searchLog("tenant-a", "expired", 1, now.Add(-40*24*time.Hour)),
+	}
+	for i := 0; i < 5; i++ {
+		entries = append(entries, searchLog("tenant-a", "recent", 1, now.Add(time.Duration(i)*time.Second)))
+	}
+	if err := repo.SaveBatch(entries); err != nil {
+		t.Fatalf("SaveBatch failed: %v", err)
+	}
+
+	pruned, err := repo.Prune(now.Add(-30*24*time.Hour), 3)
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if pruned != 3 {
+		t.Errorf("expected 1 expired + 2 overflow rows pruned, got %d", pruned)
+	}
+	if n := countRows(t, db, `SELECT COUNT(*) FROM search_logs`); n != 3 {
+		t.Errorf("expected 3 rows to remain, got %d", n)
+	}
+	if n := countRows(t, db, `SELECT COUNT(*) FROM search_logs WHERE created_at < ?`, now.Add(2*time.Second).Unix()); n != 0 {
+		t.Errorf("the oldest rows should have been pruned first, %d remain", n)
+	}
+}
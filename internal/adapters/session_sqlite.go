@@ -0,0 +1,226 @@
+package adapters
+
+import (
+	"database/sql"
+	"mini-search-platform/internal/models"
+	"time"
+)
+
+// SQLiteSessionRepository stores session and access token times as unix
+// seconds, as SQLiteRefreshTokenRepository does refresh token times, so
+// expiry can be compared in SQL.
+type SQLiteSessionRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteSessionRepository(db *sql.DB) *SQLiteSessionRepository {
+	return &SQLiteSessionRepository{db: db}
+}
+
+const sessionColumns = `id, user_id, tenant_id, device, ip_address, user_agent, created_at, last_seen_at, expires_at, revoked_at`
+
+func scanSession(row rowScanner) (*models.Session, error) {
+	session := &models.Session{}
+	var createdAt, lastSeenAt, expiresAt int64
+	var revokedAt sql.NullInt64
+	err := row.Scan(&session.ID, &session.UserID, &session.TenantID, &session.Device, &session.IPAddress,
+		&session.UserAgent, &createdAt, &lastSeenAt, &expiresAt, &revokedAt)
+	if err != nil {
+		return nil, err
+	}
+	session.CreatedAt = time.Unix(createdAt, 0).UTC()
+	session.LastSeenAt = time.Unix(lastSeenAt, 0).UTC()
+	session.ExpiresAt = time.Unix(expiresAt, 0).UTC()
+	if revokedAt.Valid {
+		revoked := time.Unix(revokedAt.Int64, 0).UTC()
+		session.RevokedAt = &revoked
+	}
+	return session, nil
+}
+
+func saveSession(db execer, session *models.Session) error {
+	query := `INSERT INTO sessions (` + sessionColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := db.Exec(query, session.ID, session.UserID, session.TenantID, session.Device, session.IPAddress,
+		session.UserAgent, session.CreatedAt.Unix(), session.LastSeenAt.Unix(), session.ExpiresAt.Unix(), nullUnix(session.RevokedAt))
+	return err
+}
+
+func (r *SQLiteSessionRepository) Save(session *models.Session) error {
+	return saveSession(r.db, session)
+}
+
+func (r *SQLiteSessionRepository) Start(session *models.Session, refreshToken *models.RefreshToken) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := saveSession(tx, session); err != nil {
+		return err
+	}
+	if err := saveRefreshToken(tx, refreshToken); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *SQLiteSessionRepository) FindByID(id string) (*models.Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM sessions WHERE id = ?`
+	session, err := scanSession(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return session, err
+}
+
+func (r *SQLiteSessionRepository) ListActiveByUser(userID string) ([]*models.Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM sessions
+		WHERE user_id = ? AND revoked_at IS NULL AND expires_at > ?
+		ORDER BY last_seen_at DESC, created_at DESC`
+	rows, err := r.db.Query(query, userID, time.Now().Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*models.Session
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+func (r *SQLiteSessionRepository) Touch(id, ipAddress string, seenAt time.Time) error {
+	query := `UPDATE sessions SET last_seen_at = ?, ip_address = ? WHERE id = ?`
+	_, err := r.db.Exec(query, seenAt.Unix(), ipAddress, id)
+	return err
+}
+
+func (r *SQLiteSessionRepository) Extend(id string, expiresAt time.Time) error {
+	_, err := r.db.Exec(`UPDATE sessions SET expires_at = ? WHERE id = ?`, expiresAt.Unix(), id)
+	return err
+}
+
+func (r *SQLiteSessionRepository) RecordAccessToken(sessionID, jti string, expiresAt time.Time) error {
+	query := `INSERT INTO access_tokens (jti, session_id, expires_at) VALUES (?, ?, ?)`
+	_, err := r.db.Exec(query, jti, sessionID, expiresAt.Unix())
+	return err
+}
+
+// revokeSession revokes the session, the refresh tokens of its family and
+// the access tokens issued in it.
+func revokeSession(db execer, id string, now time.Time) error {
+	if _, err := db.Exec(`UPDATE sessions SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`, now.Unix(), id); err != nil {
+		return err
+	}
+	if _, err := db.Exec(`UPDATE refresh_tokens SET revoked_at = ? WHERE family_id = ? AND revoked_at IS NULL`, now.Unix(), id); err != nil {
+		return err
+	}
+	_, err := db.Exec(`UPDATE access_tokens SET revoked_at = ? WHERE session_id = ? AND revoked_at IS NULL`, now.Unix(), id)
+	return err
+}
+
+func (r *SQLiteSessionRepository) Revoke(id string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := revokeSession(tx, id, time.Now()); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *SQLiteSessionRepository) RevokeAllByUser(userID string) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT id FROM sessions WHERE user_id = ? AND revoked_at IS NULL`, userID)
+	if err != nil {
+		return 0, err
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	for _, id := range ids {
+		if err := revokeSession(tx, id, now); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+func (r *SQLiteSessionRepository) ListRevokedAccessTokens(now time.Time) ([]string, error) {
+	query := `SELECT jti FROM access_tokens WHERE revoked_at IS NOT NULL AND expires_at > ?`
+	rows, err := r.db.Query(query, now.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jtis []string
+	for rows.Next() {
+		var jti string
+		if err := rows.Scan(&jti); err != nil {
+			return nil, err
+		}
+		jtis = append(jtis, jti)
+	}
+	return jtis, rows.Err()
+}
+
+// Prune deletes what expired in one transaction. A revoked access token is
+// kept until it expires, since ListRevokedAccessTokens must still list it.
+func (r *SQLiteSessionRepository) Prune(now time.Time) (int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var pruned int64
+	for _, table := range []string{"access_tokens", "refresh_tokens", "sessions"} {
+		result, err := tx.Exec(`DELETE FROM `+table+` WHERE expires_at <= ?`, now.Unix())
+		if err != nil {
+			return 0, err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		pruned += affected
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return pruned, nil
+}
@@ -0,0 +1,151 @@
+package adapters
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"mini-search-platform/internal/models"
+)
+
+func TestSQLiteSessionRepository_RevokesSessionsWithTheirTokens(t *testing.T) {
+	db := newTestDB(t)
+	sessions := NewSQLiteSessionRepository(db)
+	refreshTokens := NewSQLiteRefreshTokenRepository(db)
+	now := time.Now()
+
+	for _, s := range []*models.Session{
+		models.NewSession("s-acme", "ada", "acme", "Mac", "10.0.0.1", "Mozilla/5.0 (Macintosh)", now.Add(time.Hour)),
+		models.NewSession("s-globex", "ada", "globex", "iPhone", "10.0.0.2", "Mozilla/5.0 (iPhone)", now.Add(time.Hour)),
+		models.NewSession("s-expired", "ada", "acme", "Linux", "10.0.0.3", "curl", now.Add(-time.Hour)),
+		models.NewSession("s-bob", "bob", "acme", "Linux", "10.0.0.4", "curl", now.Add(time.Hour)),
+	} {
+		if err := sessions.Save(s); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+	if err := refreshTokens.Save(models.NewRefreshToken("rt-acme", "s-acme", "ada", "acme", "hash-acme", now.Add(time.Hour))); err != nil {
+		t.Fatalf("save refresh token: %v", err)
+	}
+	for jti, s := range map[string]string{"jti-acme": "s-acme", "jti-globex": "s-globex", "jti-bob": "s-bob"} {
+		if err := sessions.RecordAccessToken(s, jti, now.Add(time.Hour)); err != nil {
+			t.Fatalf("RecordAccessToken failed: %v", err)
+		}
+	}
+	if err := sessions.RecordAccessToken("s-acme", "jti-acme-old", now.Add(-time.Minute)); err != nil {
+		t.Fatalf("RecordAccessToken failed: %v", err)
+	}
+
+	if err := sessions.Touch("s-globex", "10.0.0.9", now.Add(time.Minute)); err != nil {
+		t.Fatalf("Touch failed: %v", err)
+	}
+	active, err := sessions.ListActiveByUser("ada")
+	if err != nil {
+		t.Fatalf("ListActiveByUser failed: %v", err)
+	}
+	if len(active) != 2 || active[0].ID != "s-globex" || active[0].IPAddress != "10.0.0.9" || active[1].ID != "s-acme" {
+		t.Fatalf("expected ada's live sessions, most recently seen first, got %+v", active)
+	}
+
+	if err := sessions.Revoke("s-acme"); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+	if token, _ := refreshTokens.FindByTokenHash("hash-acme"); token == nil || token.RevokedAt == nil {
+		t.Errorf("expected the session's refresh token revoked, got %+v", token)
+	}
+
+	jtis, err := sessions.ListRevokedAccessTokens(now)
+	if err != nil {
+		t.Fatalf("ListRevokedAccessTokens failed: %v", err)
+	}
+	if len(jtis) != 1 || jtis[0] != "jti-acme" {
+		t.Errorf("expected only the unexpired token of the revoked session, got %v", jtis)
+	}
+
+	// Every other session of ada's goes too, whatever its tenant.
+	revoked, err := sessions.RevokeAllByUser("ada")
+	if err != nil {
+		t.Fatalf("RevokeAllByUser failed: %v", err)
+	}
+	if revoked != 2 {
+		t.Errorf("expected ada's two sessions still live revoked, got %d", revoked)
+	}
+	jtis, _ = sessions.ListRevokedAccessTokens(now)
+	sort.Strings(jtis)
+	if len(jtis) != 2 || jtis[0] != "jti-acme" || jtis[1] != "jti-globex" {
+		t.Errorf("expected both of ada's sessions' tokens revoked, got %v", jtis)
+	}
+	if active, _ := sessions.ListActiveByUser("ada"); len(active) != 0 {
+		t.Errorf("expected no live sessions left, got %+v", active)
+	}
+	if active, _ := sessions.ListActiveByUser("bob"); len(active) != 1 {
+		t.Errorf("expected bob's session untouched, got %+v", active)
+	}
+}
+
+func TestSQLiteSessionRepository_PrunesWhatExpired(t *testing.T) {
+	db := newTestDB(t)
+	sessions := NewSQLiteSessionRepository(db)
+	refreshTokens := NewSQLiteRefreshTokenRepository(db)
+	now := time.Now()
+
+	for _, s := range []*models.Session{
+		models.NewSession("s-live", "ada", "acme", "Mac", "", "", now.Add(time.Hour)),
+		models.NewSession("s-expired", "ada", "acme", "Mac", "", "", now.Add(-time.Hour)),
+	} {
+		if err := sessions.Save(s); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		if err := refreshTokens.Save(models.NewRefreshToken("rt-"+s.ID, s.ID, "ada", "acme", "hash-"+s.ID, s.ExpiresAt)); err != nil {
+			t.Fatalf("save refresh token: %v", err)
+		}
+		if err := sessions.RecordAccessToken(s.ID, "jti-"+s.ID, s.ExpiresAt); err != nil {
+			t.Fatalf("RecordAccessToken failed: %v", err)
+		}
+	}
+	// A revoked token is kept until it expires, to stay refused.
+	if err := sessions.Revoke("s-live"); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+
+	pruned, err := sessions.Prune(now)
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if pruned != 3 {
+		t.Errorf("expected the expired session and its two tokens pruned, got %d rows", pruned)
+	}
+	if session, _ := sessions.FindByID("s-expired"); session != nil {
+		t.Errorf("expected the expired session gone, got %+v", session)
+	}
+	if token, _ := refreshTokens.FindByTokenHash("hash-s-expired"); token != nil {
+		t.Errorf("expected the expired refresh token gone, got %+v", token)
+	}
+	if jtis, _ := sessions.ListRevokedAccessTokens(now); len(jtis) != 1 || jtis[0] != "jti-s-live" {
+		t.Errorf("expected the live revoked token kept, got %v", jtis)
+	}
+}
+
+func TestSQLiteSessionRepository_StartSavesTheSessionOnlyWithItsRefreshToken(t *testing.T) {
+	db := newTestDB(t)
+	sessions := NewSQLiteSessionRepository(db)
+	now := time.Now()
+
+	first := models.NewSession("s-1", "ada", "acme", "Mac", "10.0.0.1", "curl", now.Add(time.Hour))
+	if err := sessions.Start(first, models.NewRefreshToken("rt-1", "s-1", "ada", "acme", "hash", now.Add(time.Hour))); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	// The token hash is taken, so the refresh token cannot be saved.
+	second := models.NewSession("s-2", "ada", "acme", "Mac", "10.0.0.1", "curl", now.Add(time.Hour))
+	if err := sessions.Start(second, models.NewRefreshToken("rt-2", "s-2", "ada", "acme", "hash", now.Add(time.Hour))); err == nil {
+		t.Fatal("expected Start to fail on a duplicate token hash")
+	}
+
+	if session, err := sessions.FindByID("s-2"); err != nil || session != nil {
+		t.Errorf("expected no session left without its refresh token, got %+v (%v)", session, err)
+	}
+	if session, _ := sessions.FindByID("s-1"); session == nil {
+		t.Error("expected the started session saved")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"mini-search-platform/internal/models"
 	"strings"
+	"time"
 )
 
 type SQLliteAuthorsRepository struct {
@@ -56,6 +57,85 @@ func (r *SQLliteAuthorsRepository) FindAuthorById(id int) (*models.Author, error
 	return &author, nil
 }
 
+func (r *SQLliteAuthorsRepository) FindAuthorByName(name string) (*models.Author, error) {
+	var author models.Author
+	err := r.db.QueryRow(`SELECT id, name, created_at FROM authors WHERE name = ?`, name).
+		Scan(&author.ID, &author.Name, &author.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &author, nil
+}
+
+// List orders authors by name. Name is matched with LIKE, its wildcards
+// escaped.
+func (r *SQLliteAuthorsRepository) List(filter models.AuthorFilter) ([]*models.Author, int, error) {
+	where := ""
+	var args []interface{}
+	if filter.Name != "" {
+		where = `WHERE name LIKE ? ESCAPE '\'`
+		args = append(args, "%"+likeEscaper.Replace(filter.Name)+"%")
+	}
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM authors `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db.Query(
+		`SELECT id, name, created_at FROM authors `+where+` ORDER BY name, id LIMIT ? OFFSET ?`,
+		append(args, filter.Limit, filter.Offset)...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	authors := []*models.Author{}
+	for rows.Next() {
+		var author models.Author
+		if err := rows.Scan(&author.ID, &author.Name, &author.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		authors = append(authors, &author)
+	}
+
+	return authors, total, rows.Err()
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Update renames the author and records an index sync intent for the
+// author's articles, whose documents carry the name, in one transaction.
+// It returns sql.ErrNoRows if there is no such author.
+func (r *SQLliteAuthorsRepository) Update(author *models.Author) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE authors SET name = ? WHERE id = ?`, author.Name, author.ID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	if err := enqueueIndexSync(tx, models.IndexSyncAuthor, author.ID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
 type SQLliteArticleRepository struct {
 	db *sql.DB
 }
@@ -63,14 +143,29 @@ type SQLliteArticleRepository struct {
 func NewSQLliteArticleRepository(db *sql.DB) *SQLliteArticleRepository {
 	return &SQLliteArticleRepository{db: db}
 }
+
+// nullString stores an empty string as NULL, which the foreign keys of
+// optional columns require.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// articleScope returns the tenant and project of the article, empty for a
+// shared article, or sql.ErrNoRows if there is no such article.
+func articleScope(tx *sql.Tx, id int) (tenantID, projectID string, err error) {
+	err = tx.QueryRow(`SELECT COALESCE(tenant_id, ''), COALESCE(project_id, '') FROM articles WHERE id = ?`, id).Scan(&tenantID, &projectID)
+	return tenantID, projectID, err
+}
 func (r *SQLliteArticleRepository) Save(article *models.Article) (int, error) {
 	query := `
 		INSERT INTO articles (
 			title, 
 			body, 
 			author_id,
+			tenant_id,
+			project_id,
 			created_at
-		) VALUES (?, ?, ?, ?);
+		) VALUES (?, ?, ?, ?, ?, ?);
 	`
 
 	tx, err := r.db.Begin()
@@ -83,6 +178,8 @@ func (r *SQLliteArticleRepository) Save(article *models.Article) (int, error) {
 		article.Title,
 		article.Body,
 		article.AuthorID,
+		nullString(article.TenantID),
+		nullString(article.ProjectID),
 		article.CreatedAt,
 	)
 	if err != nil {
@@ -106,6 +203,12 @@ func (r *SQLliteArticleRepository) Save(article *models.Article) (int, error) {
 		}
 	}
 
+	// The index sync intent commits with the article, so an article is
+	// never saved without eventually reaching the index.
+	if err := enqueueArticleIndexSync(tx, int(lastInsertedId), article.TenantID, article.ProjectID); err != nil {
+		return 0, err
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		return 0, err
@@ -114,10 +217,11 @@ func (r *SQLliteArticleRepository) Save(article *models.Article) (int, error) {
 	return int(lastInsertedId), err
 }
 
-func (r *SQLliteArticleRepository) FindByTag(tag *models.Tag) ([]*models.Article, error) {
+func (r *SQLliteArticleRepository) FindByTag(tenantID string, tag *models.Tag) ([]*models.Article, error) {
 	query := `
 		SELECT
 			a.id,
+			COALESCE(a.project_id, ''),
 			a.title, 
 			a.body, 
 			a.author_id,
@@ -125,6 +229,7 @@ func (r *SQLliteArticleRepository) FindByTag(tag *models.Tag) ([]*models.Article
 			a.created_at,
 			t.id,
 			t.label,
+			t.parent_id,
 			t.created_at,
 			t.updated_at
 		FROM articles a
@@ -136,9 +241,10 @@ func (r *SQLliteArticleRepository) FindByTag(tag *models.Tag) ([]*models.Article
 			FROM article_tags at
 			WHERE at.tag_id = ?
 		)
+		AND COALESCE(a.tenant_id, '') = ?
 	`
 
-	rows, err := r.db.Query(query, tag.ID)
+	rows, err := r.db.Query(query, tag.ID, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -149,17 +255,18 @@ func (r *SQLliteArticleRepository) FindByTag(tag *models.Tag) ([]*models.Article
 	for rows.Next() {
 		var (
 			articleID                            int
-			title, body                          string
+			projectID, title, body               string
 			authorID                             int
 			authorName, createdAt                string
 			tagID                                int
 			tagLabel, tagCreatedAt, tagUpdatedAt string
+			tagParentID                          *int
 		)
 
 		err := rows.Scan(
-			&articleID, &title, &body,
+			&articleID, &projectID, &title, &body,
 			&authorID, &authorName, &createdAt,
-			&tagID, &tagLabel, &tagCreatedAt, &tagUpdatedAt,
+			&tagID, &tagLabel, &tagParentID, &tagCreatedAt, &tagUpdatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -169,6 +276,8 @@ func (r *SQLliteArticleRepository) FindByTag(tag *models.Tag) ([]*models.Article
 		if !exists {
 			article = &models.Article{
 				ID:        articleID,
+				TenantID:  tenantID,
+				ProjectID: projectID,
 				Title:     title,
 				Body:      body,
 				AuthorID:  authorID,
@@ -182,6 +291,7 @@ func (r *SQLliteArticleRepository) FindByTag(tag *models.Tag) ([]*models.Article
 		article.Tags = append(article.Tags, &models.Tag{
 			ID:        tagID,
 			Label:     tagLabel,
+			ParentID:  tagParentID,
 			CreatedAt: tagCreatedAt,
 			UpdatedAt: tagUpdatedAt,
 		})
@@ -197,6 +307,422 @@ func (r *SQLliteArticleRepository) FindByTag(tag *models.Tag) ([]*models.Article
 
 }
 
+// FindByIDs returns the articles with the given IDs, tagged or not, in ID
+// order, with their tags' paths. IDs without an article are skipped; an
+// article whose author is gone is returned with an empty author name.
+func (r *SQLliteArticleRepository) FindByIDs(ids []int) ([]*models.Article, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	placeholders := strings.Repeat("?,", len(ids)-1) + "?"
+
+	query := fmt.Sprintf(`
+		SELECT
+			a.id,
+			COALESCE(a.tenant_id, ''),
+			COALESCE(a.project_id, ''),
+			a.title,
+			a.body,
+			a.author_id,
+			COALESCE(au.name, ''),
+			a.created_at,
+			t.id,
+			t.label,
+			t.parent_id,
+			t.created_at,
+			t.updated_at
+		FROM articles a
+		LEFT JOIN authors au ON a.author_id = au.id
+		LEFT JOIN article_tags at ON a.id = at.article_id
+		LEFT JOIN tags t ON at.tag_id = t.id
+		WHERE a.id IN (%s)
+		ORDER BY a.id, t.id
+	`, placeholders)
+
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var articles []*models.Article
+	for rows.Next() {
+		var (
+			articleID                            int
+			tenantID, projectID                  string
+			title, body                          string
+			authorID                             int
+			authorName, createdAt                string
+			tagID                                sql.NullInt64
+			tagLabel, tagCreatedAt, tagUpdatedAt sql.NullString
+			tagParentID                          *int
+		)
+
+		err := rows.Scan(
+			&articleID, &tenantID, &projectID, &title, &body,
+			&authorID, &authorName, &createdAt,
+			&tagID, &tagLabel, &tagParentID, &tagCreatedAt, &tagUpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(articles) == 0 || articles[len(articles)-1].ID != articleID {
+			articles = append(articles, &models.Article{
+				ID:        articleID,
+				TenantID:  tenantID,
+				ProjectID: projectID,
+				Title:     title,
+				Body:      body,
+				AuthorID:  authorID,
+				Author:    authorName,
+				CreatedAt: createdAt,
+				Tags:      []*models.Tag{},
+			})
+		}
+
+		if tagID.Valid {
+			article := articles[len(articles)-1]
+			article.Tags = append(article.Tags, &models.Tag{
+				ID:        int(tagID.Int64),
+				Label:     tagLabel.String,
+				ParentID:  tagParentID,
+				CreatedAt: tagCreatedAt.String,
+				UpdatedAt: tagUpdatedAt.String,
+			})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.loadTagPaths(articles); err != nil {
+		return nil, err
+	}
+	return articles, nil
+}
+
+// maxTagDepth bounds the walks up and down the taxonomy, so that a cycle
+// written to the tags table by hand cannot loop them forever.
+const maxTagDepth = 32
+
+// loadTagPaths sets the path of every tag of the articles.
+func (r *SQLliteArticleRepository) loadTagPaths(articles []*models.Article) error {
+	var tagIDs []int
+	seen := make(map[int]bool)
+	for _, article := range articles {
+		for _, tag := range article.Tags {
+			if !seen[tag.ID] {
+				seen[tag.ID] = true
+				tagIDs = append(tagIDs, tag.ID)
+			}
+		}
+	}
+	if len(tagIDs) == 0 {
+		return nil
+	}
+
+	placeholders := strings.Repeat("?,", len(tagIDs)-1) + "?"
+	query := fmt.Sprintf(`
+		WITH RECURSIVE lineage(tag_id, ancestor_id, depth) AS (
+			SELECT id, id, 0 FROM tags WHERE id IN (%s)
+			UNION ALL
+			SELECT l.tag_id, t.parent_id, l.depth + 1
+			FROM lineage l
+			JOIN tags t ON t.id = l.ancestor_id
+			WHERE t.parent_id IS NOT NULL AND l.depth < ?
+		)
+		SELECT l.tag_id, t.label
+		FROM lineage l
+		JOIN tags t ON t.id = l.ancestor_id
+		ORDER BY l.tag_id, l.depth DESC
+	`, placeholders)
+
+	args := make([]interface{}, 0, len(tagIDs)+1)
+	for _, id := range tagIDs {
+		args = append(args, id)
+	}
+	args = append(args, maxTagDepth)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	paths := make(map[int][]string, len(tagIDs))
+	for rows.Next() {
+		var tagID int
+		var label string
+		if err := rows.Scan(&tagID, &label); err != nil {
+			return err
+		}
+		paths[tagID] = append(paths[tagID], label)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, article := range articles {
+		for _, tag := range article.Tags {
+			tag.Path = paths[tag.ID]
+		}
+	}
+	return nil
+}
+
+// Update replaces the article's fields and tags and records an index sync
+// intent, in one transaction. It returns sql.ErrNoRows if there is no such
+// article.
+func (r *SQLliteArticleRepository) Update(article *models.Article) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// An article keeps its tenant and project, so the intent names the
+	// ones it was saved with.
+	tenantID, projectID, err := articleScope(tx, article.ID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE articles
+		SET title = ?, body = ?, author_id = ?
+		WHERE id = ?
+	`, article.Title, article.Body, article.AuthorID, article.ID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM article_tags WHERE article_id = ?`, article.ID); err != nil {
+		return err
+	}
+	for _, tag := range article.Tags {
+		if _, err := tx.Exec(`INSERT INTO article_tags (article_id, tag_id) VALUES (?, ?)`, article.ID, tag.ID); err != nil {
+			return err
+		}
+	}
+
+	if err := enqueueArticleIndexSync(tx, article.ID, tenantID, projectID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete removes the article and its tags and records an index sync intent,
+// which removes its document, in one transaction. It returns sql.ErrNoRows
+// if there is no such article.
+func (r *SQLliteArticleRepository) Delete(id int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// The intent keeps the tenant and project, as the sync can no longer
+	// look the article up to find the indexes holding its documents.
+	tenantID, projectID, err := articleScope(tx, id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM article_tags WHERE article_id = ?`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM articles WHERE id = ?`, id); err != nil {
+		return err
+	}
+
+	if err := enqueueArticleIndexSync(tx, id, tenantID, projectID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// FindByAuthor returns every article by the author, in ID order.
+func (r *SQLliteArticleRepository) FindByAuthor(authorID int) ([]*models.Article, error) {
+	rows, err := r.db.Query(`SELECT id FROM articles WHERE author_id = ? ORDER BY id`, authorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return r.FindByIDs(ids)
+}
+
+func (r *SQLliteArticleRepository) FindByTagSubtree(tagID int) ([]*models.Article, error) {
+	rows, err := r.db.Query(`
+		WITH RECURSIVE subtree(id, depth) AS (
+			SELECT ?, 0
+			UNION ALL
+			SELECT t.id, s.depth + 1
+			FROM tags t
+			JOIN subtree s ON t.parent_id = s.id
+			WHERE s.depth < ?
+		)
+		SELECT DISTINCT at.article_id
+		FROM article_tags at
+		JOIN subtree s ON at.tag_id = s.id
+		ORDER BY at.article_id
+	`, tagID, maxTagDepth)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return r.FindByIDs(ids)
+}
+
+func (r *SQLliteArticleRepository) FindByID(id int) (*models.Article, error) {
+	articles, err := r.FindByIDs([]int{id})
+	if err != nil || len(articles) == 0 {
+		return nil, err
+	}
+	return articles[0], nil
+}
+
+// List filters on created_at through SQLite's datetime(), which normalizes
+// the stored RFC 3339 timestamps, whatever their offset, to UTC.
+func (r *SQLliteArticleRepository) List(filter models.ArticleFilter) ([]*models.Article, int, error) {
+	conditions := []string{"COALESCE(a.tenant_id, '') = ?"}
+	args := []interface{}{filter.TenantID}
+	if filter.ProjectID != "" {
+		conditions = append(conditions, "a.project_id = ?")
+		args = append(args, filter.ProjectID)
+	}
+	if filter.AuthorID != 0 {
+		conditions = append(conditions, "a.author_id = ?")
+		args = append(args, filter.AuthorID)
+	}
+	if filter.Tag != "" {
+		conditions = append(conditions, `a.id IN (
+			SELECT at.article_id
+			FROM article_tags at
+			JOIN tags t ON at.tag_id = t.id
+			WHERE t.label = ?
+		)`)
+		args = append(args, filter.Tag)
+	}
+	if filter.From != nil {
+		conditions = append(conditions, "datetime(a.created_at) >= datetime(?)")
+		args = append(args, filter.From.UTC().Format(time.RFC3339))
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "datetime(a.created_at) <= datetime(?)")
+		args = append(args, filter.To.UTC().Format(time.RFC3339))
+	}
+
+	where := "WHERE " + strings.Join(conditions, " AND ")
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM articles a `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db.Query(
+		`SELECT a.id FROM articles a `+where+` ORDER BY datetime(a.created_at) DESC, a.id DESC LIMIT ? OFFSET ?`,
+		append(args, filter.Limit, filter.Offset)...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, 0, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	found, err := r.FindByIDs(ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	byID := make(map[int]*models.Article, len(found))
+	for _, article := range found {
+		byID[article.ID] = article
+	}
+	articles := make([]*models.Article, 0, len(ids))
+	for _, id := range ids {
+		if article, ok := byID[id]; ok {
+			articles = append(articles, article)
+		}
+	}
+
+	return articles, total, nil
+}
+
+// Count returns the number of shared articles, those without a tenant.
+func (r *SQLliteArticleRepository) Count() (int, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM articles WHERE tenant_id IS NULL`).Scan(&count)
+	return count, err
+}
+
+// ListAfterID returns up to limit shared articles with an ID above afterID,
+// in ID order, for paging through the shared articles index.
+func (r *SQLliteArticleRepository) ListAfterID(afterID, limit int) ([]*models.Article, error) {
+	rows, err := r.db.Query(`SELECT id FROM articles WHERE tenant_id IS NULL AND id > ? ORDER BY id LIMIT ?`, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return r.FindByIDs(ids)
+}
+
 type SQLliteTagsRepository struct {
 	db *sql.DB
 }
@@ -233,16 +759,40 @@ func (r *SQLliteTagsRepository) Save(tag *models.Tag) (int, error) {
 	return int(id), err
 }
 
+func (r *SQLliteTagsRepository) Update(tag *models.Tag) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE tags SET label = ?, updated_at = ? WHERE id = ?`, tag.Label, tag.UpdatedAt, tag.ID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	if err := enqueueIndexSync(tx, models.IndexSyncTag, tag.ID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
 func (r *SQLliteTagsRepository) FindByLabel(label string) (*models.Tag, error) {
 	query := `
-		SELECT id, label, created_at, updated_at
+		SELECT id, label, parent_id, created_at, updated_at
 		FROM tags
 		WHERE label = ?
 	`
 	row := r.db.QueryRow(query, label)
 
 	var tag models.Tag
-	err := row.Scan(&tag.ID, &tag.Label, &tag.CreatedAt, &tag.UpdatedAt)
+	err := row.Scan(&tag.ID, &tag.Label, &tag.ParentID, &tag.CreatedAt, &tag.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -258,7 +808,7 @@ func (r *SQLliteTagsRepository) FindByLabels(labels []string) ([]*models.Tag, er
 	placeholders := strings.Repeat("?,", len(labels)-1) + "?"
 
 	query := fmt.Sprintf(`
-		SELECT id, label, created_at, updated_at
+		SELECT id, label, parent_id, created_at, updated_at
 		FROM tags
 		WHERE label IN (%s)
 	`, placeholders)
@@ -277,7 +827,7 @@ func (r *SQLliteTagsRepository) FindByLabels(labels []string) ([]*models.Tag, er
 	var tags []*models.Tag
 	for rows.Next() {
 		var tag models.Tag
-		err := rows.Scan(&tag.ID, &tag.Label, &tag.CreatedAt, &tag.UpdatedAt)
+		err := rows.Scan(&tag.ID, &tag.Label, &tag.ParentID, &tag.CreatedAt, &tag.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -289,14 +839,14 @@ func (r *SQLliteTagsRepository) FindByLabels(labels []string) ([]*models.Tag, er
 
 func (r *SQLliteTagsRepository) FindById(id int) (*models.Tag, error) {
 	query := `
-		SELECT id, label, created_at, updated_at
+		SELECT id, label, parent_id, created_at, updated_at
 		FROM tags
 		WHERE id = ?
 	`
 	row := r.db.QueryRow(query, id)
 
 	var tag models.Tag
-	err := row.Scan(&tag.ID, &tag.Label, &tag.CreatedAt, &tag.UpdatedAt)
+	err := row.Scan(&tag.ID, &tag.Label, &tag.ParentID, &tag.CreatedAt, &tag.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -306,7 +856,7 @@ func (r *SQLliteTagsRepository) FindById(id int) (*models.Tag, error) {
 
 func (r *SQLliteTagsRepository) FindAll() ([]*models.Tag, error) {
 	query := `
-		SELECT id, label, created_at, updated_at
+		SELECT id, label, parent_id, created_at, updated_at
 		FROM tags
 	`
 	rows, err := r.db.Query(query)
@@ -318,7 +868,7 @@ func (r *SQLliteTagsRepository) FindAll() ([]*models.Tag, error) {
 	var tags []*models.Tag
 	for rows.Next() {
 		var tag models.Tag
-		err := rows.Scan(&tag.ID, &tag.Label, &tag.CreatedAt, &tag.UpdatedAt)
+		err := rows.Scan(&tag.ID, &tag.Label, &tag.ParentID, &tag.CreatedAt, &tag.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -327,3 +877,253 @@ func (r *SQLliteTagsRepository) FindAll() ([]*models.Tag, error) {
 
 	return tags, nil
 }
+
+// Delete returns sql.ErrNoRows if there is no such tag.
+func (r *SQLliteTagsRepository) Delete(id int, detach bool) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	articleIDs, err := taggedArticleIDs(tx, id)
+	if err != nil {
+		return 0, err
+	}
+	if len(articleIDs) > 0 && !detach {
+		return 0, models.ErrTagInUse
+	}
+
+	if _, err := tx.Exec(`DELETE FROM article_tags WHERE tag_id = ?`, id); err != nil {
+		return 0, err
+	}
+	if err := moveChildTags(tx, id, `(SELECT parent_id FROM tags WHERE id = ?)`, id); err != nil {
+		return 0, err
+	}
+	result, err := tx.Exec(`DELETE FROM tags WHERE id = ?`, id)
+	if err != nil {
+		return 0, err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return 0, err
+	} else if n == 0 {
+		return 0, sql.ErrNoRows
+	}
+
+	for _, articleID := range articleIDs {
+		if err := enqueueIndexSync(tx, models.IndexSyncArticle, articleID); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(articleIDs), tx.Commit()
+}
+
+// Merge keeps a single article_tags row for articles that carry both tags.
+// It fails with models.ErrTagCycle if the target is in the source's
+// subtree, and returns sql.ErrNoRows if there is no source tag.
+func (r *SQLliteTagsRepository) Merge(sourceID, targetID int) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if cycle, err := inTagSubtree(tx, sourceID, targetID); err != nil {
+		return 0, err
+	} else if cycle {
+		return 0, models.ErrTagCycle
+	}
+
+	articleIDs, err := taggedArticleIDs(tx, sourceID)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(`
+		INSERT OR IGNORE INTO article_tags (article_id, tag_id)
+		SELECT article_id, ? FROM article_tags WHERE tag_id = ?
+	`, targetID, sourceID); err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(`DELETE FROM article_tags WHERE tag_id = ?`, sourceID); err != nil {
+		return 0, err
+	}
+	if err := moveChildTags(tx, sourceID, `?`, targetID); err != nil {
+		return 0, err
+	}
+	result, err := tx.Exec(`DELETE FROM tags WHERE id = ?`, sourceID)
+	if err != nil {
+		return 0, err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return 0, err
+	} else if n == 0 {
+		return 0, sql.ErrNoRows
+	}
+
+	for _, articleID := range articleIDs {
+		if err := enqueueIndexSync(tx, models.IndexSyncArticle, articleID); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(articleIDs), tx.Commit()
+}
+
+func taggedArticleIDs(tx *sql.Tx, tagID int) ([]int, error) {
+	rows, err := tx.Query(`SELECT article_id FROM article_tags WHERE tag_id = ? ORDER BY article_id`, tagID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// moveChildTags re-parents the tag's children onto the parent that
+// parentExpr (with its args) selects, and records an index sync intent for
+// each child's subtree, whose paths change.
+func moveChildTags(tx *sql.Tx, id int, parentExpr string, args ...interface{}) error {
+	rows, err := tx.Query(`SELECT id FROM tags WHERE parent_id = ? ORDER BY id`, id)
+	if err != nil {
+		return err
+	}
+	var children []int
+	for rows.Next() {
+		var child int
+		if err := rows.Scan(&child); err != nil {
+			rows.Close()
+			return err
+		}
+		children = append(children, child)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(children) == 0 {
+		return nil
+	}
+
+	if _, err := tx.Exec(`UPDATE tags SET parent_id = `+parentExpr+` WHERE parent_id = ?`, append(args, id)...); err != nil {
+		return err
+	}
+	for _, child := range children {
+		if err := enqueueIndexSync(tx, models.IndexSyncTag, child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inTagSubtree reports whether id is rootID or one of its descendants.
+func inTagSubtree(tx *sql.Tx, rootID, id int) (bool, error) {
+	var found bool
+	err := tx.QueryRow(`
+		WITH RECURSIVE subtree(id, depth) AS (
+			SELECT ?, 0
+			UNION ALL
+			SELECT t.id, s.depth + 1
+			FROM tags t
+			JOIN subtree s ON t.parent_id = s.id
+			WHERE s.depth < ?
+		)
+		SELECT EXISTS (SELECT 1 FROM subtree WHERE id = ?)
+	`, rootID, maxTagDepth, id).Scan(&found)
+	return found, err
+}
+
+// Move returns sql.ErrNoRows if there is no such tag.
+func (r *SQLliteTagsRepository) Move(id int, parentID *int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if parentID != nil {
+		if cycle, err := inTagSubtree(tx, id, *parentID); err != nil {
+			return err
+		} else if cycle {
+			return models.ErrTagCycle
+		}
+	}
+
+	result, err := tx.Exec(`UPDATE tags SET parent_id = ?, updated_at = ? WHERE id = ?`,
+		parentID, time.Now().Format(time.RFC3339), id)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	if err := enqueueIndexSync(tx, models.IndexSyncTag, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *SQLliteTagsRepository) Ancestors(id int) ([]*models.Tag, error) {
+	return r.queryTags(`
+		WITH RECURSIVE lineage(id, depth) AS (
+			SELECT parent_id, 1 FROM tags WHERE id = ? AND parent_id IS NOT NULL
+			UNION ALL
+			SELECT t.parent_id, l.depth + 1
+			FROM lineage l
+			JOIN tags t ON t.id = l.id
+			WHERE t.parent_id IS NOT NULL AND l.depth < ?
+		)
+		SELECT t.id, t.label, t.parent_id, t.created_at, t.updated_at
+		FROM lineage l
+		JOIN tags t ON t.id = l.id
+		ORDER BY l.depth DESC
+	`, id, maxTagDepth)
+}
+
+func (r *SQLliteTagsRepository) Descendants(id int) ([]*models.Tag, error) {
+	return r.queryTags(`
+		WITH RECURSIVE subtree(id, depth) AS (
+			SELECT id, 1 FROM tags WHERE parent_id = ?
+			UNION ALL
+			SELECT t.id, s.depth + 1
+			FROM tags t
+			JOIN subtree s ON t.parent_id = s.id
+			WHERE s.depth < ?
+		)
+		SELECT t.id, t.label, t.parent_id, t.created_at, t.updated_at
+		FROM subtree s
+		JOIN tags t ON t.id = s.id
+		ORDER BY s.depth, t.label
+	`, id, maxTagDepth)
+}
+
+func (r *SQLliteTagsRepository) queryTags(query string, args ...interface{}) ([]*models.Tag, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []*models.Tag{}
+	for rows.Next() {
+		var tag models.Tag
+		if err := rows.Scan(&tag.ID, &tag.Label, &tag.ParentID, &tag.CreatedAt, &tag.UpdatedAt); err != nil {
+			return nil, err
+		}
+		tags = append(tags, &tag)
+	}
+	return tags, rows.Err()
+}
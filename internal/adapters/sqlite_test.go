@@ -0,0 +1,438 @@
+package adapters
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"mini-search-platform/internal/models"
+)
+
+func TestSQLliteArticleRepository_UpdateAndDeleteQueueIndexSyncIntents(t *testing.T) {
+	db := newTestDB(t)
+	authors := NewSQLliteAuthorsRepository(db)
+	tags := NewSQLliteTagsRepository(db)
+	articles := NewSQLliteArticleRepository(db)
+	outbox := NewSQLiteIndexSyncOutboxRepository(db)
+
+	ada, _ := authors.Save(&models.Author{ID: 1, Name: "Ada"})
+	grace, _ := authors.Save(&models.Author{ID: 2, Name: "Grace"})
+	goTag, _ := tags.Save(models.NewTag("go"))
+	sqlTag, _ := tags.Save(models.NewTag("sql"))
+
+	id, err := articles.Save(models.NewArticle("Title", "body", &models.Author{ID: ada, Name: "Ada"}, []*models.Tag{{ID: goTag}}))
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	err = articles.Update(&models.Article{ID: id, Title: "New title", Body: "new body", AuthorID: grace, Tags: []*models.Tag{{ID: sqlTag}}})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	article, err := articles.FindByID(id)
+	if err != nil {
+		t.Fatalf("FindByID failed: %v", err)
+	}
+	if article == nil || article.Title != "New title" || article.Body != "new body" || article.Author != "Grace" ||
+		len(article.Tags) != 1 || article.Tags[0].Label != "sql" {
+		t.Fatalf("unexpected updated article %+v", article)
+	}
+
+	if err := articles.Update(&models.Article{ID: 999, Title: "x", Body: "x", AuthorID: ada}); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows updating a missing article, got %v", err)
+	}
+
+	if err := articles.Delete(id); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if article, err := articles.FindByID(id); err != nil || article != nil {
+		t.Errorf("expected the article to be gone, got %+v, %v", article, err)
+	}
+	if err := articles.Delete(id); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows deleting a missing article, got %v", err)
+	}
+
+	due, err := outbox.ListDue(time.Now().Add(time.Second), 10)
+	if err != nil {
+		t.Fatalf("ListDue failed: %v", err)
+	}
+	if len(due) != 3 {
+		t.Fatalf("expected an intent for the save, the update and the delete, got %+v", due)
+	}
+	for _, intent := range due {
+		if intent.Kind != models.IndexSyncArticle || intent.EntityID != id {
+			t.Errorf("unexpected intent %+v", intent)
+		}
+	}
+}
+
+func TestSQLliteArticleRepository_List(t *testing.T) {
+	db := newTestDB(t)
+	authors := NewSQLliteAuthorsRepository(db)
+	tags := NewSQLliteTagsRepository(db)
+	articles := NewSQLliteArticleRepository(db)
+
+	ada, _ := authors.Save(&models.Author{ID: 1, Name: "Ada"})
+	grace, _ := authors.Save(&models.Author{ID: 2, Name: "Grace"})
+	goTag, _ := tags.Save(models.NewTag("go"))
+
+	save := func(title string, authorID int, createdAt string, tagged bool) int {
+		article := models.NewArticle(title, "body", &models.Author{ID: authorID}, nil)
+		article.CreatedAt = createdAt
+		if tagged {
+			article.Tags = []*models.Tag{{ID: goTag}}
+		}
+		id, err := articles.Save(article)
+		if err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		return id
+	}
+	january := save("January", ada, "2025-01-15T10:00:00Z", true)
+	february := save("February", grace, "2025-02-15T10:00:00+02:00", false)
+	march := save("March", ada, "2025-03-15T10:00:00Z", true)
+
+	ids := func(filter models.ArticleFilter) ([]int, int) {
+		t.Helper()
+		if filter.Limit == 0 {
+			filter.Limit = 10
+		}
+		found, total, err := articles.List(filter)
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+		result := make([]int, len(found))
+		for i, article := range found {
+			result[i] = article.ID
+		}
+		return result, total
+	}
+	equal := func(got []int, want ...int) bool {
+		if len(got) != len(want) {
+			return false
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	if got, total := ids(models.ArticleFilter{}); !equal(got, march, february, january) || total != 3 {
+		t.Errorf("expected every article, newest first, got %v (total %d)", got, total)
+	}
+	if got, total := ids(models.ArticleFilter{Limit: 1, Offset: 1}); !equal(got, february) || total != 3 {
+		t.Errorf("expected the second page, got %v (total %d)", got, total)
+	}
+	if got, _ := ids(models.ArticleFilter{AuthorID: ada}); !equal(got, march, january) {
+		t.Errorf("expected Ada's articles, got %v", got)
+	}
+	if got, _ := ids(models.ArticleFilter{Tag: "go"}); !equal(got, march, january) {
+		t.Errorf("expected the tagged articles, got %v", got)
+	}
+
+	// February's article was created at 08:00 UTC.
+	from := time.Date(2025, 2, 15, 8, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	if got, total := ids(models.ArticleFilter{From: &from, To: &to}); !equal(got, february) || total != 1 {
+		t.Errorf("expected February's article, got %v (total %d)", got, total)
+	}
+	from = from.Add(time.Second)
+	if got, _ := ids(models.ArticleFilter{From: &from, To: &to}); len(got) != 0 {
+		t.Errorf("expected no article, got %v", got)
+	}
+}
+
+func TestSQLliteArticleRepository_TenantScoping(t *testing.T) {
+	db := newTestDB(t)
+	tenants := NewSQLiteTenantRepository(db)
+	projects := NewSQLiteProjectRepository(db)
+	authors := NewSQLliteAuthorsRepository(db)
+	tags := NewSQLliteTagsRepository(db)
+	articles := NewSQLliteArticleRepository(db)
+	outbox := NewSQLiteIndexSyncOutboxRepository(db)
+
+	acme := models.NewTenant("acme", "Acme")
+	globex := models.NewTenant("globex", "Globex")
+	for _, tenant := range []*models.Tenant{acme, globex} {
+		if err := tenants.Save(tenant); err != nil {
+			t.Fatalf("save tenant: %v", err)
+		}
+	}
+	docs := models.NewProject("docs", acme.ID, "Docs", models.TierFree)
+	if err := projects.Save(docs); err != nil {
+		t.Fatalf("save project: %v", err)
+	}
+	ada, _ := authors.Save(&models.Author{ID: 1, Name: "Ada"})
+	goTag, _ := tags.Save(models.NewTag("go"))
+
+	save := func(tenantID, projectID string) int {
+		article := models.NewArticle("Title", "body", &models.Author{ID: ada}, []*models.Tag{{ID: goTag}})
+		article.TenantID = tenantID
+		article.ProjectID = projectID
+		id, err := articles.Save(article)
+		if err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		return id
+	}
+	shared := save("", "")
+	inProject := save(acme.ID, docs.ID)
+	acmeOnly := save(acme.ID, "")
+	globexOnly := save(globex.ID, "")
+
+	article, err := articles.FindByID(inProject)
+	if err != nil || article == nil || article.TenantID != acme.ID || article.ProjectID != docs.ID {
+		t.Fatalf("expected the article's tenant and project, got %+v, %v", article, err)
+	}
+
+	if found, total, _ := articles.List(models.ArticleFilter{TenantID: acme.ID, Limit: 10}); total != 2 || len(found) != 2 {
+		t.Errorf("expected Acme's two articles, got %d (total %d)", len(found), total)
+	}
+	if found, _, _ := articles.List(models.ArticleFilter{TenantID: acme.ID, ProjectID: docs.ID, Limit: 10}); len(found) != 1 || found[0].ID != inProject {
+		t.Errorf("expected the project's article, got %+v", found)
+	}
+	if found, _ := articles.FindByTag(globex.ID, &models.Tag{ID: goTag}); len(found) != 1 || found[0].ID != globexOnly {
+		t.Errorf("expected Globex's tagged article, got %+v", found)
+	}
+
+	// Reindex and drift page through the shared articles only.
+	if count, _ := articles.Count(); count != 1 {
+		t.Errorf("expected one shared article, got %d", count)
+	}
+	if page, _ := articles.ListAfterID(0, 10); len(page) != 1 || page[0].ID != shared {
+		t.Errorf("expected the shared article, got %+v", page)
+	}
+
+	if err := articles.Delete(acmeOnly); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	due, err := outbox.ListDue(time.Now().Add(time.Second), 10)
+	if err != nil {
+		t.Fatalf("ListDue failed: %v", err)
+	}
+	tenantOf := make(map[int][]string)
+	for _, intent := range due {
+		tenantOf[intent.EntityID] = append(tenantOf[intent.EntityID], intent.TenantID)
+	}
+	if got := tenantOf[shared]; len(got) != 1 || got[0] != "" {
+		t.Errorf("expected the shared article's intent without a tenant, got %v", got)
+	}
+	if got := tenantOf[acmeOnly]; len(got) != 2 || got[0] != acme.ID || got[1] != acme.ID {
+		t.Errorf("expected the save and delete intents to name Acme, got %v", got)
+	}
+}
+
+func TestSQLliteAuthorsRepository_ListAndUpdate(t *testing.T) {
+	db := newTestDB(t)
+	authors := NewSQLliteAuthorsRepository(db)
+	articles := NewSQLliteArticleRepository(db)
+	outbox := NewSQLiteIndexSyncOutboxRepository(db)
+
+	for i, name := range []string{"Grace Hopper", "Ada Lovelace", "Adam_Smith", "Barbara 100%"} {
+		if _, err := authors.Save(&models.Author{ID: i + 1, Name: name}); err != nil {
+			t.Fatalf("failed to save author: %v", err)
+		}
+	}
+
+	names := func(filter models.AuthorFilter) ([]string, int) {
+		t.Helper()
+		filter.Limit = max(filter.Limit, 10)
+		found, total, err := authors.List(filter)
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+		result := make([]string, len(found))
+		for i, author := range found {
+			result[i] = author.Name
+		}
+		return result, total
+	}
+
+	if got, total := names(models.AuthorFilter{}); len(got) != 4 || got[0] != "Ada Lovelace" || total != 4 {
+		t.Errorf("expected every author by name, got %v (total %d)", got, total)
+	}
+	if got, total := names(models.AuthorFilter{Name: "ada"}); len(got) != 2 || total != 2 {
+		t.Errorf("expected a case-insensitive match, got %v", got)
+	}
+	if got, _ := names(models.AuthorFilter{Name: "m_s"}); len(got) != 1 || got[0] != "Adam_Smith" {
+		t.Errorf("expected _ to match literally, got %v", got)
+	}
+	if got, _ := names(models.AuthorFilter{Name: "0%"}); len(got) != 1 || got[0] != "Barbara 100%" {
+		t.Errorf("expected %% to match literally, got %v", got)
+	}
+
+	if found, err := authors.FindAuthorByName("Ada Lovelace"); err != nil || found == nil || found.ID != 2 {
+		t.Errorf("unexpected author %+v, %v", found, err)
+	}
+	if found, err := authors.FindAuthorByName("Nobody"); err != nil || found != nil {
+		t.Errorf("expected no author, got %+v, %v", found, err)
+	}
+
+	first, _ := articles.Save(models.NewArticle("First", "body", &models.Author{ID: 2}, nil))
+	articles.Save(models.NewArticle("Other", "body", &models.Author{ID: 1}, nil))
+	second, _ := articles.Save(models.NewArticle("Second", "body", &models.Author{ID: 2}, nil))
+	byAda, err := articles.FindByAuthor(2)
+	if err != nil || len(byAda) != 2 || byAda[0].ID != first || byAda[1].ID != second {
+		t.Fatalf("expected Ada's articles, got %+v, %v", byAda, err)
+	}
+
+	stats, _ := outbox.Stats()
+	if err := authors.Update(&models.Author{ID: 2, Name: "Augusta Ada King"}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if renamed, _ := authors.FindAuthorById(2); renamed.Name != "Augusta Ada King" {
+		t.Errorf("expected the author to be renamed, got %+v", renamed)
+	}
+	if after, _ := outbox.Stats(); after.Pending != stats.Pending+1 {
+		t.Errorf("expected the rename to queue an intent, got %d pending", after.Pending)
+	}
+	if err := authors.Update(&models.Author{ID: 99, Name: "Nobody"}); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows renaming a missing author, got %v", err)
+	}
+}
+
+func TestSQLliteTagsRepository_DeleteAndMerge(t *testing.T) {
+	db := newTestDB(t)
+	authors := NewSQLliteAuthorsRepository(db)
+	tags := NewSQLliteTagsRepository(db)
+	articles := NewSQLliteArticleRepository(db)
+	outbox := NewSQLiteIndexSyncOutboxRepository(db)
+
+	authors.Save(&models.Author{ID: 1, Name: "Ada"})
+	summer, _ := tags.Save(models.NewTag("Summer"))
+	summer2025, _ := tags.Save(models.NewTag("summer-2025"))
+	unused, _ := tags.Save(models.NewTag("unused"))
+
+	both, _ := articles.Save(models.NewArticle("Both", "body", &models.Author{ID: 1}, []*models.Tag{{ID: summer}, {ID: summer2025}}))
+	source, _ := articles.Save(models.NewArticle("Source", "body", &models.Author{ID: 1}, []*models.Tag{{ID: summer2025}}))
+	pending := func() int {
+		stats, _ := outbox.Stats()
+		return stats.Pending
+	}
+	before := pending()
+
+	if _, err := tags.Delete(summer, false); err != models.ErrTagInUse {
+		t.Fatalf("expected ErrTagInUse, got %v", err)
+	}
+	if n, err := tags.Delete(unused, false); err != nil || n != 0 {
+		t.Fatalf("expected the unused tag to be deleted, got %d, %v", n, err)
+	}
+	if _, err := tags.Delete(unused, false); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows deleting a missing tag, got %v", err)
+	}
+
+	moved, err := tags.Merge(summer2025, summer)
+	if err != nil || moved != 2 {
+		t.Fatalf("expected both articles to move, got %d, %v", moved, err)
+	}
+	if _, err := tags.FindById(summer2025); err != sql.ErrNoRows {
+		t.Errorf("expected the source tag to be gone, got %v", err)
+	}
+	merged, _ := articles.FindByIDs([]int{both, source})
+	for _, article := range merged {
+		if len(article.Tags) != 1 || article.Tags[0].ID != summer {
+			t.Errorf("expected article %d to carry only the target tag, got %+v", article.ID, article.Tags)
+		}
+	}
+	if got := pending() - before; got != 2 {
+		t.Errorf("expected an intent per moved article, got %d", got)
+	}
+
+	detached, err := tags.Delete(summer, true)
+	if err != nil || detached != 2 {
+		t.Fatalf("expected the tag to be detached from both articles, got %d, %v", detached, err)
+	}
+	if remaining, _ := articles.FindByIDs([]int{both}); len(remaining[0].Tags) != 0 {
+		t.Errorf("expected no tags left, got %+v", remaining[0].Tags)
+	}
+	if got := pending() - before; got != 4 {
+		t.Errorf("expected an intent per detached article, got %d", got-2)
+	}
+}
+
+func TestSQLliteTagsRepository_Hierarchy(t *testing.T) {
+	db := newTestDB(t)
+	authors := NewSQLliteAuthorsRepository(db)
+	tags := NewSQLliteTagsRepository(db)
+	articles := NewSQLliteArticleRepository(db)
+	outbox := NewSQLiteIndexSyncOutboxRepository(db)
+
+	authors.Save(&models.Author{ID: 1, Name: "Ada"})
+	clothing, _ := tags.Save(models.NewTag("Clothing"))
+	dresses, _ := tags.Save(models.NewTag("Dresses"))
+	maxi, _ := tags.Save(models.NewTag("Maxi"))
+	shoes, _ := tags.Save(models.NewTag("Shoes"))
+
+	for child, parent := range map[int]int{dresses: clothing, maxi: dresses, shoes: clothing} {
+		if err := tags.Move(child, &parent); err != nil {
+			t.Fatalf("Move failed: %v", err)
+		}
+	}
+	if err := tags.Move(clothing, &maxi); err != models.ErrTagCycle {
+		t.Errorf("expected ErrTagCycle moving a tag under its descendant, got %v", err)
+	}
+	if err := tags.Move(dresses, &dresses); err != models.ErrTagCycle {
+		t.Errorf("expected ErrTagCycle moving a tag under itself, got %v", err)
+	}
+	if _, err := tags.Merge(clothing, maxi); err != models.ErrTagCycle {
+		t.Errorf("expected ErrTagCycle merging a tag into its descendant, got %v", err)
+	}
+
+	labels := func(found []*models.Tag, err error) []string {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("query failed: %v", err)
+		}
+		result := make([]string, len(found))
+		for i, tag := range found {
+			result[i] = tag.Label
+		}
+		return result
+	}
+	if got := labels(tags.Ancestors(maxi)); len(got) != 2 || got[0] != "Clothing" || got[1] != "Dresses" {
+		t.Errorf("expected Maxi's ancestors root first, got %v", got)
+	}
+	if got := labels(tags.Descendants(clothing)); len(got) != 3 || got[0] != "Dresses" || got[1] != "Shoes" || got[2] != "Maxi" {
+		t.Errorf("expected Clothing's subtree level by level, got %v", got)
+	}
+	if got := labels(tags.Ancestors(clothing)); len(got) != 0 {
+		t.Errorf("expected a root to have no ancestors, got %v", got)
+	}
+
+	dress, _ := articles.Save(models.NewArticle("Dress", "body", &models.Author{ID: 1}, []*models.Tag{{ID: maxi}}))
+	shoe, _ := articles.Save(models.NewArticle("Shoe", "body", &models.Author{ID: 1}, []*models.Tag{{ID: shoes}}))
+
+	found, err := articles.FindByID(dress)
+	if err != nil {
+		t.Fatalf("FindByID failed: %v", err)
+	}
+	if got := found.Tags[0].PathLabel(); got != "Clothing > Dresses > Maxi" {
+		t.Errorf("expected the tag's path, got %q", got)
+	}
+	if subtree, _ := articles.FindByTagSubtree(clothing); len(subtree) != 2 || subtree[0].ID != dress || subtree[1].ID != shoe {
+		t.Errorf("expected every article under Clothing, got %+v", subtree)
+	}
+	if subtree, _ := articles.FindByTagSubtree(dresses); len(subtree) != 1 || subtree[0].ID != dress {
+		t.Errorf("expected only the dress under Dresses, got %+v", subtree)
+	}
+
+	before, _ := outbox.Stats()
+	if _, err := tags.Delete(dresses, false); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if got := labels(tags.Ancestors(maxi)); len(got) != 1 || got[0] != "Clothing" {
+		t.Errorf("expected Maxi to move up to Clothing, got %v", got)
+	}
+	if after, _ := outbox.Stats(); after.Pending != before.Pending+1 {
+		t.Errorf("expected an intent for the moved child, got %d pending", after.Pending-before.Pending)
+	}
+
+	if err := tags.Move(maxi, nil); err != nil {
+		t.Fatalf("Move failed: %v", err)
+	}
+	if found, _ := articles.FindByID(dress); found.Tags[0].PathLabel() != "Maxi" || found.Tags[0].ParentID != nil {
+		t.Errorf("expected Maxi to be a root, got %+v", found.Tags[0])
+	}
+}
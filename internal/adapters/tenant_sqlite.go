@@ -69,3 +69,151 @@ func (r *SQLiteTenantRepository) Delete(id string) error {
 	_, err := r.db.Exec(query, id)
 	return err
 }
+
+func (r *SQLiteTenantRepository) Purge(id string) (*models.TenantDeletionReceipt, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	receipt := &models.TenantDeletionReceipt{TenantID: id}
+
+	// Foreign keys are not enforced on our SQLite connections, so the
+	// ON DELETE CASCADE clauses in the schema never fire; every dependent
+	// table is cleared explicitly, children first.
+	tenantArticles := `
+		SELECT id FROM articles
+		WHERE tenant_id = ?
+		OR project_id IN (SELECT id FROM projects WHERE tenant_id = ?)
+	`
+
+	steps := []struct {
+		query string
+		args  []interface{}
+		count *int
+	}{
+		{`DELETE FROM article_tags WHERE article_id IN (` + tenantArticles + `)`, []interface{}{id, id}, &receipt.ArticleTagsDeleted},
+		{`DELETE FROM articles WHERE id IN (` + tenantArticles + `)`, []interface{}{id, id}, &receipt.ArticlesDeleted},
+		{`DELETE FROM projects WHERE tenant_id = ?`, []interface{}{id}, &receipt.ProjectsDeleted},
+		{`DELETE FROM memberships WHERE tenant_id = ?`, []interface{}{id}, &receipt.MembershipsDeleted},
+		// Pending invitations and custom roles are not reported on the
+		// receipt.
+		{`DELETE FROM invitations WHERE tenant_id = ?`, []interface{}{id}, new(int)},
+		{`DELETE FROM custom_roles WHERE tenant_id = ?`, []interface{}{id}, new(int)},
+		// Neither is the tenant's other data, which the receipt only vouches
+		// for by the tenant being purged.
+		{`DELETE FROM usage_rollups WHERE tenant_id = ?`, []interface{}{id}, new(int)},
+		{`DELETE FROM search_logs WHERE tenant_id = ?`, []interface{}{id}, new(int)},
+		{`DELETE FROM search_events WHERE tenant_id = ?`, []interface{}{id}, new(int)},
+		{`DELETE FROM document_popularity WHERE tenant_id = ?`, []interface{}{id}, new(int)},
+		{`DELETE FROM query_rules WHERE tenant_id = ?`, []interface{}{id}, new(int)},
+		{`DELETE FROM tenant_vocabulary WHERE tenant_id = ?`, []interface{}{id}, new(int)},
+		{`DELETE FROM saved_search_matches WHERE saved_search_id IN (SELECT id FROM saved_searches WHERE tenant_id = ?)`, []interface{}{id}, new(int)},
+		{`DELETE FROM saved_searches WHERE tenant_id = ?`, []interface{}{id}, new(int)},
+		{`DELETE FROM webhook_deliveries WHERE tenant_id = ?`, []interface{}{id}, new(int)},
+		{`DELETE FROM webhook_subscriptions WHERE tenant_id = ?`, []interface{}{id}, new(int)},
+		{`DELETE FROM webhook_dead_letters WHERE tenant_id = ?`, []interface{}{id}, new(int)},
+		// Except the drops of deleted projects' indexes, which offboarding
+		// no longer finds among the tenant's projects.
+		{`DELETE FROM index_sync_outbox WHERE tenant_id = ? AND kind != 'project'`, []interface{}{id}, new(int)},
+		{`DELETE FROM index_sync_dead_letters WHERE tenant_id = ?`, []interface{}{id}, new(int)},
+	}
+
+	for _, step := range steps {
+		result, err := tx.Exec(step.query, step.args...)
+		if err != nil {
+			return nil, err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		*step.count = int(affected)
+	}
+
+	result, err := tx.Exec(`DELETE FROM tenants WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	receipt.TenantFound = affected > 0
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return receipt, nil
+}
+
+type SQLiteTenantDeletionReceiptRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteTenantDeletionReceiptRepository(db *sql.DB) *SQLiteTenantDeletionReceiptRepository {
+	return &SQLiteTenantDeletionReceiptRepository{db: db}
+}
+
+func (r *SQLiteTenantDeletionReceiptRepository) Save(receipt *models.TenantDeletionReceipt) error {
+	query := `
+		INSERT INTO tenant_deletion_receipts (
+			id,
+			tenant_id,
+			tenant_found,
+			memberships_deleted,
+			projects_deleted,
+			articles_deleted,
+			article_tags_deleted,
+			index_name,
+			index_task_uid,
+			deleted_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := r.db.Exec(query,
+		receipt.ID,
+		receipt.TenantID,
+		receipt.TenantFound,
+		receipt.MembershipsDeleted,
+		receipt.ProjectsDeleted,
+		receipt.ArticlesDeleted,
+		receipt.ArticleTagsDeleted,
+		receipt.IndexName,
+		receipt.IndexTaskUID,
+		receipt.DeletedAt,
+	)
+	return err
+}
+
+func (r *SQLiteTenantDeletionReceiptRepository) ListByTenant(tenantID string) ([]*models.TenantDeletionReceipt, error) {
+	query := `
+		SELECT id, tenant_id, tenant_found, memberships_deleted, projects_deleted,
+			articles_deleted, article_tags_deleted, index_name, index_task_uid, deleted_at
+		FROM tenant_deletion_receipts
+		WHERE tenant_id = ?
+		ORDER BY deleted_at DESC
+	`
+	rows, err := r.db.Query(query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var receipts []*models.TenantDeletionReceipt
+	for rows.Next() {
+		receipt := &models.TenantDeletionReceipt{}
+		if err := rows.Scan(
+			&receipt.ID, &receipt.TenantID, &receipt.TenantFound,
+			&receipt.MembershipsDeleted, &receipt.ProjectsDeleted,
+			&receipt.ArticlesDeleted, &receipt.ArticleTagsDeleted,
+			&receipt.IndexName, &receipt.IndexTaskUID, &receipt.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		receipts = append(receipts, receipt)
+	}
+
+	return receipts, rows.Err()
+}
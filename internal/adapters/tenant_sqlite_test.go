@@ -0,0 +1,200 @@
+package adapters
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+
+	"mini-search-platform/internal/database"
+	"mini-search-platform/internal/models"
+	"mini-search-platform/pkg/sqlite"
+
+	"github.com/google/uuid"
+)
+
+// newTestDB opens a private in-memory SQLite database with the full schema.
+// Each call gets its own name, so tests never see each other's rows even
+// though the shared cache keeps the database alive across pooled connections.
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sqlite.Init("file:" + uuid.NewString() + "?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { sqlite.Close(db) })
+
+	if err := database.Create(db); err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+	return db
+}
+
+func TestSQLiteTenantRepository_Purge_CascadesAndLeavesOtherTenantsAlone(t *testing.T) {
+	db := newTestDB(t)
+	tenants := NewSQLiteTenantRepository(db)
+	memberships := NewSQLiteMembershipRepository(db)
+	projects := NewSQLiteProjectRepository(db)
+	users := NewSQLiteUserRepository(db)
+
+	user := models.NewUser(uuid.NewString(), "owner@example.com", "hash")
+	if err := users.Save(user); err != nil {
+		t.Fatalf("save user: %v", err)
+	}
+
+	doomed := models.NewTenant(uuid.NewString(), "doomed")
+	kept := models.NewTenant(uuid.NewString(), "kept")
+	for _, tenant := range []*models.Tenant{doomed, kept} {
+		if err := tenants.Save(tenant); err != nil {
+			t.Fatalf("save tenant: %v", err)
+		}
+		if err := memberships.Save(models.NewMembership(uuid.NewString(), user.ID, tenant.ID, models.RoleAdmin)); err != nil {
+			t.Fatalf("save membership: %v", err)
+		}
+		if err := projects.Save(models.NewProject(uuid.NewString(), tenant.ID, "catalog", models.TierFree)); err != nil {
+			t.Fatalf("save project: %v", err)
+		}
+	}
+
+	mustExec(t, db, `INSERT INTO authors (id, name) VALUES (1, 'Ada')`)
+	mustExec(t, db, `INSERT INTO tags (id, label) VALUES (1, 'summer')`)
+	mustExec(t, db, `INSERT INTO articles (id, title, body, author_id, tenant_id) VALUES (1, 'a', 'b', 1, ?)`, doomed.ID)
+	mustExec(t, db, `INSERT INTO articles (id, title, body, author_id, tenant_id) VALUES (2, 'a', 'b', 1, ?)`, kept.ID)
+	mustExec(t, db, `INSERT INTO article_tags (article_id, tag_id) VALUES (1, 1), (2, 1)`)
+
+	receipt, err := tenants.Purge(doomed.ID)
+	if err != nil {
+		t.Fatalf("Purge failed: %v", err)
+	}
+
+	if !receipt.TenantFound {
+		t.Error("expected TenantFound to be true")
+	}
+	if receipt.MembershipsDeleted != 1 || receipt.ProjectsDeleted != 1 ||
+		receipt.ArticlesDeleted != 1 || receipt.ArticleTagsDeleted != 1 {
+		t.Errorf("unexpected receipt counts: %+v", receipt)
+	}
+
+	if got, _ := tenants.FindByID(doomed.ID); got != nil {
+		t.Error("expected purged tenant to be gone")
+	}
+	if got, _ := tenants.FindByID(kept.ID); got == nil {
+		t.Error("expected other tenant to survive")
+	}
+	if n := countRows(t, db, `SELECT COUNT(*) FROM articles WHERE tenant_id = ?`, kept.ID); n != 1 {
+		t.Errorf("expected other tenant's article to survive, got %d", n)
+	}
+	if n := countRows(t, db, `SELECT COUNT(*) FROM article_tags`); n != 1 {
+		t.Errorf("expected 1 article_tags row left, got %d", n)
+	}
+}
+
+// tenantRows inserts a row of the tenant (the query's only argument) into
+// each tenant-keyed table, with the query counting what is left of them.
+var tenantRows = []struct{ insert, count string }{
+	{`INSERT INTO invitations (id, tenant_id, email, role, token_hash, invited_by, expires_at) VALUES (?1 || '-invitation', ?1, 'lee@example.com', 'member', ?1 || '-hash', 'ada', 0)`, `SELECT COUNT(*) FROM invitations WHERE tenant_id = ?`},
+	{`INSERT INTO custom_roles (id, tenant_id, name, permissions, created_at, updated_at) VALUES (?1 || '-role', ?1, 'manager', '[]', 0, 0)`, `SELECT COUNT(*) FROM custom_roles WHERE tenant_id = ?`},
+	{`INSERT INTO usage_rollups (tenant_id, hour) VALUES (?, 0)`, `SELECT COUNT(*) FROM usage_rollups WHERE tenant_id = ?`},
+	{`INSERT INTO search_logs (id, tenant_id, query, created_at) VALUES (?1 || '-log', ?1, 'shoe', 0)`, `SELECT COUNT(*) FROM search_logs WHERE tenant_id = ?`},
+	{`INSERT INTO search_events (id, tenant_id, type, document_id, created_at) VALUES (?1 || '-event', ?1, 'click', 'doc-1', 0)`, `SELECT COUNT(*) FROM search_events WHERE tenant_id = ?`},
+	{`INSERT INTO document_popularity (tenant_id, document_id, score, updated_at) VALUES (?, 'doc-1', 1, 0)`, `SELECT COUNT(*) FROM document_popularity WHERE tenant_id = ?`},
+	{`INSERT INTO query_rules (id, tenant_id, match_type, query, consequences, created_at, updated_at) VALUES (?1 || '-rule', ?1, 'exact', 'shoe', '{}', 0, 0)`, `SELECT COUNT(*) FROM query_rules WHERE tenant_id = ?`},
+	{`INSERT INTO tenant_vocabulary (tenant_id, term, frequency) VALUES (?, 'shoe', 1)`, `SELECT COUNT(*) FROM tenant_vocabulary WHERE tenant_id = ?`},
+	{`INSERT INTO saved_searches (id, tenant_id, query, webhook_url, secret, created_at, updated_at) VALUES (?1 || '-saved', ?1, 'shoe', 'https://example.com', 's', 0, 0)`, `SELECT COUNT(*) FROM saved_searches WHERE tenant_id = ?`},
+	{`INSERT INTO saved_search_matches (saved_search_id, document_id, matched_at) VALUES (? || '-saved', 'doc-1', 0)`, `SELECT COUNT(*) FROM saved_search_matches WHERE saved_search_id = ? || '-saved'`},
+	{`INSERT INTO webhook_deliveries (id, tenant_id, source_id, event, url, payload, secret, status, next_attempt_at, created_at) VALUES (?1 || '-delivery', ?1, 's', 'e', 'https://example.com', '{}', 's', 'pending', 0, 0)`, `SELECT COUNT(*) FROM webhook_deliveries WHERE tenant_id = ?`},
+	{`INSERT INTO webhook_subscriptions (id, tenant_id, url, events, secret, created_at, updated_at) VALUES (?1 || '-subscription', ?1, 'https://example.com', '[]', 's', 0, 0)`, `SELECT COUNT(*) FROM webhook_subscriptions WHERE tenant_id = ?`},
+	{`INSERT INTO webhook_dead_letters (id, delivery_id, tenant_id, source_id, event, url, payload, secret, attempts, failed_at) VALUES (?1 || '-dead', 'd', ?1, 's', 'e', 'https://example.com', '{}', 's', 1, 0)`, `SELECT COUNT(*) FROM webhook_dead_letters WHERE tenant_id = ?`},
+	{`INSERT INTO index_sync_outbox (kind, entity_id, tenant_id, next_attempt_at, created_at) VALUES ('article', 1, ?, 0, 0)`, `SELECT COUNT(*) FROM index_sync_outbox WHERE tenant_id = ?`},
+	{`INSERT INTO index_sync_dead_letters (intent_id, kind, entity_id, tenant_id, attempts, last_error, created_at, failed_at) VALUES (1, 'article', 1, ?, 1, 'e', 0, 0)`, `SELECT COUNT(*) FROM index_sync_dead_letters WHERE tenant_id = ?`},
+}
+
+func TestSQLiteTenantRepository_Purge_DeletesTheTenantsData(t *testing.T) {
+	db := newTestDB(t)
+	tenants := NewSQLiteTenantRepository(db)
+	doomed, kept := uuid.NewString(), uuid.NewString()
+
+	for _, row := range tenantRows {
+		mustExec(t, db, row.insert, doomed)
+		mustExec(t, db, row.insert, kept)
+	}
+
+	if _, err := tenants.Purge(doomed); err != nil {
+		t.Fatalf("Purge failed: %v", err)
+	}
+
+	for _, row := range tenantRows {
+		if n := countRows(t, db, row.count, doomed); n != 0 {
+			t.Errorf("expected %q to find nothing of the purged tenant, got %d", row.count, n)
+		}
+		if n := countRows(t, db, row.count, kept); n != 1 {
+			t.Errorf("expected %q to find the other tenant's row, got %d", row.count, n)
+		}
+	}
+}
+
+// keptTenantTables have a tenant_id column but outlive the tenant: the
+// receipts prove the deletion, and sessions and refresh tokens belong to
+// their user, who may still be a member of other tenants.
+var keptTenantTables = map[string]bool{
+	"tenant_deletion_receipts": true,
+	"sessions":                 true,
+	"refresh_tokens":           true,
+}
+
+func TestSQLiteTenantRepository_Purge_CoversEveryTenantKeyedTable(t *testing.T) {
+	db := newTestDB(t)
+
+	// Tables the cascade test covers, and those tenantRows does.
+	covered := map[string]bool{"memberships": true, "projects": true, "articles": true}
+	for _, row := range tenantRows {
+		covered[strings.Fields(row.insert)[2]] = true
+	}
+
+	rows, err := db.Query(`SELECT m.name FROM sqlite_master m, pragma_table_info(m.name) c WHERE m.type = 'table' AND c.name = 'tenant_id'`)
+	if err != nil {
+		t.Fatalf("failed to list the tenant-keyed tables: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		if !covered[table] && !keptTenantTables[table] {
+			t.Errorf("table %s has a tenant_id column: purge it and add it to tenantRows, or list it in keptTenantTables", table)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("rows: %v", err)
+	}
+}
+
+func TestSQLiteTenantRepository_Purge_UnknownTenantIsNotAnError(t *testing.T) {
+	db := newTestDB(t)
+	tenants := NewSQLiteTenantRepository(db)
+
+	receipt, err := tenants.Purge(uuid.NewString())
+	if err != nil {
+		t.Fatalf("expected no error purging an unknown tenant, got: %v", err)
+	}
+	if receipt.TenantFound {
+		t.Error("expected TenantFound to be false")
+	}
+}
+
+func mustExec(t *testing.T, db *sql.DB, query string, args ...interface{}) {
+	t.Helper()
+	if _, err := db.Exec(query, args...); err != nil {
+		t.Fatalf("exec %q: %v", query, err)
+	}
+}
+
+func countRows(t *testing.T, db *sql.DB, query string, args ...interface{}) int {
+	t.Helper()
+	var n int
+	if err := db.QueryRow(query, args...).Scan(&n); err != nil {
+		t.Fatalf("count %q: %v", query, err)
+	}
+	return n
+}
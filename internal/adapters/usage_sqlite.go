@@ -0,0 +1,87 @@
+package adapters
+
+import (
+	"database/sql"
+	"mini-search-platform/internal/models"
+	"time"
+)
+
+type SQLiteUsageRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteUsageRepository(db *sql.DB) *SQLiteUsageRepository {
+	return &SQLiteUsageRepository{db: db}
+}
+
+func (r *SQLiteUsageRepository) Increment(rollup *models.UsageRollup) error {
+	query := `
+		INSERT INTO usage_rollups (
+			tenant_id,
+			hour,
+			searches,
+			documents_indexed,
+			documents_deleted,
+			bytes_ingested,
+			engine_calls,
+			engine_errors,
+			engine_latency_ms,
+			engine_latency_max_ms
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(tenant_id, hour) DO UPDATE SET
+			searches = searches + excluded.searches,
+			documents_indexed = documents_indexed + excluded.documents_indexed,
+			documents_deleted = documents_deleted + excluded.documents_deleted,
+			bytes_ingested = bytes_ingested + excluded.bytes_ingested,
+			engine_calls = engine_calls + excluded.engine_calls,
+			engine_errors = engine_errors + excluded.engine_errors,
+			engine_latency_ms = engine_latency_ms + excluded.engine_latency_ms,
+			engine_latency_max_ms = MAX(engine_latency_max_ms, excluded.engine_latency_max_ms)
+	`
+	_, err := r.db.Exec(query,
+		rollup.TenantID,
+		rollup.Hour.UTC().Unix(),
+		rollup.Searches,
+		rollup.DocumentsIndexed,
+		rollup.DocumentsDeleted,
+		rollup.BytesIngested,
+		rollup.EngineCalls,
+		rollup.EngineErrors,
+		rollup.EngineLatencyMs,
+		rollup.EngineLatencyMaxMs,
+	)
+	return err
+}
+
+func (r *SQLiteUsageRepository) ListByTenant(tenantID string, since time.Time) ([]*models.UsageRollup, error) {
+	query := `
+		SELECT tenant_id, hour, searches, documents_indexed, documents_deleted,
+			bytes_ingested, engine_calls, engine_errors, engine_latency_ms, engine_latency_max_ms
+		FROM usage_rollups
+		WHERE tenant_id = ? AND hour >= ?
+		ORDER BY hour ASC
+	`
+	rows, err := r.db.Query(query, tenantID, since.UTC().Truncate(time.Hour).Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rollups []*models.UsageRollup
+	for rows.Next() {
+		var hour int64
+		rollup := &models.UsageRollup{}
+		if err := rows.Scan(
+			&rollup.TenantID, &hour, &rollup.Searches,
+			&rollup.DocumentsIndexed, &rollup.DocumentsDeleted, &rollup.BytesIngested,
+			&rollup.EngineCalls, &rollup.EngineErrors,
+			&rollup.EngineLatencyMs, &rollup.EngineLatencyMaxMs,
+		); err != nil {
+			return nil, err
+		}
+		rollup.Hour = time.Unix(hour, 0).UTC()
+		rollups = append(rollups, rollup)
+	}
+
+	return rollups, rows.Err()
+}
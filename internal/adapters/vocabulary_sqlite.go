@@ -0,0 +1,67 @@
+package adapters
+
+import (
+	"database/sql"
+)
+
+type SQLiteVocabularyRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteVocabularyRepository(db *sql.DB) *SQLiteVocabularyRepository {
+	return &SQLiteVocabularyRepository{db: db}
+}
+
+func (r *SQLiteVocabularyRepository) Increment(tenantID string, counts map[string]int64) error {
+	if len(counts) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO tenant_vocabulary (tenant_id, term, frequency) VALUES (?, ?, ?)
+		ON CONFLICT(tenant_id, term) DO UPDATE SET frequency = frequency + excluded.frequency
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for term, count := range counts {
+		if _, err := stmt.Exec(tenantID, term, count); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *SQLiteVocabularyRepository) Load(tenantID string) (map[string]int64, error) {
+	rows, err := r.db.Query(`SELECT term, frequency FROM tenant_vocabulary WHERE tenant_id = ?`, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	terms := make(map[string]int64)
+	for rows.Next() {
+		var term string
+		var frequency int64
+		if err := rows.Scan(&term, &frequency); err != nil {
+			return nil, err
+		}
+		terms[term] = frequency
+	}
+
+	return terms, rows.Err()
+}
+
+func (r *SQLiteVocabularyRepository) DeleteByTenant(tenantID string) error {
+	_, err := r.db.Exec(`DELETE FROM tenant_vocabulary WHERE tenant_id = ?`, tenantID)
+	return err
+}
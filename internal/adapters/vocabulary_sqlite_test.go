@@ -0,0 +1,36 @@
+package adapters
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestVocabularyRepository_IncrementAccumulatesPerTenant(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewSQLiteVocabularyRepository(db)
+
+	if err := repo.Increment("tenant-a", map[string]int64{"leather": 2, "sandals": 1}); err != nil {
+		t.Fatalf("Increment failed: %v", err)
+	}
+	if err := repo.Increment("tenant-a", map[string]int64{"leather": 3}); err != nil {
+		t.Fatalf("Increment failed: %v", err)
+	}
+	if err := repo.Increment("tenant-b", map[string]int64{"candles": 1}); err != nil {
+		t.Fatalf("Increment failed: %v", err)
+	}
+
+	terms, err := repo.Load("tenant-a")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if want := map[string]int64{"leather": 5, "sandals": 1}; !reflect.DeepEqual(terms, want) {
+		t.Errorf("expected %v, got %v", want, terms)
+	}
+
+	if err := repo.DeleteByTenant("tenant-a"); err != nil {
+		t.Fatalf("DeleteByTenant failed: %v", err)
+	}
+	if n := countRows(t, db, `SELECT COUNT(*) FROM tenant_vocabulary`); n != 1 {
+		t.Errorf("expected only tenant-b's term to remain, got %d rows", n)
+	}
+}
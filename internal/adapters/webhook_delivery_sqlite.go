@@ -0,0 +1,255 @@
+package adapters
+
+import (
+	"database/sql"
+	"mini-search-platform/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SQLiteWebhookDeliveryRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteWebhookDeliveryRepository(db *sql.DB) *SQLiteWebhookDeliveryRepository {
+	return &SQLiteWebhookDeliveryRepository{db: db}
+}
+
+const webhookDeadLetterColumns = `id, delivery_id, tenant_id, source_id, event, url, payload, secret, attempts,
+	response_status, last_error, failed_at`
+
+const webhookDeliveryColumns = `id, tenant_id, source_id, event, url, payload, secret, status, attempts,
+	response_status, last_error, next_attempt_at, created_at, delivered_at`
+
+func unixOrNull(t *time.Time) sql.NullInt64 {
+	if t == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: t.UTC().Unix(), Valid: true}
+}
+
+func (r *SQLiteWebhookDeliveryRepository) Save(delivery *models.WebhookDelivery) error {
+	query := `INSERT INTO webhook_deliveries (` + webhookDeliveryColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := r.db.Exec(query,
+		delivery.ID,
+		delivery.TenantID,
+		delivery.SourceID,
+		delivery.Event,
+		delivery.URL,
+		string(delivery.Payload),
+		delivery.Secret,
+		string(delivery.Status),
+		delivery.Attempts,
+		delivery.ResponseStatus,
+		delivery.LastError,
+		delivery.NextAttemptAt.UTC().Unix(),
+		delivery.CreatedAt.UTC().Unix(),
+		unixOrNull(delivery.DeliveredAt),
+	)
+	return err
+}
+
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// Update records the outcome of a delivery attempt.
+func (r *SQLiteWebhookDeliveryRepository) Update(delivery *models.WebhookDelivery) error {
+	return updateWebhookDelivery(r.db, delivery)
+}
+
+func updateWebhookDelivery(db execer, delivery *models.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = ?, response_status = ?, last_error = ?, next_attempt_at = ?, delivered_at = ?
+		WHERE id = ?
+	`
+	_, err := db.Exec(query,
+		string(delivery.Status),
+		delivery.Attempts,
+		delivery.ResponseStatus,
+		delivery.LastError,
+		delivery.NextAttemptAt.UTC().Unix(),
+		unixOrNull(delivery.DeliveredAt),
+		delivery.ID,
+	)
+	return err
+}
+
+func (r *SQLiteWebhookDeliveryRepository) DeadLetter(delivery *models.WebhookDelivery) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := updateWebhookDelivery(tx, delivery); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO webhook_dead_letters (` + webhookDeadLetterColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err = tx.Exec(query,
+		uuid.NewString(),
+		delivery.ID,
+		delivery.TenantID,
+		delivery.SourceID,
+		delivery.Event,
+		delivery.URL,
+		string(delivery.Payload),
+		delivery.Secret,
+		delivery.Attempts,
+		delivery.ResponseStatus,
+		delivery.LastError,
+		time.Now().UTC().Unix(),
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func scanWebhookDelivery(row rowScanner) (*models.WebhookDelivery, error) {
+	var payload, status string
+	var nextAttemptAt, createdAt int64
+	var deliveredAt sql.NullInt64
+	delivery := &models.WebhookDelivery{}
+
+	if err := row.Scan(
+		&delivery.ID,
+		&delivery.TenantID,
+		&delivery.SourceID,
+		&delivery.Event,
+		&delivery.URL,
+		&payload,
+		&delivery.Secret,
+		&status,
+		&delivery.Attempts,
+		&delivery.ResponseStatus,
+		&delivery.LastError,
+		&nextAttemptAt,
+		&createdAt,
+		&deliveredAt,
+	); err != nil {
+		return nil, err
+	}
+
+	delivery.Payload = []byte(payload)
+	delivery.Status = models.WebhookDeliveryStatus(status)
+	delivery.NextAttemptAt = time.Unix(nextAttemptAt, 0).UTC()
+	delivery.CreatedAt = time.Unix(createdAt, 0).UTC()
+	if deliveredAt.Valid {
+		t := time.Unix(deliveredAt.Int64, 0).UTC()
+		delivery.DeliveredAt = &t
+	}
+	return delivery, nil
+}
+
+func (r *SQLiteWebhookDeliveryRepository) list(query string, args ...interface{}) ([]*models.WebhookDelivery, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []*models.WebhookDelivery{}
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func (r *SQLiteWebhookDeliveryRepository) ListDue(now time.Time, limit int) ([]*models.WebhookDelivery, error) {
+	query := `
+		SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries
+		WHERE status = ? AND next_attempt_at <= ?
+		ORDER BY next_attempt_at ASC, created_at ASC
+		LIMIT ?
+	`
+	return r.list(query, string(models.DeliveryPending), now.UTC().Unix(), limit)
+}
+
+func (r *SQLiteWebhookDeliveryRepository) ListBySource(tenantID, sourceID string, limit int) ([]*models.WebhookDelivery, error) {
+	query := `
+		SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries
+		WHERE tenant_id = ? AND source_id = ?
+		ORDER BY created_at DESC, rowid DESC
+		LIMIT ?
+	`
+	return r.list(query, tenantID, sourceID, limit)
+}
+
+func scanWebhookDeadLetter(row rowScanner) (*models.WebhookDeadLetter, error) {
+	var payload string
+	var failedAt int64
+	deadLetter := &models.WebhookDeadLetter{}
+
+	if err := row.Scan(
+		&deadLetter.ID,
+		&deadLetter.DeliveryID,
+		&deadLetter.TenantID,
+		&deadLetter.SourceID,
+		&deadLetter.Event,
+		&deadLetter.URL,
+		&payload,
+		&deadLetter.Secret,
+		&deadLetter.Attempts,
+		&deadLetter.ResponseStatus,
+		&deadLetter.LastError,
+		&failedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	deadLetter.Payload = []byte(payload)
+	deadLetter.FailedAt = time.Unix(failedAt, 0).UTC()
+	return deadLetter, nil
+}
+
+func (r *SQLiteWebhookDeliveryRepository) ListDeadLetters(tenantID string, limit int) ([]*models.WebhookDeadLetter, error) {
+	query := `
+		SELECT ` + webhookDeadLetterColumns + ` FROM webhook_dead_letters
+		WHERE tenant_id = ?
+		ORDER BY failed_at DESC, rowid DESC
+		LIMIT ?
+	`
+	rows, err := r.db.Query(query, tenantID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deadLetters := []*models.WebhookDeadLetter{}
+	for rows.Next() {
+		deadLetter, err := scanWebhookDeadLetter(rows)
+		if err != nil {
+			return nil, err
+		}
+		deadLetters = append(deadLetters, deadLetter)
+	}
+
+	return deadLetters, rows.Err()
+}
+
+func (r *SQLiteWebhookDeliveryRepository) FindDeadLetter(tenantID, id string) (*models.WebhookDeadLetter, error) {
+	query := `SELECT ` + webhookDeadLetterColumns + ` FROM webhook_dead_letters WHERE tenant_id = ? AND id = ?`
+	deadLetter, err := scanWebhookDeadLetter(r.db.QueryRow(query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return deadLetter, nil
+}
+
+func (r *SQLiteWebhookDeliveryRepository) DeleteDeadLetter(tenantID, id string) error {
+	_, err := r.db.Exec(`DELETE FROM webhook_dead_letters WHERE tenant_id = ? AND id = ?`, tenantID, id)
+	return err
+}
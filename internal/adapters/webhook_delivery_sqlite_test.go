@@ -0,0 +1,135 @@
+package adapters
+
+import (
+	"testing"
+	"time"
+
+	"mini-search-platform/internal/models"
+)
+
+func TestWebhookDeliveryRepository_ListDueReturnsPendingDeliveriesInOrder(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewSQLiteWebhookDeliveryRepository(db)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	delivery := func(id string, status models.WebhookDeliveryStatus, next time.Time) *models.WebhookDelivery {
+		d := &models.WebhookDelivery{
+			ID:            id,
+			TenantID:      "tenant-a",
+			SourceID:      "saved-1",
+			Event:         "saved_search.matches",
+			URL:           "https://buyer.example.com/hooks",
+			Payload:       []byte(`{"matches":[]}`),
+			Secret:        "whsec_test",
+			Status:        status,
+			NextAttemptAt: next,
+			CreatedAt:     now,
+		}
+		if err := repo.Save(d); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		return d
+	}
+
+	retry := delivery("retry", models.DeliveryPending, now.Add(-time.Minute))
+	delivery("fresh", models.DeliveryPending, now)
+	delivery("later", models.DeliveryPending, now.Add(time.Minute))
+	delivery("done", models.DeliveryDelivered, now.Add(-time.Hour))
+
+	due, err := repo.ListDue(now, 10)
+	if err != nil {
+		t.Fatalf("ListDue failed: %v", err)
+	}
+	if len(due) != 2 || due[0].ID != "retry" || due[1].ID != "fresh" {
+		t.Fatalf("expected the retry then the fresh delivery, got %+v", due)
+	}
+	if string(due[0].Payload) != `{"matches":[]}` || due[0].Secret != "whsec_test" || !due[0].NextAttemptAt.Equal(retry.NextAttemptAt) {
+		t.Errorf("delivery did not round-trip: %+v", due[0])
+	}
+
+	retry.Status = models.DeliveryDelivered
+	retry.Attempts = 2
+	retry.ResponseStatus = 204
+	retry.DeliveredAt = &now
+	if err := repo.Update(retry); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	log, err := repo.ListBySource("tenant-a", "saved-1", 10)
+	if err != nil {
+		t.Fatalf("ListBySource failed: %v", err)
+	}
+	if len(log) != 4 {
+		t.Fatalf("expected 4 deliveries in the log, got %d", len(log))
+	}
+	for _, d := range log {
+		if d.ID == "retry" && (d.Attempts != 2 || d.DeliveredAt == nil || !d.DeliveredAt.Equal(now)) {
+			t.Errorf("update was not persisted: %+v", d)
+		}
+	}
+	if other, _ := repo.ListBySource("tenant-b", "saved-1", 10); len(other) != 0 {
+		t.Errorf("another tenant must not see the deliveries, got %d", len(other))
+	}
+}
+
+func TestWebhookDeliveryRepository_DeadLetterMovesFailedDelivery(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewSQLiteWebhookDeliveryRepository(db)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	delivery := &models.WebhookDelivery{
+		ID:            "failing",
+		TenantID:      "tenant-a",
+		SourceID:      "sub-1",
+		Event:         "documents.indexed",
+		URL:           "https://ops.example.com/hooks",
+		Payload:       []byte(`{"event":"documents.indexed"}`),
+		Secret:        "whsec_test",
+		Status:        models.DeliveryPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+	if err := repo.Save(delivery); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	delivery.Status = models.DeliveryFailed
+	delivery.Attempts = 8
+	delivery.ResponseStatus = 500
+	delivery.LastError = "receiver responded 500: oops"
+	if err := repo.DeadLetter(delivery); err != nil {
+		t.Fatalf("DeadLetter failed: %v", err)
+	}
+
+	if due, _ := repo.ListDue(now.Add(time.Hour), 10); len(due) != 0 {
+		t.Errorf("a dead-lettered delivery must not be due, got %+v", due)
+	}
+
+	deadLetters, err := repo.ListDeadLetters("tenant-a", 10)
+	if err != nil {
+		t.Fatalf("ListDeadLetters failed: %v", err)
+	}
+	if len(deadLetters) != 1 {
+		t.Fatalf("expected one dead letter, got %d", len(deadLetters))
+	}
+	dl := deadLetters[0]
+	if dl.DeliveryID != "failing" || dl.SourceID != "sub-1" || dl.Attempts != 8 || dl.LastError != delivery.LastError ||
+		string(dl.Payload) != `{"event":"documents.indexed"}` || dl.Secret != "whsec_test" {
+		t.Errorf("dead letter did not keep the delivery: %+v", dl)
+	}
+
+	if other, _ := repo.FindDeadLetter("tenant-b", dl.ID); other != nil {
+		t.Errorf("another tenant must not see the dead letter, got %+v", other)
+	}
+	found, err := repo.FindDeadLetter("tenant-a", dl.ID)
+	if err != nil || found == nil || found.URL != delivery.URL {
+		t.Fatalf("FindDeadLetter failed: %+v, %v", found, err)
+	}
+
+	if err := repo.DeleteDeadLetter("tenant-a", dl.ID); err != nil {
+		t.Fatalf("DeleteDeadLetter failed: %v", err)
+	}
+	if found, _ := repo.FindDeadLetter("tenant-a", dl.ID); found != nil {
+		t.Errorf("expected the dead letter to be deleted, got %+v", found)
+	}
+}
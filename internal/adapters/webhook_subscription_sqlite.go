@@ -0,0 +1,122 @@
+package adapters
+
+import (
+	"database/sql"
+	"encoding/json"
+	"mini-search-platform/internal/models"
+)
+
+type SQLiteWebhookSubscriptionRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteWebhookSubscriptionRepository(db *sql.DB) *SQLiteWebhookSubscriptionRepository {
+	return &SQLiteWebhookSubscriptionRepository{db: db}
+}
+
+const webhookSubscriptionColumns = `id, tenant_id, description, url, events, enabled, secret, created_at, updated_at`
+
+func (r *SQLiteWebhookSubscriptionRepository) Save(subscription *models.WebhookSubscription) error {
+	events, err := json.Marshal(subscription.Events)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO webhook_subscriptions (` + webhookSubscriptionColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err = r.db.Exec(query,
+		subscription.ID,
+		subscription.TenantID,
+		subscription.Description,
+		subscription.URL,
+		string(events),
+		subscription.Enabled,
+		subscription.Secret,
+		subscription.CreatedAt,
+		subscription.UpdatedAt,
+	)
+	return err
+}
+
+func (r *SQLiteWebhookSubscriptionRepository) Update(subscription *models.WebhookSubscription) error {
+	events, err := json.Marshal(subscription.Events)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE webhook_subscriptions
+		SET description = ?, url = ?, events = ?, enabled = ?, updated_at = ?
+		WHERE tenant_id = ? AND id = ?
+	`
+	_, err = r.db.Exec(query,
+		subscription.Description,
+		subscription.URL,
+		string(events),
+		subscription.Enabled,
+		subscription.UpdatedAt,
+		subscription.TenantID,
+		subscription.ID,
+	)
+	return err
+}
+
+func (r *SQLiteWebhookSubscriptionRepository) Delete(tenantID, id string) error {
+	_, err := r.db.Exec(`DELETE FROM webhook_subscriptions WHERE tenant_id = ? AND id = ?`, tenantID, id)
+	return err
+}
+
+func scanWebhookSubscription(row rowScanner) (*models.WebhookSubscription, error) {
+	var events string
+	subscription := &models.WebhookSubscription{}
+
+	if err := row.Scan(
+		&subscription.ID,
+		&subscription.TenantID,
+		&subscription.Description,
+		&subscription.URL,
+		&events,
+		&subscription.Enabled,
+		&subscription.Secret,
+		&subscription.CreatedAt,
+		&subscription.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(events), &subscription.Events); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (r *SQLiteWebhookSubscriptionRepository) FindByID(tenantID, id string) (*models.WebhookSubscription, error) {
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions WHERE tenant_id = ? AND id = ?`
+	subscription, err := scanWebhookSubscription(r.db.QueryRow(query, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (r *SQLiteWebhookSubscriptionRepository) ListByTenant(tenantID string) ([]*models.WebhookSubscription, error) {
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions WHERE tenant_id = ? ORDER BY created_at ASC, id ASC`
+	rows, err := r.db.Query(query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subscriptions := []*models.WebhookSubscription{}
+	for rows.Next() {
+		subscription, err := scanWebhookSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	return subscriptions, rows.Err()
+}
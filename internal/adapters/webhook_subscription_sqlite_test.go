@@ -0,0 +1,67 @@
+package adapters
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"mini-search-platform/internal/models"
+)
+
+func TestWebhookSubscriptionRepository_RoundTripsAndScopesByTenant(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewSQLiteWebhookSubscriptionRepository(db)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	subscription := &models.WebhookSubscription{
+		ID:          "sub-1",
+		TenantID:    "tenant-a",
+		Description: "ops alerts",
+		URL:         "https://ops.example.com/hooks",
+		Events:      []models.WebhookEvent{models.EventDocumentsFailed, models.EventIndexReset},
+		Enabled:     true,
+		Secret:      "whsec_test",
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := repo.Save(subscription); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	got, err := repo.FindByID("tenant-a", "sub-1")
+	if err != nil || got == nil {
+		t.Fatalf("FindByID failed: %v (subscription=%v)", err, got)
+	}
+	if !reflect.DeepEqual(got, subscription) {
+		t.Errorf("subscription did not round-trip: %+v", got)
+	}
+	if other, _ := repo.FindByID("tenant-b", "sub-1"); other != nil {
+		t.Fatal("another tenant must not see the subscription")
+	}
+
+	subscription.Enabled = false
+	subscription.Events = []models.WebhookEvent{models.EventDocumentsIndexed}
+	if err := repo.Update(subscription); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	list, err := repo.ListByTenant("tenant-a")
+	if err != nil {
+		t.Fatalf("ListByTenant failed: %v", err)
+	}
+	if len(list) != 1 || list[0].Enabled || !reflect.DeepEqual(list[0].Events, subscription.Events) {
+		t.Errorf("update was not persisted: %+v", list)
+	}
+
+	if err := repo.Delete("tenant-b", "sub-1"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if list, _ := repo.ListByTenant("tenant-a"); len(list) != 1 {
+		t.Fatal("another tenant's delete must not remove the subscription")
+	}
+	if err := repo.Delete("tenant-a", "sub-1"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if list, _ := repo.ListByTenant("tenant-a"); len(list) != 0 {
+		t.Errorf("expected no subscriptions after delete, got %d", len(list))
+	}
+}
@@ -0,0 +1,187 @@
+package analytics
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+
+	"mini-search-platform/internal/models"
+	"mini-search-platform/pkg/logging"
+)
+
+// NormalizeQuery folds case and whitespace so "Summer  Dress" and
+// "summer dress" are reported as one query.
+func NormalizeQuery(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
+
+// AnonymizeSession hashes a shopper's session identifier, salted with the
+// tenant ID so the same session cannot be correlated across tenants. An
+// empty session stays empty.
+func AnonymizeSession(tenantID, sessionID string) string {
+	if sessionID == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(tenantID + "\x00" + sessionID))
+	return hex.EncodeToString(sum[:16])
+}
+
+// Recorder buffers search log entries in memory and periodically writes
+// them to a SearchLogRepository in batches, keeping the SQLite write off the
+// search path. The buffer is bounded: when the store falls behind, new
+// entries are dropped rather than growing memory without limit. Reads go
+// through Report, which flushes first.
+type Recorder struct {
+	repo       models.SearchLogRepository
+	maxPending int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	pending []*models.SearchLogEntry
+	dropped int64
+
+	// flushing is held for a whole flush, so Forget can wait out one in
+	// flight.
+	flushing sync.Mutex
+}
+
+func NewRecorder(repo models.SearchLogRepository, maxPending int) *Recorder {
+	return &Recorder{
+		repo:       repo,
+		maxPending: maxPending,
+		now:        time.Now,
+	}
+}
+
+// Record queues an entry, stamping CreatedAt if it is unset.
+func (r *Recorder) Record(entry *models.SearchLogEntry) {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = r.now().UTC()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.pending) >= r.maxPending {
+		r.dropped++
+		return
+	}
+	r.pending = append(r.pending, entry)
+}
+
+// Forget runs purge, which deletes the tenant's stored search log, with
+// flushing held off, then drops the tenant's queued entries. Neither a flush
+// in flight nor a failed one putting its batch back can then write the
+// tenant's entries again after the purge. If purge fails, nothing is
+// dropped.
+func (r *Recorder) Forget(tenantID string, purge func() error) error {
+	r.flushing.Lock()
+	defer r.flushing.Unlock()
+
+	if err := purge(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.pending[:0]
+	for _, entry := range r.pending {
+		if entry.TenantID != tenantID {
+			kept = append(kept, entry)
+		}
+	}
+	clear(r.pending[len(kept):])
+	r.pending = kept
+	return nil
+}
+
+// Flush writes every queued entry. On failure the batch is put back, as far
+// as the buffer bound allows, so the next flush retries it.
+func (r *Recorder) Flush() error {
+	r.flushing.Lock()
+	defer r.flushing.Unlock()
+
+	r.mu.Lock()
+	pending := r.pending
+	r.pending = nil
+	dropped := r.dropped
+	r.dropped = 0
+	r.mu.Unlock()
+
+	if dropped > 0 {
+		logging.Warn("dropped search log entries", "count", dropped)
+	}
+
+	if err := r.repo.SaveBatch(pending); err != nil {
+		r.mu.Lock()
+		room := min(max(r.maxPending-len(r.pending), 0), len(pending))
+		r.pending = append(pending[:room:room], r.pending...)
+		r.dropped += int64(len(pending) - room)
+		r.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Start flushes the recorder every interval in the background.
+func (r *Recorder) Start(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			if err := r.Flush(); err != nil {
+				logging.Error("failed to flush search logs", "error", err)
+			}
+		}
+	}()
+}
+
+// StartRetention prunes the search log every interval, deleting entries
+// older than maxAge and then the oldest entries beyond maxRows, so the table
+// stays bounded however much traffic tenants send.
+func (r *Recorder) StartRetention(interval, maxAge time.Duration, maxRows int) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			pruned, err := r.repo.Prune(r.now().Add(-maxAge), maxRows)
+			if err != nil {
+				logging.Error("failed to prune search logs", "error", err)
+				continue
+			}
+			if pruned > 0 {
+				logging.Info("pruned search logs", "rows", pruned)
+			}
+		}
+	}()
+}
+
+// Report builds the tenant's search analytics between from and to, with trend
+// buckets of the given size and at most limit queries per list.
+func (r *Recorder) Report(tenantID string, from, to time.Time, bucket time.Duration, limit int) (*models.SearchAnalyticsReport, error) {
+	if err := r.Flush(); err != nil {
+		return nil, err
+	}
+
+	report := &models.SearchAnalyticsReport{}
+	var err error
+
+	if report.Summary, err = r.repo.Summary(tenantID, from, to); err != nil {
+		return nil, err
+	}
+	if report.TopQueries, err = r.repo.TopQueries(tenantID, from, to, limit); err != nil {
+		return nil, err
+	}
+	if report.ZeroResultQueries, err = r.repo.ZeroResultQueries(tenantID, from, to, limit); err != nil {
+		return nil, err
+	}
+	if report.NoClickQueries, err = r.repo.NoClickQueries(tenantID, from, to, limit); err != nil {
+		return nil, err
+	}
+	if report.Trend, err = r.repo.Trend(tenantID, from, to, bucket); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
@@ -0,0 +1,140 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// migrations bring databases created by earlier versions up to the schema
+// Create declares: CREATE TABLE IF NOT EXISTS leaves a table that already
+// exists as it was. Each runs once, in order and in its own transaction,
+// and PRAGMA user_version counts those that have run. On a database Create
+// just made, the tables are already current, so each migration checks
+// whether it has anything to do.
+var migrations = []func(tx *sql.Tx) error{
+	// Author index sync intents.
+	func(tx *sql.Tx) error {
+		return rewriteTable(tx, "index_sync_outbox",
+			`CHECK(kind IN ('article', 'tag'))`, `CHECK(kind IN ('article', 'tag', 'author'))`)
+	},
+	// The tag hierarchy.
+	func(tx *sql.Tx) error {
+		return addColumn(tx, "tags", "parent_id", `INTEGER REFERENCES tags (id)`)
+	},
+	// Custom roles, assigned to memberships and invitations by name.
+	func(tx *sql.Tx) error {
+		for _, table := range []string{"memberships", "invitations"} {
+			if err := rewriteTable(tx, table, ` CHECK(role IN ('admin', 'billing', 'member'))`, ""); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	// Refresh token times as unix seconds, like the sessions'.
+	func(tx *sql.Tx) error {
+		columns := []string{"expires_at", "used_at", "revoked_at", "created_at"}
+		for _, column := range columns {
+			_, err := tx.Exec(fmt.Sprintf(`UPDATE refresh_tokens SET %[1]s = CAST(strftime('%%s', %[1]s) AS INTEGER) WHERE typeof(%[1]s) = 'text'`, column))
+			if err != nil {
+				return err
+			}
+		}
+		return rewriteTable(tx, "refresh_tokens",
+			"expires_at TIMESTAMP NOT NULL", "expires_at INTEGER NOT NULL",
+			"used_at TIMESTAMP", "used_at INTEGER",
+			"revoked_at TIMESTAMP", "revoked_at INTEGER",
+			"created_at TIMESTAMP NOT NULL", "created_at INTEGER NOT NULL")
+	},
+	// Project index drop intents.
+	func(tx *sql.Tx) error {
+		return rewriteTable(tx, "index_sync_outbox",
+			`CHECK(kind IN ('article', 'tag', 'author'))`, `CHECK(kind IN ('article', 'tag', 'author', 'project'))`)
+	},
+}
+
+func migrate(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return err
+	}
+
+	for ; version < len(migrations); version++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if err := migrations[version](tx); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %w", version+1, err)
+		}
+		if _, err := tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, version+1)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addColumn adds the column to the table unless it already has it.
+func addColumn(tx *sql.Tx, table, column, definition string) error {
+	rows, err := tx.Query(fmt.Sprintf(`PRAGMA table_info(%s)`, table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, columnType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &columnType, &notNull, &defaultValue, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	_, err = tx.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, definition))
+	return err
+}
+
+// rewriteTable rebuilds the table from its stored definition with each old
+// text replaced by the new text following it, keeping its rows, as SQLite
+// cannot alter a column's type or constraints in place. A table whose
+// definition has none of the old texts is left alone. Its indexes are
+// dropped with it, for Create to make again.
+func rewriteTable(tx *sql.Tx, table string, oldNew ...string) error {
+	var definition string
+	err := tx.QueryRow(`SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?`, table).Scan(&definition)
+	if err != nil {
+		return err
+	}
+	rewritten := strings.NewReplacer(oldNew...).Replace(definition)
+	if rewritten == definition {
+		return nil
+	}
+
+	rebuilt := table + "_rebuilt"
+	// The stored definition starts "CREATE TABLE <table>".
+	rewritten = strings.Replace(rewritten, table, rebuilt, 1)
+	for _, statement := range []string{
+		rewritten,
+		fmt.Sprintf(`INSERT INTO %s SELECT * FROM %s`, rebuilt, table),
+		fmt.Sprintf(`DROP TABLE %s`, table),
+		fmt.Sprintf(`ALTER TABLE %s RENAME TO %s`, rebuilt, table),
+	} {
+		if _, err := tx.Exec(statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package database
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"mini-search-platform/pkg/sqlite"
+
+	"github.com/google/uuid"
+)
+
+// newOldDB opens an empty database and runs schema on it, as an earlier
+// version of Create would have.
+func newOldDB(t *testing.T, schema string) *sql.DB {
+	t.Helper()
+
+	db, err := sqlite.Init("file:" + uuid.NewString() + "?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { sqlite.Close(db) })
+
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatalf("failed to create the old schema: %v", err)
+	}
+	return db
+}
+
+func mustExec(t *testing.T, db *sql.DB, query string, args ...interface{}) {
+	t.Helper()
+	if _, err := db.Exec(query, args...); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+}
+
+func TestCreate_MigratesTheIndexSyncOutboxKinds(t *testing.T) {
+	db := newOldDB(t, `
+		CREATE TABLE index_sync_outbox (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			kind TEXT NOT NULL CHECK(kind IN ('article', 'tag')),
+			entity_id INTEGER NOT NULL,
+			tenant_id TEXT NOT NULL DEFAULT '',
+			project_id TEXT NOT NULL DEFAULT '',
+			attempts INTEGER NOT NULL DEFAULT 0,
+			last_error TEXT NOT NULL DEFAULT '',
+			next_attempt_at INTEGER NOT NULL,
+			created_at INTEGER NOT NULL
+		);
+		INSERT INTO index_sync_outbox (kind, entity_id, next_attempt_at, created_at) VALUES ('tag', 7, 0, 0);
+	`)
+
+	if err := Create(db); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	mustExec(t, db, `INSERT INTO index_sync_outbox (kind, entity_id, next_attempt_at, created_at) VALUES ('author', 3, 0, 0)`)
+	mustExec(t, db, `INSERT INTO index_sync_outbox (kind, entity_id, tenant_id, project_id, next_attempt_at, created_at) VALUES ('project', 0, 'acme', 'p-1', 0, 0)`)
+	var kinds string
+	db.QueryRow(`SELECT group_concat(kind || ':' || id) FROM (SELECT kind, id FROM index_sync_outbox ORDER BY id)`).Scan(&kinds)
+	if kinds != "tag:1,author:2,project:3" {
+		t.Errorf("expected the old intent kept and ids to go on, got %q", kinds)
+	}
+
+	// Migrations run once; running Create again is a no-op.
+	var version int
+	db.QueryRow(`PRAGMA user_version`).Scan(&version)
+	if version != len(migrations) {
+		t.Errorf("expected user_version %d, got %d", len(migrations), version)
+	}
+	if err := Create(db); err != nil {
+		t.Fatalf("Create failed on a current database: %v", err)
+	}
+}
+
+func TestCreate_AddsTheTagParentColumn(t *testing.T) {
+	db := newOldDB(t, `
+		CREATE TABLE tags (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			label TEXT NOT NULL UNIQUE,
+			created_at TIMESTAMP,
+			updated_at TIMESTAMP
+		);
+		INSERT INTO tags (label) VALUES ('Clothing'), ('Dresses');
+	`)
+
+	if err := Create(db); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	mustExec(t, db, `UPDATE tags SET parent_id = 1 WHERE label = 'Dresses'`)
+	var indexed int
+	db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name = 'idx_tags_parent'`).Scan(&indexed)
+	if indexed != 1 {
+		t.Error("expected the parent index created once the column exists")
+	}
+}
+
+func TestCreate_AllowsCustomRolesOnOldMembershipsAndInvitations(t *testing.T) {
+	db := newOldDB(t, `
+		CREATE TABLE memberships (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			tenant_id TEXT NOT NULL,
+			role TEXT NOT NULL CHECK(role IN ('admin', 'billing', 'member')),
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(user_id, tenant_id)
+		);
+		CREATE TABLE invitations (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL,
+			email TEXT NOT NULL,
+			role TEXT NOT NULL CHECK(role IN ('admin', 'billing', 'member')),
+			token_hash TEXT NOT NULL UNIQUE,
+			invited_by TEXT NOT NULL,
+			expires_at TIMESTAMP NOT NULL,
+			accepted_at TIMESTAMP,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+		INSERT INTO memberships (id, user_id, tenant_id, role) VALUES ('m-1', 'ada', 'acme', 'admin');
+	`)
+
+	if err := Create(db); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	mustExec(t, db, `INSERT INTO memberships (id, user_id, tenant_id, role) VALUES ('m-2', 'lee', 'acme', 'manager')`)
+	mustExec(t, db, `INSERT INTO invitations (id, tenant_id, email, role, token_hash, invited_by, expires_at) VALUES ('i-1', 'acme', 'grace@example.com', 'manager', 'hash', 'ada', 0)`)
+	var roles string
+	db.QueryRow(`SELECT group_concat(role) FROM (SELECT role FROM memberships ORDER BY id)`).Scan(&roles)
+	if roles != "admin,manager" {
+		t.Errorf("expected the old membership kept beside the custom role, got %q", roles)
+	}
+	if _, err := db.Exec(`INSERT INTO memberships (id, user_id, tenant_id, role) VALUES ('m-3', 'ada', 'acme', 'member')`); err == nil {
+		t.Error("expected the rebuilt table to keep its unique constraint")
+	}
+}
+
+func TestCreate_StoresRefreshTokenTimesAsUnixSeconds(t *testing.T) {
+	db := newOldDB(t, `
+		CREATE TABLE refresh_tokens (
+			id TEXT PRIMARY KEY,
+			family_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			tenant_id TEXT NOT NULL DEFAULT '',
+			token_hash TEXT NOT NULL UNIQUE,
+			expires_at TIMESTAMP NOT NULL,
+			used_at TIMESTAMP,
+			revoked_at TIMESTAMP,
+			created_at TIMESTAMP NOT NULL
+		);
+	`)
+	expires := time.Date(2026, 10, 19, 12, 0, 0, 0, time.FixedZone("EDT", -4*60*60))
+	mustExec(t, db, `INSERT INTO refresh_tokens (id, family_id, user_id, token_hash, expires_at, used_at, created_at) VALUES ('rt-1', 's-1', 'ada', 'hash', ?, ?, ?)`,
+		expires, expires.UTC(), expires.Add(-time.Hour))
+
+	if err := Create(db); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	var expiresAt, usedAt, createdAt int64
+	var revokedAt sql.NullInt64
+	err := db.QueryRow(`SELECT expires_at, used_at, revoked_at, created_at FROM refresh_tokens WHERE id = 'rt-1'`).
+		Scan(&expiresAt, &usedAt, &revokedAt, &createdAt)
+	if err != nil {
+		t.Fatalf("failed to read the migrated token: %v", err)
+	}
+	if expiresAt != expires.Unix() || usedAt != expires.Unix() || revokedAt.Valid || createdAt != expires.Unix()-3600 {
+		t.Errorf("expected the times converted to unix seconds, got %d, %d, %v, %d", expiresAt, usedAt, revokedAt, createdAt)
+	}
+}
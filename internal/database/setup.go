@@ -22,13 +22,72 @@ func Create(db *sql.DB) error {
 			id TEXT PRIMARY KEY,
 			user_id TEXT NOT NULL,
 			tenant_id TEXT NOT NULL,
-			role TEXT NOT NULL CHECK(role IN ('admin', 'billing', 'member')),
+			role TEXT NOT NULL,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
 			FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
 			UNIQUE(user_id, tenant_id)
 		);
 
+		CREATE TABLE IF NOT EXISTS custom_roles (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			permissions TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL,
+			FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+			UNIQUE(tenant_id, name)
+		);
+
+		CREATE TABLE IF NOT EXISTS refresh_tokens (
+			id TEXT PRIMARY KEY,
+			family_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			tenant_id TEXT NOT NULL DEFAULT '',
+			token_hash TEXT NOT NULL UNIQUE,
+			expires_at INTEGER NOT NULL,
+			used_at INTEGER,
+			revoked_at INTEGER,
+			created_at INTEGER NOT NULL,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		);
+
+		CREATE TABLE IF NOT EXISTS sessions (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			tenant_id TEXT NOT NULL DEFAULT '',
+			device TEXT NOT NULL DEFAULT '',
+			ip_address TEXT NOT NULL DEFAULT '',
+			user_agent TEXT NOT NULL DEFAULT '',
+			created_at INTEGER NOT NULL,
+			last_seen_at INTEGER NOT NULL,
+			expires_at INTEGER NOT NULL,
+			revoked_at INTEGER,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		);
+
+		CREATE TABLE IF NOT EXISTS access_tokens (
+			jti TEXT PRIMARY KEY,
+			session_id TEXT NOT NULL,
+			expires_at INTEGER NOT NULL,
+			revoked_at INTEGER,
+			FOREIGN KEY (session_id) REFERENCES sessions(id) ON DELETE CASCADE
+		);
+
+		CREATE TABLE IF NOT EXISTS invitations (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL,
+			email TEXT NOT NULL,
+			role TEXT NOT NULL,
+			token_hash TEXT NOT NULL UNIQUE,
+			invited_by TEXT NOT NULL,
+			expires_at TIMESTAMP NOT NULL,
+			accepted_at TIMESTAMP,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE
+		);
+
 		CREATE TABLE IF NOT EXISTS projects (
 			id TEXT PRIMARY KEY,
 			tenant_id TEXT NOT NULL,
@@ -60,8 +119,10 @@ func Create(db *sql.DB) error {
 		CREATE TABLE IF NOT EXISTS tags (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
 			label TEXT NOT NULL UNIQUE,
+			parent_id INTEGER,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP
+			updated_at TIMESTAMP,
+			FOREIGN KEY (parent_id) REFERENCES tags (id)
 		);
 
 		CREATE TABLE IF NOT EXISTS article_tags (
@@ -72,11 +133,215 @@ func Create(db *sql.DB) error {
 			FOREIGN KEY (tag_id) REFERENCES tags (id)
 		);
 
+		CREATE TABLE IF NOT EXISTS tenant_deletion_receipts (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL,
+			tenant_found BOOLEAN NOT NULL,
+			memberships_deleted INTEGER NOT NULL DEFAULT 0,
+			projects_deleted INTEGER NOT NULL DEFAULT 0,
+			articles_deleted INTEGER NOT NULL DEFAULT 0,
+			article_tags_deleted INTEGER NOT NULL DEFAULT 0,
+			index_name TEXT NOT NULL,
+			index_task_uid INTEGER NOT NULL DEFAULT 0,
+			deleted_at TIMESTAMP NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS usage_rollups (
+			tenant_id TEXT NOT NULL,
+			hour INTEGER NOT NULL,
+			searches INTEGER NOT NULL DEFAULT 0,
+			documents_indexed INTEGER NOT NULL DEFAULT 0,
+			documents_deleted INTEGER NOT NULL DEFAULT 0,
+			bytes_ingested INTEGER NOT NULL DEFAULT 0,
+			engine_calls INTEGER NOT NULL DEFAULT 0,
+			engine_errors INTEGER NOT NULL DEFAULT 0,
+			engine_latency_ms INTEGER NOT NULL DEFAULT 0,
+			engine_latency_max_ms INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (tenant_id, hour)
+		);
+
+		CREATE TABLE IF NOT EXISTS search_logs (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL,
+			query TEXT NOT NULL,
+			filters TEXT NOT NULL DEFAULT '',
+			total_hits INTEGER NOT NULL DEFAULT 0,
+			latency_ms INTEGER NOT NULL DEFAULT 0,
+			session_id TEXT NOT NULL DEFAULT '',
+			created_at INTEGER NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS search_events (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL,
+			type TEXT NOT NULL,
+			document_id TEXT NOT NULL,
+			search_id TEXT NOT NULL DEFAULT '',
+			query TEXT NOT NULL DEFAULT '',
+			session_id TEXT NOT NULL DEFAULT '',
+			created_at INTEGER NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS query_rules (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			enabled INTEGER NOT NULL DEFAULT 1,
+			match_type TEXT NOT NULL,
+			query TEXT NOT NULL,
+			starts_at TIMESTAMP,
+			ends_at TIMESTAMP,
+			consequences TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS saved_searches (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL,
+			name TEXT NOT NULL DEFAULT '',
+			query TEXT NOT NULL,
+			filter TEXT NOT NULL DEFAULT '',
+			sort TEXT NOT NULL DEFAULT '[]',
+			webhook_url TEXT NOT NULL,
+			secret TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS saved_search_matches (
+			saved_search_id TEXT NOT NULL,
+			document_id TEXT NOT NULL,
+			matched_at INTEGER NOT NULL,
+			PRIMARY KEY (saved_search_id, document_id)
+		);
+
+		CREATE TABLE IF NOT EXISTS webhook_deliveries (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL,
+			source_id TEXT NOT NULL,
+			event TEXT NOT NULL,
+			url TEXT NOT NULL,
+			payload TEXT NOT NULL,
+			secret TEXT NOT NULL,
+			status TEXT NOT NULL,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			response_status INTEGER NOT NULL DEFAULT 0,
+			last_error TEXT NOT NULL DEFAULT '',
+			next_attempt_at INTEGER NOT NULL,
+			created_at INTEGER NOT NULL,
+			delivered_at INTEGER
+		);
+
+		CREATE TABLE IF NOT EXISTS webhook_dead_letters (
+			id TEXT PRIMARY KEY,
+			delivery_id TEXT NOT NULL,
+			tenant_id TEXT NOT NULL,
+			source_id TEXT NOT NULL,
+			event TEXT NOT NULL,
+			url TEXT NOT NULL,
+			payload TEXT NOT NULL,
+			secret TEXT NOT NULL,
+			attempts INTEGER NOT NULL,
+			response_status INTEGER NOT NULL DEFAULT 0,
+			last_error TEXT NOT NULL DEFAULT '',
+			failed_at INTEGER NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+			id TEXT PRIMARY KEY,
+			tenant_id TEXT NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			url TEXT NOT NULL,
+			events TEXT NOT NULL,
+			enabled INTEGER NOT NULL DEFAULT 1,
+			secret TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS index_sync_outbox (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			kind TEXT NOT NULL CHECK(kind IN ('article', 'tag', 'author', 'project')),
+			entity_id INTEGER NOT NULL,
+			tenant_id TEXT NOT NULL DEFAULT '',
+			project_id TEXT NOT NULL DEFAULT '',
+			attempts INTEGER NOT NULL DEFAULT 0,
+			last_error TEXT NOT NULL DEFAULT '',
+			next_attempt_at INTEGER NOT NULL,
+			created_at INTEGER NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS index_sync_dead_letters (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			intent_id INTEGER NOT NULL,
+			kind TEXT NOT NULL,
+			entity_id INTEGER NOT NULL,
+			tenant_id TEXT NOT NULL DEFAULT '',
+			project_id TEXT NOT NULL DEFAULT '',
+			attempts INTEGER NOT NULL,
+			last_error TEXT NOT NULL,
+			created_at INTEGER NOT NULL,
+			failed_at INTEGER NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS reindex_checkpoints (
+			index_name TEXT PRIMARY KEY,
+			last_article_id INTEGER NOT NULL,
+			indexed INTEGER NOT NULL,
+			started_at INTEGER NOT NULL,
+			updated_at INTEGER NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS tenant_vocabulary (
+			tenant_id TEXT NOT NULL,
+			term TEXT NOT NULL,
+			frequency INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (tenant_id, term)
+		);
+
+		CREATE TABLE IF NOT EXISTS document_popularity (
+			tenant_id TEXT NOT NULL,
+			document_id TEXT NOT NULL,
+			score REAL NOT NULL DEFAULT 0,
+			updated_at INTEGER NOT NULL,
+			PRIMARY KEY (tenant_id, document_id)
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	if err := migrate(db); err != nil {
+		return err
+	}
+
+	// Indexes come after the migrations, which may add the columns they cover.
+	_, err = db.Exec(`
 		CREATE INDEX IF NOT EXISTS idx_memberships_user ON memberships(user_id);
 		CREATE INDEX IF NOT EXISTS idx_memberships_tenant ON memberships(tenant_id);
+		CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family ON refresh_tokens(family_id);
+		CREATE INDEX IF NOT EXISTS idx_sessions_user ON sessions(user_id);
+		CREATE INDEX IF NOT EXISTS idx_access_tokens_session ON access_tokens(session_id);
+		CREATE INDEX IF NOT EXISTS idx_access_tokens_revoked ON access_tokens(revoked_at, expires_at);
+		CREATE INDEX IF NOT EXISTS idx_invitations_tenant ON invitations(tenant_id, created_at);
 		CREATE INDEX IF NOT EXISTS idx_projects_tenant ON projects(tenant_id);
 		CREATE INDEX IF NOT EXISTS idx_articles_project ON articles(project_id);
 		CREATE INDEX IF NOT EXISTS idx_articles_tenant ON articles(tenant_id);
+		CREATE INDEX IF NOT EXISTS idx_tenant_deletion_receipts_tenant ON tenant_deletion_receipts(tenant_id);
+		CREATE INDEX IF NOT EXISTS idx_search_logs_tenant_created ON search_logs(tenant_id, created_at);
+		CREATE INDEX IF NOT EXISTS idx_search_logs_created ON search_logs(created_at);
+		CREATE INDEX IF NOT EXISTS idx_query_rules_tenant ON query_rules(tenant_id, created_at);
+		CREATE INDEX IF NOT EXISTS idx_search_events_search ON search_events(tenant_id, search_id);
+		CREATE INDEX IF NOT EXISTS idx_search_events_created ON search_events(created_at);
+		CREATE INDEX IF NOT EXISTS idx_saved_searches_tenant ON saved_searches(tenant_id, created_at);
+		CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(status, next_attempt_at);
+		CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_source ON webhook_deliveries(tenant_id, source_id, created_at);
+		CREATE INDEX IF NOT EXISTS idx_webhook_dead_letters_tenant ON webhook_dead_letters(tenant_id, failed_at);
+		CREATE INDEX IF NOT EXISTS idx_webhook_subscriptions_tenant ON webhook_subscriptions(tenant_id, created_at);
+		CREATE INDEX IF NOT EXISTS idx_tags_parent ON tags(parent_id);
+		CREATE INDEX IF NOT EXISTS idx_index_sync_outbox_due ON index_sync_outbox(next_attempt_at);
+		CREATE INDEX IF NOT EXISTS idx_index_sync_dead_letters_failed ON index_sync_dead_letters(failed_at);
 	`)
 
 	return err
@@ -0,0 +1,269 @@
+// Package drift detects, and repairs, divergence between the articles in
+// SQLite and the documents in the public articles index.
+package drift
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"mini-search-platform/internal/models"
+	"mini-search-platform/pkg/logging"
+)
+
+// logSampleSize bounds the IDs of each kind listed in the log.
+const logSampleSize = 20
+
+// Articles is implemented by adapters.SQLliteArticleRepository.
+type Articles interface {
+	ListAfterID(afterID, limit int) ([]*models.Article, error)
+	FindByIDs(ids []int) ([]*models.Article, error)
+}
+
+// Engine is implemented by adapters.MeilisearchEngine.
+type Engine interface {
+	ListArticleDocuments(offset, limit int) ([]*models.Article, int64, error)
+	IndexArticles(articles []*models.Article) error
+	DeleteArticles(ids []int) error
+}
+
+// Report is the outcome of a check. Missing articles are in SQLite but not
+// in the index, stale ones are in both with different content, and orphaned
+// documents are in the index with no article behind them.
+type Report struct {
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration"`
+	Articles  int           `json:"articles"`
+	Documents int           `json:"documents"`
+	Missing   []int         `json:"missing"`
+	Stale     []int         `json:"stale"`
+	Orphaned  []int         `json:"orphaned"`
+	Repaired  bool          `json:"repaired"`
+}
+
+func (r *Report) Consistent() bool {
+	return len(r.Missing) == 0 && len(r.Stale) == 0 && len(r.Orphaned) == 0
+}
+
+// ContentHash hashes the searchable content of an article: title, body,
+// author and tag paths (in any order). It is what a check compares.
+func ContentHash(article *models.Article) [sha256.Size]byte {
+	labels := make([]string, 0, len(article.Tags))
+	for _, tag := range article.Tags {
+		if tag != nil {
+			labels = append(labels, tag.PathLabel())
+		}
+	}
+	sort.Strings(labels)
+
+	h := sha256.New()
+	for _, field := range []string{article.Title, article.Body, article.Author, strings.Join(labels, "\x1f")} {
+		h.Write([]byte(field))
+		h.Write([]byte{0})
+	}
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+	return sum
+}
+
+// Checker compares both stores page by page. It holds one hash per article
+// in memory while reading the index. Only the shared articles index is
+// checked, against the articles without a tenant; tenants' articles are
+// indexed into their own indexes.
+//
+// Neither store is read in a single snapshot, so an article saved during a
+// check can show up as missing; re-indexing it is harmless. Documents look
+// orphaned only if their article is still absent from SQLite when the
+// check ends, so a repair never deletes an article saved meanwhile.
+type Checker struct {
+	articles Articles
+	engine   Engine
+
+	PageSize int
+
+	now func() time.Time
+}
+
+func NewChecker(articles Articles, engine Engine) *Checker {
+	return &Checker{articles: articles, engine: engine, PageSize: 1000, now: time.Now}
+}
+
+// Check reads both stores and reports how they differ.
+func (c *Checker) Check(ctx context.Context) (*Report, error) {
+	report := &Report{StartedAt: c.now().UTC(), Missing: []int{}, Stale: []int{}, Orphaned: []int{}}
+
+	expected := make(map[int][sha256.Size]byte)
+	for afterID := 0; ; {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		page, err := c.articles.ListAfterID(afterID, c.PageSize)
+		if err != nil {
+			return nil, fmt.Errorf("listing articles after %d: %w", afterID, err)
+		}
+		if len(page) == 0 {
+			break
+		}
+		for _, article := range page {
+			expected[article.ID] = ContentHash(article)
+		}
+		afterID = page[len(page)-1].ID
+	}
+	report.Articles = len(expected)
+
+	seen := make(map[int]bool, len(expected))
+	var unknown []int
+	for offset := 0; ; offset += c.PageSize {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		documents, _, err := c.engine.ListArticleDocuments(offset, c.PageSize)
+		if err != nil {
+			return nil, fmt.Errorf("listing index documents at %d: %w", offset, err)
+		}
+		for _, document := range documents {
+			if seen[document.ID] {
+				continue
+			}
+			seen[document.ID] = true
+			report.Documents++
+
+			hash, ok := expected[document.ID]
+			switch {
+			case !ok:
+				unknown = append(unknown, document.ID)
+			case hash != ContentHash(document):
+				report.Stale = append(report.Stale, document.ID)
+			}
+		}
+		if len(documents) < c.PageSize {
+			break
+		}
+	}
+
+	for id := range expected {
+		if !seen[id] {
+			report.Missing = append(report.Missing, id)
+		}
+	}
+
+	// Articles saved since SQLite was read are not orphans, unless they
+	// belong to a tenant and so have no place in the shared index.
+	for start := 0; start < len(unknown); start += c.PageSize {
+		batch := unknown[start:min(start+c.PageSize, len(unknown))]
+		found, err := c.articles.FindByIDs(batch)
+		if err != nil {
+			return nil, fmt.Errorf("confirming orphaned documents: %w", err)
+		}
+		saved := make(map[int]bool, len(found))
+		for _, article := range found {
+			saved[article.ID] = article.TenantID == ""
+		}
+		for _, id := range batch {
+			if !saved[id] {
+				report.Orphaned = append(report.Orphaned, id)
+			}
+		}
+	}
+
+	sort.Ints(report.Missing)
+	sort.Ints(report.Stale)
+	sort.Ints(report.Orphaned)
+	report.Duration = c.now().Sub(report.StartedAt)
+	return report, nil
+}
+
+// Repair re-indexes the report's missing and stale articles, from their
+// current state in SQLite, and deletes its orphaned documents.
+func (c *Checker) Repair(ctx context.Context, report *Report) error {
+	reindex := append(append([]int{}, report.Missing...), report.Stale...)
+	for start := 0; start < len(reindex); start += c.PageSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		articles, err := c.articles.FindByIDs(reindex[start:min(start+c.PageSize, len(reindex))])
+		if err != nil {
+			return fmt.Errorf("loading articles to re-index: %w", err)
+		}
+		if len(articles) == 0 {
+			continue
+		}
+		if err := c.engine.IndexArticles(articles); err != nil {
+			return fmt.Errorf("re-indexing articles: %w", err)
+		}
+	}
+
+	for start := 0; start < len(report.Orphaned); start += c.PageSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := c.engine.DeleteArticles(report.Orphaned[start:min(start+c.PageSize, len(report.Orphaned))]); err != nil {
+			return fmt.Errorf("deleting orphaned documents: %w", err)
+		}
+	}
+
+	report.Repaired = true
+	return nil
+}
+
+// Run checks, repairs when asked to and drift was found, and logs the
+// report.
+func (c *Checker) Run(ctx context.Context, repair bool) (*Report, error) {
+	report, err := c.Check(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if repair && !report.Consistent() {
+		if err := c.Repair(ctx, report); err != nil {
+			LogReport(report)
+			return report, err
+		}
+	}
+	LogReport(report)
+	return report, nil
+}
+
+// Start runs a check every interval, repairing the drift it finds when
+// repair is set.
+func (c *Checker) Start(interval time.Duration, repair bool) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			if _, err := c.Run(context.Background(), repair); err != nil {
+				logging.Error("articles index drift check failed", "error", err)
+			}
+		}
+	}()
+}
+
+// LogReport logs the report's counts, with a sample of the IDs of each
+// kind of drift.
+func LogReport(report *Report) {
+	args := []any{
+		"articles", report.Articles,
+		"documents", report.Documents,
+		"missing", len(report.Missing),
+		"stale", len(report.Stale),
+		"orphaned", len(report.Orphaned),
+		"repaired", report.Repaired,
+		"duration_ms", report.Duration.Milliseconds(),
+	}
+	if report.Consistent() {
+		logging.Info("articles index is consistent with SQLite", args...)
+		return
+	}
+
+	args = append(args,
+		"missing_ids", sample(report.Missing),
+		"stale_ids", sample(report.Stale),
+		"orphaned_ids", sample(report.Orphaned),
+	)
+	logging.Warn("articles index has drifted from SQLite", args...)
+}
+
+func sample(ids []int) []int {
+	return ids[:min(len(ids), logSampleSize)]
+}
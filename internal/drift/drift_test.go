@@ -0,0 +1,175 @@
+package drift
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"mini-search-platform/internal/models"
+)
+
+type memoryArticles struct {
+	articles map[int]*models.Article
+}
+
+func (a *memoryArticles) ListAfterID(afterID, limit int) ([]*models.Article, error) {
+	var ids []int
+	for id, article := range a.articles {
+		if id > afterID && article.TenantID == "" {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return a.FindByIDs(ids[:min(len(ids), limit)])
+}
+
+func (a *memoryArticles) FindByIDs(ids []int) ([]*models.Article, error) {
+	var found []*models.Article
+	for _, id := range ids {
+		if article, ok := a.articles[id]; ok {
+			copied := *article
+			found = append(found, &copied)
+		}
+	}
+	return found, nil
+}
+
+type memoryIndex struct {
+	order     []int
+	documents map[int]*models.Article
+}
+
+func newMemoryIndex(articles ...*models.Article) *memoryIndex {
+	index := &memoryIndex{documents: make(map[int]*models.Article)}
+	index.IndexArticles(articles)
+	return index
+}
+
+func (i *memoryIndex) ListArticleDocuments(offset, limit int) ([]*models.Article, int64, error) {
+	var page []*models.Article
+	for _, id := range i.order[min(offset, len(i.order)):min(offset+limit, len(i.order))] {
+		page = append(page, i.documents[id])
+	}
+	return page, int64(len(i.order)), nil
+}
+
+func (i *memoryIndex) IndexArticles(articles []*models.Article) error {
+	for _, article := range articles {
+		if _, ok := i.documents[article.ID]; !ok {
+			i.order = append(i.order, article.ID)
+		}
+		copied := *article
+		i.documents[article.ID] = &copied
+	}
+	return nil
+}
+
+func (i *memoryIndex) DeleteArticles(ids []int) error {
+	for _, id := range ids {
+		delete(i.documents, id)
+		for j, other := range i.order {
+			if other == id {
+				i.order = append(i.order[:j], i.order[j+1:]...)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func article(id int, title string, tags ...string) *models.Article {
+	a := &models.Article{ID: id, Title: title, Body: "body", Author: "Ada"}
+	for _, label := range tags {
+		a.Tags = append(a.Tags, &models.Tag{Label: label})
+	}
+	return a
+}
+
+func TestChecker_ReportsAndRepairsDrift(t *testing.T) {
+	articles := &memoryArticles{articles: map[int]*models.Article{
+		1: article(1, "Linen blazer", "summer", "linen"),
+		2: article(2, "Wool coat", "winter"),
+		3: article(3, "Rain jacket"),
+		4: article(4, "Leather boots"),
+	}}
+	index := newMemoryIndex(
+		article(1, "Linen blazer", "linen", "summer"), // same tags, other order
+		article(2, "Wool coat", "autumn"),             // stale tag
+		article(3, "Rain jacket"),
+		article(9, "Deleted dress"), // orphaned
+	)
+
+	checker := NewChecker(articles, index)
+	checker.PageSize = 2
+
+	report, err := checker.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if report.Articles != 4 || report.Documents != 4 {
+		t.Errorf("unexpected counts %+v", report)
+	}
+	if !reflect.DeepEqual(report.Missing, []int{4}) || !reflect.DeepEqual(report.Stale, []int{2}) || !reflect.DeepEqual(report.Orphaned, []int{9}) {
+		t.Fatalf("unexpected drift: missing=%v stale=%v orphaned=%v", report.Missing, report.Stale, report.Orphaned)
+	}
+
+	if err := checker.Repair(context.Background(), report); err != nil {
+		t.Fatalf("Repair failed: %v", err)
+	}
+	if !report.Repaired {
+		t.Error("expected the report to be marked repaired")
+	}
+
+	report, err = checker.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if !report.Consistent() {
+		t.Errorf("expected no drift after the repair, got %+v", report)
+	}
+}
+
+func TestChecker_ArticleSavedDuringCheckIsNotOrphaned(t *testing.T) {
+	articles := &memoryArticles{articles: map[int]*models.Article{1: article(1, "Linen blazer")}}
+	index := newMemoryIndex(article(1, "Linen blazer"), article(2, "New arrival"))
+
+	// The article reaches SQLite after SQLite was read but before the
+	// check ends.
+	checker := NewChecker(articles, &savingIndex{memoryIndex: index, onList: func() {
+		articles.articles[2] = article(2, "New arrival")
+	}})
+
+	report, err := checker.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if len(report.Orphaned) != 0 {
+		t.Errorf("a document whose article was just saved must not be orphaned, got %v", report.Orphaned)
+	}
+}
+
+func TestChecker_TenantArticleInTheSharedIndexIsOrphaned(t *testing.T) {
+	tenantArticle := article(2, "Tenant blazer")
+	tenantArticle.TenantID = "acme"
+	articles := &memoryArticles{articles: map[int]*models.Article{1: article(1, "Linen blazer"), 2: tenantArticle}}
+	index := newMemoryIndex(article(1, "Linen blazer"), article(2, "Tenant blazer"))
+
+	report, err := NewChecker(articles, index).Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if report.Articles != 1 || len(report.Missing) != 0 || !reflect.DeepEqual(report.Orphaned, []int{2}) {
+		t.Errorf("expected only the shared article checked and the tenant's document orphaned, got %+v", report)
+	}
+}
+
+type savingIndex struct {
+	*memoryIndex
+	onList func()
+}
+
+func (i *savingIndex) ListArticleDocuments(offset, limit int) ([]*models.Article, int64, error) {
+	i.onList()
+	return i.memoryIndex.ListArticleDocuments(offset, limit)
+}
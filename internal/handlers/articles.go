@@ -1,25 +1,50 @@
 package handlers
 
 import (
-	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
 	"mini-search-platform/internal/models"
 	"mini-search-platform/internal/search"
 	"mini-search-platform/pkg/errors"
-	"mini-search-platform/pkg/retry"
+	"mini-search-platform/pkg/security"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mcuadros/go-defaults"
 )
 
 type AuthorsFinder interface {
 	FindAuthorById(id int) (*models.Author, error)
 }
 
+type ProjectFinder interface {
+	FindByID(id string) (*models.Project, error)
+}
+
+// ArticleInput is an article to create in the request's tenant, and in
+// ProjectID when given, which must be one of the tenant's projects.
 type ArticleInput struct {
-	Title    string   `json:"title" binding:"required"`
-	Body     string   `json:"body" binding:"required"`
-	AuthorID int      `json:"author_id" binding:"required"`
-	Author   string   `json:"author"`
-	Tags     []string `json:"tags"`
+	Title     string   `json:"title" binding:"required"`
+	Body      string   `json:"body" binding:"required"`
+	AuthorID  int      `json:"author_id" binding:"required"`
+	Author    string   `json:"author"`
+	ProjectID string   `json:"project_id"`
+	Tags      []string `json:"tags"`
+}
+
+// findTenantProject checks that the project belongs to the tenant. Another
+// tenant's project is reported as not found, like a missing one.
+func findTenantProject(projects ProjectFinder, tenantID, projectID string) error {
+	project, err := projects.FindByID(projectID)
+	if err != nil {
+		return errors.Database("failed to fetch project", err)
+	}
+	if project == nil || project.TenantID != tenantID {
+		return errors.NotFound(fmt.Sprintf("project '%s'", projectID))
+	}
+	return nil
 }
 
 type AddArticlesSummary struct {
@@ -33,7 +58,7 @@ type AddArticlesResponse struct {
 	Failed   []map[string]ArticleInput `json:"failed"`
 }
 
-func AddArticles(repository models.ArticleRepository, finder AuthorsFinder, tagsRepository models.TagsRepository, sync *search.IndexSyncManager) gin.HandlerFunc {
+func AddArticles(repository models.ArticleRepository, finder AuthorsFinder, tagsRepository models.TagsRepository, projects ProjectFinder, sync *search.IndexSyncManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var inputs []ArticleInput
 		if err := c.ShouldBindJSON(&inputs); err != nil {
@@ -41,9 +66,20 @@ func AddArticles(repository models.ArticleRepository, finder AuthorsFinder, tags
 			return
 		}
 
+		tenantID := security.MustGetTenantID(c)
+
 		var inserted []*models.Article
 		var failed = []map[string]ArticleInput{}
 		for _, input := range inputs {
+			if input.ProjectID != "" {
+				if err := findTenantProject(projects, tenantID, input.ProjectID); err != nil {
+					failed = append(failed, map[string]ArticleInput{
+						"project not found": input,
+					})
+					continue
+				}
+			}
+
 			author, err := finder.FindAuthorById(input.AuthorID)
 			if err != nil {
 				failed = append(failed, map[string]ArticleInput{
@@ -61,6 +97,8 @@ func AddArticles(repository models.ArticleRepository, finder AuthorsFinder, tags
 			}
 
 			article := models.NewArticle(input.Title, input.Body, author, tags)
+			article.TenantID = tenantID
+			article.ProjectID = input.ProjectID
 
 			lastInsertedId, err := repository.Save(article)
 			if err != nil {
@@ -74,13 +112,8 @@ func AddArticles(repository models.ArticleRepository, finder AuthorsFinder, tags
 			inserted = append(inserted, article)
 		}
 
-		resync := func(articlesToSync []*models.Article) error {
-			operation := func() error {
-				return sync.SyncAfterArticlesChanged(articlesToSync)
-			}
-			return retry.WithBackoff(context.Background(), operation)
-		}
-		go resync(inserted)
+		// Save queued an index sync intent with each article.
+		sync.Notify()
 
 		c.JSON(201, AddArticlesResponse{
 			Summary: AddArticlesSummary{
@@ -93,7 +126,7 @@ func AddArticles(repository models.ArticleRepository, finder AuthorsFinder, tags
 	}
 }
 
-func AddArticle(repository models.ArticleRepository, finder AuthorsFinder, tagsRepository models.TagsRepository, sync *search.IndexSyncManager) gin.HandlerFunc {
+func AddArticle(repository models.ArticleRepository, finder AuthorsFinder, tagsRepository models.TagsRepository, projects ProjectFinder, sync *search.IndexSyncManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
 
 		var input ArticleInput
@@ -102,6 +135,14 @@ func AddArticle(repository models.ArticleRepository, finder AuthorsFinder, tagsR
 			return
 		}
 
+		tenantID := security.MustGetTenantID(c)
+		if input.ProjectID != "" {
+			if err := findTenantProject(projects, tenantID, input.ProjectID); err != nil {
+				errors.Handle(c, err)
+				return
+			}
+		}
+
 		author, err := finder.FindAuthorById(input.AuthorID)
 		if err != nil {
 			errors.Handle(c, errors.NotFound("author"))
@@ -115,6 +156,8 @@ func AddArticle(repository models.ArticleRepository, finder AuthorsFinder, tagsR
 		}
 
 		article := models.NewArticle(input.Title, input.Body, author, tags)
+		article.TenantID = tenantID
+		article.ProjectID = input.ProjectID
 
 		lastInsertedId, err := repository.Save(article)
 		if err != nil {
@@ -124,14 +167,290 @@ func AddArticle(repository models.ArticleRepository, finder AuthorsFinder, tagsR
 
 		article.ID = lastInsertedId
 
-		resync := func(articlesToSync []*models.Article) error {
-			operation := func() error {
-				return sync.SyncAfterArticlesChanged(articlesToSync)
+		// Save queued an index sync intent with the article.
+		sync.Notify()
+
+		c.JSON(201, article)
+	}
+}
+
+// articleID parses the :id path parameter, handling the error if it is not
+// an article ID.
+func articleID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		errors.Handle(c, errors.Validation("article id must be a positive integer"))
+		return 0, false
+	}
+	return id, true
+}
+
+// findArticle loads the :id article, handling the error if it cannot. An
+// article of another tenant is not found.
+func findArticle(c *gin.Context, repository models.ArticleRepository) (*models.Article, bool) {
+	id, ok := articleID(c)
+	if !ok {
+		return nil, false
+	}
+
+	article, err := repository.FindByID(id)
+	if err != nil {
+		errors.Handle(c, errors.Database("failed to fetch article", err))
+		return nil, false
+	}
+	if article == nil || article.TenantID != security.MustGetTenantID(c) {
+		errors.Handle(c, errors.NotFound(fmt.Sprintf("article %d", id)))
+		return nil, false
+	}
+	return article, true
+}
+
+func GetArticle(repository models.ArticleRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		article, ok := findArticle(c, repository)
+		if !ok {
+			return
+		}
+
+		c.JSON(200, article)
+	}
+}
+
+// ListArticlesParams is the GET /articles query, over the request tenant's
+// articles. From and To bound the creation date, inclusive; they take an
+// RFC 3339 timestamp or a date, and a date as To covers that whole day (UTC).
+type ListArticlesParams struct {
+	ProjectID string `form:"project_id"`
+	AuthorID  int    `form:"author_id" binding:"min=0"`
+	Tag       string `form:"tag"`
+	From      string `form:"from"`
+	To        string `form:"to"`
+	Limit     int    `form:"limit" default:"20" binding:"min=1,max=100"`
+	Offset    int    `form:"offset" default:"0" binding:"min=0"`
+}
+
+type ListArticlesResponse struct {
+	Articles []*models.Article `json:"articles"`
+	Total    int               `json:"total"`
+	Limit    int               `json:"limit"`
+	Offset   int               `json:"offset"`
+}
+
+func ListArticles(repository models.ArticleRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var params ListArticlesParams
+		defaults.SetDefaults(&params)
+
+		if err := c.ShouldBindQuery(&params); err != nil {
+			errors.Handle(c, errors.Validation(err.Error()))
+			return
+		}
+
+		listArticles(c, repository, params)
+	}
+}
+
+func listArticles(c *gin.Context, repository models.ArticleRepository, params ListArticlesParams) {
+	filter := models.ArticleFilter{
+		TenantID:  security.MustGetTenantID(c),
+		ProjectID: params.ProjectID,
+		AuthorID:  params.AuthorID,
+		Tag:       params.Tag,
+		Limit:     params.Limit,
+		Offset:    params.Offset,
+	}
+	var err error
+	if filter.From, err = parseDateBound("from", params.From, false); err != nil {
+		errors.Handle(c, err)
+		return
+	}
+	if filter.To, err = parseDateBound("to", params.To, true); err != nil {
+		errors.Handle(c, err)
+		return
+	}
+	if filter.From != nil && filter.To != nil && filter.From.After(*filter.To) {
+		errors.Handle(c, errors.Validation("from must not be after to"))
+		return
+	}
+
+	articles, total, err := repository.List(filter)
+	if err != nil {
+		errors.Handle(c, errors.Database("failed to list articles", err))
+		return
+	}
+	if articles == nil {
+		articles = []*models.Article{}
+	}
+
+	c.JSON(200, ListArticlesResponse{
+		Articles: articles,
+		Total:    total,
+		Limit:    params.Limit,
+		Offset:   params.Offset,
+	})
+}
+
+func parseDateBound(field, raw string, endOfDay bool) (*time.Time, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		return nil, errors.Validation(fmt.Sprintf("%s must be an RFC 3339 timestamp or a YYYY-MM-DD date", field))
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Second)
+	}
+	return &t, nil
+}
+
+// UpdateArticleInput is the PUT /articles/:id body, which replaces the
+// article's content; omitted tags leave the article untagged.
+type UpdateArticleInput struct {
+	Title    string   `json:"title" binding:"required"`
+	Body     string   `json:"body" binding:"required"`
+	AuthorID int      `json:"author_id" binding:"required"`
+	Tags     []string `json:"tags"`
+}
+
+// PatchArticleInput is the PATCH /articles/:id body; only the fields given
+// change. Tags, when given, replace the article's tags.
+type PatchArticleInput struct {
+	Title    *string   `json:"title" binding:"omitempty,min=1"`
+	Body     *string   `json:"body" binding:"omitempty,min=1"`
+	AuthorID *int      `json:"author_id" binding:"omitempty,min=1"`
+	Tags     *[]string `json:"tags"`
+}
+
+func UpdateArticle(repository models.ArticleRepository, finder AuthorsFinder, tagsRepository models.TagsRepository, sync *search.IndexSyncManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		article, ok := findArticle(c, repository)
+		if !ok {
+			return
+		}
+
+		var input UpdateArticleInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			errors.Handle(c, errors.Validation(err.Error()))
+			return
+		}
+
+		saveArticleChanges(c, repository, finder, tagsRepository, sync, article, PatchArticleInput{
+			Title:    &input.Title,
+			Body:     &input.Body,
+			AuthorID: &input.AuthorID,
+			Tags:     &input.Tags,
+		})
+	}
+}
+
+func PatchArticle(repository models.ArticleRepository, finder AuthorsFinder, tagsRepository models.TagsRepository, sync *search.IndexSyncManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		article, ok := findArticle(c, repository)
+		if !ok {
+			return
+		}
+
+		var input PatchArticleInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			errors.Handle(c, errors.Validation(err.Error()))
+			return
+		}
+
+		saveArticleChanges(c, repository, finder, tagsRepository, sync, article, input)
+	}
+}
+
+// saveArticleChanges applies the changes to the article, saves it and
+// responds with the saved article. Unlike AddArticle, it rejects unknown
+// tag labels rather than dropping them.
+func saveArticleChanges(c *gin.Context, repository models.ArticleRepository, finder AuthorsFinder, tagsRepository models.TagsRepository, sync *search.IndexSyncManager, article *models.Article, changes PatchArticleInput) {
+	if changes.Title != nil {
+		article.Title = *changes.Title
+	}
+	if changes.Body != nil {
+		article.Body = *changes.Body
+	}
+	if changes.AuthorID != nil && *changes.AuthorID != article.AuthorID {
+		author, err := finder.FindAuthorById(*changes.AuthorID)
+		if err != nil {
+			errors.Handle(c, errors.NotFound("author"))
+			return
+		}
+		article.AuthorID = author.ID
+		article.Author = author.Name
+	}
+	if changes.Tags != nil {
+		tags, err := findTagsByLabels(tagsRepository, *changes.Tags)
+		if err != nil {
+			errors.Handle(c, err)
+			return
+		}
+		article.Tags = tags
+	}
+
+	if err := repository.Update(article); err != nil {
+		if err == sql.ErrNoRows {
+			errors.Handle(c, errors.NotFound(fmt.Sprintf("article %d", article.ID)))
+			return
+		}
+		errors.Handle(c, errors.Database("failed to update article", err))
+		return
+	}
+
+	// Update queued an index sync intent with the article.
+	sync.Notify()
+
+	updated, err := repository.FindByID(article.ID)
+	if err != nil || updated == nil {
+		updated = article
+	}
+
+	c.JSON(200, updated)
+}
+
+// findTagsByLabels returns the tags with the labels, or a NotFound error
+// naming the first label that has no tag.
+func findTagsByLabels(tagsRepository models.TagsRepository, labels []string) ([]*models.Tag, error) {
+	tags, err := tagsRepository.FindByLabels(labels)
+	if err != nil {
+		return nil, errors.Database("failed to fetch tags", err)
+	}
+
+	found := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		found[tag.Label] = true
+	}
+	for _, label := range labels {
+		if !found[label] {
+			return nil, errors.NotFound(fmt.Sprintf("tag '%s'", label))
+		}
+	}
+	return tags, nil
+}
+
+func DeleteArticle(repository models.ArticleRepository, sync *search.IndexSyncManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		article, ok := findArticle(c, repository)
+		if !ok {
+			return
+		}
+
+		if err := repository.Delete(article.ID); err != nil {
+			if err == sql.ErrNoRows {
+				errors.Handle(c, errors.NotFound(fmt.Sprintf("article %d", article.ID)))
+				return
 			}
-			return retry.WithBackoff(context.Background(), operation)
+			errors.Handle(c, errors.Database("failed to delete article", err))
+			return
 		}
-		go resync([]*models.Article{article})
 
-		c.JSON(201, article)
+		// Delete queued the index sync intent that removes the document.
+		sync.Notify()
+
+		c.Status(204)
 	}
 }
@@ -10,23 +10,44 @@ import (
 	"github.com/google/uuid"
 )
 
+// RegisterRequest creates the user with a new tenant named TenantName, or,
+// given an InvitationToken, joins the tenant the user was invited to.
 type RegisterRequest struct {
-	Email      string `json:"email" binding:"required,email"`
-	Password   string `json:"password" binding:"required,min=8"`
-	TenantName string `json:"tenant_name" binding:"required"`
+	Email           string `json:"email" binding:"required,email"`
+	Password        string `json:"password" binding:"required,min=8"`
+	TenantName      string `json:"tenant_name" binding:"required_without=InvitationToken"`
+	InvitationToken string `json:"invitation_token"`
 }
 
+// LoginRequest may carry an InvitationToken, accepted once the credentials
+// check out, or the TenantID the tokens should be bound to.
 type LoginRequest struct {
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required"`
+	Email           string `json:"email" binding:"required,email"`
+	Password        string `json:"password" binding:"required"`
+	InvitationToken string `json:"invitation_token"`
+	TenantID        string `json:"tenant_id"`
 }
 
+type SwitchTenantRequest struct {
+	TenantID string `json:"tenant_id" binding:"required"`
+}
+
+// RefreshRequest is the body of POST /auth/refresh and POST /auth/logout.
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// TokenResponse carries the tenant the tokens are bound to, and the user's
+// role in it, if any.
 type TokenResponse struct {
-	AccessToken  string   `json:"access_token"`
-	RefreshToken string   `json:"refresh_token"`
-	TokenType    string   `json:"token_type"`
-	ExpiresIn    int64    `json:"expires_in"`
-	User         UserInfo `json:"user"`
+	AccessToken      string      `json:"access_token"`
+	RefreshToken     string      `json:"refresh_token"`
+	TokenType        string      `json:"token_type"`
+	ExpiresIn        int64       `json:"expires_in"`
+	RefreshExpiresIn int64       `json:"refresh_expires_in"`
+	TenantID         string      `json:"tenant_id,omitempty"`
+	Role             models.Role `json:"role,omitempty"`
+	User             UserInfo    `json:"user"`
 }
 
 type UserInfo struct {
@@ -38,8 +59,8 @@ func Register(
 	userRepo models.UserRepository,
 	tenantRepo models.TenantRepository,
 	membershipRepo models.MembershipRepository,
-	jwtService *security.JWTService,
-	accessTTL int64,
+	invitationRepo models.InvitationRepository,
+	issuer *TokenIssuer,
 ) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req RegisterRequest
@@ -48,6 +69,15 @@ func Register(
 			return
 		}
 
+		var invitation *models.Invitation
+		if req.InvitationToken != "" {
+			found, ok := findInvitation(c, invitationRepo, req.InvitationToken, req.Email)
+			if !ok {
+				return
+			}
+			invitation = found
+		}
+
 		existingUser, err := userRepo.FindByEmail(req.Email)
 		if err != nil {
 			errors.Handle(c, errors.Database("failed to check existing user", err))
@@ -67,135 +97,223 @@ func Register(
 		userID := uuid.New().String()
 		user := models.NewUser(userID, req.Email, passwordHash)
 
-		if err := userRepo.Save(user); err != nil {
-			errors.Handle(c, errors.Database("failed to create user", err))
-			return
+		var membership *models.Membership
+		if invitation != nil {
+			accepted, ok := acceptInvitation(c, invitationRepo, invitation, userID, user)
+			if !ok {
+				return
+			}
+			membership = accepted
+		} else {
+			if err := userRepo.Save(user); err != nil {
+				errors.Handle(c, errors.Database("failed to create user", err))
+				return
+			}
+
+			tenantID := uuid.New().String()
+			tenant := models.NewTenant(tenantID, req.TenantName)
+
+			if err := tenantRepo.Save(tenant); err != nil {
+				errors.Handle(c, errors.Database("failed to create tenant", err))
+				return
+			}
+
+			membershipID := uuid.New().String()
+			membership = models.NewMembership(membershipID, userID, tenantID, models.RoleAdmin)
+
+			if err := membershipRepo.Save(membership); err != nil {
+				errors.Handle(c, errors.Database("failed to create membership", err))
+				return
+			}
 		}
 
-		tenantID := uuid.New().String()
-		tenant := models.NewTenant(tenantID, req.TenantName)
+		respondWithTokens(c, issuer, http.StatusCreated, userID, req.Email, membership)
+	}
+}
 
-		if err := tenantRepo.Save(tenant); err != nil {
-			errors.Handle(c, errors.Database("failed to create tenant", err))
+// Login binds the tokens to the tenant the user accepts an invitation to,
+// the requested tenant_id, or the user's only tenant, in that order. A user
+// of several tenants who names none gets unbound tokens and picks one with
+// SwitchTenant.
+func Login(
+	userRepo models.UserRepository,
+	membershipRepo models.MembershipRepository,
+	invitationRepo models.InvitationRepository,
+	issuer *TokenIssuer,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req LoginRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			errors.Handle(c, errors.Validation(err.Error()))
 			return
 		}
 
-		membershipID := uuid.New().String()
-		membership := models.NewMembership(membershipID, userID, tenantID, models.RoleAdmin)
-
-		if err := membershipRepo.Save(membership); err != nil {
-			errors.Handle(c, errors.Database("failed to create membership", err))
+		user, err := userRepo.FindByEmail(req.Email)
+		if err != nil {
+			errors.Handle(c, errors.Database("failed to find user", err))
 			return
 		}
 
-		accessToken, err := jwtService.GenerateToken(userID, req.Email)
-		if err != nil {
-			errors.Handle(c, errors.Internal("failed to generate token", err))
+		if user == nil {
+			errors.Handle(c, errors.Unauthorized("invalid credentials"))
 			return
 		}
 
-		refreshToken, err := jwtService.GenerateToken(userID, req.Email)
-		if err != nil {
-			errors.Handle(c, errors.Internal("failed to generate refresh token", err))
+		if err := security.ComparePassword(user.PasswordHash, req.Password); err != nil {
+			errors.Handle(c, errors.Unauthorized("invalid credentials"))
 			return
 		}
 
-		c.JSON(http.StatusCreated, TokenResponse{
-			AccessToken:  accessToken,
-			RefreshToken: refreshToken,
-			TokenType:    "Bearer",
-			ExpiresIn:    accessTTL,
-			User: UserInfo{
-				ID:    userID,
-				Email: req.Email,
-			},
-		})
+		var membership *models.Membership
+		switch {
+		case req.InvitationToken != "":
+			invitation, ok := findInvitation(c, invitationRepo, req.InvitationToken, user.Email)
+			if !ok {
+				return
+			}
+			if membership, ok = acceptInvitation(c, invitationRepo, invitation, user.ID, nil); !ok {
+				return
+			}
+		case req.TenantID != "":
+			if membership, err = membershipRepo.FindByUserAndTenant(user.ID, req.TenantID); err != nil {
+				errors.Handle(c, errors.Database("failed to verify tenant access", err))
+				return
+			}
+			if membership == nil {
+				errors.Handle(c, errors.Forbidden("access denied to this tenant"))
+				return
+			}
+		default:
+			memberships, err := membershipRepo.ListByUser(user.ID)
+			if err != nil {
+				errors.Handle(c, errors.Database("failed to list the user's tenants", err))
+				return
+			}
+			if len(memberships) == 1 {
+				membership = memberships[0]
+			}
+		}
+
+		respondWithTokens(c, issuer, http.StatusOK, user.ID, user.Email, membership)
 	}
 }
 
-func Login(
-	userRepo models.UserRepository,
-	jwtService *security.JWTService,
-	accessTTL int64,
-) gin.HandlerFunc {
+// SwitchTenant handles POST /auth/switch-tenant: it issues tokens bound to
+// another of the user's tenants.
+func SwitchTenant(membershipRepo models.MembershipRepository, issuer *TokenIssuer) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var req LoginRequest
+		var req SwitchTenantRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
 			errors.Handle(c, errors.Validation(err.Error()))
 			return
 		}
 
-		user, err := userRepo.FindByEmail(req.Email)
+		userID := security.MustGetUserID(c)
+		membership, err := membershipRepo.FindByUserAndTenant(userID, req.TenantID)
 		if err != nil {
-			errors.Handle(c, errors.Database("failed to find user", err))
+			errors.Handle(c, errors.Database("failed to verify tenant access", err))
+			return
+		}
+		if membership == nil {
+			errors.Handle(c, errors.Forbidden("access denied to this tenant"))
 			return
 		}
 
-		if user == nil {
-			errors.Handle(c, errors.Unauthorized("invalid credentials"))
+		respondWithTokens(c, issuer, http.StatusOK, userID, security.MustGetUserEmail(c), membership)
+	}
+}
+
+// RefreshToken handles POST /auth/refresh: the refresh token is rotated
+// and a new access token issued in the same session, bound to the same
+// tenant with the user's current role there. Presenting an already rotated
+// token revokes the whole session.
+func RefreshToken(userRepo models.UserRepository, membershipRepo models.MembershipRepository, issuer *TokenIssuer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req RefreshRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			errors.Handle(c, errors.Validation(err.Error()))
 			return
 		}
 
-		if err := security.ComparePassword(user.PasswordHash, req.Password); err != nil {
-			errors.Handle(c, errors.Unauthorized("invalid credentials"))
+		current, err := issuer.current(req.RefreshToken)
+		switch {
+		case err == security.ErrInvalidToken:
+			errors.Handle(c, errors.Unauthorized("invalid refresh token"))
+			return
+		case err == models.ErrRefreshTokenReused:
+			errors.Handle(c, errors.Unauthorized("refresh token reuse detected; log in again"))
+			return
+		case err != nil:
+			errors.Handle(c, errors.Database("failed to verify refresh token", err))
 			return
 		}
 
-		accessToken, err := jwtService.GenerateToken(user.ID, user.Email)
+		user, err := userRepo.FindByID(current.UserID)
 		if err != nil {
-			errors.Handle(c, errors.Internal("failed to generate token", err))
+			errors.Handle(c, errors.Database("failed to find user", err))
+			return
+		}
+		if user == nil {
+			errors.Handle(c, errors.Unauthorized("invalid refresh token"))
 			return
 		}
 
-		refreshToken, err := jwtService.GenerateToken(user.ID, user.Email)
-		if err != nil {
-			errors.Handle(c, errors.Internal("failed to generate refresh token", err))
+		var membership *models.Membership
+		if current.TenantID != "" {
+			membership, err = membershipRepo.FindByUserAndTenant(user.ID, current.TenantID)
+			if err != nil {
+				errors.Handle(c, errors.Database("failed to verify tenant access", err))
+				return
+			}
+			if membership == nil {
+				errors.Handle(c, errors.Unauthorized("no longer a member of the token's tenant"))
+				return
+			}
+		}
+
+		tokens, err := issuer.issue(c, user.ID, user.Email, membership, current)
+		switch {
+		case err == models.ErrRefreshTokenReused:
+			// Another request rotated the token first.
+			if err := issuer.revoke(current.FamilyID); err != nil {
+				errors.Handle(c, errors.Database("failed to revoke session", err))
+				return
+			}
+			errors.Handle(c, errors.Unauthorized("refresh token reuse detected; log in again"))
+			return
+		case err != nil:
+			errors.Handle(c, errors.Internal("failed to issue tokens", err))
 			return
 		}
 
-		c.JSON(http.StatusOK, TokenResponse{
-			AccessToken:  accessToken,
-			RefreshToken: refreshToken,
-			TokenType:    "Bearer",
-			ExpiresIn:    accessTTL,
-			User: UserInfo{
-				ID:    user.ID,
-				Email: user.Email,
-			},
-		})
+		c.JSON(http.StatusOK, tokens)
 	}
 }
 
-func RefreshToken(jwtService *security.JWTService, accessTTL int64) gin.HandlerFunc {
+// Logout handles POST /auth/logout: the refresh token's session is revoked,
+// so neither its refresh tokens nor its access tokens work again. Unknown
+// tokens are ignored.
+func Logout(issuer *TokenIssuer) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		type RefreshRequest struct {
-			RefreshToken string `json:"refresh_token" binding:"required"`
-		}
-
 		var req RefreshRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
 			errors.Handle(c, errors.Validation(err.Error()))
 			return
 		}
 
-		newAccessToken, err := jwtService.RefreshToken(req.RefreshToken)
+		token, err := issuer.refreshTokens.FindByTokenHash(security.HashToken(req.RefreshToken))
 		if err != nil {
-			errors.Handle(c, errors.Unauthorized("invalid refresh token"))
+			errors.Handle(c, errors.Database("failed to find refresh token", err))
 			return
 		}
+		if token != nil {
+			if err := issuer.revoke(token.FamilyID); err != nil {
+				errors.Handle(c, errors.Database("failed to revoke session", err))
+				return
+			}
+		}
 
-		claims, _ := jwtService.ValidateToken(newAccessToken)
-
-		c.JSON(http.StatusOK, TokenResponse{
-			AccessToken:  newAccessToken,
-			RefreshToken: req.RefreshToken,
-			TokenType:    "Bearer",
-			ExpiresIn:    accessTTL,
-			User: UserInfo{
-				ID:    claims.UserID,
-				Email: claims.Email,
-			},
-		})
+		c.Status(http.StatusNoContent)
 	}
 }
 
@@ -0,0 +1,160 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"mini-search-platform/internal/adapters"
+	"mini-search-platform/internal/handlers"
+	"mini-search-platform/internal/middleware"
+	"mini-search-platform/internal/models"
+	"mini-search-platform/pkg/security"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSwitchTenant_IssuesTokensBoundToAMembersTenant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newProjectsDB(t)
+	users := adapters.NewSQLiteUserRepository(db)
+	memberships := adapters.NewSQLiteMembershipRepository(db)
+	invitations := adapters.NewSQLiteInvitationRepository(db)
+	jwtSvc := security.NewJWTService("test-secret", "test", time.Hour)
+	sessions := adapters.NewSQLiteSessionRepository(db)
+	revocations := security.NewRevocationList(sessions, time.Minute)
+	issuer := handlers.NewTokenIssuer(jwtSvc, adapters.NewSQLiteRefreshTokenRepository(db), sessions, revocations, time.Hour, 24*time.Hour)
+
+	hash, err := security.HashPassword("password123")
+	if err != nil {
+		t.Fatalf("HashPassword failed: %v", err)
+	}
+	if err := users.Save(models.NewUser("ada", "ada@example.com", hash)); err != nil {
+		t.Fatalf("save user: %v", err)
+	}
+	if err := memberships.Save(models.NewMembership("m-acme", "ada", "acme", models.RoleAdmin)); err != nil {
+		t.Fatalf("save membership: %v", err)
+	}
+
+	r := gin.New()
+	auth := middleware.NewAuthMiddleware(jwtSvc, users, memberships, sessions, revocations)
+	r.POST("/auth/login", handlers.Login(users, memberships, invitations, issuer))
+	r.POST("/auth/switch-tenant", auth.RequireAuth(), handlers.SwitchTenant(memberships, issuer))
+
+	post := func(path, token string, body interface{}) (*httptest.ResponseRecorder, handlers.TokenResponse) {
+		payload, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(payload))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var tokens handlers.TokenResponse
+		json.Unmarshal(w.Body.Bytes(), &tokens)
+		return w, tokens
+	}
+
+	// A user of a single tenant is logged into it.
+	credentials := map[string]string{"email": "ada@example.com", "password": "password123"}
+	w, tokens := post("/auth/login", "", credentials)
+	if w.Code != http.StatusOK || tokens.TenantID != "acme" || tokens.Role != models.RoleAdmin {
+		t.Fatalf("expected tokens bound to acme, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if w, _ := post("/auth/switch-tenant", tokens.AccessToken, map[string]string{"tenant_id": "globex"}); w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 switching to a tenant the user is not in, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if err := memberships.Save(models.NewMembership("m-globex", "ada", "globex", models.RoleBilling)); err != nil {
+		t.Fatalf("save membership: %v", err)
+	}
+	w, switched := post("/auth/switch-tenant", tokens.AccessToken, map[string]string{"tenant_id": "globex"})
+	if w.Code != http.StatusOK || switched.TenantID != "globex" || switched.Role != models.RoleBilling {
+		t.Fatalf("expected tokens bound to globex, got %d: %s", w.Code, w.Body.String())
+	}
+	claims, err := jwtSvc.ValidateToken(switched.AccessToken)
+	if err != nil || claims.TenantID != "globex" || claims.Role != models.RoleBilling {
+		t.Errorf("expected the access token to embed globex and billing, got %+v, %v", claims, err)
+	}
+
+	// With two tenants, login binds to none unless asked.
+	if _, tokens := post("/auth/login", "", credentials); tokens.TenantID != "" {
+		t.Errorf("expected unbound tokens for a user of two tenants, got %q", tokens.TenantID)
+	}
+	credentials["tenant_id"] = "globex"
+	if _, tokens := post("/auth/login", "", credentials); tokens.TenantID != "globex" {
+		t.Errorf("expected tokens bound to the requested tenant, got %q", tokens.TenantID)
+	}
+}
+
+func TestRefreshToken_RotatesAndRevokesTheFamilyOnReuse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newProjectsDB(t)
+	users := adapters.NewSQLiteUserRepository(db)
+	memberships := adapters.NewSQLiteMembershipRepository(db)
+	invitations := adapters.NewSQLiteInvitationRepository(db)
+	jwtSvc := security.NewJWTService("test-secret", "test", time.Hour)
+	sessions := adapters.NewSQLiteSessionRepository(db)
+	revocations := security.NewRevocationList(sessions, time.Minute)
+	issuer := handlers.NewTokenIssuer(jwtSvc, adapters.NewSQLiteRefreshTokenRepository(db), sessions, revocations, time.Hour, 24*time.Hour)
+
+	hash, err := security.HashPassword("password123")
+	if err != nil {
+		t.Fatalf("HashPassword failed: %v", err)
+	}
+	if err := users.Save(models.NewUser("ada", "ada@example.com", hash)); err != nil {
+		t.Fatalf("save user: %v", err)
+	}
+	if err := memberships.Save(models.NewMembership("m-acme", "ada", "acme", models.RoleAdmin)); err != nil {
+		t.Fatalf("save membership: %v", err)
+	}
+
+	r := gin.New()
+	r.POST("/auth/login", handlers.Login(users, memberships, invitations, issuer))
+	r.POST("/auth/refresh", handlers.RefreshToken(users, memberships, issuer))
+	r.POST("/auth/logout", handlers.Logout(issuer))
+
+	post := func(path string, body interface{}) (*httptest.ResponseRecorder, handlers.TokenResponse) {
+		payload, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, bytes.NewReader(payload)))
+		var tokens handlers.TokenResponse
+		json.Unmarshal(w.Body.Bytes(), &tokens)
+		return w, tokens
+	}
+	refresh := func(token string) (*httptest.ResponseRecorder, handlers.TokenResponse) {
+		return post("/auth/refresh", map[string]string{"refresh_token": token})
+	}
+
+	_, login := post("/auth/login", map[string]string{"email": "ada@example.com", "password": "password123"})
+	if login.RefreshToken == "" || login.RefreshToken == login.AccessToken {
+		t.Fatalf("expected an opaque refresh token distinct from the access token, got %+v", login)
+	}
+	if _, err := jwtSvc.ValidateToken(login.RefreshToken); err == nil {
+		t.Error("expected the refresh token not to be usable as an access token")
+	}
+
+	w, rotated := refresh(login.RefreshToken)
+	if w.Code != http.StatusOK || rotated.RefreshToken == login.RefreshToken || rotated.TenantID != "acme" {
+		t.Fatalf("expected a rotated refresh token for acme, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Replaying the first token revokes its whole family, rotated one too.
+	if w, _ := refresh(login.RefreshToken); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 reusing a rotated token, got %d: %s", w.Code, w.Body.String())
+	}
+	if w, _ := refresh(rotated.RefreshToken); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected the family revoked after a reuse, got %d: %s", w.Code, w.Body.String())
+	}
+
+	_, second := post("/auth/login", map[string]string{"email": "ada@example.com", "password": "password123"})
+	if w, _ := post("/auth/logout", map[string]string{"refresh_token": second.RefreshToken}); w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204 logging out, got %d: %s", w.Code, w.Body.String())
+	}
+	if w, _ := refresh(second.RefreshToken); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 refreshing after logout, got %d: %s", w.Code, w.Body.String())
+	}
+}
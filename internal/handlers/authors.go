@@ -1,16 +1,18 @@
 package handlers
 
 import (
+	"database/sql"
+	"fmt"
+	"strconv"
+
 	"mini-search-platform/internal/models"
+	"mini-search-platform/internal/search"
 	"mini-search-platform/pkg/errors"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mcuadros/go-defaults"
 )
 
-type AuthorsRepository interface {
-	Save(*models.Author) (int, error)
-}
-
 type AuthorInput struct {
 	Name     string `json:"name" binding:"required"`
 	AuthorID int    `json:"author_id"`
@@ -27,7 +29,7 @@ type AddAuthorsResponse struct {
 	Failed   []map[string]models.Author `json:"failed"`
 }
 
-func AddAuthors(repository AuthorsRepository) gin.HandlerFunc {
+func AddAuthors(repository models.AuthorsRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var inputs []AuthorInput
 		if err := c.ShouldBindJSON(&inputs); err != nil {
@@ -63,7 +65,7 @@ func AddAuthors(repository AuthorsRepository) gin.HandlerFunc {
 	}
 }
 
-func AddAuthor(repository AuthorsRepository) gin.HandlerFunc {
+func AddAuthor(repository models.AuthorsRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var input AuthorInput
 		if err := c.ShouldBindJSON(&input); err != nil {
@@ -84,3 +86,151 @@ func AddAuthor(repository AuthorsRepository) gin.HandlerFunc {
 		c.JSON(201, author)
 	}
 }
+
+// findAuthor loads the :id author, handling the error if it cannot.
+func findAuthor(c *gin.Context, repository models.AuthorsRepository) (*models.Author, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 0 {
+		errors.Handle(c, errors.Validation("author id must be a non-negative integer"))
+		return nil, false
+	}
+
+	author, err := repository.FindAuthorById(id)
+	if err == sql.ErrNoRows {
+		errors.Handle(c, errors.NotFound(fmt.Sprintf("author %d", id)))
+		return nil, false
+	}
+	if err != nil {
+		errors.Handle(c, errors.Database("failed to fetch author", err))
+		return nil, false
+	}
+	return author, true
+}
+
+// ListAuthorsParams is the GET /authors query. Name matches authors whose
+// name contains it, ignoring case.
+type ListAuthorsParams struct {
+	Name   string `form:"name"`
+	Limit  int    `form:"limit" default:"20" binding:"min=1,max=100"`
+	Offset int    `form:"offset" default:"0" binding:"min=0"`
+}
+
+type ListAuthorsResponse struct {
+	Authors []*models.Author `json:"authors"`
+	Total   int              `json:"total"`
+	Limit   int              `json:"limit"`
+	Offset  int              `json:"offset"`
+}
+
+func ListAuthors(repository models.AuthorsRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var params ListAuthorsParams
+		defaults.SetDefaults(&params)
+
+		if err := c.ShouldBindQuery(&params); err != nil {
+			errors.Handle(c, errors.Validation(err.Error()))
+			return
+		}
+
+		authors, total, err := repository.List(models.AuthorFilter{
+			Name:   params.Name,
+			Limit:  params.Limit,
+			Offset: params.Offset,
+		})
+		if err != nil {
+			errors.Handle(c, errors.Database("failed to list authors", err))
+			return
+		}
+
+		c.JSON(200, ListAuthorsResponse{
+			Authors: authors,
+			Total:   total,
+			Limit:   params.Limit,
+			Offset:  params.Offset,
+		})
+	}
+}
+
+func GetAuthor(repository models.AuthorsRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		author, ok := findAuthor(c, repository)
+		if !ok {
+			return
+		}
+
+		c.JSON(200, author)
+	}
+}
+
+// ListAuthorArticles handles GET /authors/:id/articles, which takes the
+// GET /articles query, author aside.
+func ListAuthorArticles(repository models.AuthorsRepository, articles models.ArticleRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		author, ok := findAuthor(c, repository)
+		if !ok {
+			return
+		}
+
+		var params ListArticlesParams
+		defaults.SetDefaults(&params)
+
+		if err := c.ShouldBindQuery(&params); err != nil {
+			errors.Handle(c, errors.Validation(err.Error()))
+			return
+		}
+		params.AuthorID = author.ID
+
+		listArticles(c, articles, params)
+	}
+}
+
+type UpdateAuthorInput struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// UpdateAuthor handles PATCH /authors/:id. A rename re-indexes the
+// author's articles, whose documents carry the name.
+func UpdateAuthor(repository models.AuthorsRepository, sync *search.IndexSyncManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		author, ok := findAuthor(c, repository)
+		if !ok {
+			return
+		}
+
+		var input UpdateAuthorInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			errors.Handle(c, errors.Validation(err.Error()))
+			return
+		}
+
+		if input.Name == author.Name {
+			c.JSON(200, author)
+			return
+		}
+
+		existing, err := repository.FindAuthorByName(input.Name)
+		if err != nil {
+			errors.Handle(c, errors.Database("failed to check existing author", err))
+			return
+		}
+		if existing != nil {
+			errors.Handle(c, errors.Conflict(fmt.Sprintf("author name '%s' already taken", input.Name)))
+			return
+		}
+
+		author.Name = input.Name
+		if err := repository.Update(author); err != nil {
+			if err == sql.ErrNoRows {
+				errors.Handle(c, errors.NotFound(fmt.Sprintf("author %d", author.ID)))
+				return
+			}
+			errors.Handle(c, errors.Database("failed to update author", err))
+			return
+		}
+
+		// Update queued an index sync intent for the author's articles.
+		sync.Notify()
+
+		c.JSON(200, author)
+	}
+}
@@ -29,10 +29,15 @@ func SetupSwagger(r *gin.Engine) {
 			"spec":    "/swagger.yaml",
 			"endpoints": gin.H{
 				"auth": gin.H{
-					"register": "POST /auth/register",
-					"login":    "POST /auth/login",
-					"refresh":  "POST /auth/refresh",
-					"me":       "GET /api/me",
+					"register":          "POST /auth/register",
+					"login":             "POST /auth/login",
+					"refresh":           "POST /auth/refresh",
+					"logout":            "POST /auth/logout",
+					"switchTenant":      "POST /auth/switch-tenant",
+					"sessions":          "GET /auth/sessions",
+					"revokeSession":     "DELETE /auth/sessions/:id",
+					"revokeAllSessions": "DELETE /auth/sessions",
+					"me":                "GET /api/me",
 				},
 				"articles": gin.H{
 					"create":      "POST /articles",
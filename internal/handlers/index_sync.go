@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"mini-search-platform/internal/models"
+	"mini-search-platform/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mcuadros/go-defaults"
+)
+
+// IndexSyncStatusParams is the GET /index-sync query: how many of the most
+// recent dead letters to list.
+type IndexSyncStatusParams struct {
+	Limit int `form:"limit" default:"50" binding:"min=1,max=200"`
+}
+
+type IndexSyncStatusResponse struct {
+	models.IndexSyncStats
+	RecentDeadLetters []*models.IndexSyncDeadLetter `json:"recent_dead_letters"`
+}
+
+// GetIndexSyncStatus handles GET /index-sync, reporting the articles index
+// sync outbox: what is still pending or retrying, and what was given up.
+func GetIndexSyncStatus(outbox models.IndexSyncOutboxRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var params IndexSyncStatusParams
+		defaults.SetDefaults(&params)
+
+		if err := c.ShouldBindQuery(&params); err != nil {
+			errors.Handle(c, errors.Validation(err.Error()))
+			return
+		}
+
+		stats, err := outbox.Stats()
+		if err != nil {
+			errors.Handle(c, errors.Database("failed to read index sync outbox", err))
+			return
+		}
+
+		deadLetters, err := outbox.ListDeadLetters(params.Limit)
+		if err != nil {
+			errors.Handle(c, errors.Database("failed to list index sync dead letters", err))
+			return
+		}
+
+		c.JSON(200, IndexSyncStatusResponse{IndexSyncStats: stats, RecentDeadLetters: deadLetters})
+	}
+}
@@ -0,0 +1,78 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"mini-search-platform/internal/adapters"
+	"mini-search-platform/internal/handlers"
+	"mini-search-platform/internal/middleware"
+	"mini-search-platform/internal/models"
+	"mini-search-platform/pkg/security"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetIndexSyncStatus_IsForOperatorsOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newProjectsDB(t)
+	users := adapters.NewSQLiteUserRepository(db)
+	memberships := adapters.NewSQLiteMembershipRepository(db)
+	sessions := adapters.NewSQLiteSessionRepository(db)
+	revocations := security.NewRevocationList(sessions, time.Minute)
+	jwtSvc := security.NewJWTService("test-secret", "test", time.Hour)
+	issuer := handlers.NewTokenIssuer(jwtSvc, adapters.NewSQLiteRefreshTokenRepository(db), sessions, revocations, time.Hour, 24*time.Hour)
+
+	hash, err := security.HashPassword("password123")
+	if err != nil {
+		t.Fatalf("HashPassword failed: %v", err)
+	}
+	for _, user := range []*models.User{
+		models.NewUser("ada", "ada@example.com", hash),
+		models.NewUser("bob", "bob@example.com", hash),
+	} {
+		if err := users.Save(user); err != nil {
+			t.Fatalf("save user: %v", err)
+		}
+	}
+
+	r := gin.New()
+	auth := middleware.NewAuthMiddleware(jwtSvc, users, memberships, sessions, revocations)
+	r.POST("/auth/login", handlers.Login(users, memberships, adapters.NewSQLiteInvitationRepository(db), issuer))
+	r.GET("/index-sync", auth.RequireAuth(), middleware.RequireOperator([]string{"ada@example.com"}),
+		handlers.GetIndexSyncStatus(adapters.NewSQLiteIndexSyncOutboxRepository(db)))
+
+	get := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/index-sync", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+	login := func(email string) string {
+		payload, _ := json.Marshal(map[string]string{"email": email, "password": "password123"})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader(payload)))
+		var tokens handlers.TokenResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &tokens); err != nil || w.Code != http.StatusOK {
+			t.Fatalf("login %s: %d: %s", email, w.Code, w.Body.String())
+		}
+		return tokens.AccessToken
+	}
+
+	if code := get(""); code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without a token, got %d", code)
+	}
+	if code := get(login("bob@example.com")); code != http.StatusForbidden {
+		t.Errorf("expected 403 for a user who is not an operator, got %d", code)
+	}
+	if code := get(login("ada@example.com")); code != http.StatusOK {
+		t.Errorf("expected 200 for an operator, got %d", code)
+	}
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"mini-search-platform/internal/models"
+	"mini-search-platform/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultAnalyticsLimit = 10
+	maxAnalyticsLimit     = 100
+	// Windows up to this long are trended by hour, longer ones by day.
+	hourlyTrendWindow = 48 * time.Hour
+)
+
+// SearchAnalyticsReader is implemented by analytics.Recorder.
+type SearchAnalyticsReader interface {
+	Report(tenantID string, from, to time.Time, bucket time.Duration, limit int) (*models.SearchAnalyticsReport, error)
+}
+
+// SearchAnalyticsResponse is the GET /internal/analytics/searches body.
+type SearchAnalyticsResponse struct {
+	TenantID string    `json:"tenant_id"`
+	Window   string    `json:"window"`
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Bucket   string    `json:"bucket"`
+	*models.SearchAnalyticsReport
+}
+
+// InternalSearchAnalytics handles GET /internal/analytics/searches?window=7d,
+// reporting the X-Tenant-ID tenant's top, zero-result and no-click queries
+// and its search volume trend over the window.
+func InternalSearchAnalytics(reader SearchAnalyticsReader) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID, ok := requireTenantID(c)
+		if !ok {
+			return
+		}
+
+		window, err := parseUsageWindow(c.Query("window"))
+		if err != nil {
+			errors.Handle(c, errors.Validation(err.Error()))
+			return
+		}
+
+		limit := defaultAnalyticsLimit
+		if raw := c.Query("limit"); raw != "" {
+			limit, err = strconv.Atoi(raw)
+			if err != nil || limit <= 0 || limit > maxAnalyticsLimit {
+				errors.Handle(c, errors.Validation("limit must be between 1 and 100"))
+				return
+			}
+		}
+
+		bucket, bucketName := time.Hour, "hour"
+		if window > hourlyTrendWindow {
+			bucket, bucketName = 24*time.Hour, "day"
+		}
+
+		to := time.Now().UTC()
+		from := to.Add(-window)
+
+		report, err := reader.Report(tenantID, from, to, bucket, limit)
+		if err != nil {
+			errors.Handle(c, errors.Database("failed to fetch search analytics", err))
+			return
+		}
+
+		c.JSON(200, SearchAnalyticsResponse{
+			TenantID:              tenantID,
+			Window:                window.String(),
+			From:                  from,
+			To:                    to,
+			Bucket:                bucketName,
+			SearchAnalyticsReport: report,
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"mini-search-platform/internal/analytics"
+	"mini-search-platform/internal/models"
+	"mini-search-platform/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const maxEventsPerBatch = 500
+
+// maxEventAge bounds how far back a reported occurred_at may be, so a replayed
+// backlog cannot rewrite popularity history.
+const maxEventAge = 7 * 24 * time.Hour
+
+// SearchEventInput is one shopper interaction reported by the control plane.
+// SearchID is the `search_id` of the /internal/search response the shopper
+// came from, when they came from search.
+type SearchEventInput struct {
+	Type       models.SearchEventType `json:"type" binding:"required"`
+	DocumentID string                 `json:"document_id" binding:"required"`
+	SearchID   string                 `json:"search_id"`
+	Query      string                 `json:"query"`
+	SessionID  string                 `json:"session_id"`
+	OccurredAt *time.Time             `json:"occurred_at"`
+}
+
+type SearchEventsInput struct {
+	Events []SearchEventInput `json:"events" binding:"required"`
+}
+
+// InternalRecordEvents handles POST /internal/events, storing click,
+// add-to-cart and purchase events for the X-Tenant-ID tenant. Events feed
+// the no-click search analytics and the popularity score.
+func InternalRecordEvents(events models.SearchEventRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID, ok := requireTenantID(c)
+		if !ok {
+			return
+		}
+
+		var input SearchEventsInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			errors.Handle(c, errors.Validation(err.Error()))
+			return
+		}
+		if len(input.Events) == 0 || len(input.Events) > maxEventsPerBatch {
+			errors.Handle(c, errors.Validation(fmt.Sprintf("events must contain between 1 and %d entries", maxEventsPerBatch)))
+			return
+		}
+
+		now := time.Now().UTC()
+		batch := make([]*models.SearchEvent, 0, len(input.Events))
+		for i, event := range input.Events {
+			if !event.Type.Valid() {
+				errors.Handle(c, errors.Validation(fmt.Sprintf("events[%d]: type must be click, add_to_cart or purchase", i)))
+				return
+			}
+			documentID := strings.TrimSpace(event.DocumentID)
+			if documentID == "" {
+				errors.Handle(c, errors.Validation(fmt.Sprintf("events[%d]: document_id is required", i)))
+				return
+			}
+
+			occurredAt := now
+			if event.OccurredAt != nil {
+				occurredAt = event.OccurredAt.UTC()
+				if occurredAt.After(now) || now.Sub(occurredAt) > maxEventAge {
+					errors.Handle(c, errors.Validation(fmt.Sprintf("events[%d]: occurred_at must be within the last 7 days", i)))
+					return
+				}
+			}
+
+			batch = append(batch, &models.SearchEvent{
+				ID:         uuid.NewString(),
+				TenantID:   tenantID,
+				Type:       event.Type,
+				DocumentID: documentID,
+				SearchID:   strings.TrimSpace(event.SearchID),
+				Query:      analytics.NormalizeQuery(event.Query),
+				SessionID:  analytics.AnonymizeSession(tenantID, strings.TrimSpace(event.SessionID)),
+				CreatedAt:  occurredAt,
+			})
+		}
+
+		if err := events.SaveBatch(batch); err != nil {
+			errors.Handle(c, errors.Database("failed to record events", err))
+			return
+		}
+
+		c.JSON(202, gin.H{"accepted": len(batch)})
+	}
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"mini-search-platform/internal/models"
+	"mini-search-platform/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	maxRulePins = 20
+	maxRuleHide = 100
+)
+
+// SearchCacheInvalidator is implemented by search.CachingEngine. Rule
+// changes must drop the tenant's cached searches, which were shaped by the
+// old rules.
+type SearchCacheInvalidator interface {
+	InvalidateTenant(tenantID string)
+}
+
+// QueryRuleInput is the body of POST and PUT /internal/rules. Enabled
+// defaults to true.
+type QueryRuleInput struct {
+	Description  string                       `json:"description"`
+	Enabled      *bool                        `json:"enabled"`
+	Conditions   models.QueryRuleConditions   `json:"conditions"`
+	Consequences models.QueryRuleConsequences `json:"consequences"`
+}
+
+func (input *QueryRuleInput) validate() error {
+	cond := input.Conditions
+	if !cond.Match.Valid() {
+		return fmt.Errorf("conditions.match must be exact or contains")
+	}
+	if strings.TrimSpace(cond.Query) == "" {
+		return fmt.Errorf("conditions.query is required")
+	}
+	if cond.StartsAt != nil && cond.EndsAt != nil && !cond.EndsAt.After(*cond.StartsAt) {
+		return fmt.Errorf("conditions.ends_at must be after starts_at")
+	}
+
+	c := input.Consequences
+	if len(c.Pins) == 0 && len(c.Hide) == 0 && c.Filter == "" && c.Boost == "" && c.Bury == "" {
+		return fmt.Errorf("consequences must pin, hide, filter, boost or bury something")
+	}
+	if len(c.Pins) > maxRulePins {
+		return fmt.Errorf("consequences.pins may hold at most %d documents", maxRulePins)
+	}
+	if len(c.Hide) > maxRuleHide {
+		return fmt.Errorf("consequences.hide may hold at most %d documents", maxRuleHide)
+	}
+
+	positions := make(map[int]bool)
+	for i, pin := range c.Pins {
+		if strings.TrimSpace(pin.DocumentID) == "" {
+			return fmt.Errorf("consequences.pins[%d].document_id is required", i)
+		}
+		if pin.Position < 1 {
+			return fmt.Errorf("consequences.pins[%d].position must be 1 or greater", i)
+		}
+		if positions[pin.Position] {
+			return fmt.Errorf("consequences.pins: position %d is pinned twice", pin.Position)
+		}
+		positions[pin.Position] = true
+	}
+	for i, id := range c.Hide {
+		if strings.TrimSpace(id) == "" {
+			return fmt.Errorf("consequences.hide[%d] is empty", i)
+		}
+	}
+	return nil
+}
+
+// apply copies the input onto rule, leaving ID, tenant and timestamps alone.
+func (input *QueryRuleInput) apply(rule *models.QueryRule) {
+	rule.Description = strings.TrimSpace(input.Description)
+	rule.Enabled = input.Enabled == nil || *input.Enabled
+	rule.Conditions = input.Conditions
+	rule.Conditions.Query = strings.TrimSpace(rule.Conditions.Query)
+	rule.Consequences = input.Consequences
+}
+
+func bindQueryRule(c *gin.Context) (*QueryRuleInput, bool) {
+	var input QueryRuleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		errors.Handle(c, errors.Validation(err.Error()))
+		return nil, false
+	}
+	if err := input.validate(); err != nil {
+		errors.Handle(c, errors.Validation(err.Error()))
+		return nil, false
+	}
+	return &input, true
+}
+
+// InternalListQueryRules handles GET /internal/rules.
+func InternalListQueryRules(rules models.QueryRuleRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID, ok := requireTenantID(c)
+		if !ok {
+			return
+		}
+
+		list, err := rules.ListByTenant(tenantID)
+		if err != nil {
+			errors.Handle(c, errors.Database("failed to list query rules", err))
+			return
+		}
+
+		c.JSON(200, gin.H{"rules": list})
+	}
+}
+
+// InternalCreateQueryRule handles POST /internal/rules.
+func InternalCreateQueryRule(rules models.QueryRuleRepository, cache SearchCacheInvalidator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID, ok := requireTenantID(c)
+		if !ok {
+			return
+		}
+
+		input, ok := bindQueryRule(c)
+		if !ok {
+			return
+		}
+
+		now := time.Now().UTC()
+		rule := &models.QueryRule{
+			ID:        uuid.NewString(),
+			TenantID:  tenantID,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		input.apply(rule)
+
+		if err := rules.Save(rule); err != nil {
+			errors.Handle(c, errors.Database("failed to save query rule", err))
+			return
+		}
+		cache.InvalidateTenant(tenantID)
+
+		c.JSON(201, rule)
+	}
+}
+
+// InternalUpdateQueryRule handles PUT /internal/rules/:id, replacing the
+// rule's description, conditions and consequences.
+func InternalUpdateQueryRule(rules models.QueryRuleRepository, cache SearchCacheInvalidator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID, ok := requireTenantID(c)
+		if !ok {
+			return
+		}
+
+		rule, err := rules.FindByID(tenantID, c.Param("id"))
+		if err != nil {
+			errors.Handle(c, errors.Database("failed to fetch query rule", err))
+			return
+		}
+		if rule == nil {
+			errors.Handle(c, errors.NotFound("query rule"))
+			return
+		}
+
+		input, ok := bindQueryRule(c)
+		if !ok {
+			return
+		}
+		input.apply(rule)
+		rule.UpdatedAt = time.Now().UTC()
+
+		if err := rules.Update(rule); err != nil {
+			errors.Handle(c, errors.Database("failed to update query rule", err))
+			return
+		}
+		cache.InvalidateTenant(tenantID)
+
+		c.JSON(200, rule)
+	}
+}
+
+// InternalDeleteQueryRule handles DELETE /internal/rules/:id.
+func InternalDeleteQueryRule(rules models.QueryRuleRepository, cache SearchCacheInvalidator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID, ok := requireTenantID(c)
+		if !ok {
+			return
+		}
+
+		rule, err := rules.FindByID(tenantID, c.Param("id"))
+		if err != nil {
+			errors.Handle(c, errors.Database("failed to fetch query rule", err))
+			return
+		}
+		if rule == nil {
+			errors.Handle(c, errors.NotFound("query rule"))
+			return
+		}
+
+		if err := rules.Delete(tenantID, rule.ID); err != nil {
+			errors.Handle(c, errors.Database("failed to delete query rule", err))
+			return
+		}
+		cache.InvalidateTenant(tenantID)
+
+		c.Status(204)
+	}
+}
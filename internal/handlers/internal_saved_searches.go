@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"mini-search-platform/internal/models"
+	"mini-search-platform/internal/webhooks"
+	"mini-search-platform/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Every saved search costs one engine search per indexing batch.
+const maxSavedSearchesPerTenant = 100
+
+// SavedSearchInput is the body of POST and PUT /internal/saved-searches.
+type SavedSearchInput struct {
+	Name       string   `json:"name"`
+	Query      string   `json:"query"`
+	Filter     string   `json:"filter"`
+	Sort       []string `json:"sort"`
+	WebhookURL string   `json:"webhook_url"`
+}
+
+// SavedSearchCreatedResponse is returned by POST /internal/saved-searches;
+// it is the only response carrying the webhook signing secret.
+type SavedSearchCreatedResponse struct {
+	*models.SavedSearch
+	Secret string `json:"secret"`
+}
+
+func (input *SavedSearchInput) validate(urls WebhookURLValidator) error {
+	if strings.TrimSpace(input.Query) == "" && strings.TrimSpace(input.Filter) == "" {
+		return fmt.Errorf("query or filter is required")
+	}
+	for i, s := range input.Sort {
+		if strings.TrimSpace(s) == "" {
+			return fmt.Errorf("sort[%d] is empty", i)
+		}
+	}
+	return validateWebhookURL(urls, "webhook_url", input.WebhookURL)
+}
+
+// apply copies the input onto savedSearch, leaving ID, tenant, secret and
+// timestamps alone.
+func (input *SavedSearchInput) apply(savedSearch *models.SavedSearch) {
+	savedSearch.Name = strings.TrimSpace(input.Name)
+	savedSearch.Query = strings.TrimSpace(input.Query)
+	savedSearch.Filter = strings.TrimSpace(input.Filter)
+	savedSearch.Sort = input.Sort
+	savedSearch.WebhookURL = strings.TrimSpace(input.WebhookURL)
+}
+
+func bindSavedSearch(c *gin.Context, urls WebhookURLValidator) (*SavedSearchInput, bool) {
+	var input SavedSearchInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		errors.Handle(c, errors.Validation(err.Error()))
+		return nil, false
+	}
+	if err := input.validate(urls); err != nil {
+		errors.Handle(c, errors.Validation(err.Error()))
+		return nil, false
+	}
+	return &input, true
+}
+
+func findSavedSearch(c *gin.Context, searches models.SavedSearchRepository, tenantID string) (*models.SavedSearch, bool) {
+	savedSearch, err := searches.FindByID(tenantID, c.Param("id"))
+	if err != nil {
+		errors.Handle(c, errors.Database("failed to fetch saved search", err))
+		return nil, false
+	}
+	if savedSearch == nil {
+		errors.Handle(c, errors.NotFound("saved search"))
+		return nil, false
+	}
+	return savedSearch, true
+}
+
+// InternalListSavedSearches handles GET /internal/saved-searches.
+func InternalListSavedSearches(searches models.SavedSearchRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID, ok := requireTenantID(c)
+		if !ok {
+			return
+		}
+
+		list, err := searches.ListByTenant(tenantID)
+		if err != nil {
+			errors.Handle(c, errors.Database("failed to list saved searches", err))
+			return
+		}
+
+		c.JSON(200, gin.H{"saved_searches": list})
+	}
+}
+
+// InternalCreateSavedSearch handles POST /internal/saved-searches.
+func InternalCreateSavedSearch(searches models.SavedSearchRepository, urls WebhookURLValidator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID, ok := requireTenantID(c)
+		if !ok {
+			return
+		}
+
+		input, ok := bindSavedSearch(c, urls)
+		if !ok {
+			return
+		}
+
+		existing, err := searches.ListByTenant(tenantID)
+		if err != nil {
+			errors.Handle(c, errors.Database("failed to list saved searches", err))
+			return
+		}
+		if len(existing) >= maxSavedSearchesPerTenant {
+			errors.Handle(c, errors.Conflict(fmt.Sprintf("a tenant may have at most %d saved searches", maxSavedSearchesPerTenant)))
+			return
+		}
+
+		secret, err := webhooks.NewSecret()
+		if err != nil {
+			errors.Handle(c, errors.Internal("failed to generate webhook secret", err))
+			return
+		}
+
+		now := time.Now().UTC()
+		savedSearch := &models.SavedSearch{
+			ID:        uuid.NewString(),
+			TenantID:  tenantID,
+			Secret:    secret,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		input.apply(savedSearch)
+
+		if err := searches.Save(savedSearch); err != nil {
+			errors.Handle(c, errors.Database("failed to save saved search", err))
+			return
+		}
+
+		c.JSON(201, SavedSearchCreatedResponse{SavedSearch: savedSearch, Secret: secret})
+	}
+}
+
+// InternalUpdateSavedSearch handles PUT /internal/saved-searches/:id. The
+// signing secret and the record of already announced matches are kept.
+func InternalUpdateSavedSearch(searches models.SavedSearchRepository, urls WebhookURLValidator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID, ok := requireTenantID(c)
+		if !ok {
+			return
+		}
+
+		savedSearch, ok := findSavedSearch(c, searches, tenantID)
+		if !ok {
+			return
+		}
+
+		input, ok := bindSavedSearch(c, urls)
+		if !ok {
+			return
+		}
+		input.apply(savedSearch)
+		savedSearch.UpdatedAt = time.Now().UTC()
+
+		if err := searches.Update(savedSearch); err != nil {
+			errors.Handle(c, errors.Database("failed to update saved search", err))
+			return
+		}
+
+		c.JSON(200, savedSearch)
+	}
+}
+
+// InternalDeleteSavedSearch handles DELETE /internal/saved-searches/:id.
+func InternalDeleteSavedSearch(searches models.SavedSearchRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID, ok := requireTenantID(c)
+		if !ok {
+			return
+		}
+
+		savedSearch, ok := findSavedSearch(c, searches, tenantID)
+		if !ok {
+			return
+		}
+
+		if err := searches.Delete(tenantID, savedSearch.ID); err != nil {
+			errors.Handle(c, errors.Database("failed to delete saved search", err))
+			return
+		}
+
+		c.Status(204)
+	}
+}
+
+// InternalListSavedSearchDeliveries handles GET
+// /internal/saved-searches/:id/deliveries, the saved search's webhook
+// delivery log, newest first.
+func InternalListSavedSearchDeliveries(searches models.SavedSearchRepository, deliveries models.WebhookDeliveryRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID, ok := requireTenantID(c)
+		if !ok {
+			return
+		}
+
+		limit, ok := deliveryLimit(c)
+		if !ok {
+			return
+		}
+
+		savedSearch, ok := findSavedSearch(c, searches, tenantID)
+		if !ok {
+			return
+		}
+
+		list, err := deliveries.ListBySource(tenantID, savedSearch.ID, limit)
+		if err != nil {
+			errors.Handle(c, errors.Database("failed to list webhook deliveries", err))
+			return
+		}
+
+		c.JSON(200, gin.H{"deliveries": list})
+	}
+}
@@ -2,12 +2,16 @@ package handlers
 
 import (
 	"strings"
+	"time"
 
+	"mini-search-platform/internal/analytics"
+	"mini-search-platform/internal/models"
 	"mini-search-platform/internal/search"
 	"mini-search-platform/pkg/errors"
 	"mini-search-platform/pkg/logging"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/mcuadros/go-defaults"
 )
 
@@ -16,6 +20,16 @@ import (
 // §2 and §4).
 const TenantIDHeader = "X-Tenant-ID"
 
+// SessionIDHeader optionally carries the shopper's session identifier,
+// forwarded by the control plane for search analytics. It is hashed before
+// being stored.
+const SessionIDHeader = "X-Session-ID"
+
+// SearchRecorder is implemented by analytics.Recorder.
+type SearchRecorder interface {
+	Record(entry *models.SearchLogEntry)
+}
+
 // requireTenantID extracts and validates the X-Tenant-ID header, writing a
 // 400 response and returning ok=false when it is missing or empty.
 func requireTenantID(c *gin.Context) (tenantID string, ok bool) {
@@ -35,7 +49,9 @@ func requireTenantID(c *gin.Context) (tenantID string, ok bool) {
 // InternalSearch handles GET /internal/search?q=... — the internal,
 // tenant-scoped counterpart of the public /search endpoint. Only the
 // Fastify control plane is expected to call this route (CONTRACT.md §4).
-func InternalSearch(engine search.TenantSearchEngine) gin.HandlerFunc {
+// When recorder is non-nil every successful search is logged for analytics
+// and its log ID returned as `search_id`.
+func InternalSearch(engine search.TenantSearchEngine, recorder SearchRecorder) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tenantID, ok := requireTenantID(c)
 		if !ok {
@@ -50,6 +66,7 @@ func InternalSearch(engine search.TenantSearchEngine) gin.HandlerFunc {
 			return
 		}
 
+		started := time.Now()
 		result, err := engine.SearchTenant(tenantID, params.Query, search.SearchOptions{
 			Limit:  params.Limit,
 			Offset: params.Offset,
@@ -62,10 +79,41 @@ func InternalSearch(engine search.TenantSearchEngine) gin.HandlerFunc {
 			return
 		}
 
+		if recorder != nil {
+			result.SearchID = uuid.NewString()
+			recorder.Record(&models.SearchLogEntry{
+				ID:        result.SearchID,
+				TenantID:  tenantID,
+				Query:     analytics.NormalizeQuery(params.Query),
+				Filters:   params.Filter,
+				TotalHits: result.Total,
+				LatencyMs: time.Since(started).Milliseconds(),
+				SessionID: analytics.AnonymizeSession(tenantID, strings.TrimSpace(c.GetHeader(SessionIDHeader))),
+			})
+		}
+
 		c.JSON(200, result)
 	}
 }
 
+// SearchCacheStatsReader is implemented by search.CachingEngine.
+type SearchCacheStatsReader interface {
+	Stats(tenantID string) search.CacheStats
+}
+
+// InternalSearchCacheStats handles GET /internal/search/cache-stats,
+// reporting the X-Tenant-ID tenant's search cache hit rate and footprint.
+func InternalSearchCacheStats(cache SearchCacheStatsReader) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID, ok := requireTenantID(c)
+		if !ok {
+			return
+		}
+
+		c.JSON(200, cache.Stats(tenantID))
+	}
+}
+
 // InternalDocumentsBatchInput matches CONTRACT.md §3's
 // `POST /organizations/:slug/documents/batch` body, which is proxied
 // unchanged into the internal API.
@@ -73,9 +121,23 @@ type InternalDocumentsBatchInput struct {
 	Documents []search.TenantDocument `json:"documents" binding:"required"`
 }
 
+// LifecyclePublisher is implemented by webhooks.Publisher.
+type LifecyclePublisher interface {
+	Publish(tenantID string, event models.WebhookEvent, data interface{})
+}
+
+// TenantResyncer is implemented by search.IndexSyncManager.
+type TenantResyncer interface {
+	ResyncTenant(tenantID string) error
+}
+
 // InternalIndexDocumentsBatch handles POST /internal/documents/batch,
 // indexing documents into the caller-supplied tenant's isolated index.
-func InternalIndexDocumentsBatch(engine search.TenantSearchEngine) gin.HandlerFunc {
+// When events is non-nil a batch the engine refuses is published as
+// documents.failed; batches it accepts are reported once indexed (see
+// webhooks.Publisher.HandleTask). The tenant's articles share its index, so
+// when articles is non-nil a reset queues them to be indexed again.
+func InternalIndexDocumentsBatch(engine search.TenantSearchEngine, articles TenantResyncer, events LifecyclePublisher) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tenantID, ok := requireTenantID(c)
 		if !ok {
@@ -92,12 +154,20 @@ func InternalIndexDocumentsBatch(engine search.TenantSearchEngine) gin.HandlerFu
 		// rebuilds the catalog from scratch instead of layering onto stale docs.
 		if c.Query("reset") == "true" {
 			if err := engine.DeleteAllTenantDocuments(tenantID); err != nil {
+				publishBatchFailed(events, tenantID, len(input.Documents), err)
 				errors.Handle(c, errors.Search("failed to reset tenant documents", err))
 				return
 			}
+			if articles != nil {
+				if err := articles.ResyncTenant(tenantID); err != nil {
+					errors.Handle(c, errors.Database("failed to queue the tenant's articles for indexing", err))
+					return
+				}
+			}
 		}
 
 		if err := engine.IndexTenantDocuments(tenantID, input.Documents); err != nil {
+			publishBatchFailed(events, tenantID, len(input.Documents), err)
 			errors.Handle(c, errors.Search("failed to index tenant documents", err))
 			return
 		}
@@ -105,3 +175,13 @@ func InternalIndexDocumentsBatch(engine search.TenantSearchEngine) gin.HandlerFu
 		c.JSON(202, gin.H{"accepted": len(input.Documents)})
 	}
 }
+
+func publishBatchFailed(events LifecyclePublisher, tenantID string, received int, err error) {
+	if events == nil {
+		return
+	}
+	events.Publish(tenantID, models.EventDocumentsFailed, gin.H{
+		"received_documents": received,
+		"error":              err.Error(),
+	})
+}
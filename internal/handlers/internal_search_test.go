@@ -69,8 +69,8 @@ func newTestRouter(t *testing.T) (*gin.Engine, string) {
 	engine := adapters.Init(host, os.Getenv("MEILISEARCH_API_KEY"))
 
 	r := gin.New()
-	r.GET("/internal/search", handlers.InternalSearch(engine))
-	r.POST("/internal/documents/batch", handlers.InternalIndexDocumentsBatch(engine))
+	r.GET("/internal/search", handlers.InternalSearch(engine, nil))
+	r.POST("/internal/documents/batch", handlers.InternalIndexDocumentsBatch(engine, nil, nil))
 
 	return r, host
 }
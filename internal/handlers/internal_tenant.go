@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"time"
+
+	"mini-search-platform/internal/analytics"
+	"mini-search-platform/internal/models"
+	"mini-search-platform/internal/search"
+	"mini-search-platform/internal/usage"
+	"mini-search-platform/pkg/errors"
+	"mini-search-platform/pkg/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// InternalDeleteTenant handles DELETE /internal/tenant, offboarding the
+// tenant named by X-Tenant-ID: its isolated search index and all of its
+// SQLite data (memberships, projects, articles, search logs and so on) are
+// removed, and a deletion receipt is written as proof of erasure.
+//
+// The indexes, the tenant's and each of its projects', are dropped first. If
+// that fails no data has been deleted yet and the caller can simply retry; once it succeeds, every later step is
+// idempotent, so a retry after a partial failure converges too. The tenant
+// does not have to exist in SQLite: organizations created through the
+// control plane only ever have an index here.
+func InternalDeleteTenant(
+	tenants models.TenantRepository,
+	receipts models.TenantDeletionReceiptRepository,
+	engine search.TenantIndexDeleter,
+	projects models.ProjectRepository,
+	projectIndexes search.ProjectIndexer,
+	recorder *analytics.Recorder,
+	meter *usage.Meter,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID, ok := requireTenantID(c)
+		if !ok {
+			return
+		}
+
+		// The projects are gone after the purge, and their indexes with no
+		// way to find them.
+		list, err := projects.ListByTenant(tenantID)
+		if err != nil {
+			errors.Handle(c, errors.Database("failed to list the tenant's projects", err))
+			return
+		}
+		for _, project := range list {
+			if _, err := projectIndexes.DeleteProjectIndex(tenantID, project.ID); err != nil {
+				errors.Handle(c, errors.Search("failed to delete project index", err))
+				return
+			}
+		}
+
+		taskUID, err := engine.DeleteTenantIndex(tenantID)
+		if err != nil {
+			errors.Handle(c, errors.Search("failed to delete tenant index", err))
+			return
+		}
+
+		// Buffered search logs and usage would otherwise be written after
+		// the purge, so flushing them waits for it.
+		var receipt *models.TenantDeletionReceipt
+		err = recorder.Forget(tenantID, func() error {
+			return meter.Forget(tenantID, func() error {
+				var err error
+				receipt, err = tenants.Purge(tenantID)
+				return err
+			})
+		})
+		if err != nil {
+			errors.Handle(c, errors.Database("failed to delete tenant data", err))
+			return
+		}
+
+		receipt.ID = uuid.New().String()
+		receipt.IndexName = search.TenantIndexName(tenantID)
+		receipt.IndexTaskUID = taskUID
+		receipt.DeletedAt = time.Now().UTC()
+
+		if err := receipts.Save(receipt); err != nil {
+			errors.Handle(c, errors.Database("failed to record tenant deletion receipt", err))
+			return
+		}
+
+		logging.WithContext(c).Info("tenant offboarded",
+			"tenant_id", tenantID,
+			"receipt_id", receipt.ID,
+			"memberships_deleted", receipt.MembershipsDeleted,
+			"projects_deleted", receipt.ProjectsDeleted,
+			"project_indexes_deleted", len(list),
+			"articles_deleted", receipt.ArticlesDeleted,
+			"index_task_uid", receipt.IndexTaskUID,
+		)
+
+		c.JSON(200, receipt)
+	}
+}
+
+// InternalListTenantDeletionReceipts handles
+// GET /internal/tenant/deletion-receipts, returning every receipt recorded
+// for the X-Tenant-ID tenant, newest first.
+func InternalListTenantDeletionReceipts(receipts models.TenantDeletionReceiptRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID, ok := requireTenantID(c)
+		if !ok {
+			return
+		}
+
+		list, err := receipts.ListByTenant(tenantID)
+		if err != nil {
+			errors.Handle(c, errors.Database("failed to fetch tenant deletion receipts", err))
+			return
+		}
+		if list == nil {
+			list = []*models.TenantDeletionReceipt{}
+		}
+
+		c.JSON(200, gin.H{"receipts": list})
+	}
+}
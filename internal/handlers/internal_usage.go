@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"mini-search-platform/internal/models"
+	"mini-search-platform/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultUsageWindow = 24 * time.Hour
+	maxUsageWindow     = 90 * 24 * time.Hour
+)
+
+// UsageReader is implemented by usage.Meter.
+type UsageReader interface {
+	Rollups(tenantID string, since time.Time) ([]*models.UsageRollup, error)
+}
+
+// UsageResponse is the GET /internal/usage body: the hourly rollups within
+// the requested window plus their totals, for billing reconciliation.
+type UsageResponse struct {
+	TenantID           string                `json:"tenant_id"`
+	Window             string                `json:"window"`
+	From               time.Time             `json:"from"`
+	To                 time.Time             `json:"to"`
+	Totals             models.UsageRollup    `json:"totals"`
+	EngineLatencyAvgMs float64               `json:"engine_latency_avg_ms"`
+	Hours              []*models.UsageRollup `json:"hours"`
+}
+
+// parseUsageWindow accepts a Go duration ("6h", "90m") or a whole number of
+// days ("7d"), defaulting to 24h and capped at 90 days.
+func parseUsageWindow(raw string) (time.Duration, error) {
+	if raw == "" {
+		return defaultUsageWindow, nil
+	}
+
+	var window time.Duration
+	if days, ok := strings.CutSuffix(raw, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid window %q", raw)
+		}
+		window = time.Duration(n) * 24 * time.Hour
+	} else {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			return 0, fmt.Errorf("invalid window %q", raw)
+		}
+		window = parsed
+	}
+
+	if window <= 0 || window > maxUsageWindow {
+		return 0, fmt.Errorf("window must be positive and at most %s", maxUsageWindow)
+	}
+	return window, nil
+}
+
+// InternalUsage handles GET /internal/usage?window=24h, returning the
+// X-Tenant-ID tenant's metered usage of the internal API rolled up by hour.
+func InternalUsage(reader UsageReader) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID, ok := requireTenantID(c)
+		if !ok {
+			return
+		}
+
+		window, err := parseUsageWindow(c.Query("window"))
+		if err != nil {
+			errors.Handle(c, errors.Validation(err.Error()))
+			return
+		}
+
+		to := time.Now().UTC()
+		from := to.Add(-window)
+
+		hours, err := reader.Rollups(tenantID, from)
+		if err != nil {
+			errors.Handle(c, errors.Database("failed to fetch usage", err))
+			return
+		}
+		if hours == nil {
+			hours = []*models.UsageRollup{}
+		}
+
+		totals := models.UsageRollup{}
+		for _, hour := range hours {
+			totals.Merge(hour)
+		}
+
+		var avg float64
+		if totals.EngineCalls > 0 {
+			avg = float64(totals.EngineLatencyMs) / float64(totals.EngineCalls)
+		}
+
+		c.JSON(200, UsageResponse{
+			TenantID:           tenantID,
+			Window:             window.String(),
+			From:               from,
+			To:                 to,
+			Totals:             totals,
+			EngineLatencyAvgMs: avg,
+			Hours:              hours,
+		})
+	}
+}
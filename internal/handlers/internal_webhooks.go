@@ -0,0 +1,329 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"mini-search-platform/internal/models"
+	"mini-search-platform/internal/webhooks"
+	"mini-search-platform/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	maxWebhookSubscriptionsPerTenant = 20
+	defaultDeliveryLimit             = 50
+	maxDeliveryLimit                 = 200
+)
+
+// WebhookURLValidator is implemented by webhooks.Dispatcher.
+type WebhookURLValidator interface {
+	// ValidateURL checks that raw is a URL webhooks may be delivered to.
+	ValidateURL(raw string) error
+}
+
+// validateWebhookURL checks that raw, the value of the named field, is a
+// URL webhooks may be delivered to: an absolute http or https URL on the
+// public internet.
+func validateWebhookURL(urls WebhookURLValidator, field, raw string) error {
+	if err := urls.ValidateURL(raw); err != nil {
+		return fmt.Errorf("%s %v", field, err)
+	}
+	return nil
+}
+
+// deliveryLimit reads the optional ?limit= of the delivery log routes,
+// writing a 400 response and returning ok=false when it is out of range.
+func deliveryLimit(c *gin.Context) (limit int, ok bool) {
+	raw := c.Query("limit")
+	if raw == "" {
+		return defaultDeliveryLimit, true
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit <= 0 || limit > maxDeliveryLimit {
+		errors.Handle(c, errors.Validation(fmt.Sprintf("limit must be between 1 and %d", maxDeliveryLimit)))
+		return 0, false
+	}
+	return limit, true
+}
+
+// WebhookSubscriptionInput is the body of POST and PUT /internal/webhooks.
+// Enabled defaults to true.
+type WebhookSubscriptionInput struct {
+	URL         string                `json:"url"`
+	Events      []models.WebhookEvent `json:"events"`
+	Description string                `json:"description"`
+	Enabled     *bool                 `json:"enabled"`
+}
+
+// WebhookSubscriptionCreatedResponse is returned by POST /internal/webhooks;
+// it is the only response carrying the signing secret.
+type WebhookSubscriptionCreatedResponse struct {
+	*models.WebhookSubscription
+	Secret string `json:"secret"`
+}
+
+func (input *WebhookSubscriptionInput) validate(urls WebhookURLValidator) error {
+	if err := validateWebhookURL(urls, "url", input.URL); err != nil {
+		return err
+	}
+	if len(input.Events) == 0 {
+		return fmt.Errorf("events must list at least one event")
+	}
+	for i, event := range input.Events {
+		if !event.Valid() {
+			return fmt.Errorf("events[%d]: unknown event %q", i, event)
+		}
+	}
+	return nil
+}
+
+// apply copies the input onto subscription, leaving ID, tenant, secret and
+// timestamps alone. Repeated events are listed once.
+func (input *WebhookSubscriptionInput) apply(subscription *models.WebhookSubscription) {
+	subscription.URL = strings.TrimSpace(input.URL)
+	subscription.Description = strings.TrimSpace(input.Description)
+	subscription.Enabled = input.Enabled == nil || *input.Enabled
+
+	subscription.Events = subscription.Events[:0]
+	seen := make(map[models.WebhookEvent]bool, len(input.Events))
+	for _, event := range input.Events {
+		if !seen[event] {
+			seen[event] = true
+			subscription.Events = append(subscription.Events, event)
+		}
+	}
+}
+
+func bindWebhookSubscription(c *gin.Context, urls WebhookURLValidator) (*WebhookSubscriptionInput, bool) {
+	var input WebhookSubscriptionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		errors.Handle(c, errors.Validation(err.Error()))
+		return nil, false
+	}
+	if err := input.validate(urls); err != nil {
+		errors.Handle(c, errors.Validation(err.Error()))
+		return nil, false
+	}
+	return &input, true
+}
+
+func findWebhookSubscription(c *gin.Context, subscriptions models.WebhookSubscriptionRepository, tenantID string) (*models.WebhookSubscription, bool) {
+	subscription, err := subscriptions.FindByID(tenantID, c.Param("id"))
+	if err != nil {
+		errors.Handle(c, errors.Database("failed to fetch webhook subscription", err))
+		return nil, false
+	}
+	if subscription == nil {
+		errors.Handle(c, errors.NotFound("webhook subscription"))
+		return nil, false
+	}
+	return subscription, true
+}
+
+// InternalListWebhookSubscriptions handles GET /internal/webhooks.
+func InternalListWebhookSubscriptions(subscriptions models.WebhookSubscriptionRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID, ok := requireTenantID(c)
+		if !ok {
+			return
+		}
+
+		list, err := subscriptions.ListByTenant(tenantID)
+		if err != nil {
+			errors.Handle(c, errors.Database("failed to list webhook subscriptions", err))
+			return
+		}
+
+		c.JSON(200, gin.H{"webhooks": list})
+	}
+}
+
+// InternalCreateWebhookSubscription handles POST /internal/webhooks.
+func InternalCreateWebhookSubscription(subscriptions models.WebhookSubscriptionRepository, urls WebhookURLValidator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID, ok := requireTenantID(c)
+		if !ok {
+			return
+		}
+
+		input, ok := bindWebhookSubscription(c, urls)
+		if !ok {
+			return
+		}
+
+		existing, err := subscriptions.ListByTenant(tenantID)
+		if err != nil {
+			errors.Handle(c, errors.Database("failed to list webhook subscriptions", err))
+			return
+		}
+		if len(existing) >= maxWebhookSubscriptionsPerTenant {
+			errors.Handle(c, errors.Conflict(fmt.Sprintf("a tenant may have at most %d webhook subscriptions", maxWebhookSubscriptionsPerTenant)))
+			return
+		}
+
+		secret, err := webhooks.NewSecret()
+		if err != nil {
+			errors.Handle(c, errors.Internal("failed to generate webhook secret", err))
+			return
+		}
+
+		now := time.Now().UTC()
+		subscription := &models.WebhookSubscription{
+			ID:        uuid.NewString(),
+			TenantID:  tenantID,
+			Secret:    secret,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		input.apply(subscription)
+
+		if err := subscriptions.Save(subscription); err != nil {
+			errors.Handle(c, errors.Database("failed to save webhook subscription", err))
+			return
+		}
+
+		c.JSON(201, WebhookSubscriptionCreatedResponse{WebhookSubscription: subscription, Secret: secret})
+	}
+}
+
+// InternalUpdateWebhookSubscription handles PUT /internal/webhooks/:id. The
+// signing secret is kept.
+func InternalUpdateWebhookSubscription(subscriptions models.WebhookSubscriptionRepository, urls WebhookURLValidator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID, ok := requireTenantID(c)
+		if !ok {
+			return
+		}
+
+		subscription, ok := findWebhookSubscription(c, subscriptions, tenantID)
+		if !ok {
+			return
+		}
+
+		input, ok := bindWebhookSubscription(c, urls)
+		if !ok {
+			return
+		}
+		input.apply(subscription)
+		subscription.UpdatedAt = time.Now().UTC()
+
+		if err := subscriptions.Update(subscription); err != nil {
+			errors.Handle(c, errors.Database("failed to update webhook subscription", err))
+			return
+		}
+
+		c.JSON(200, subscription)
+	}
+}
+
+// InternalDeleteWebhookSubscription handles DELETE /internal/webhooks/:id.
+// Deliveries already queued for it are still attempted.
+func InternalDeleteWebhookSubscription(subscriptions models.WebhookSubscriptionRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID, ok := requireTenantID(c)
+		if !ok {
+			return
+		}
+
+		subscription, ok := findWebhookSubscription(c, subscriptions, tenantID)
+		if !ok {
+			return
+		}
+
+		if err := subscriptions.Delete(tenantID, subscription.ID); err != nil {
+			errors.Handle(c, errors.Database("failed to delete webhook subscription", err))
+			return
+		}
+
+		c.Status(204)
+	}
+}
+
+// InternalListWebhookSubscriptionDeliveries handles GET
+// /internal/webhooks/:id/deliveries, the subscription's delivery log,
+// newest first.
+func InternalListWebhookSubscriptionDeliveries(subscriptions models.WebhookSubscriptionRepository, deliveries models.WebhookDeliveryRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID, ok := requireTenantID(c)
+		if !ok {
+			return
+		}
+
+		limit, ok := deliveryLimit(c)
+		if !ok {
+			return
+		}
+
+		subscription, ok := findWebhookSubscription(c, subscriptions, tenantID)
+		if !ok {
+			return
+		}
+
+		list, err := deliveries.ListBySource(tenantID, subscription.ID, limit)
+		if err != nil {
+			errors.Handle(c, errors.Database("failed to list webhook deliveries", err))
+			return
+		}
+
+		c.JSON(200, gin.H{"deliveries": list})
+	}
+}
+
+// InternalListWebhookDeadLetters handles GET /internal/webhooks/dead-letters,
+// the tenant's deliveries that exhausted their attempts (lifecycle and
+// saved search webhooks alike), newest first.
+func InternalListWebhookDeadLetters(deliveries models.WebhookDeliveryRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID, ok := requireTenantID(c)
+		if !ok {
+			return
+		}
+
+		limit, ok := deliveryLimit(c)
+		if !ok {
+			return
+		}
+
+		list, err := deliveries.ListDeadLetters(tenantID, limit)
+		if err != nil {
+			errors.Handle(c, errors.Database("failed to list webhook dead letters", err))
+			return
+		}
+
+		c.JSON(200, gin.H{"dead_letters": list})
+	}
+}
+
+// WebhookRedeliverer is implemented by webhooks.Dispatcher.
+type WebhookRedeliverer interface {
+	Redeliver(tenantID, deadLetterID string) (*models.WebhookDelivery, error)
+}
+
+// InternalRedeliverWebhook handles POST
+// /internal/webhooks/dead-letters/:id/redeliver, queueing the dead letter
+// for delivery again and answering 202 with the new delivery.
+func InternalRedeliverWebhook(dispatcher WebhookRedeliverer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID, ok := requireTenantID(c)
+		if !ok {
+			return
+		}
+
+		delivery, err := dispatcher.Redeliver(tenantID, c.Param("id"))
+		if err != nil {
+			errors.Handle(c, errors.Database("failed to redeliver webhook", err))
+			return
+		}
+		if delivery == nil {
+			errors.Handle(c, errors.NotFound("webhook dead letter"))
+			return
+		}
+
+		c.JSON(202, delivery)
+	}
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"mini-search-platform/internal/models"
+	"mini-search-platform/pkg/errors"
+	"mini-search-platform/pkg/security"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// InviteMemberInput is the POST /invitations body.
+type InviteMemberInput struct {
+	Email string      `json:"email" binding:"required,email"`
+	Role  models.Role `json:"role" binding:"required"`
+}
+
+// InvitationResponse carries the invitation's token, which is only ever
+// shown here: the invitee passes it to POST /invitations/accept or as
+// invitation_token when registering or logging in.
+type InvitationResponse struct {
+	*models.Invitation
+	Token string `json:"token"`
+}
+
+type ListInvitationsResponse struct {
+	Invitations []*models.Invitation `json:"invitations"`
+	Total       int                  `json:"total"`
+}
+
+type AcceptInvitationInput struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// InviteMember handles POST /invitations: an email is invited into the
+// request's tenant with a built-in or custom role. The invitation expires
+// after ttl.
+func InviteMember(invitations models.InvitationRepository, memberships models.MembershipRepository, users models.UserRepository, roles models.CustomRoleRepository, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input InviteMemberInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			errors.Handle(c, errors.Validation(err.Error()))
+			return
+		}
+		tenantID := security.MustGetTenantID(c)
+		if !checkRole(c, roles, tenantID, input.Role) {
+			return
+		}
+		email := strings.ToLower(strings.TrimSpace(input.Email))
+
+		user, err := users.FindByEmail(email)
+		if err != nil {
+			errors.Handle(c, errors.Database("failed to check existing user", err))
+			return
+		}
+		if user != nil {
+			membership, err := memberships.FindByUserAndTenant(user.ID, tenantID)
+			if err != nil {
+				errors.Handle(c, errors.Database("failed to check existing membership", err))
+				return
+			}
+			if membership != nil {
+				errors.Handle(c, errors.Conflict(fmt.Sprintf("%s is already a member", email)))
+				return
+			}
+		}
+
+		token, err := security.NewOpaqueToken()
+		if err != nil {
+			errors.Handle(c, errors.Internal("failed to generate invitation token", err))
+			return
+		}
+		invitation := models.NewInvitation(uuid.New().String(), tenantID, email, input.Role,
+			security.HashToken(token), security.MustGetUserID(c), time.Now().Add(ttl))
+		if err := invitations.Save(invitation); err != nil {
+			errors.Handle(c, errors.Database("failed to save invitation", err))
+			return
+		}
+
+		c.JSON(201, InvitationResponse{Invitation: invitation, Token: token})
+	}
+}
+
+func ListInvitations(invitations models.InvitationRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		list, err := invitations.ListPendingByTenant(security.MustGetTenantID(c))
+		if err != nil {
+			errors.Handle(c, errors.Database("failed to list invitations", err))
+			return
+		}
+		if list == nil {
+			list = []*models.Invitation{}
+		}
+
+		c.JSON(200, ListInvitationsResponse{Invitations: list, Total: len(list)})
+	}
+}
+
+// RevokeInvitation handles DELETE /invitations/:id. Another tenant's
+// invitation is not found.
+func RevokeInvitation(invitations models.InvitationRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		invitation, err := invitations.FindByID(id)
+		if err != nil {
+			errors.Handle(c, errors.Database("failed to fetch invitation", err))
+			return
+		}
+		if invitation == nil || invitation.TenantID != security.MustGetTenantID(c) {
+			errors.Handle(c, errors.NotFound(fmt.Sprintf("invitation '%s'", id)))
+			return
+		}
+
+		if err := invitations.Delete(id); err != nil {
+			errors.Handle(c, errors.Database("failed to revoke invitation", err))
+			return
+		}
+
+		c.Status(204)
+	}
+}
+
+// AcceptInvitation handles POST /invitations/accept for a logged-in user.
+func AcceptInvitation(invitations models.InvitationRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input AcceptInvitationInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			errors.Handle(c, errors.Validation(err.Error()))
+			return
+		}
+
+		invitation, ok := findInvitation(c, invitations, input.Token, security.MustGetUserEmail(c))
+		if !ok {
+			return
+		}
+		membership, ok := acceptInvitation(c, invitations, invitation, security.MustGetUserID(c), nil)
+		if !ok {
+			return
+		}
+
+		c.JSON(201, membership)
+	}
+}
+
+// findInvitation loads the invitation token was issued for, handling the
+// error if the user signed in as email cannot accept it.
+func findInvitation(c *gin.Context, invitations models.InvitationRepository, token, email string) (*models.Invitation, bool) {
+	invitation, err := invitations.FindByTokenHash(security.HashToken(token))
+	if err != nil {
+		errors.Handle(c, errors.Database("failed to fetch invitation", err))
+		return nil, false
+	}
+
+	switch {
+	case invitation == nil:
+		errors.Handle(c, errors.NotFound("invitation"))
+	case invitation.AcceptedAt != nil:
+		errors.Handle(c, errors.Conflict(models.ErrInvitationAccepted.Error()))
+	case invitation.ExpiredAt(time.Now()):
+		errors.Handle(c, errors.Validation("invitation has expired"))
+	case !strings.EqualFold(invitation.Email, email):
+		errors.Handle(c, errors.Forbidden("invitation was sent to another email"))
+	default:
+		return invitation, true
+	}
+	return nil, false
+}
+
+// acceptInvitation makes the user a member of the invitation's tenant,
+// handling the error if it cannot. A newUser registering with the
+// invitation is saved along with the membership; it is nil for an existing
+// user.
+func acceptInvitation(c *gin.Context, invitations models.InvitationRepository, invitation *models.Invitation, userID string, newUser *models.User) (*models.Membership, bool) {
+	membership := models.NewMembership(uuid.New().String(), userID, invitation.TenantID, invitation.Role)
+
+	var err error
+	if newUser != nil {
+		err = invitations.AcceptAsNewUser(invitation.ID, newUser, membership)
+	} else {
+		err = invitations.Accept(invitation.ID, membership)
+	}
+	switch {
+	case err == models.ErrInvitationAccepted, err == models.ErrAlreadyMember:
+		errors.Handle(c, errors.Conflict(err.Error()))
+		return nil, false
+	case err != nil:
+		errors.Handle(c, errors.Database("failed to accept invitation", err))
+		return nil, false
+	}
+	return membership, true
+}
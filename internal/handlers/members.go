@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+
+	"mini-search-platform/internal/models"
+	"mini-search-platform/pkg/errors"
+	"mini-search-platform/pkg/security"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Member is a membership of the request's tenant with its user's email.
+type Member struct {
+	*models.Membership
+	Email string `json:"email"`
+}
+
+type ListMembersResponse struct {
+	Members []Member `json:"members"`
+	Total   int      `json:"total"`
+}
+
+type UpdateMemberRoleInput struct {
+	Role models.Role `json:"role" binding:"required"`
+}
+
+// findMember loads the :id membership of the request's tenant, handling
+// the error if it cannot. Another tenant's membership is not found.
+func findMember(c *gin.Context, memberships models.MembershipRepository) (*models.Membership, bool) {
+	id := c.Param("id")
+	membership, err := memberships.FindByID(id)
+	if err != nil {
+		errors.Handle(c, errors.Database("failed to fetch member", err))
+		return nil, false
+	}
+	if membership == nil || membership.TenantID != security.MustGetTenantID(c) {
+		errors.Handle(c, errors.NotFound(fmt.Sprintf("member '%s'", id)))
+		return nil, false
+	}
+	return membership, true
+}
+
+// rolePermissions returns what role grants in the tenant, and false if it is
+// neither a built-in role nor one of the tenant's custom roles.
+func rolePermissions(roles models.CustomRoleRepository, tenantID string, role models.Role) ([]models.Permission, bool, error) {
+	if role.IsValid() {
+		return models.BuiltinRolePermissions[role], true, nil
+	}
+
+	custom, err := roles.FindByName(tenantID, role)
+	if err != nil || custom == nil {
+		return nil, false, err
+	}
+	return custom.Permissions, true, nil
+}
+
+// checkRole handles the error if role is neither a built-in role nor one of
+// the tenant's custom roles, or grants a permission the caller does not
+// hold: members:manage alone must not let a member make anyone, themselves
+// included, more than they are.
+func checkRole(c *gin.Context, roles models.CustomRoleRepository, tenantID string, role models.Role) bool {
+	permissions, found, err := rolePermissions(roles, tenantID, role)
+	if err != nil {
+		errors.Handle(c, errors.Database("failed to fetch role", err))
+		return false
+	}
+	if !found {
+		errors.Handle(c, errors.Validation(fmt.Sprintf("role must be %q, %q, %q or one of the tenant's custom roles", models.RoleAdmin, models.RoleBilling, models.RoleMember)))
+		return false
+	}
+	return checkGrantable(c, permissions)
+}
+
+// checkOutranked handles the error if the member's role holds a permission
+// the caller does not, refusing the caller the action on them.
+func checkOutranked(c *gin.Context, roles models.CustomRoleRepository, membership *models.Membership, action string) bool {
+	current, _, err := rolePermissions(roles, membership.TenantID, membership.Role)
+	if err != nil {
+		errors.Handle(c, errors.Database("failed to fetch role", err))
+		return false
+	}
+	if permission, ok := missingPermission(c, current); ok {
+		errors.Handle(c, errors.Forbidden(fmt.Sprintf("cannot %s a member holding the %s permission, which you do not hold", action, permission)))
+		return false
+	}
+	return true
+}
+
+// handleMembershipChange handles the error of UpdateRole or Delete.
+func handleMembershipChange(c *gin.Context, id string, err error, action string) {
+	switch {
+	case err == sql.ErrNoRows:
+		errors.Handle(c, errors.NotFound(fmt.Sprintf("member '%s'", id)))
+	case err == models.ErrLastAdmin:
+		errors.Handle(c, errors.Conflict(err.Error()))
+	default:
+		errors.Handle(c, errors.Database("failed to "+action, err))
+	}
+}
+
+func ListMembers(memberships models.MembershipRepository, users models.UserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		list, err := memberships.ListByTenant(security.MustGetTenantID(c))
+		if err != nil {
+			errors.Handle(c, errors.Database("failed to list members", err))
+			return
+		}
+
+		members := make([]Member, 0, len(list))
+		for _, membership := range list {
+			user, err := users.FindByID(membership.UserID)
+			if err != nil {
+				errors.Handle(c, errors.Database("failed to fetch member's user", err))
+				return
+			}
+			member := Member{Membership: membership}
+			if user != nil {
+				member.Email = user.Email
+			}
+			members = append(members, member)
+		}
+
+		c.JSON(200, ListMembersResponse{Members: members, Total: len(members)})
+	}
+}
+
+// UpdateMemberRole handles PUT /members/:id/role. Demoting the tenant's
+// last admin is refused, and so is granting, or taking from a member,
+// a permission the caller does not hold.
+func UpdateMemberRole(memberships models.MembershipRepository, roles models.CustomRoleRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		membership, ok := findMember(c, memberships)
+		if !ok {
+			return
+		}
+
+		var input UpdateMemberRoleInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			errors.Handle(c, errors.Validation(err.Error()))
+			return
+		}
+		if !checkRole(c, roles, membership.TenantID, input.Role) {
+			return
+		}
+		// Nor may it demote a member who can do more than the caller.
+		if !checkOutranked(c, roles, membership, "change the role of") {
+			return
+		}
+
+		if err := memberships.UpdateRole(membership.ID, input.Role); err != nil {
+			handleMembershipChange(c, membership.ID, err, "change the member's role")
+			return
+		}
+
+		membership.Role = input.Role
+		c.JSON(200, membership)
+	}
+}
+
+// RemoveMember handles DELETE /members/:id. Members may remove themselves;
+// removing others takes the members:manage permission, and only those
+// holding no permission the caller lacks. The tenant's last admin is never
+// removed.
+func RemoveMember(memberships models.MembershipRepository, roles models.CustomRoleRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		membership, ok := findMember(c, memberships)
+		if !ok {
+			return
+		}
+		if membership.UserID != security.MustGetUserID(c) {
+			if !security.HasPermission(c, models.PermMembersManage) {
+				errors.Handle(c, errors.Forbidden(fmt.Sprintf("the %s permission is required", models.PermMembersManage)))
+				return
+			}
+			if !checkOutranked(c, roles, membership, "remove") {
+				return
+			}
+		}
+
+		if err := memberships.Delete(membership.ID); err != nil {
+			handleMembershipChange(c, membership.ID, err, "remove member")
+			return
+		}
+
+		c.Status(204)
+	}
+}
@@ -0,0 +1,202 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"mini-search-platform/internal/adapters"
+	"mini-search-platform/internal/handlers"
+	"mini-search-platform/internal/middleware"
+	"mini-search-platform/internal/models"
+	"mini-search-platform/pkg/security"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestInvitationFlow_JoinsTheTenantAndKeepsTheLastAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newProjectsDB(t)
+	users := adapters.NewSQLiteUserRepository(db)
+	tenants := adapters.NewSQLiteTenantRepository(db)
+	memberships := adapters.NewSQLiteMembershipRepository(db)
+	invitations := adapters.NewSQLiteInvitationRepository(db)
+	roles := adapters.NewSQLiteCustomRoleRepository(db)
+	jwtSvc := security.NewJWTService("test-secret", "test", time.Hour)
+	sessions := adapters.NewSQLiteSessionRepository(db)
+	revocations := security.NewRevocationList(sessions, time.Minute)
+	issuer := handlers.NewTokenIssuer(jwtSvc, adapters.NewSQLiteRefreshTokenRepository(db), sessions, revocations, time.Hour, 24*time.Hour)
+
+	if err := tenants.Save(models.NewTenant("acme", "Acme")); err != nil {
+		t.Fatalf("save tenant: %v", err)
+	}
+	if err := users.Save(models.NewUser("owner", "owner@example.com", "x")); err != nil {
+		t.Fatalf("save user: %v", err)
+	}
+	if err := memberships.Save(models.NewMembership("m-owner", "owner", "acme", models.RoleAdmin)); err != nil {
+		t.Fatalf("save membership: %v", err)
+	}
+
+	r := gin.New()
+	r.POST("/auth/register", handlers.Register(users, tenants, memberships, invitations, issuer))
+	tenant := r.Group("/", func(c *gin.Context) {
+		security.SetUserContext(c, c.GetHeader("X-Test-User"), "")
+		role := models.Role(c.GetHeader("X-Test-Role"))
+		security.SetTenantContext(c, "acme", role)
+		security.SetPermissions(c, models.BuiltinRolePermissions[role])
+	})
+	manage := middleware.RequirePermission(models.PermMembersManage)
+	tenant.POST("/invitations", manage, handlers.InviteMember(invitations, memberships, users, roles, time.Hour))
+	tenant.GET("/members", handlers.ListMembers(memberships, users))
+	tenant.PUT("/members/:id/role", manage, handlers.UpdateMemberRole(memberships, roles))
+	tenant.DELETE("/members/:id", handlers.RemoveMember(memberships, roles))
+
+	do := func(method, path, userID string, role models.Role, body interface{}) *httptest.ResponseRecorder {
+		payload, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+		req.Header.Set("X-Test-User", userID)
+		req.Header.Set("X-Test-Role", string(role))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	invite := map[string]string{"email": "Ada@Example.com", "role": "member"}
+	if w := do(http.MethodPost, "/invitations", "owner", models.RoleMember, invite); w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for a non-admin inviting, got %d: %s", w.Code, w.Body.String())
+	}
+	w := do(http.MethodPost, "/invitations", "owner", models.RoleAdmin, invite)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var invitation handlers.InvitationResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &invitation); err != nil {
+		t.Fatalf("failed to decode invitation: %v", err)
+	}
+	if invitation.Token == "" || invitation.Email != "ada@example.com" {
+		t.Fatalf("expected a token for the normalized email, got %+v", invitation)
+	}
+
+	register := map[string]string{"email": "ada@example.com", "password": "password123", "invitation_token": invitation.Token}
+	if w := do(http.MethodPost, "/auth/register", "", "", register); w.Code != http.StatusCreated {
+		t.Fatalf("expected 201 registering with the invitation, got %d: %s", w.Code, w.Body.String())
+	}
+	ada, _ := users.FindByEmail("ada@example.com")
+	membership, err := memberships.FindByUserAndTenant(ada.ID, "acme")
+	if err != nil || membership == nil || membership.Role != models.RoleMember {
+		t.Fatalf("expected ada to have joined acme as a member, got %+v, %v", membership, err)
+	}
+	if list, _ := tenants.ListByUserID(ada.ID); len(list) != 1 {
+		t.Errorf("expected no tenant created for an invited user, got %d tenants", len(list))
+	}
+
+	register["email"] = "grace@example.com"
+	if w := do(http.MethodPost, "/auth/register", "", "", register); w.Code != http.StatusConflict {
+		t.Errorf("expected 409 reusing the invitation, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = do(http.MethodGet, "/members", "owner", models.RoleMember, nil)
+	var members handlers.ListMembersResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &members); err != nil || members.Total != 2 {
+		t.Errorf("expected two members, got %s", w.Body.String())
+	}
+
+	demote := map[string]string{"role": "member"}
+	if w := do(http.MethodPut, "/members/m-owner/role", "owner", models.RoleAdmin, demote); w.Code != http.StatusConflict {
+		t.Errorf("expected 409 demoting the last admin, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodDelete, "/members/m-owner", "owner", models.RoleAdmin, nil); w.Code != http.StatusConflict {
+		t.Errorf("expected 409 removing the last admin, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodDelete, "/members/m-owner", ada.ID, models.RoleMember, nil); w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for a member removing someone else, got %d: %s", w.Code, w.Body.String())
+	}
+
+	promote := map[string]string{"role": "admin"}
+	if w := do(http.MethodPut, "/members/"+membership.ID+"/role", "owner", models.RoleAdmin, promote); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 promoting ada, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodDelete, "/members/m-owner", "owner", models.RoleAdmin, nil); w.Code != http.StatusNoContent {
+		t.Errorf("expected 204 once another admin remains, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestMembersManage_CannotGrantMoreThanTheCallerHolds(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newProjectsDB(t)
+	users := adapters.NewSQLiteUserRepository(db)
+	memberships := adapters.NewSQLiteMembershipRepository(db)
+	invitations := adapters.NewSQLiteInvitationRepository(db)
+	roles := adapters.NewSQLiteCustomRoleRepository(db)
+
+	manager := models.NewCustomRole("r-manager", "acme", "manager", []models.Permission{models.PermDocumentsRead, models.PermMembersRead, models.PermMembersManage})
+	treasurer := models.NewCustomRole("r-treasurer", "acme", "treasurer", []models.Permission{models.PermDocumentsRead, models.PermBillingUpdate})
+	for _, role := range []*models.CustomRole{manager, treasurer} {
+		if err := roles.Save(role); err != nil {
+			t.Fatalf("save role: %v", err)
+		}
+	}
+	for _, membership := range []*models.Membership{
+		models.NewMembership("m-owner", "owner", "acme", models.RoleAdmin),
+		models.NewMembership("m-manager", "lee", "acme", "manager"),
+		models.NewMembership("m-reader", "ada", "acme", "manager"),
+	} {
+		if err := memberships.Save(membership); err != nil {
+			t.Fatalf("save membership: %v", err)
+		}
+	}
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		security.SetUserContext(c, "lee", "")
+		security.SetTenantContext(c, "acme", "manager")
+		security.SetPermissions(c, manager.Permissions)
+	})
+	r.POST("/invitations", handlers.InviteMember(invitations, memberships, users, roles, time.Hour))
+	r.PUT("/members/:id/role", handlers.UpdateMemberRole(memberships, roles))
+	r.POST("/roles", handlers.CreateRole(roles))
+	r.PUT("/roles/:id", handlers.UpdateRole(roles))
+	r.DELETE("/members/:id", handlers.RemoveMember(memberships, roles))
+
+	do := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		payload, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, tc := range []struct {
+		name         string
+		method, path string
+		body         interface{}
+		want         int
+	}{
+		{"inviting an admin", http.MethodPost, "/invitations", map[string]string{"email": "grace@example.com", "role": "admin"}, http.StatusForbidden},
+		{"inviting a member, who may write", http.MethodPost, "/invitations", map[string]string{"email": "grace@example.com", "role": "member"}, http.StatusForbidden},
+		{"promoting themselves", http.MethodPut, "/members/m-manager/role", map[string]string{"role": "admin"}, http.StatusForbidden},
+		{"demoting an admin", http.MethodPut, "/members/m-owner/role", map[string]string{"role": "manager"}, http.StatusForbidden},
+		{"removing an admin", http.MethodDelete, "/members/m-owner", nil, http.StatusForbidden},
+		{"creating a billing role", http.MethodPost, "/roles", map[string]interface{}{"name": "payer", "permissions": []string{"billing:update"}}, http.StatusForbidden},
+		{"widening their own role", http.MethodPut, "/roles/r-manager", map[string]interface{}{"permissions": []string{"members:manage", "documents:write"}}, http.StatusForbidden},
+		{"narrowing a role holding more", http.MethodPut, "/roles/r-treasurer", map[string]interface{}{"permissions": []string{"documents:read"}}, http.StatusForbidden},
+		{"inviting a peer", http.MethodPost, "/invitations", map[string]string{"email": "grace@example.com", "role": "manager"}, http.StatusCreated},
+		{"creating a narrower role", http.MethodPost, "/roles", map[string]interface{}{"name": "reader", "permissions": []string{"documents:read"}}, http.StatusCreated},
+		{"narrowing a peer", http.MethodPut, "/members/m-reader/role", map[string]string{"role": "reader"}, http.StatusOK},
+		{"removing a narrower member", http.MethodDelete, "/members/m-reader", nil, http.StatusNoContent},
+	} {
+		if w := do(tc.method, tc.path, tc.body); w.Code != tc.want {
+			t.Errorf("%s: expected %d, got %d: %s", tc.name, tc.want, w.Code, w.Body.String())
+		}
+	}
+
+	if membership, _ := memberships.FindByID("m-owner"); membership.Role != models.RoleAdmin {
+		t.Errorf("expected the admin to stay admin, got %q", membership.Role)
+	}
+	if role, _ := roles.FindByName("acme", "treasurer"); len(role.Permissions) != 2 {
+		t.Errorf("expected the treasurer role left alone, got %v", role.Permissions)
+	}
+}
@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+
+	"mini-search-platform/internal/models"
+	"mini-search-platform/internal/search"
+	"mini-search-platform/pkg/errors"
+	"mini-search-platform/pkg/security"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/mcuadros/go-defaults"
+)
+
+// TenantPlans resolves the plan a tenant is billed on, which sets how many
+// projects it may have.
+type TenantPlans interface {
+	TenantTier(tenantID string) (models.ProjectTier, error)
+}
+
+// ProjectLimits is the most projects a tenant may have, by plan.
+type ProjectLimits map[models.ProjectTier]int
+
+// CreateProjectInput is the POST /projects body. New projects are free;
+// only members who may update billing make one premium, through
+// PUT /projects/:id/tier.
+type CreateProjectInput struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type RenameProjectInput struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type UpdateProjectTierInput struct {
+	Tier models.ProjectTier `json:"tier" binding:"required"`
+}
+
+type ListProjectsResponse struct {
+	Projects []*models.Project `json:"projects"`
+	Total    int               `json:"total"`
+}
+
+type DeleteProjectResponse struct {
+	ID               string `json:"id"`
+	DetachedArticles int    `json:"detached_articles"`
+}
+
+// findProject loads the :id project of the request's tenant, handling the
+// error if it cannot. Another tenant's project is not found.
+func findProject(c *gin.Context, projects models.ProjectRepository) (*models.Project, bool) {
+	id := c.Param("id")
+	project, err := projects.FindByID(id)
+	if err != nil {
+		errors.Handle(c, errors.Database("failed to fetch project", err))
+		return nil, false
+	}
+	if project == nil || project.TenantID != security.MustGetTenantID(c) {
+		errors.Handle(c, errors.NotFound(fmt.Sprintf("project '%s'", id)))
+		return nil, false
+	}
+	return project, true
+}
+
+// CreateProject handles POST /projects. A tenant already at its plan's
+// project limit is refused.
+func CreateProject(projects models.ProjectRepository, plans TenantPlans, limits ProjectLimits) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input CreateProjectInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			errors.Handle(c, errors.Validation(err.Error()))
+			return
+		}
+		tenantID := security.MustGetTenantID(c)
+		plan, err := plans.TenantTier(tenantID)
+		if err != nil {
+			errors.Handle(c, errors.Database("failed to resolve the tenant's plan", err))
+			return
+		}
+		count, err := projects.CountByTenant(tenantID)
+		if err != nil {
+			errors.Handle(c, errors.Database("failed to count projects", err))
+			return
+		}
+		if count >= limits[plan] {
+			errors.Handle(c, errors.Forbidden(fmt.Sprintf("the %s plan allows at most %d projects", plan, limits[plan])))
+			return
+		}
+
+		project := models.NewProject(uuid.New().String(), tenantID, input.Name, models.TierFree)
+		if err := projects.Save(project); err != nil {
+			errors.Handle(c, errors.Database("failed to save project", err))
+			return
+		}
+
+		c.JSON(201, project)
+	}
+}
+
+func ListProjects(projects models.ProjectRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		list, err := projects.ListByTenant(security.MustGetTenantID(c))
+		if err != nil {
+			errors.Handle(c, errors.Database("failed to list projects", err))
+			return
+		}
+		if list == nil {
+			list = []*models.Project{}
+		}
+
+		c.JSON(200, ListProjectsResponse{Projects: list, Total: len(list)})
+	}
+}
+
+func GetProject(projects models.ProjectRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		project, ok := findProject(c, projects)
+		if !ok {
+			return
+		}
+
+		c.JSON(200, project)
+	}
+}
+
+func RenameProject(projects models.ProjectRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		project, ok := findProject(c, projects)
+		if !ok {
+			return
+		}
+
+		var input RenameProjectInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			errors.Handle(c, errors.Validation(err.Error()))
+			return
+		}
+
+		project.Name = input.Name
+		if err := projects.Update(project); err != nil {
+			errors.Handle(c, errors.Database("failed to rename project", err))
+			return
+		}
+
+		c.JSON(200, project)
+	}
+}
+
+// UpdateProjectTier handles PUT /projects/:id/tier. A downgrade that would
+// leave the tenant on a plan whose project limit it exceeds is refused.
+func UpdateProjectTier(projects models.ProjectRepository, limits ProjectLimits) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		project, ok := findProject(c, projects)
+		if !ok {
+			return
+		}
+
+		var input UpdateProjectTierInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			errors.Handle(c, errors.Validation(err.Error()))
+			return
+		}
+		if !input.Tier.IsValid() {
+			errors.Handle(c, errors.Validation(fmt.Sprintf("tier must be %q or %q", models.TierFree, models.TierPremium)))
+			return
+		}
+
+		// The tenant's plan follows its projects' tiers, as in
+		// middleware.ProjectPlanResolver.
+		list, err := projects.ListByTenant(project.TenantID)
+		if err != nil {
+			errors.Handle(c, errors.Database("failed to list projects", err))
+			return
+		}
+		plan := input.Tier
+		for _, other := range list {
+			if other.ID != project.ID && other.Tier == models.TierPremium {
+				plan = models.TierPremium
+			}
+		}
+		if len(list) > limits[plan] {
+			errors.Handle(c, errors.Conflict(fmt.Sprintf("the %s plan allows at most %d projects; delete some first", plan, limits[plan])))
+			return
+		}
+
+		project.Tier = input.Tier
+		if err := projects.UpdateTier(project.ID, project.Tier); err != nil {
+			errors.Handle(c, errors.Database("failed to change the project's tier", err))
+			return
+		}
+
+		c.JSON(200, project)
+	}
+}
+
+// SearchProject handles GET /projects/:id/search, searching the project's
+// own index, which holds only the project's articles, with the parameters
+// of /internal/search.
+func SearchProject(projects models.ProjectRepository, engine search.ProjectSearcher) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		project, ok := findProject(c, projects)
+		if !ok {
+			return
+		}
+
+		var params SearchQueryParams
+		defaults.SetDefaults(&params)
+
+		if err := c.ShouldBindQuery(&params); err != nil {
+			errors.Handle(c, errors.Validation(err.Error()))
+			return
+		}
+
+		result, err := engine.SearchProject(project.TenantID, project.ID, params.Query, search.SearchOptions{
+			Limit:  params.Limit,
+			Offset: params.Offset,
+			Filter: params.Filter,
+			Sort:   []string{params.Sort},
+			Facets: params.Facets,
+		})
+		if err != nil {
+			errors.Handle(c, errors.Search("failed to search project documents", err))
+			return
+		}
+
+		c.JSON(200, result)
+	}
+}
+
+// DeleteProject handles DELETE /projects/:id. The project is deleted, its
+// articles staying in the tenant, re-indexed without it, and its index is
+// dropped through the index sync outbox, which retries a drop the engine
+// fails.
+func DeleteProject(projects models.ProjectRepository, sync *search.IndexSyncManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		project, ok := findProject(c, projects)
+		if !ok {
+			return
+		}
+
+		detached, err := projects.Delete(project.ID)
+		switch {
+		case err == sql.ErrNoRows:
+			errors.Handle(c, errors.NotFound(fmt.Sprintf("project '%s'", project.ID)))
+			return
+		case err != nil:
+			errors.Handle(c, errors.Database("failed to delete project", err))
+			return
+		}
+
+		// Delete queued an index sync intent for each detached article and
+		// one for the project's index.
+		sync.Notify()
+
+		c.JSON(200, DeleteProjectResponse{ID: project.ID, DetachedArticles: detached})
+	}
+}
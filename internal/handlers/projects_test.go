@@ -0,0 +1,178 @@
+package handlers_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"mini-search-platform/internal/adapters"
+	"mini-search-platform/internal/database"
+	"mini-search-platform/internal/handlers"
+	"mini-search-platform/internal/models"
+	"mini-search-platform/internal/search"
+	"mini-search-platform/pkg/security"
+	"mini-search-platform/pkg/sqlite"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type freePlans struct{}
+
+func (freePlans) TenantTier(tenantID string) (models.ProjectTier, error) {
+	return models.TierFree, nil
+}
+
+func newProjectsDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sqlite.Init("file:" + uuid.NewString() + "?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { sqlite.Close(db) })
+	if err := database.Create(db); err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+	return db
+}
+
+func TestCreateProject_EnforcesThePlansProjectLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	projects := adapters.NewSQLiteProjectRepository(newProjectsDB(t))
+	limits := handlers.ProjectLimits{models.TierFree: 1, models.TierPremium: 5}
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		security.SetTenantContext(c, c.GetHeader("X-Test-Tenant"), models.RoleAdmin)
+	})
+	r.POST("/projects", handlers.CreateProject(projects, freePlans{}, limits))
+	r.PUT("/projects/:id/tier", handlers.UpdateProjectTier(projects, limits))
+
+	create := func(tenantID, name string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]string{"name": name})
+		req := httptest.NewRequest(http.MethodPost, "/projects", bytes.NewReader(body))
+		req.Header.Set("X-Test-Tenant", tenantID)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := create("acme", "docs")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var project models.Project
+	if err := json.Unmarshal(w.Body.Bytes(), &project); err != nil {
+		t.Fatalf("failed to decode project: %v", err)
+	}
+	if project.TenantID != "acme" || project.Tier != models.TierFree || project.Name != "docs" {
+		t.Errorf("unexpected project %+v", project)
+	}
+
+	if w := create("acme", "blog"); w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 past the free plan's limit, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := create("globex", "blog"); w.Code != http.StatusCreated {
+		t.Errorf("expected another tenant's limit to be separate, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Another tenant's project is not found.
+	req := httptest.NewRequest(http.MethodPut, "/projects/"+project.ID+"/tier", bytes.NewReader([]byte(`{"tier":"premium"}`)))
+	req.Header.Set("X-Test-Tenant", "globex")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for another tenant's project, got %d", w.Code)
+	}
+}
+
+// projectIndexes records the engine calls made for projects, checking that
+// a project's index is dropped only once the project is gone.
+type projectIndexes struct {
+	t        *testing.T
+	projects models.ProjectRepository
+	dropped  []string
+	searched []string
+}
+
+func (i *projectIndexes) IndexProjectDocuments(tenantID, projectID string, documents []search.TenantDocument) error {
+	return nil
+}
+
+func (i *projectIndexes) DeleteProjectDocuments(tenantID, projectID string, ids []string) error {
+	return nil
+}
+
+func (i *projectIndexes) DeleteProjectIndex(tenantID, projectID string) (int64, error) {
+	if project, _ := i.projects.FindByID(projectID); project != nil {
+		i.t.Errorf("expected project %s deleted before its index", projectID)
+	}
+	i.dropped = append(i.dropped, tenantID+"/"+projectID)
+	return 7, nil
+}
+
+func (i *projectIndexes) SearchProject(tenantID, projectID string, query string, options search.SearchOptions) (search.TenantSearchResponse, error) {
+	i.searched = append(i.searched, tenantID+"/"+projectID+"?"+query)
+	return search.TenantSearchResponse{Query: query, Hits: []search.TenantDocument{}}, nil
+}
+
+func TestProjects_CreateIsFreeAndSearchAndDeleteUseTheProjectsIndex(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newProjectsDB(t)
+	projects := adapters.NewSQLiteProjectRepository(db)
+	limits := handlers.ProjectLimits{models.TierFree: 3, models.TierPremium: 5}
+	engine := &projectIndexes{t: t, projects: projects}
+	sync := search.NewIndexSyncManager(nil, nil, engine, nil, nil, adapters.NewSQLiteIndexSyncOutboxRepository(db), 3)
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		security.SetTenantContext(c, c.GetHeader("X-Test-Tenant"), models.RoleAdmin)
+	})
+	r.POST("/projects", handlers.CreateProject(projects, freePlans{}, limits))
+	r.GET("/projects/:id/search", handlers.SearchProject(projects, engine))
+	r.DELETE("/projects/:id", handlers.DeleteProject(projects, sync))
+
+	serve := func(method, path, tenantID, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewReader([]byte(body)))
+		req.Header.Set("X-Test-Tenant", tenantID)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// A tier in the body is ignored: premium is set through the tier route.
+	w := serve(http.MethodPost, "/projects", "acme", `{"name":"docs","tier":"premium"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var project models.Project
+	if err := json.Unmarshal(w.Body.Bytes(), &project); err != nil {
+		t.Fatalf("failed to decode project: %v", err)
+	}
+	if project.Tier != models.TierFree {
+		t.Errorf("expected a new project to be free, got %q", project.Tier)
+	}
+
+	if w := serve(http.MethodGet, "/projects/"+project.ID+"/search?q=shoe", "globex", ""); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 searching another tenant's project, got %d", w.Code)
+	}
+	if w := serve(http.MethodGet, "/projects/"+project.ID+"/search?q=shoe", "acme", ""); w.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(engine.searched) != 1 || engine.searched[0] != "acme/"+project.ID+"?shoe" {
+		t.Errorf("expected the project's index searched, got %v", engine.searched)
+	}
+
+	if w := serve(http.MethodDelete, "/projects/"+project.ID, "acme", ""); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if err := sync.DrainOutbox(); err != nil {
+		t.Fatalf("DrainOutbox failed: %v", err)
+	}
+	if len(engine.dropped) != 1 || engine.dropped[0] != "acme/"+project.ID {
+		t.Errorf("expected the project's index dropped, got %v", engine.dropped)
+	}
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"mini-search-platform/internal/models"
+	"mini-search-platform/pkg/errors"
+	"mini-search-platform/pkg/security"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RoleResponse describes a built-in or custom role and what it grants.
+type RoleResponse struct {
+	ID          string              `json:"id,omitempty"`
+	Name        models.Role         `json:"name"`
+	Builtin     bool                `json:"builtin"`
+	Permissions []models.Permission `json:"permissions"`
+}
+
+type ListRolesResponse struct {
+	Roles []RoleResponse `json:"roles"`
+	Total int            `json:"total"`
+}
+
+// CreateRoleInput is the POST /roles body.
+type CreateRoleInput struct {
+	Name        models.Role         `json:"name" binding:"required"`
+	Permissions []models.Permission `json:"permissions" binding:"required"`
+}
+
+// UpdateRoleInput is the PUT /roles/:id body; it replaces the permissions.
+type UpdateRoleInput struct {
+	Permissions []models.Permission `json:"permissions" binding:"required"`
+}
+
+func customRoleResponse(role *models.CustomRole) RoleResponse {
+	return RoleResponse{ID: role.ID, Name: role.Name, Permissions: role.Permissions}
+}
+
+// checkPermissions handles the error if a permission is unknown.
+func checkPermissions(c *gin.Context, permissions []models.Permission) bool {
+	for _, permission := range permissions {
+		if !permission.IsValid() {
+			errors.Handle(c, errors.Validation(fmt.Sprintf("unknown permission %q", permission)).
+				WithDetails(map[string]interface{}{"permissions": models.AllPermissions}))
+			return false
+		}
+	}
+	return true
+}
+
+// missingPermission returns the first of permissions the caller does not
+// hold.
+func missingPermission(c *gin.Context, permissions []models.Permission) (models.Permission, bool) {
+	for _, permission := range permissions {
+		if !security.HasPermission(c, permission) {
+			return permission, true
+		}
+	}
+	return "", false
+}
+
+// checkGrantable handles the error if the caller does not hold one of the
+// permissions, which it then may not grant.
+func checkGrantable(c *gin.Context, permissions []models.Permission) bool {
+	if permission, ok := missingPermission(c, permissions); ok {
+		errors.Handle(c, errors.Forbidden(fmt.Sprintf("cannot grant the %s permission, which you do not hold", permission)))
+		return false
+	}
+	return true
+}
+
+// findRole loads the :id custom role of the request's tenant, handling the
+// error if it cannot.
+func findRole(c *gin.Context, roles models.CustomRoleRepository) (*models.CustomRole, bool) {
+	id := c.Param("id")
+	role, err := roles.FindByID(security.MustGetTenantID(c), id)
+	if err != nil {
+		errors.Handle(c, errors.Database("failed to fetch role", err))
+		return nil, false
+	}
+	if role == nil {
+		errors.Handle(c, errors.NotFound(fmt.Sprintf("role '%s'", id)))
+		return nil, false
+	}
+	return role, true
+}
+
+// ListRoles handles GET /roles: the built-in roles, then the tenant's
+// custom roles by name.
+func ListRoles(roles models.CustomRoleRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		custom, err := roles.ListByTenant(security.MustGetTenantID(c))
+		if err != nil {
+			errors.Handle(c, errors.Database("failed to list roles", err))
+			return
+		}
+
+		list := make([]RoleResponse, 0, len(models.BuiltinRolePermissions)+len(custom))
+		for _, name := range []models.Role{models.RoleAdmin, models.RoleBilling, models.RoleMember} {
+			list = append(list, RoleResponse{Name: name, Builtin: true, Permissions: models.BuiltinRolePermissions[name]})
+		}
+		for _, role := range custom {
+			list = append(list, customRoleResponse(role))
+		}
+
+		c.JSON(200, ListRolesResponse{Roles: list, Total: len(list)})
+	}
+}
+
+// CreateRole handles POST /roles. The caller may only grant permissions it
+// holds.
+func CreateRole(roles models.CustomRoleRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input CreateRoleInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			errors.Handle(c, errors.Validation(err.Error()))
+			return
+		}
+		if !models.IsValidCustomRoleName(input.Name) {
+			errors.Handle(c, errors.Validation("name must be a lowercase slug of 2 to 32 characters and not a built-in role"))
+			return
+		}
+		if !checkPermissions(c, input.Permissions) || !checkGrantable(c, input.Permissions) {
+			return
+		}
+
+		tenantID := security.MustGetTenantID(c)
+		existing, err := roles.FindByName(tenantID, input.Name)
+		if err != nil {
+			errors.Handle(c, errors.Database("failed to check existing role", err))
+			return
+		}
+		if existing != nil {
+			errors.Handle(c, errors.Conflict(fmt.Sprintf("role '%s' already exists", input.Name)))
+			return
+		}
+
+		role := models.NewCustomRole(uuid.New().String(), tenantID, input.Name, input.Permissions)
+		if err := roles.Save(role); err != nil {
+			errors.Handle(c, errors.Database("failed to save role", err))
+			return
+		}
+
+		c.JSON(201, customRoleResponse(role))
+	}
+}
+
+// UpdateRole handles PUT /roles/:id. Members holding the role get the new
+// permissions on their next request. As with CreateRole, the caller may only
+// grant permissions it holds, and as with UpdateMemberRole, may not change a
+// role holding one it lacks: that would demote its members.
+func UpdateRole(roles models.CustomRoleRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, ok := findRole(c, roles)
+		if !ok {
+			return
+		}
+
+		var input UpdateRoleInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			errors.Handle(c, errors.Validation(err.Error()))
+			return
+		}
+		if !checkPermissions(c, input.Permissions) || !checkGrantable(c, input.Permissions) {
+			return
+		}
+		if permission, ok := missingPermission(c, role.Permissions); ok {
+			errors.Handle(c, errors.Forbidden(fmt.Sprintf("cannot change a role holding the %s permission, which you do not hold", permission)))
+			return
+		}
+
+		role.Permissions = input.Permissions
+		role.UpdatedAt = time.Now()
+		if err := roles.Update(role); err != nil {
+			errors.Handle(c, errors.Database("failed to update role", err))
+			return
+		}
+
+		c.JSON(200, customRoleResponse(role))
+	}
+}
+
+// DeleteRole handles DELETE /roles/:id. A role still assigned to members
+// or pending invitations is refused.
+func DeleteRole(roles models.CustomRoleRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, ok := findRole(c, roles)
+		if !ok {
+			return
+		}
+
+		err := roles.Delete(role.TenantID, role.ID)
+		switch {
+		case err == models.ErrRoleInUse:
+			errors.Handle(c, errors.Conflict(err.Error()))
+			return
+		case err != nil:
+			errors.Handle(c, errors.Database("failed to delete role", err))
+			return
+		}
+
+		c.Status(204)
+	}
+}
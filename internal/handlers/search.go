@@ -33,6 +33,7 @@ func SearchArticles(engine search.SearchEngine) gin.HandlerFunc {
 			Offset: params.Offset,
 			Filter: params.Filter,
 			Sort:   []string{params.Sort},
+			Facets: params.Facets,
 		})
 		if err != nil {
 			errors.Handle(c, errors.Search("failed to search articles", err))
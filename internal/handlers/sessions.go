@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"mini-search-platform/internal/models"
+	"mini-search-platform/pkg/errors"
+	"mini-search-platform/pkg/security"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SessionInfo is one of the user's sessions; Current marks the session of
+// the request's own access token.
+type SessionInfo struct {
+	*models.Session
+	Current bool `json:"current"`
+}
+
+type ListSessionsResponse struct {
+	Sessions []SessionInfo `json:"sessions"`
+	Total    int           `json:"total"`
+}
+
+type RevokeSessionsResponse struct {
+	Revoked int `json:"revoked"`
+}
+
+// deviceNames maps user agent fragments to the device they reveal, most
+// specific first.
+var deviceNames = []struct{ fragment, name string }{
+	{"iPhone", "iPhone"},
+	{"iPad", "iPad"},
+	{"Android", "Android"},
+	{"Windows", "Windows"},
+	{"Macintosh", "Mac"},
+	{"CrOS", "ChromeOS"},
+	{"Linux", "Linux"},
+}
+
+// deviceName names the device a session was started from after its user
+// agent, or "unknown".
+func deviceName(userAgent string) string {
+	for _, device := range deviceNames {
+		if strings.Contains(userAgent, device.fragment) {
+			return device.name
+		}
+	}
+	return "unknown"
+}
+
+// revokeSession revokes the session, dropping the cached revocation list so
+// its access tokens stop working on this server at once.
+func revokeSession(sessions models.SessionRepository, revocations *security.RevocationList, id string) error {
+	if err := sessions.Revoke(id); err != nil {
+		return err
+	}
+	revocations.Invalidate()
+	return nil
+}
+
+// ListSessions handles GET /auth/sessions, listing the user's sessions that
+// are neither revoked nor expired, most recently seen first.
+func ListSessions(sessions models.SessionRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		list, err := sessions.ListActiveByUser(security.MustGetUserID(c))
+		if err != nil {
+			errors.Handle(c, errors.Database("failed to fetch sessions", err))
+			return
+		}
+
+		currentID := security.GetSessionID(c)
+		infos := make([]SessionInfo, 0, len(list))
+		for _, session := range list {
+			infos = append(infos, SessionInfo{Session: session, Current: session.ID == currentID})
+		}
+
+		c.JSON(200, ListSessionsResponse{Sessions: infos, Total: len(infos)})
+	}
+}
+
+// RevokeSession handles DELETE /auth/sessions/:id, revoking one of the
+// user's sessions; its refresh and access tokens stop working at once.
+func RevokeSession(sessions models.SessionRepository, revocations *security.RevocationList) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		session, err := sessions.FindByID(id)
+		if err != nil {
+			errors.Handle(c, errors.Database("failed to fetch session", err))
+			return
+		}
+		if session == nil || session.UserID != security.MustGetUserID(c) || session.RevokedAt != nil {
+			errors.Handle(c, errors.NotFound(fmt.Sprintf("session '%s'", id)))
+			return
+		}
+
+		if err := revokeSession(sessions, revocations, session.ID); err != nil {
+			errors.Handle(c, errors.Database("failed to revoke session", err))
+			return
+		}
+
+		c.Status(204)
+	}
+}
+
+// RevokeAllSessions handles DELETE /auth/sessions, revoking every session
+// of the user, the request's own included.
+func RevokeAllSessions(sessions models.SessionRepository, revocations *security.RevocationList) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		revoked, err := sessions.RevokeAllByUser(security.MustGetUserID(c))
+		if err != nil {
+			errors.Handle(c, errors.Database("failed to revoke sessions", err))
+			return
+		}
+		revocations.Invalidate()
+
+		c.JSON(200, RevokeSessionsResponse{Revoked: revoked})
+	}
+}
+
+// RevokeUserSessions handles DELETE /users/:id/sessions, revoking every
+// session of the user, whatever tenant each is bound to: a session in one
+// tenant can switch to any other of the user's tenants, so revoking only
+// some would not sign the user out. As that reaches past any one tenant, the
+// route is reserved to the platform's operators.
+func RevokeUserSessions(users models.UserRepository, sessions models.SessionRepository, revocations *security.RevocationList) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		user, err := users.FindByID(id)
+		if err != nil {
+			errors.Handle(c, errors.Database("failed to fetch user", err))
+			return
+		}
+		if user == nil {
+			errors.Handle(c, errors.NotFound(fmt.Sprintf("user '%s'", id)))
+			return
+		}
+
+		revoked, err := sessions.RevokeAllByUser(user.ID)
+		if err != nil {
+			errors.Handle(c, errors.Database("failed to revoke sessions", err))
+			return
+		}
+		revocations.Invalidate()
+
+		c.JSON(200, RevokeSessionsResponse{Revoked: revoked})
+	}
+}
@@ -0,0 +1,156 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"mini-search-platform/internal/adapters"
+	"mini-search-platform/internal/handlers"
+	"mini-search-platform/internal/middleware"
+	"mini-search-platform/internal/models"
+	"mini-search-platform/pkg/security"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSessions_RevokingASessionRevokesItsAccessTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newProjectsDB(t)
+	users := adapters.NewSQLiteUserRepository(db)
+	memberships := adapters.NewSQLiteMembershipRepository(db)
+	invitations := adapters.NewSQLiteInvitationRepository(db)
+	sessions := adapters.NewSQLiteSessionRepository(db)
+	// Revocations through this server must not wait for the cache to expire.
+	revocations := security.NewRevocationList(sessions, time.Hour)
+	jwtSvc := security.NewJWTService("test-secret", "test", time.Hour)
+	issuer := handlers.NewTokenIssuer(jwtSvc, adapters.NewSQLiteRefreshTokenRepository(db), sessions, revocations, time.Hour, 24*time.Hour)
+
+	hash, err := security.HashPassword("password123")
+	if err != nil {
+		t.Fatalf("HashPassword failed: %v", err)
+	}
+	for _, user := range []*models.User{
+		models.NewUser("ada", "ada@example.com", hash),
+		models.NewUser("bob", "bob@example.com", hash),
+	} {
+		if err := users.Save(user); err != nil {
+			t.Fatalf("save user: %v", err)
+		}
+	}
+	for _, m := range []*models.Membership{
+		models.NewMembership("m-ada", "ada", "acme", models.RoleAdmin),
+		models.NewMembership("m-bob", "bob", "acme", models.RoleMember),
+	} {
+		if err := memberships.Save(m); err != nil {
+			t.Fatalf("save membership: %v", err)
+		}
+	}
+
+	r := gin.New()
+	auth := middleware.NewAuthMiddleware(jwtSvc, users, memberships, sessions, revocations)
+	r.POST("/auth/login", handlers.Login(users, memberships, invitations, issuer))
+	r.POST("/auth/refresh", handlers.RefreshToken(users, memberships, issuer))
+	r.GET("/auth/sessions", auth.RequireAuth(), handlers.ListSessions(sessions))
+	r.DELETE("/auth/sessions", auth.RequireAuth(), handlers.RevokeAllSessions(sessions, revocations))
+	r.DELETE("/auth/sessions/:id", auth.RequireAuth(), handlers.RevokeSession(sessions, revocations))
+	r.DELETE("/users/:id/sessions", auth.RequireAuth(), middleware.RequireOperator([]string{"ada@example.com"}),
+		handlers.RevokeUserSessions(users, sessions, revocations))
+
+	do := func(method, path, token, userAgent string, body interface{}) *httptest.ResponseRecorder {
+		payload, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		req.Header.Set("User-Agent", userAgent)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	login := func(email, userAgent string) handlers.TokenResponse {
+		w := do(http.MethodPost, "/auth/login", "", userAgent, map[string]string{"email": email, "password": "password123"})
+		var tokens handlers.TokenResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &tokens); err != nil || w.Code != http.StatusOK {
+			t.Fatalf("login %s: %d: %s", email, w.Code, w.Body.String())
+		}
+		return tokens
+	}
+	listSessions := func(token string) (*httptest.ResponseRecorder, handlers.ListSessionsResponse) {
+		w := do(http.MethodGet, "/auth/sessions", token, "", nil)
+		var list handlers.ListSessionsResponse
+		json.Unmarshal(w.Body.Bytes(), &list)
+		return w, list
+	}
+
+	laptop := login("ada@example.com", "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_0)")
+	phone := login("ada@example.com", "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0)")
+
+	w, list := listSessions(laptop.AccessToken)
+	if w.Code != http.StatusOK || list.Total != 2 {
+		t.Fatalf("expected ada's two sessions, got %d: %s", w.Code, w.Body.String())
+	}
+	var phoneSessionID string
+	for _, session := range list.Sessions {
+		switch session.Device {
+		case "Mac":
+			if !session.Current {
+				t.Errorf("expected the laptop session marked current, got %+v", session)
+			}
+		case "iPhone":
+			phoneSessionID = session.ID
+		default:
+			t.Errorf("unexpected session %+v", session)
+		}
+	}
+
+	if w := do(http.MethodDelete, "/auth/sessions/"+phoneSessionID, laptop.AccessToken, "", nil); w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204 revoking the phone session, got %d: %s", w.Code, w.Body.String())
+	}
+	if w, _ := listSessions(phone.AccessToken); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected the revoked session's access token rejected at once, got %d", w.Code)
+	}
+	if w := do(http.MethodPost, "/auth/refresh", "", "", map[string]string{"refresh_token": phone.RefreshToken}); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected the revoked session's refresh token rejected, got %d", w.Code)
+	}
+	if w, list := listSessions(laptop.AccessToken); w.Code != http.StatusOK || list.Total != 1 {
+		t.Errorf("expected the laptop session alone left, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Another user's session is not found.
+	bob := login("bob@example.com", "curl/8.0")
+	_, bobSessions := listSessions(bob.AccessToken)
+	if len(bobSessions.Sessions) != 1 {
+		t.Fatalf("expected bob's session, got %+v", bobSessions)
+	}
+	if w := do(http.MethodDelete, "/auth/sessions/"+bobSessions.Sessions[0].ID, laptop.AccessToken, "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 revoking another user's session, got %d", w.Code)
+	}
+
+	// Only an operator revokes another user's sessions.
+	if w := do(http.MethodDelete, "/users/ada/sessions", bob.AccessToken, "", nil); w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for a non-operator revoking sessions, got %d", w.Code)
+	}
+	if w := do(http.MethodDelete, "/users/nobody/sessions", laptop.AccessToken, "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown user, got %d", w.Code)
+	}
+	w = do(http.MethodDelete, "/users/bob/sessions", laptop.AccessToken, "", nil)
+	var revoked handlers.RevokeSessionsResponse
+	json.Unmarshal(w.Body.Bytes(), &revoked)
+	if w.Code != http.StatusOK || revoked.Revoked != 1 {
+		t.Fatalf("expected bob's session revoked by the operator, got %d: %s", w.Code, w.Body.String())
+	}
+	if w, _ := listSessions(bob.AccessToken); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected bob's access token rejected, got %d", w.Code)
+	}
+
+	if w := do(http.MethodDelete, "/auth/sessions", laptop.AccessToken, "", nil); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 revoking every session, got %d: %s", w.Code, w.Body.String())
+	}
+	if w, _ := listSessions(laptop.AccessToken); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected the current session revoked too, got %d", w.Code)
+	}
+}
@@ -1,12 +1,12 @@
 package handlers
 
 import (
-	"context"
+	"database/sql"
 	"fmt"
 	"mini-search-platform/internal/models"
 	"mini-search-platform/internal/search"
 	"mini-search-platform/pkg/errors"
-	"mini-search-platform/pkg/retry"
+	"mini-search-platform/pkg/security"
 
 	"github.com/gin-gonic/gin"
 )
@@ -105,23 +105,21 @@ func UpdateTagWithLabel(repository models.TagsRepository, sync *search.IndexSync
 			return
 		}
 
+		if existing, err := repository.FindByLabel(input.NewLabel); err == nil && existing.ID != tag.ID {
+			errors.Handle(c, errors.Conflict(fmt.Sprintf("tag '%s' already exists", input.NewLabel)))
+			return
+		}
+
 		tag.Update(input.NewLabel)
 
-		lastInsertedId, err := repository.Save(tag)
-		if err != nil {
+		// The rename and its index sync intent are written together.
+		if err := repository.Update(tag); err != nil {
 			errors.Handle(c, errors.Database(fmt.Sprintf("failed to update tag '%s'", tag.Label), err))
 			return
 		}
+		sync.Notify()
 
-		retrieved, _ := repository.FindById(lastInsertedId)
-
-		resync := func(tagToSync *models.Tag) error {
-			operation := func() error {
-				return sync.SyncAfterTagsChanged(tagToSync)
-			}
-			return retry.WithBackoff(context.Background(), operation)
-		}
-		go resync(retrieved)
+		retrieved, _ := repository.FindById(tag.ID)
 
 		c.JSON(200, retrieved)
 	}
@@ -166,7 +164,7 @@ func FindArticlesByLabels(articlesRepository models.ArticleRepository, tagsRepos
 			return
 		}
 
-		articles, err := articlesRepository.FindByTag(tag)
+		articles, err := articlesRepository.FindByTag(security.MustGetTenantID(c), tag)
 		if err != nil {
 			errors.Handle(c, errors.Database(fmt.Sprintf("could not find articles with tag %s", tag.Label), err))
 			return
@@ -175,3 +173,206 @@ func FindArticlesByLabels(articlesRepository models.ArticleRepository, tagsRepos
 		c.JSON(200, articles)
 	}
 }
+
+// DeleteTagParams is the DELETE /tags/:label query. Without detach, a tag
+// that articles carry is not deleted.
+type DeleteTagParams struct {
+	Detach bool `form:"detach"`
+}
+
+type DeleteTagResponse struct {
+	Label            string `json:"label"`
+	DetachedArticles int    `json:"detached_articles"`
+}
+
+// DeleteTag handles DELETE /tags/:label. The articles it is detached from
+// are re-indexed.
+func DeleteTag(repository models.TagsRepository, sync *search.IndexSyncManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		label := c.Param("label")
+
+		var params DeleteTagParams
+		if err := c.ShouldBindQuery(&params); err != nil {
+			errors.Handle(c, errors.Validation(err.Error()))
+			return
+		}
+
+		tag, err := repository.FindByLabel(label)
+		if err != nil {
+			errors.Handle(c, errors.NotFound(fmt.Sprintf("tag '%s'", label)))
+			return
+		}
+
+		detached, err := repository.Delete(tag.ID, params.Detach)
+		switch {
+		case err == models.ErrTagInUse:
+			errors.Handle(c, errors.Conflict(fmt.Sprintf("tag '%s' is in use; pass detach=true to remove it from its articles", label)))
+			return
+		case err == sql.ErrNoRows:
+			errors.Handle(c, errors.NotFound(fmt.Sprintf("tag '%s'", label)))
+			return
+		case err != nil:
+			errors.Handle(c, errors.Database(fmt.Sprintf("failed to delete tag '%s'", label), err))
+			return
+		}
+
+		// Delete queued an index sync intent for each detached article.
+		sync.Notify()
+
+		c.JSON(200, DeleteTagResponse{Label: label, DetachedArticles: detached})
+	}
+}
+
+type MergeTagInput struct {
+	Into string `json:"into" binding:"required"`
+}
+
+type MergeTagResponse struct {
+	Source        string      `json:"source"`
+	Target        *models.Tag `json:"target"`
+	MovedArticles int         `json:"moved_articles"`
+}
+
+// MergeTag handles POST /tags/:label/merge, moving the tag's articles onto
+// the "into" tag and deleting it. The moved articles are re-indexed.
+func MergeTag(repository models.TagsRepository, sync *search.IndexSyncManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		label := c.Param("label")
+
+		var input MergeTagInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			errors.Handle(c, errors.Validation(err.Error()))
+			return
+		}
+
+		source, err := repository.FindByLabel(label)
+		if err != nil {
+			errors.Handle(c, errors.NotFound(fmt.Sprintf("tag '%s'", label)))
+			return
+		}
+		target, err := repository.FindByLabel(input.Into)
+		if err != nil {
+			errors.Handle(c, errors.NotFound(fmt.Sprintf("tag '%s'", input.Into)))
+			return
+		}
+
+		moved, err := repository.Merge(source.ID, target.ID)
+		if err == models.ErrTagCycle {
+			errors.Handle(c, errors.Validation(fmt.Sprintf("cannot merge tag '%s' into itself or its descendants", label)))
+			return
+		}
+		if err == sql.ErrNoRows {
+			errors.Handle(c, errors.NotFound(fmt.Sprintf("tag '%s'", label)))
+			return
+		}
+		if err != nil {
+			errors.Handle(c, errors.Database(fmt.Sprintf("failed to merge tag '%s' into '%s'", label, target.Label), err))
+			return
+		}
+
+		// Merge queued an index sync intent for each moved article.
+		sync.Notify()
+
+		c.JSON(200, MergeTagResponse{Source: label, Target: target, MovedArticles: moved})
+	}
+}
+
+// MoveTagInput is the POST /tags/:label/move body. An empty or missing
+// parent makes the tag a root.
+type MoveTagInput struct {
+	Parent string `json:"parent"`
+}
+
+// MoveTag handles POST /tags/:label/move, moving the tag and its subtree
+// under another tag. The subtree's articles are re-indexed, as their tag
+// paths change.
+func MoveTag(repository models.TagsRepository, sync *search.IndexSyncManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		label := c.Param("label")
+
+		var input MoveTagInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			errors.Handle(c, errors.Validation(err.Error()))
+			return
+		}
+
+		tag, err := repository.FindByLabel(label)
+		if err != nil {
+			errors.Handle(c, errors.NotFound(fmt.Sprintf("tag '%s'", label)))
+			return
+		}
+
+		var parentID *int
+		if input.Parent != "" {
+			parent, err := repository.FindByLabel(input.Parent)
+			if err != nil {
+				errors.Handle(c, errors.NotFound(fmt.Sprintf("tag '%s'", input.Parent)))
+				return
+			}
+			parentID = &parent.ID
+		}
+
+		err = repository.Move(tag.ID, parentID)
+		if err == models.ErrTagCycle {
+			errors.Handle(c, errors.Validation(fmt.Sprintf("cannot move tag '%s' under itself or its descendants", label)))
+			return
+		}
+		if err == sql.ErrNoRows {
+			errors.Handle(c, errors.NotFound(fmt.Sprintf("tag '%s'", label)))
+			return
+		}
+		if err != nil {
+			errors.Handle(c, errors.Database(fmt.Sprintf("failed to move tag '%s'", label), err))
+			return
+		}
+
+		// Move queued an index sync intent for the tag's subtree.
+		sync.Notify()
+
+		moved, _ := repository.FindById(tag.ID)
+
+		c.JSON(200, moved)
+	}
+}
+
+// GetTagAncestors handles GET /tags/:label/ancestors, listing the tag's
+// ancestors root first.
+func GetTagAncestors(repository models.TagsRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		label := c.Param("label")
+		tag, err := repository.FindByLabel(label)
+		if err != nil {
+			errors.Handle(c, errors.NotFound(fmt.Sprintf("tag '%s'", label)))
+			return
+		}
+
+		ancestors, err := repository.Ancestors(tag.ID)
+		if err != nil {
+			errors.Handle(c, errors.Database(fmt.Sprintf("failed to fetch ancestors of tag '%s'", label), err))
+			return
+		}
+
+		c.JSON(200, ancestors)
+	}
+}
+
+// GetTagDescendants handles GET /tags/:label/descendants, listing the
+// tag's subtree level by level; parent_id links each tag to its parent.
+func GetTagDescendants(repository models.TagsRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		label := c.Param("label")
+		tag, err := repository.FindByLabel(label)
+		if err != nil {
+			errors.Handle(c, errors.NotFound(fmt.Sprintf("tag '%s'", label)))
+			return
+		}
+
+		descendants, err := repository.Descendants(tag.ID)
+		if err != nil {
+			errors.Handle(c, errors.Database(fmt.Sprintf("failed to fetch descendants of tag '%s'", label), err))
+			return
+		}
+
+		c.JSON(200, descendants)
+	}
+}
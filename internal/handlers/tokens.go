@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"time"
+
+	"mini-search-platform/internal/models"
+	"mini-search-platform/pkg/errors"
+	"mini-search-platform/pkg/security"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// TokenIssuer issues short-lived JWT access tokens and the opaque refresh
+// tokens that renew them (see models.RefreshToken), each login in its own
+// session (see models.Session).
+type TokenIssuer struct {
+	jwtService    *security.JWTService
+	refreshTokens models.RefreshTokenRepository
+	sessions      models.SessionRepository
+	revocations   *security.RevocationList
+	accessTTL     time.Duration
+	refreshTTL    time.Duration
+}
+
+func NewTokenIssuer(
+	jwtService *security.JWTService,
+	refreshTokens models.RefreshTokenRepository,
+	sessions models.SessionRepository,
+	revocations *security.RevocationList,
+	accessTTL, refreshTTL time.Duration,
+) *TokenIssuer {
+	return &TokenIssuer{
+		jwtService:    jwtService,
+		refreshTokens: refreshTokens,
+		sessions:      sessions,
+		revocations:   revocations,
+		accessTTL:     accessTTL,
+		refreshTTL:    refreshTTL,
+	}
+}
+
+// issue returns tokens for the user, bound to the membership's tenant
+// unless membership is nil. Without previous it starts a new session for
+// the client of c; otherwise it replaces previous in its session, failing
+// with models.ErrRefreshTokenReused if previous was used meanwhile.
+func (t *TokenIssuer) issue(c *gin.Context, userID, email string, membership *models.Membership, previous *models.RefreshToken) (*TokenResponse, error) {
+	var tenantID string
+	var role models.Role
+	if membership != nil {
+		tenantID, role = membership.TenantID, membership.Role
+	}
+
+	refreshToken, err := security.NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	sessionID := uuid.New().String()
+	if previous != nil {
+		sessionID = previous.FamilyID
+	}
+	now := time.Now()
+	next := models.NewRefreshToken(uuid.New().String(), sessionID, userID, tenantID,
+		security.HashToken(refreshToken), now.Add(t.refreshTTL))
+
+	if previous != nil {
+		if err := t.refreshTokens.Rotate(previous.ID, next); err != nil {
+			return nil, err
+		}
+		if err := t.sessions.Touch(sessionID, c.ClientIP(), now); err != nil {
+			return nil, err
+		}
+		if err := t.sessions.Extend(sessionID, next.ExpiresAt); err != nil {
+			return nil, err
+		}
+	} else {
+		userAgent := c.Request.UserAgent()
+		session := models.NewSession(sessionID, userID, tenantID, deviceName(userAgent), c.ClientIP(), userAgent, next.ExpiresAt)
+		if err := t.sessions.Start(session, next); err != nil {
+			return nil, err
+		}
+	}
+
+	accessToken, claims, err := t.jwtService.GenerateSessionToken(sessionID, userID, email, tenantID, role)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.sessions.RecordAccessToken(sessionID, claims.ID, claims.ExpiresAt.Time); err != nil {
+		return nil, err
+	}
+
+	return &TokenResponse{
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		TokenType:        "Bearer",
+		ExpiresIn:        int64(t.accessTTL.Seconds()),
+		RefreshExpiresIn: int64(t.refreshTTL.Seconds()),
+		TenantID:         tenantID,
+		Role:             role,
+		User: UserInfo{
+			ID:    userID,
+			Email: email,
+		},
+	}, nil
+}
+
+// current returns the stored refresh token presented, failing with
+// security.ErrInvalidToken if it is unknown, expired or revoked. A token
+// that was already rotated has leaked: its session is revoked and
+// models.ErrRefreshTokenReused returned.
+func (t *TokenIssuer) current(presented string) (*models.RefreshToken, error) {
+	token, err := t.refreshTokens.FindByTokenHash(security.HashToken(presented))
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case token == nil, token.RevokedAt != nil, !time.Now().Before(token.ExpiresAt):
+		return nil, security.ErrInvalidToken
+	case token.UsedAt != nil:
+		if err := t.revoke(token.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, models.ErrRefreshTokenReused
+	}
+	return token, nil
+}
+
+// revoke revokes the session with its refresh and access tokens.
+func (t *TokenIssuer) revoke(sessionID string) error {
+	return revokeSession(t.sessions, t.revocations, sessionID)
+}
+
+// respondWithTokens issues new tokens for the user, handling the error if
+// it cannot.
+func respondWithTokens(c *gin.Context, issuer *TokenIssuer, status int, userID, email string, membership *models.Membership) {
+	tokens, err := issuer.issue(c, userID, email, membership, nil)
+	if err != nil {
+		errors.Handle(c, errors.Internal("failed to issue tokens", err))
+		return
+	}
+
+	c.JSON(status, tokens)
+}
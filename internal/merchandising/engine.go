@@ -0,0 +1,294 @@
+package merchandising
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"mini-search-platform/internal/analytics"
+	"mini-search-platform/internal/models"
+	"mini-search-platform/internal/search"
+	"mini-search-platform/pkg/logging"
+)
+
+// Engine decorates a search.TenantEngine with per-tenant query rules. Every
+// active rule whose query condition matches is applied around the engine
+// call: pinned documents are placed at their positions, hidden documents and
+// rule filters narrow the search, and boosted/buried documents are ranked
+// ahead of/behind the rest. The IDs of the applied rules are reported in
+// TenantSearchResponse.AppliedRules.
+//
+// Boosting runs one engine search per partition (boosted, neutral, buried)
+// and pinning one more to fetch the pinned documents, so rules cost extra
+// engine calls only for the queries they match. If a rule-shaped search
+// fails (e.g. a rule filter references an attribute that is not filterable),
+// the search is retried without rules rather than failing the shopper.
+//
+// Write and list calls pass through unchanged.
+type Engine struct {
+	engine search.TenantEngine
+	rules  models.QueryRuleRepository
+	now    func() time.Time
+}
+
+func NewEngine(engine search.TenantEngine, rules models.QueryRuleRepository) *Engine {
+	return &Engine{engine: engine, rules: rules, now: time.Now}
+}
+
+// plan is the merged effect of every rule that matched one search.
+type plan struct {
+	ruleIDs []string
+	pins    []models.PinnedDocument
+	hide    []string
+	filters []string
+	boost   []string
+	bury    []string
+}
+
+// matches reports whether the rule's query condition matches query. Both
+// sides are compared normalized, like the search analytics.
+func matches(rule *models.QueryRule, query string) bool {
+	pattern := analytics.NormalizeQuery(rule.Conditions.Query)
+	query = analytics.NormalizeQuery(query)
+
+	switch rule.Conditions.Match {
+	case models.MatchExact:
+		return query == pattern
+	case models.MatchContains:
+		return pattern != "" && strings.Contains(query, pattern)
+	}
+	return false
+}
+
+// planFor merges the consequences of every active, matching rule. Rules are
+// applied in order; when two rules pin to the same position or pin the same
+// document, the earlier rule wins.
+func (e *Engine) planFor(rules []*models.QueryRule, query string) *plan {
+	now := e.now()
+	p := &plan{}
+	takenPositions := make(map[int]bool)
+	pinned := make(map[string]bool)
+
+	for _, rule := range rules {
+		if !rule.ActiveAt(now) || !matches(rule, query) {
+			continue
+		}
+		p.ruleIDs = append(p.ruleIDs, rule.ID)
+
+		c := rule.Consequences
+		for _, pin := range c.Pins {
+			if takenPositions[pin.Position] || pinned[pin.DocumentID] {
+				continue
+			}
+			takenPositions[pin.Position] = true
+			pinned[pin.DocumentID] = true
+			p.pins = append(p.pins, pin)
+		}
+		p.hide = append(p.hide, c.Hide...)
+		if c.Filter != "" {
+			p.filters = append(p.filters, c.Filter)
+		}
+		if c.Boost != "" {
+			p.boost = append(p.boost, c.Boost)
+		}
+		if c.Bury != "" {
+			p.bury = append(p.bury, c.Bury)
+		}
+	}
+
+	sort.Slice(p.pins, func(i, j int) bool { return p.pins[i].Position < p.pins[j].Position })
+	return p
+}
+
+func (e *Engine) SearchTenant(tenantID string, query string, options search.SearchOptions) (search.TenantSearchResponse, error) {
+	rules, err := e.rules.ListByTenant(tenantID)
+	if err != nil {
+		logging.Error("failed to load query rules", "tenant_id", tenantID, "error", err)
+		return e.engine.SearchTenant(tenantID, query, options)
+	}
+
+	p := e.planFor(rules, query)
+	if len(p.ruleIDs) == 0 {
+		return e.engine.SearchTenant(tenantID, query, options)
+	}
+
+	result, err := e.searchWithPlan(tenantID, query, options, p)
+	if err != nil {
+		logging.Warn("query rules failed, searching without them",
+			"tenant_id", tenantID, "rules", p.ruleIDs, "error", err)
+		return e.engine.SearchTenant(tenantID, query, options)
+	}
+
+	result.AppliedRules = p.ruleIDs
+	return result, nil
+}
+
+func (e *Engine) searchWithPlan(tenantID, query string, options search.SearchOptions, p *plan) (search.TenantSearchResponse, error) {
+	scope := search.AndFilters(append([]string{options.Filter}, p.filters...)...)
+
+	pinIDs := make([]string, len(p.pins))
+	for i, pin := range p.pins {
+		pinIDs[i] = pin.DocumentID
+	}
+
+	// Pinned documents are shown whatever the query, but still respect the
+	// shopper's filters and the rules' hides.
+	pinnedDocs, err := e.fetchPinned(tenantID, scope, pinIDs, p.hide)
+	if err != nil {
+		return search.TenantSearchResponse{Query: query}, err
+	}
+	var pins []pinnedHit
+	for _, pin := range p.pins {
+		if doc, ok := pinnedDocs[pin.DocumentID]; ok {
+			pins = append(pins, pinnedHit{index: pin.Position - 1, doc: doc})
+		}
+	}
+
+	// Pins before the page shift the organic results back; pins on the page
+	// take slots from them.
+	pinsBefore, pinsOnPage := 0, 0
+	for _, pin := range pins {
+		switch {
+		case pin.index < options.Offset:
+			pinsBefore++
+		case pin.index < options.Offset+options.Limit:
+			pinsOnPage++
+		}
+	}
+
+	organicFilter := search.AndFilters(scope, search.IDNotInFilter(append(append([]string{}, p.hide...), pinIDs...)))
+	organic, err := e.partitioned(tenantID, query, organicFilter, options.Sort,
+		options.Offset-pinsBefore, options.Limit-pinsOnPage, search.OrFilters(p.boost...), search.OrFilters(p.bury...))
+	if err != nil {
+		return search.TenantSearchResponse{Query: query}, err
+	}
+
+	hits := make([]search.TenantDocument, 0, options.Limit)
+	next := 0
+	for index := options.Offset; index < options.Offset+options.Limit; index++ {
+		if doc, ok := pinAt(pins, index); ok {
+			hits = append(hits, doc)
+		} else if next < len(organic.Hits) {
+			hits = append(hits, organic.Hits[next])
+			next++
+		}
+	}
+
+	result := search.TenantSearchResponse{
+		Query:  query,
+		Hits:   hits,
+		Total:  organic.Total + len(pins),
+		Limit:  options.Limit,
+		Offset: options.Offset,
+	}
+
+	if options.Facets != "" {
+		facets, err := e.engine.SearchTenant(tenantID, query, search.SearchOptions{
+			Limit:  1,
+			Filter: search.AndFilters(scope, search.IDNotInFilter(p.hide)),
+			Facets: options.Facets,
+		})
+		if err != nil {
+			return search.TenantSearchResponse{Query: query}, err
+		}
+		result.FacetDistribution = facets.FacetDistribution
+	}
+
+	return result, nil
+}
+
+type pinnedHit struct {
+	index int
+	doc   search.TenantDocument
+}
+
+func pinAt(pins []pinnedHit, index int) (search.TenantDocument, bool) {
+	for _, pin := range pins {
+		if pin.index == index {
+			return pin.doc, true
+		}
+	}
+	return nil, false
+}
+
+func (e *Engine) fetchPinned(tenantID, scope string, ids, hide []string) (map[string]search.TenantDocument, error) {
+	docs := make(map[string]search.TenantDocument)
+	if len(ids) == 0 {
+		return docs, nil
+	}
+
+	result, err := e.engine.SearchTenant(tenantID, "", search.SearchOptions{
+		Limit:  len(ids),
+		Filter: search.AndFilters(scope, search.IDInFilter(ids), search.IDNotInFilter(hide)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, hit := range result.Hits {
+		docs[search.DocumentID(hit["id"])] = hit
+	}
+	return docs, nil
+}
+
+// partitioned returns one page of results where hits matching boost come
+// first and hits matching bury (and not boost) come last. Each partition is
+// an engine search; the page is stitched across them by their totals.
+func (e *Engine) partitioned(tenantID, query, filter string, sortBy []string, offset, limit int, boost, bury string) (search.TenantSearchResponse, error) {
+	var partitions []string
+	if boost != "" {
+		partitions = append(partitions, search.AndFilters(filter, boost))
+	}
+	partitions = append(partitions, search.AndFilters(filter, search.NotFilter(boost), search.NotFilter(bury)))
+	if bury != "" {
+		partitions = append(partitions, search.AndFilters(filter, bury, search.NotFilter(boost)))
+	}
+
+	result := search.TenantSearchResponse{Query: query, Hits: []search.TenantDocument{}}
+	skip, remaining := max(offset, 0), max(limit, 0)
+
+	for _, partition := range partitions {
+		// Meilisearch treats a limit of 0 as its default, so ask for at
+		// least one hit to learn the partition's total and discard it.
+		page, err := e.engine.SearchTenant(tenantID, query, search.SearchOptions{
+			Limit:  max(remaining, 1),
+			Offset: skip,
+			Filter: partition,
+			Sort:   sortBy,
+		})
+		if err != nil {
+			return result, err
+		}
+
+		taken := page.Hits[:min(remaining, len(page.Hits))]
+		result.Hits = append(result.Hits, taken...)
+		result.Total += page.Total
+		remaining -= len(taken)
+		skip = max(skip-page.Total, 0)
+	}
+
+	return result, nil
+}
+
+func (e *Engine) IndexTenantDocuments(tenantID string, documents []search.TenantDocument) error {
+	return e.engine.IndexTenantDocuments(tenantID, documents)
+}
+
+func (e *Engine) DeleteAllTenantDocuments(tenantID string) error {
+	return e.engine.DeleteAllTenantDocuments(tenantID)
+}
+
+func (e *Engine) DeleteTenantIndex(tenantID string) (int64, error) {
+	return e.engine.DeleteTenantIndex(tenantID)
+}
+
+func (e *Engine) PatchTenantDocuments(tenantID string, patches []search.TenantDocument) error {
+	return e.engine.PatchTenantDocuments(tenantID, patches)
+}
+
+func (e *Engine) DeleteTenantDocuments(tenantID string, ids []string) error {
+	return e.engine.DeleteTenantDocuments(tenantID, ids)
+}
+
+func (e *Engine) ListTenantDocuments(tenantID string, offset, limit int) (search.TenantListResponse, error) {
+	return e.engine.ListTenantDocuments(tenantID, offset, limit)
+}
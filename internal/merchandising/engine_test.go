@@ -0,0 +1,199 @@
+package merchandising
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"mini-search-platform/internal/models"
+	"mini-search-platform/internal/search"
+)
+
+// filterEngine serves canned results keyed by the exact filter string, which
+// lets tests assert on the filters the rules engine builds without a real
+// filter evaluator. Unknown filters fail like an invalid Meilisearch filter.
+type filterEngine struct {
+	search.TenantEngine
+	results map[string][]string
+}
+
+func (e *filterEngine) SearchTenant(tenantID, query string, options search.SearchOptions) (search.TenantSearchResponse, error) {
+	ids, ok := e.results[options.Filter]
+	if !ok {
+		return search.TenantSearchResponse{}, fmt.Errorf("invalid filter %q", options.Filter)
+	}
+
+	hits := []search.TenantDocument{}
+	for i := options.Offset; i < len(ids) && len(hits) < options.Limit; i++ {
+		hits = append(hits, search.TenantDocument{"id": ids[i]})
+	}
+	return search.TenantSearchResponse{Query: query, Hits: hits, Total: len(ids)}, nil
+}
+
+type staticRules []*models.QueryRule
+
+func (r staticRules) Save(rule *models.QueryRule) error                       { return nil }
+func (r staticRules) Update(rule *models.QueryRule) error                     { return nil }
+func (r staticRules) Delete(tenantID, id string) error                        { return nil }
+func (r staticRules) FindByID(tenantID, id string) (*models.QueryRule, error) { return nil, nil }
+func (r staticRules) ListByTenant(tenantID string) ([]*models.QueryRule, error) {
+	return r, nil
+}
+
+func rule(id string, match models.QueryRuleMatch, query string, consequences models.QueryRuleConsequences) *models.QueryRule {
+	return &models.QueryRule{
+		ID:           id,
+		Enabled:      true,
+		Conditions:   models.QueryRuleConditions{Match: match, Query: query},
+		Consequences: consequences,
+	}
+}
+
+func hitIDs(result search.TenantSearchResponse) []string {
+	ids := make([]string, len(result.Hits))
+	for i, hit := range result.Hits {
+		ids[i] = search.DocumentID(hit["id"])
+	}
+	return ids
+}
+
+func TestPlanFor_MatchesActiveRulesAndFirstPinWins(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	expired := rule("expired", models.MatchExact, "summer dress", models.QueryRuleConsequences{Hide: []string{"x"}})
+	expired.Conditions.EndsAt = &past
+	scheduled := rule("scheduled", models.MatchExact, "summer dress", models.QueryRuleConsequences{Hide: []string{"x"}})
+	scheduled.Conditions.StartsAt = &future
+	disabled := rule("disabled", models.MatchContains, "dress", models.QueryRuleConsequences{Hide: []string{"x"}})
+	disabled.Enabled = false
+
+	engine := NewEngine(nil, nil)
+	engine.now = func() time.Time { return now }
+
+	p := engine.planFor([]*models.QueryRule{
+		expired,
+		scheduled,
+		disabled,
+		rule("hero", models.MatchExact, "Summer  Dress", models.QueryRuleConsequences{
+			Pins: []models.PinnedDocument{{DocumentID: "hero", Position: 1}},
+		}),
+		rule("contains", models.MatchContains, "dress", models.QueryRuleConsequences{
+			Pins: []models.PinnedDocument{{DocumentID: "other", Position: 1}, {DocumentID: "second", Position: 2}},
+		}),
+		rule("unrelated", models.MatchExact, "dress", models.QueryRuleConsequences{Hide: []string{"x"}}),
+	}, "summer dress")
+
+	if want := []string{"hero", "contains"}; !reflect.DeepEqual(p.ruleIDs, want) {
+		t.Errorf("expected applied rules %v, got %v", want, p.ruleIDs)
+	}
+	want := []models.PinnedDocument{{DocumentID: "hero", Position: 1}, {DocumentID: "second", Position: 2}}
+	if !reflect.DeepEqual(p.pins, want) {
+		t.Errorf("expected the earlier rule to keep position 1, got %v", p.pins)
+	}
+}
+
+func TestEngine_PinsAndHidesAcrossPages(t *testing.T) {
+	hidden := []string{"sold-out"}
+	inner := &filterEngine{results: map[string][]string{
+		`(id IN ["hero"]) AND (NOT (id IN ["sold-out"]))`: {"hero"},
+		`NOT (id IN ["sold-out", "hero"])`:                {"a", "b", "c", "d"},
+	}}
+	engine := NewEngine(inner, staticRules{
+		rule("r1", models.MatchExact, "summer dress", models.QueryRuleConsequences{
+			Pins: []models.PinnedDocument{{DocumentID: "hero", Position: 1}},
+			Hide: hidden,
+		}),
+	})
+
+	first, err := engine.SearchTenant("tenant-a", "summer dress", search.SearchOptions{Limit: 3})
+	if err != nil {
+		t.Fatalf("SearchTenant failed: %v", err)
+	}
+	if got, want := hitIDs(first), []string{"hero", "a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("page 1: expected %v, got %v", want, got)
+	}
+	if first.Total != 5 {
+		t.Errorf("expected total 5 (4 organic + 1 pinned), got %d", first.Total)
+	}
+	if !reflect.DeepEqual(first.AppliedRules, []string{"r1"}) {
+		t.Errorf("expected applied rules [r1], got %v", first.AppliedRules)
+	}
+
+	second, err := engine.SearchTenant("tenant-a", "summer dress", search.SearchOptions{Limit: 3, Offset: 3})
+	if err != nil {
+		t.Fatalf("SearchTenant failed: %v", err)
+	}
+	if got, want := hitIDs(second), []string{"c", "d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("page 2: expected %v, got %v", want, got)
+	}
+}
+
+func TestEngine_BoostAndBuryReorderResults(t *testing.T) {
+	boost, bury := `brand = "hero"`, `stock = 0`
+	inner := &filterEngine{results: map[string][]string{
+		`brand = "hero"`: {"h1", "h2"},
+		`(NOT (brand = "hero")) AND (NOT (stock = 0))`: {"n1", "n2"},
+		`(stock = 0) AND (NOT (brand = "hero"))`:       {"b1"},
+	}}
+	engine := NewEngine(inner, staticRules{
+		rule("boost", models.MatchContains, "dress", models.QueryRuleConsequences{Boost: boost}),
+		rule("bury", models.MatchContains, "dress", models.QueryRuleConsequences{Bury: bury}),
+	})
+
+	result, err := engine.SearchTenant("tenant-a", "red dress", search.SearchOptions{Limit: 10})
+	if err != nil {
+		t.Fatalf("SearchTenant failed: %v", err)
+	}
+	if got, want := hitIDs(result), []string{"h1", "h2", "n1", "n2", "b1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected boosted first and buried last %v, got %v", want, got)
+	}
+
+	page, err := engine.SearchTenant("tenant-a", "red dress", search.SearchOptions{Limit: 2, Offset: 3})
+	if err != nil {
+		t.Fatalf("SearchTenant failed: %v", err)
+	}
+	if got, want := hitIDs(page), []string{"n2", "b1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("a page spanning partitions: expected %v, got %v", want, got)
+	}
+	if page.Total != 5 {
+		t.Errorf("expected total 5, got %d", page.Total)
+	}
+}
+
+func TestEngine_FallsBackToPlainSearchWhenRulesFail(t *testing.T) {
+	inner := &filterEngine{results: map[string][]string{
+		"": {"a", "b"},
+	}}
+	engine := NewEngine(inner, staticRules{
+		rule("broken", models.MatchExact, "dress", models.QueryRuleConsequences{Filter: "unknown_attribute = 1"}),
+	})
+
+	result, err := engine.SearchTenant("tenant-a", "dress", search.SearchOptions{Limit: 10})
+	if err != nil {
+		t.Fatalf("expected a fallback search, got error: %v", err)
+	}
+	if got := hitIDs(result); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("expected the unruled results, got %v", got)
+	}
+	if len(result.AppliedRules) != 0 {
+		t.Errorf("a failed rule must not be reported as applied, got %v", result.AppliedRules)
+	}
+}
+
+func TestEngine_RuleStoreErrorDoesNotFailSearch(t *testing.T) {
+	inner := &filterEngine{results: map[string][]string{"": {"a"}}}
+	engine := NewEngine(inner, failingRules{})
+
+	if _, err := engine.SearchTenant("tenant-a", "dress", search.SearchOptions{Limit: 10}); err != nil {
+		t.Fatalf("expected the search to proceed without rules, got %v", err)
+	}
+}
+
+type failingRules struct{ staticRules }
+
+func (failingRules) ListByTenant(tenantID string) ([]*models.QueryRule, error) {
+	return nil, errors.New("database is locked")
+}
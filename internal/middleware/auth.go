@@ -2,25 +2,52 @@ package middleware
 
 import (
 	"mini-search-platform/internal/models"
+	"mini-search-platform/pkg/logging"
 	"mini-search-platform/pkg/security"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// sessionTouchInterval is how often a session's last seen time is written
+// while it is being used.
+const sessionTouchInterval = time.Minute
+
 type AuthMiddleware struct {
-	jwtService *security.JWTService
-	userRepo   models.UserRepository
+	jwtService     *security.JWTService
+	userRepo       models.UserRepository
+	membershipRepo models.MembershipRepository
+	sessionRepo    models.SessionRepository
+	revocations    *security.RevocationList
+
+	touchMu   sync.Mutex
+	touchedAt map[string]time.Time
 }
 
-func NewAuthMiddleware(jwtService *security.JWTService, userRepo models.UserRepository) *AuthMiddleware {
+func NewAuthMiddleware(
+	jwtService *security.JWTService,
+	userRepo models.UserRepository,
+	membershipRepo models.MembershipRepository,
+	sessionRepo models.SessionRepository,
+	revocations *security.RevocationList,
+) *AuthMiddleware {
 	return &AuthMiddleware{
-		jwtService: jwtService,
-		userRepo:   userRepo,
+		jwtService:     jwtService,
+		userRepo:       userRepo,
+		membershipRepo: membershipRepo,
+		sessionRepo:    sessionRepo,
+		revocations:    revocations,
+		touchedAt:      make(map[string]time.Time),
 	}
 }
 
+// RequireAuth authenticates the bearer token, which must not have been
+// revoked (see models.Session). A token bound to a tenant (see
+// POST /auth/switch-tenant) only works while the user is still a member of
+// it; the tenant and the user's current role in it go on the context.
 func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		token := extractToken(c)
@@ -31,12 +58,25 @@ func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 		}
 
 		claims, err := m.jwtService.ValidateToken(token)
-		if err != nil {
+		if err != nil || claims.ID == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
 			c.Abort()
 			return
 		}
 
+		revoked, err := m.revocations.IsRevoked(claims.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to verify token"})
+			c.Abort()
+			return
+		}
+
+		if revoked {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "token has been revoked"})
+			c.Abort()
+			return
+		}
+
 		user, err := m.userRepo.FindByID(claims.UserID)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to verify user"})
@@ -53,13 +93,31 @@ func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 		security.SetUserContext(c, user.ID, user.Email)
 
 		if claims.TenantID != "" {
-			c.Set(security.ContextKeyTenantID, claims.TenantID)
+			membership, err := m.membershipRepo.FindByUserAndTenant(user.ID, claims.TenantID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to verify tenant access"})
+				c.Abort()
+				return
+			}
+
+			if membership == nil {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "no longer a member of the token's tenant"})
+				c.Abort()
+				return
+			}
+
+			security.SetTenantContext(c, claims.TenantID, membership.Role)
 		}
 
+		m.touchSession(c, claims.SessionID)
+
 		c.Next()
 	}
 }
 
+// OptionalAuth is RequireAuth for routes that also serve anonymous
+// requests: an invalid or revoked token, or a tenant the user has left, is
+// ignored.
 func (m *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		token := extractToken(c)
@@ -69,7 +127,12 @@ func (m *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
 		}
 
 		claims, err := m.jwtService.ValidateToken(token)
-		if err != nil {
+		if err != nil || claims.ID == "" {
+			c.Next()
+			return
+		}
+
+		if revoked, err := m.revocations.IsRevoked(claims.ID); err != nil || revoked {
 			c.Next()
 			return
 		}
@@ -83,14 +146,53 @@ func (m *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
 		security.SetUserContext(c, user.ID, user.Email)
 
 		if claims.TenantID != "" {
-			c.Set(security.ContextKeyTenantID, claims.TenantID)
+			membership, err := m.membershipRepo.FindByUserAndTenant(user.ID, claims.TenantID)
+			if err == nil && membership != nil {
+				security.SetTenantContext(c, claims.TenantID, membership.Role)
+			}
 		}
 
+		m.touchSession(c, claims.SessionID)
+
 		c.Next()
 	}
 }
 
-func (m *AuthMiddleware) RequireTenant(membershipRepo models.MembershipRepository) gin.HandlerFunc {
+// touchSession puts the session on the context and records the request as
+// its last activity, at most once per sessionTouchInterval. Failing to
+// record it does not fail the request.
+func (m *AuthMiddleware) touchSession(c *gin.Context, sessionID string) {
+	if sessionID == "" {
+		return
+	}
+	security.SetSessionID(c, sessionID)
+
+	now := time.Now()
+	m.touchMu.Lock()
+	if now.Sub(m.touchedAt[sessionID]) < sessionTouchInterval {
+		m.touchMu.Unlock()
+		return
+	}
+	// Forget sessions idle for a while rather than every session ever seen.
+	for id, touchedAt := range m.touchedAt {
+		if now.Sub(touchedAt) >= sessionTouchInterval {
+			delete(m.touchedAt, id)
+		}
+	}
+	m.touchedAt[sessionID] = now
+	m.touchMu.Unlock()
+
+	if err := m.sessionRepo.Touch(sessionID, c.ClientIP(), now); err != nil {
+		logging.WithContext(c).Warn("failed to record session activity", "session_id", sessionID, "error", err)
+	}
+}
+
+// RequireTenant checks that the user is a member of the request's tenant and
+// sets the tenant, the user's role in it and the permissions the role grants
+// (see RolePermissions) on the context. The tenant is the
+// :tenantID path parameter on routes that have one, and otherwise the tenant
+// of the access token, which RequireAuth put on the context.
+func (m *AuthMiddleware) RequireTenant(roleRepo models.CustomRoleRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userID, err := security.GetUserID(c)
 		if err != nil {
@@ -100,13 +202,16 @@ func (m *AuthMiddleware) RequireTenant(membershipRepo models.MembershipRepositor
 		}
 
 		tenantID := c.Param("tenantID")
+		if tenantID == "" {
+			tenantID = security.MustGetTenantID(c)
+		}
 		if tenantID == "" {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "tenant ID required"})
 			c.Abort()
 			return
 		}
 
-		membership, err := membershipRepo.FindByUserAndTenant(userID, tenantID)
+		membership, err := m.membershipRepo.FindByUserAndTenant(userID, tenantID)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to verify tenant access"})
 			c.Abort()
@@ -119,7 +224,15 @@ func (m *AuthMiddleware) RequireTenant(membershipRepo models.MembershipRepositor
 			return
 		}
 
+		permissions, err := RolePermissions(roleRepo, tenantID, membership.Role)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to resolve role permissions"})
+			c.Abort()
+			return
+		}
+
 		security.SetTenantContext(c, tenantID, membership.Role)
+		security.SetPermissions(c, permissions)
 
 		c.Next()
 	}
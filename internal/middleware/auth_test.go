@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"mini-search-platform/internal/adapters"
+	"mini-search-platform/internal/models"
+	"mini-search-platform/pkg/security"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequireAuth_RevalidatesTheTokensTenant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newPermissionsDB(t)
+	users := adapters.NewSQLiteUserRepository(db)
+	memberships := adapters.NewSQLiteMembershipRepository(db)
+	sessions := adapters.NewSQLiteSessionRepository(db)
+	jwtSvc := security.NewJWTService("test-secret", "test", time.Hour)
+
+	if err := users.Save(models.NewUser("ada", "ada@example.com", "x")); err != nil {
+		t.Fatalf("save user: %v", err)
+	}
+	for _, m := range []*models.Membership{
+		models.NewMembership("m-owner", "owner", "acme", models.RoleAdmin),
+		models.NewMembership("m-ada", "ada", "acme", models.RoleAdmin),
+	} {
+		if err := memberships.Save(m); err != nil {
+			t.Fatalf("save membership: %v", err)
+		}
+	}
+	// The token still says admin after the role changes.
+	token, err := jwtSvc.GenerateTenantToken("ada", "ada@example.com", "acme", models.RoleAdmin)
+	if err != nil {
+		t.Fatalf("GenerateTenantToken failed: %v", err)
+	}
+	if err := memberships.UpdateRole("m-ada", models.RoleMember); err != nil {
+		t.Fatalf("UpdateRole failed: %v", err)
+	}
+
+	r := gin.New()
+	r.GET("/me", NewAuthMiddleware(jwtSvc, users, memberships, sessions, security.NewRevocationList(sessions, time.Minute)).RequireAuth(), func(c *gin.Context) {
+		c.String(http.StatusOK, security.MustGetTenantID(c)+"/"+string(security.MustGetRole(c)))
+	})
+	get := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := get(); w.Code != http.StatusOK || w.Body.String() != "acme/member" {
+		t.Errorf("expected the tenant with the current role, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if err := memberships.Delete("m-ada"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if w := get(); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 once the membership is revoked, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+
+	"mini-search-platform/internal/models"
+	"mini-search-platform/pkg/errors"
+	"mini-search-platform/pkg/security"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RolePermissions returns the permissions role grants in the tenant: the
+// built-in matrix for the built-in roles, and the tenant's custom role of
+// that name otherwise. A role the tenant no longer defines grants nothing.
+func RolePermissions(roles models.CustomRoleRepository, tenantID string, role models.Role) ([]models.Permission, error) {
+	if permissions, ok := models.BuiltinRolePermissions[role]; ok {
+		return permissions, nil
+	}
+
+	custom, err := roles.FindByName(tenantID, role)
+	if err != nil {
+		return nil, err
+	}
+	if custom == nil {
+		return nil, nil
+	}
+	return custom.Permissions, nil
+}
+
+// RequirePermission refuses the request unless the user's role in the
+// request's tenant grants permission. It runs after RequireTenant, which
+// resolves the role's permissions.
+func RequirePermission(permission models.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !security.HasPermission(c, permission) {
+			errors.Abort(c, errors.Forbidden(fmt.Sprintf("the %s permission is required", permission)))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireOperator refuses the request unless the user is one of the
+// platform's operators, named by email (compared case-insensitively). It
+// guards what belongs to no tenant, such as the shared author and tag
+// catalog, so no tenant role can grant it. It runs after RequireAuth.
+func RequireOperator(emails []string) gin.HandlerFunc {
+	operators := make(map[string]bool, len(emails))
+	for _, email := range emails {
+		operators[strings.ToLower(strings.TrimSpace(email))] = true
+	}
+
+	return func(c *gin.Context) {
+		email, err := security.GetUserEmail(c)
+		if err != nil || !operators[strings.ToLower(email)] {
+			errors.Abort(c, errors.Forbidden("a platform operator is required"))
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"mini-search-platform/internal/adapters"
+	"mini-search-platform/internal/database"
+	"mini-search-platform/internal/models"
+	"mini-search-platform/pkg/security"
+	"mini-search-platform/pkg/sqlite"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func newPermissionsDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sqlite.Init("file:" + uuid.NewString() + "?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { sqlite.Close(db) })
+	if err := database.Create(db); err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+	return db
+}
+
+func TestRequirePermission_ResolvesBuiltinAndCustomRoles(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newPermissionsDB(t)
+	memberships := adapters.NewSQLiteMembershipRepository(db)
+	roles := adapters.NewSQLiteCustomRoleRepository(db)
+
+	editor := models.NewCustomRole("role-editor", "acme", "editor", []models.Permission{models.PermDocumentsWrite})
+	if err := roles.Save(editor); err != nil {
+		t.Fatalf("save role: %v", err)
+	}
+	for _, m := range []*models.Membership{
+		models.NewMembership("m-ada", "ada", "acme", models.RoleBilling),
+		models.NewMembership("m-grace", "grace", "acme", "editor"),
+		models.NewMembership("m-linus", "linus", "acme", "retired"),
+	} {
+		if err := memberships.Save(m); err != nil {
+			t.Fatalf("save membership: %v", err)
+		}
+	}
+
+	auth := NewAuthMiddleware(nil, nil, memberships, nil, nil)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		security.SetUserContext(c, c.GetHeader("X-Test-User"), "")
+		c.Set(security.ContextKeyTenantID, "acme")
+	})
+	r.POST("/articles", auth.RequireTenant(roles), RequirePermission(models.PermDocumentsWrite), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	tests := []struct {
+		user string
+		want int
+	}{
+		// The billing role may read documents but not write them.
+		{"ada", http.StatusForbidden},
+		// The custom editor role grants documents:write.
+		{"grace", http.StatusCreated},
+		// A role the tenant does not define grants nothing.
+		{"linus", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/articles", nil)
+		req.Header.Set("X-Test-User", tt.user)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s: expected %d, got %d: %s", tt.user, tt.want, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestRequireOperator_OnlyAdmitsTheConfiguredEmails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if email := c.GetHeader("X-Test-Email"); email != "" {
+			security.SetUserContext(c, "user", email)
+		}
+	})
+	r.PATCH("/authors/1", RequireOperator([]string{" Ops@Example.com "}), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		email string
+		want  int
+	}{
+		{"ops@example.com", http.StatusOK},
+		{"OPS@example.com", http.StatusOK},
+		{"ada@example.com", http.StatusForbidden},
+		// No authenticated user.
+		{"", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPatch, "/authors/1", nil)
+		req.Header.Set("X-Test-Email", tt.email)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%q: expected %d, got %d: %s", tt.email, tt.want, w.Code, w.Body.String())
+		}
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"sync"
 	"time"
 
+	"mini-search-platform/pkg/logging"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -13,6 +15,12 @@ type RateLimiter struct {
 	mu      sync.RWMutex
 	rate    int
 	period  time.Duration
+
+	// store, when set, replaces the limiter's own in-memory buckets (e.g.
+	// with a RedisRateLimitStore shared across replicas). failOpen decides
+	// whether requests pass or are rejected while that store is unreachable.
+	store    RateLimitStore
+	failOpen bool
 }
 
 type clientBucket struct {
@@ -29,42 +37,44 @@ func NewRateLimiter(requestsPerMinute int) *RateLimiter {
 	}
 }
 
-func (rl *RateLimiter) getClientBucket(clientIP string) *clientBucket {
-	rl.mu.RLock()
-	bucket, exists := rl.clients[clientIP]
-	rl.mu.RUnlock()
+// NewSharedRateLimiter is NewRateLimiter backed by an external store.
+func NewSharedRateLimiter(requestsPerMinute int, store RateLimitStore, failOpen bool) *RateLimiter {
+	limiter := NewRateLimiter(requestsPerMinute)
+	limiter.store = store
+	limiter.failOpen = failOpen
+	return limiter
+}
 
-	if !exists {
-		rl.mu.Lock()
-		bucket = &clientBucket{
-			tokens:     rl.rate,
-			lastRefill: time.Now(),
-		}
-		rl.clients[clientIP] = bucket
-		rl.mu.Unlock()
+// backend returns the configured store, or a MemoryRateLimitStore view over
+// the limiter's own buckets when none was configured.
+func (rl *RateLimiter) backend() RateLimitStore {
+	if rl.store != nil {
+		return rl.store
 	}
-
-	return bucket
+	return &MemoryRateLimitStore{clients: rl.clients, mu: &rl.mu}
 }
 
 func (rl *RateLimiter) Middleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		clientIP := c.ClientIP()
-		bucket := rl.getClientBucket(clientIP)
-
-		bucket.mu.Lock()
-		defer bucket.mu.Unlock()
-
-		now := time.Now()
-		elapsed := now.Sub(bucket.lastRefill)
 
-		if elapsed >= rl.period {
-			bucket.tokens = rl.rate
-			bucket.lastRefill = now
+		result, err := rl.backend().Take(c.Request.Context(), "ip:"+clientIP, rl.rate, rl.period)
+		if err != nil {
+			logging.Warn("rate limit store unavailable", "fail_open", rl.failOpen, "error", err)
+			if rl.failOpen {
+				c.Next()
+				return
+			}
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error": "Rate limiting is temporarily unavailable. Please try again later.",
+			})
+			c.Abort()
+			return
 		}
 
-		if bucket.tokens > 0 {
-			bucket.tokens--
+		setRateLimitHeaders(c.Header, result)
+
+		if result.Allowed {
 			c.Next()
 		} else {
 			c.JSON(http.StatusTooManyRequests, gin.H{
@@ -76,19 +86,16 @@ func (rl *RateLimiter) Middleware() gin.HandlerFunc {
 }
 
 func (rl *RateLimiter) Cleanup(interval time.Duration) {
+	memory, ok := rl.backend().(*MemoryRateLimitStore)
+	if !ok {
+		// Shared stores expire their own keys.
+		return
+	}
+
 	ticker := time.NewTicker(interval)
 	go func() {
 		for range ticker.C {
-			rl.mu.Lock()
-			now := time.Now()
-			for ip, bucket := range rl.clients {
-				bucket.mu.Lock()
-				if now.Sub(bucket.lastRefill) > 10*time.Minute {
-					delete(rl.clients, ip)
-				}
-				bucket.mu.Unlock()
-			}
-			rl.mu.Unlock()
+			memory.Sweep(10 * time.Minute)
 		}
 	}()
 }
@@ -0,0 +1,206 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RateLimitResult is the outcome of taking one request from a budget.
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAt    time.Time
+	RetryAfter time.Duration
+}
+
+// RateLimitStore holds rate limit state. The in-memory store is only correct
+// for a single process; with several replicas behind a Service, a shared
+// store keeps the effective limit from being multiplied by the replica count.
+type RateLimitStore interface {
+	// Take consumes one request from key's budget of limit requests per
+	// period and reports whether it was allowed.
+	Take(ctx context.Context, key string, limit int, period time.Duration) (RateLimitResult, error)
+}
+
+// MemoryRateLimitStore is the process-local store: one fixed window per key,
+// refilled in full once the period has elapsed.
+type MemoryRateLimitStore struct {
+	clients map[string]*clientBucket
+	mu      *sync.RWMutex
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		clients: make(map[string]*clientBucket),
+		mu:      &sync.RWMutex{},
+	}
+}
+
+func (s *MemoryRateLimitStore) bucket(key string, limit int) *clientBucket {
+	s.mu.RLock()
+	bucket, exists := s.clients[key]
+	s.mu.RUnlock()
+	if exists {
+		return bucket
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if bucket, exists = s.clients[key]; !exists {
+		bucket = &clientBucket{
+			tokens:     limit,
+			lastRefill: time.Now(),
+		}
+		s.clients[key] = bucket
+	}
+	return bucket
+}
+
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, limit int, period time.Duration) (RateLimitResult, error) {
+	bucket := s.bucket(key, limit)
+
+	bucket.mu.Lock()
+	defer bucket.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(bucket.lastRefill) >= period {
+		bucket.tokens = limit
+		bucket.lastRefill = now
+	}
+
+	result := RateLimitResult{
+		Limit:   limit,
+		ResetAt: bucket.lastRefill.Add(period),
+	}
+	if bucket.tokens > 0 {
+		bucket.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = result.ResetAt.Sub(now)
+	}
+	result.Remaining = bucket.tokens
+
+	return result, nil
+}
+
+// Sweep drops buckets that have not been refilled for longer than idle.
+func (s *MemoryRateLimitStore) Sweep(idle time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for key, bucket := range s.clients {
+		bucket.mu.Lock()
+		if now.Sub(bucket.lastRefill) > idle {
+			delete(s.clients, key)
+		}
+		bucket.mu.Unlock()
+	}
+}
+
+// gcraScript implements the generic cell rate algorithm atomically on the
+// Redis side. Each key stores its theoretical arrival time (TAT) in
+// milliseconds; a request is admitted when the TAT it would advance to stays
+// within one period of now, which allows bursts of up to `limit` requests
+// while spacing sustained traffic at period/limit. Time is read from the
+// Redis server so replicas with skewed clocks still agree.
+//
+// Returns {allowed, remaining, retry_after_ms, reset_after_ms}.
+var gcraScript = redis.NewScript(`
+local key = KEYS[1]
+local limit = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local interval = period / limit
+local tat = tonumber(redis.call('GET', key))
+if tat == nil or tat < now then
+	tat = now
+end
+
+local new_tat = tat + interval
+local allow_at = new_tat - period
+if now < allow_at then
+	return {0, 0, math.ceil(allow_at - now), math.ceil(tat - now)}
+end
+
+redis.call('SET', key, tostring(new_tat), 'PX', math.ceil(new_tat - now))
+local remaining = math.floor((now - allow_at) / interval)
+return {1, remaining, 0, math.ceil(new_tat - now)}
+`)
+
+// RedisRateLimitStore keeps rate limit state in any server speaking the
+// Redis protocol, shared by every replica. Keys are namespaced under
+// `rate:` (e.g. `rate:<tenant>:search`, matching CONTRACT.md §6).
+type RedisRateLimitStore struct {
+	client redis.UniversalClient
+}
+
+func NewRedisRateLimitStore(client redis.UniversalClient) *RedisRateLimitStore {
+	return &RedisRateLimitStore{client: client}
+}
+
+// NewRedisRateLimitStoreFromURL connects to a redis:// or rediss:// URL.
+func NewRedisRateLimitStoreFromURL(url string) (*RedisRateLimitStore, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis url: %w", err)
+	}
+	// A rate limit check sits on the request path; fail fast rather than
+	// hold requests while the store is unreachable.
+	opts.DialTimeout = 500 * time.Millisecond
+	opts.ReadTimeout = 250 * time.Millisecond
+	opts.WriteTimeout = 250 * time.Millisecond
+	opts.MaxRetries = 1
+	return NewRedisRateLimitStore(redis.NewClient(opts)), nil
+}
+
+func (s *RedisRateLimitStore) Take(ctx context.Context, key string, limit int, period time.Duration) (RateLimitResult, error) {
+	values, err := gcraScript.Run(ctx, s.client,
+		[]string{"rate:" + key},
+		limit, period.Milliseconds(),
+	).Int64Slice()
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+	if len(values) != 4 {
+		return RateLimitResult{}, fmt.Errorf("unexpected rate limit script reply: %v", values)
+	}
+
+	now := time.Now()
+	return RateLimitResult{
+		Allowed:    values[0] == 1,
+		Limit:      limit,
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Millisecond,
+		ResetAt:    now.Add(time.Duration(values[3]) * time.Millisecond),
+	}, nil
+}
+
+// setRateLimitHeaders writes the X-RateLimit-* headers for a result, plus
+// Retry-After (whole seconds, rounded up) when the request was rejected.
+func setRateLimitHeaders(header func(key, value string), result RateLimitResult) {
+	header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+	header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	header("X-RateLimit-Reset", strconv.FormatInt(result.ResetAt.Unix(), 10))
+
+	if !result.Allowed {
+		header("Retry-After", strconv.Itoa(retryAfterSeconds(result.RetryAfter)))
+	}
+}
+
+func retryAfterSeconds(d time.Duration) int {
+	seconds := int((d + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
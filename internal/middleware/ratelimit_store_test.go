@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"mini-search-platform/internal/models"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// newRedisStore starts an in-process Redis stand-in and returns a store
+// pointed at it.
+func newRedisStore(t *testing.T) (*RedisRateLimitStore, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return NewRedisRateLimitStore(client), server
+}
+
+func TestRedisRateLimitStore_AllowsBurstThenBlocks(t *testing.T) {
+	store, _ := newRedisStore(t)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		result, err := store.Take(ctx, "tenant-a:search", 3, time.Minute)
+		if err != nil {
+			t.Fatalf("Take failed: %v", err)
+		}
+		if !result.Allowed {
+			t.Fatalf("request %d should have been allowed", i+1)
+		}
+		if result.Remaining != 2-i {
+			t.Errorf("request %d: expected %d remaining, got %d", i+1, 2-i, result.Remaining)
+		}
+	}
+
+	result, err := store.Take(ctx, "tenant-a:search", 3, time.Minute)
+	if err != nil {
+		t.Fatalf("Take failed: %v", err)
+	}
+	if result.Allowed {
+		t.Fatal("4th request should have been blocked")
+	}
+	if result.RetryAfter <= 0 || result.RetryAfter > 20*time.Second {
+		t.Errorf("expected retry after roughly one emission interval (20s), got %v", result.RetryAfter)
+	}
+}
+
+func TestRedisRateLimitStore_SharedAcrossReplicas(t *testing.T) {
+	store, server := newRedisStore(t)
+
+	// A second client against the same server stands in for another replica.
+	other := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { other.Close() })
+	replica := NewRedisRateLimitStore(other)
+
+	ctx := context.Background()
+	if result, _ := store.Take(ctx, "tenant-a:search", 2, time.Minute); !result.Allowed {
+		t.Fatal("first request should be allowed")
+	}
+	if result, _ := replica.Take(ctx, "tenant-a:search", 2, time.Minute); !result.Allowed {
+		t.Fatal("second request should be allowed")
+	}
+	if result, _ := store.Take(ctx, "tenant-a:search", 2, time.Minute); result.Allowed {
+		t.Fatal("third request should be blocked regardless of which replica serves it")
+	}
+}
+
+func TestRedisRateLimitStore_RefillsOverTime(t *testing.T) {
+	store, server := newRedisStore(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	server.SetTime(now)
+
+	for i := 0; i < 2; i++ {
+		if result, _ := store.Take(ctx, "tenant-a:search", 2, time.Minute); !result.Allowed {
+			t.Fatalf("request %d should have been allowed", i+1)
+		}
+	}
+	if result, _ := store.Take(ctx, "tenant-a:search", 2, time.Minute); result.Allowed {
+		t.Fatal("third request should have been blocked")
+	}
+
+	// One emission interval (period / limit) frees exactly one slot.
+	server.SetTime(now.Add(30 * time.Second))
+	if result, _ := store.Take(ctx, "tenant-a:search", 2, time.Minute); !result.Allowed {
+		t.Fatal("request after one emission interval should have been allowed")
+	}
+}
+
+func TestTenantRateLimiter_FailOpenAndClosed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store, server := newRedisStore(t)
+	server.Close()
+
+	limits := map[models.ProjectTier]TenantLimits{models.TierFree: {Search: 1, Index: 1}}
+
+	for _, tc := range []struct {
+		failOpen bool
+		want     int
+	}{
+		{failOpen: true, want: http.StatusOK},
+		{failOpen: false, want: http.StatusServiceUnavailable},
+	} {
+		limiter := NewTenantRateLimiter(store, tc.failOpen, staticPlanResolver{}, limits)
+		router := gin.New()
+		router.GET("/search", limiter.Middleware(ScopeSearch), func(c *gin.Context) {
+			c.JSON(200, gin.H{"message": "success"})
+		})
+
+		if w := tenantRequest(router, "GET", "/search", "tenant-a"); w.Code != tc.want {
+			t.Errorf("failOpen=%v: expected %d with the store down, got %d", tc.failOpen, tc.want, w.Code)
+		}
+	}
+}
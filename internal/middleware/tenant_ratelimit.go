@@ -0,0 +1,206 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"mini-search-platform/internal/models"
+	"mini-search-platform/pkg/errors"
+	"mini-search-platform/pkg/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitScope separates the budgets a tenant draws from, so a bulk
+// re-seed, or a flood of shopper events, cannot starve the same tenant's
+// storefront searches.
+type RateLimitScope string
+
+const (
+	ScopeSearch RateLimitScope = "search"
+	ScopeIndex  RateLimitScope = "index"
+	ScopeEvents RateLimitScope = "events"
+)
+
+// TenantLimits is the per-period request budget for each scope.
+type TenantLimits struct {
+	Search int
+	Index  int
+	Events int
+}
+
+func (l TenantLimits) forScope(scope RateLimitScope) int {
+	switch scope {
+	case ScopeIndex:
+		return l.Index
+	case ScopeEvents:
+		return l.Events
+	}
+	return l.Search
+}
+
+// TenantPlanResolver looks up the plan a tenant is billed on.
+type TenantPlanResolver interface {
+	TenantTier(tenantID string) (models.ProjectTier, error)
+}
+
+// ProjectPlanResolver derives a tenant's plan from its projects: a tenant
+// with at least one premium project is premium, everyone else is free.
+type ProjectPlanResolver struct {
+	projects models.ProjectRepository
+}
+
+func NewProjectPlanResolver(projects models.ProjectRepository) *ProjectPlanResolver {
+	return &ProjectPlanResolver{projects: projects}
+}
+
+func (r *ProjectPlanResolver) TenantTier(tenantID string) (models.ProjectTier, error) {
+	projects, err := r.projects.ListByTenant(tenantID)
+	if err != nil {
+		return models.TierFree, err
+	}
+	for _, project := range projects {
+		if project.Tier == models.TierPremium {
+			return models.TierPremium, nil
+		}
+	}
+	return models.TierFree, nil
+}
+
+type cachedPlan struct {
+	tier      models.ProjectTier
+	expiresAt time.Time
+}
+
+// TenantRateLimiter limits the internal /internal/* routes per tenant rather
+// than per client IP: behind the control plane every request arrives from
+// the same pod address, so IP keying would make all tenants share a single
+// bucket (CONTRACT.md §6 requires organization-scoped quotas).
+type TenantRateLimiter struct {
+	store    RateLimitStore
+	failOpen bool
+	plans    TenantPlanResolver
+	limits   map[models.ProjectTier]TenantLimits
+	period   time.Duration
+	planTTL  time.Duration
+
+	planMu    sync.Mutex
+	planCache map[string]cachedPlan
+}
+
+// NewTenantRateLimiter builds a limiter over store. When the store cannot be
+// reached, failOpen lets requests through; otherwise they are rejected with
+// 503 until it recovers.
+func NewTenantRateLimiter(
+	store RateLimitStore,
+	failOpen bool,
+	plans TenantPlanResolver,
+	limits map[models.ProjectTier]TenantLimits,
+) *TenantRateLimiter {
+	return &TenantRateLimiter{
+		store:     store,
+		failOpen:  failOpen,
+		plans:     plans,
+		limits:    limits,
+		period:    time.Minute,
+		planTTL:   time.Minute,
+		planCache: make(map[string]cachedPlan),
+	}
+}
+
+// tier resolves the tenant's plan, caching it briefly so the plan lookup is
+// not a database query on every request. Lookup failures fall back to the
+// free plan rather than rejecting traffic.
+func (rl *TenantRateLimiter) tier(tenantID string) models.ProjectTier {
+	now := time.Now()
+
+	rl.planMu.Lock()
+	cached, ok := rl.planCache[tenantID]
+	rl.planMu.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.tier
+	}
+
+	tier, err := rl.plans.TenantTier(tenantID)
+	if err != nil {
+		logging.Warn("failed to resolve tenant plan, applying free limits", "tenant_id", tenantID, "error", err)
+		tier = models.TierFree
+	}
+
+	rl.planMu.Lock()
+	rl.planCache[tenantID] = cachedPlan{tier: tier, expiresAt: now.Add(rl.planTTL)}
+	rl.planMu.Unlock()
+
+	return tier
+}
+
+// Middleware enforces the tenant's budget for the given scope. The tenant is
+// taken from the trusted X-Tenant-ID header; a missing header is rejected
+// here with the same 400 the handlers would return.
+func (rl *TenantRateLimiter) Middleware(scope RateLimitScope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID := strings.TrimSpace(c.GetHeader("X-Tenant-ID"))
+		if tenantID == "" {
+			errors.Abort(c, errors.Validation("X-Tenant-ID header is required"))
+			return
+		}
+		logging.SetTenantID(c, tenantID)
+
+		tier := rl.tier(tenantID)
+		limit := rl.limits[tier].forScope(scope)
+
+		result, err := rl.store.Take(c.Request.Context(), tenantID+":"+string(scope), limit, rl.period)
+		if err != nil {
+			logging.WithContext(c).Warn("rate limit store unavailable",
+				"tenant_id", tenantID, "fail_open", rl.failOpen, "error", err)
+			if rl.failOpen {
+				c.Next()
+				return
+			}
+			errors.Abort(c, errors.Unavailable("rate limiting is temporarily unavailable", err))
+			return
+		}
+
+		setRateLimitHeaders(c.Header, result)
+
+		if !result.Allowed {
+			errors.Abort(c, errors.RateLimited(
+				fmt.Sprintf("%s rate limit exceeded for %s plan", scope, tier),
+			).WithDetails(map[string]interface{}{
+				"scope":       string(scope),
+				"plan":        string(tier),
+				"limit":       limit,
+				"retry_after": retryAfterSeconds(result.RetryAfter),
+			}))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// Cleanup periodically drops cached plans that have expired and, for the
+// in-memory store, buckets that have gone idle.
+func (rl *TenantRateLimiter) Cleanup(interval time.Duration) {
+	memory, _ := rl.store.(*MemoryRateLimitStore)
+
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			if memory != nil {
+				memory.Sweep(10 * rl.period)
+			}
+
+			now := time.Now()
+			rl.planMu.Lock()
+			for tenantID, cached := range rl.planCache {
+				if now.After(cached.expiresAt) {
+					delete(rl.planCache, tenantID)
+				}
+			}
+			rl.planMu.Unlock()
+		}
+	}()
+}
@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"mini-search-platform/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type staticPlanResolver map[string]models.ProjectTier
+
+func (r staticPlanResolver) TenantTier(tenantID string) (models.ProjectTier, error) {
+	if tier, ok := r[tenantID]; ok {
+		return tier, nil
+	}
+	return models.TierFree, nil
+}
+
+func newTenantLimitedRouter(plans staticPlanResolver) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	limiter := NewTenantRateLimiter(NewMemoryRateLimitStore(), true, plans, map[models.ProjectTier]TenantLimits{
+		models.TierFree:    {Search: 2, Index: 1, Events: 1},
+		models.TierPremium: {Search: 4, Index: 2, Events: 2},
+	})
+
+	router := gin.New()
+	router.GET("/search", limiter.Middleware(ScopeSearch), func(c *gin.Context) {
+		c.JSON(200, gin.H{"message": "success"})
+	})
+	router.POST("/index", limiter.Middleware(ScopeIndex), func(c *gin.Context) {
+		c.JSON(202, gin.H{"message": "accepted"})
+	})
+	router.POST("/events", limiter.Middleware(ScopeEvents), func(c *gin.Context) {
+		c.JSON(202, gin.H{"accepted": 1})
+	})
+	return router
+}
+
+func tenantRequest(router *gin.Engine, method, path, tenantID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	// Every request comes from the same address, as it would behind the
+	// control plane; only the tenant header differs.
+	req.RemoteAddr = "10.0.0.1:1234"
+	if tenantID != "" {
+		req.Header.Set("X-Tenant-ID", tenantID)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestTenantRateLimiter_TenantsBehindOneIPAreTrackedSeparately(t *testing.T) {
+	router := newTenantLimitedRouter(staticPlanResolver{})
+
+	for i := 0; i < 2; i++ {
+		if w := tenantRequest(router, "GET", "/search", "tenant-a"); w.Code != http.StatusOK {
+			t.Fatalf("tenant-a request %d: expected 200, got %d", i+1, w.Code)
+		}
+	}
+	if w := tenantRequest(router, "GET", "/search", "tenant-a"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("tenant-a should be rate limited, got %d", w.Code)
+	}
+
+	if w := tenantRequest(router, "GET", "/search", "tenant-b"); w.Code != http.StatusOK {
+		t.Fatalf("tenant-b should have its own bucket, got %d", w.Code)
+	}
+}
+
+func TestTenantRateLimiter_LimitsFollowThePlan(t *testing.T) {
+	router := newTenantLimitedRouter(staticPlanResolver{"premium": models.TierPremium})
+
+	for i := 0; i < 4; i++ {
+		if w := tenantRequest(router, "GET", "/search", "premium"); w.Code != http.StatusOK {
+			t.Fatalf("premium request %d: expected 200, got %d", i+1, w.Code)
+		}
+	}
+	if w := tenantRequest(router, "GET", "/search", "premium"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("premium tenant should be limited after 4 requests, got %d", w.Code)
+	}
+}
+
+func TestTenantRateLimiter_SearchAndIndexingAreLimitedSeparately(t *testing.T) {
+	router := newTenantLimitedRouter(staticPlanResolver{})
+
+	if w := tenantRequest(router, "POST", "/index", "tenant-a"); w.Code != http.StatusAccepted {
+		t.Fatalf("expected first index request to pass, got %d", w.Code)
+	}
+	if w := tenantRequest(router, "POST", "/index", "tenant-a"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected second index request to be limited, got %d", w.Code)
+	}
+	if w := tenantRequest(router, "GET", "/search", "tenant-a"); w.Code != http.StatusOK {
+		t.Fatalf("exhausting the index budget must not block search, got %d", w.Code)
+	}
+}
+
+func TestTenantRateLimiter_EventsAreLimitedSeparately(t *testing.T) {
+	router := newTenantLimitedRouter(staticPlanResolver{})
+
+	if w := tenantRequest(router, "POST", "/events", "tenant-a"); w.Code != http.StatusAccepted {
+		t.Fatalf("expected first events request to pass, got %d", w.Code)
+	}
+	if w := tenantRequest(router, "POST", "/events", "tenant-a"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected second events request to be limited, got %d", w.Code)
+	}
+	if w := tenantRequest(router, "GET", "/search", "tenant-a"); w.Code != http.StatusOK {
+		t.Fatalf("exhausting the events budget must not block search, got %d", w.Code)
+	}
+	if w := tenantRequest(router, "POST", "/index", "tenant-a"); w.Code != http.StatusAccepted {
+		t.Fatalf("exhausting the events budget must not block indexing, got %d", w.Code)
+	}
+}
+
+func TestTenantRateLimiter_SetsHeadersAndErrorCode(t *testing.T) {
+	router := newTenantLimitedRouter(staticPlanResolver{})
+
+	w := tenantRequest(router, "POST", "/index", "tenant-a")
+	if got := w.Header().Get("X-RateLimit-Limit"); got != "1" {
+		t.Errorf("expected X-RateLimit-Limit 1, got %q", got)
+	}
+	if got := w.Header().Get("X-RateLimit-Remaining"); got != "0" {
+		t.Errorf("expected X-RateLimit-Remaining 0, got %q", got)
+	}
+	if w.Header().Get("X-RateLimit-Reset") == "" {
+		t.Error("expected X-RateLimit-Reset to be set")
+	}
+
+	w = tenantRequest(router, "POST", "/index", "tenant-a")
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("expected Retry-After on a rejected request")
+	}
+
+	var body struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode error body: %v", err)
+	}
+	if body.Error.Code != "RATE_LIMITED" {
+		t.Errorf("expected RATE_LIMITED, got %q", body.Error.Code)
+	}
+}
+
+func TestTenantRateLimiter_RequiresTenantHeader(t *testing.T) {
+	router := newTenantLimitedRouter(staticPlanResolver{})
+
+	if w := tenantRequest(router, "GET", "/search", ""); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without X-Tenant-ID, got %d", w.Code)
+	}
+}
@@ -0,0 +1,69 @@
+package models
+
+import "time"
+
+// SearchLogEntry records one internal search call for analytics. Query is
+// stored normalized (lowercased, whitespace folded) so variants of the same
+// search group together, and SessionID is a one-way hash of the shopper's
+// session, never the raw identifier.
+type SearchLogEntry struct {
+	ID        string
+	TenantID  string
+	Query     string
+	Filters   string
+	TotalHits int
+	LatencyMs int64
+	SessionID string
+	CreatedAt time.Time
+}
+
+// SearchQueryStat aggregates every logged search for one normalized query.
+type SearchQueryStat struct {
+	Query       string    `json:"query"`
+	Searches    int64     `json:"searches"`
+	AvgHits     float64   `json:"avg_hits"`
+	LastQueried time.Time `json:"last_queried"`
+}
+
+// SearchTrendPoint is one bucket (hour or day) of a tenant's search volume.
+type SearchTrendPoint struct {
+	Bucket       time.Time `json:"bucket"`
+	Searches     int64     `json:"searches"`
+	ZeroResults  int64     `json:"zero_results"`
+	AvgLatencyMs float64   `json:"avg_latency_ms"`
+}
+
+// SearchAnalyticsSummary totals a tenant's searches within a window.
+type SearchAnalyticsSummary struct {
+	Searches       int64   `json:"searches"`
+	ZeroResults    int64   `json:"zero_results"`
+	ZeroResultRate float64 `json:"zero_result_rate"`
+	UniqueQueries  int64   `json:"unique_queries"`
+	UniqueSessions int64   `json:"unique_sessions"`
+	AvgLatencyMs   float64 `json:"avg_latency_ms"`
+}
+
+// SearchAnalyticsReport is everything GET /internal/analytics/searches
+// returns for one tenant and window.
+type SearchAnalyticsReport struct {
+	Summary           SearchAnalyticsSummary `json:"summary"`
+	TopQueries        []*SearchQueryStat     `json:"top_queries"`
+	ZeroResultQueries []*SearchQueryStat     `json:"zero_result_queries"`
+	NoClickQueries    []*SearchQueryStat     `json:"no_click_queries"`
+	Trend             []*SearchTrendPoint    `json:"trend"`
+}
+
+type SearchLogRepository interface {
+	SaveBatch(entries []*SearchLogEntry) error
+	Summary(tenantID string, from, to time.Time) (SearchAnalyticsSummary, error)
+	// TopQueries, ZeroResultQueries and NoClickQueries return at most limit
+	// queries, most searched first. A no-click query returned hits but no
+	// SearchEvent was ever recorded against any of its searches.
+	TopQueries(tenantID string, from, to time.Time, limit int) ([]*SearchQueryStat, error)
+	ZeroResultQueries(tenantID string, from, to time.Time, limit int) ([]*SearchQueryStat, error)
+	NoClickQueries(tenantID string, from, to time.Time, limit int) ([]*SearchQueryStat, error)
+	Trend(tenantID string, from, to time.Time, bucket time.Duration) ([]*SearchTrendPoint, error)
+	// Prune deletes entries created before olderThan, then the oldest
+	// entries beyond maxRows, returning how many rows were removed.
+	Prune(olderThan time.Time, maxRows int) (int64, error)
+}
@@ -2,8 +2,13 @@ package models
 
 import "time"
 
+// Article belongs to a tenant, and optionally to one of its projects, and
+// is indexed into that tenant's index. Articles without a tenant predate
+// tenancy and live in the shared articles index.
 type Article struct {
 	ID        int    `json:"id"`
+	TenantID  string `json:"tenant_id,omitempty"`
+	ProjectID string `json:"project_id,omitempty"`
 	Title     string `json:"title"`
 	Body      string `json:"body"`
 	Author    string `json:"author"`
@@ -23,7 +28,39 @@ func NewArticle(title, body string, author *Author, tags []*Tag) *Article {
 	}
 }
 
+// ArticleFilter selects articles for ArticleRepository.List. TenantID is
+// required; other zero fields do not filter. From and To bound created_at,
+// inclusive.
+type ArticleFilter struct {
+	TenantID  string
+	ProjectID string
+	AuthorID  int
+	Tag       string
+	From      *time.Time
+	To        *time.Time
+	Limit     int
+	Offset    int
+}
+
+// ArticleRepository writes record an index sync intent for the article in
+// the same transaction (see IndexSyncOutboxRepository).
 type ArticleRepository interface {
 	Save(*Article) (int, error)
-	FindByTag(tags *Tag) ([]*Article, error)
+	// Update replaces the article's title, body, author and tags.
+	Update(*Article) error
+	Delete(id int) error
+	// FindByID returns nil if there is no such article.
+	FindByID(id int) (*Article, error)
+	// FindByTag returns the tenant's articles carrying the tag.
+	FindByTag(tenantID string, tag *Tag) ([]*Article, error)
+	// FindByTagSubtree returns the articles carrying the tag or one of its
+	// descendants.
+	FindByTagSubtree(tagID int) ([]*Article, error)
+	FindByAuthor(authorID int) ([]*Article, error)
+	// FindByIDs finds articles whatever their tenant; callers serving a
+	// tenant must check TenantID.
+	FindByIDs(ids []int) ([]*Article, error)
+	// List returns a page of the matching articles, newest first, and how
+	// many match in total.
+	List(filter ArticleFilter) ([]*Article, int, error)
 }
@@ -8,6 +8,28 @@ type Author struct {
 	CreatedAt string `json:"created_at"`
 }
 
+// AuthorFilter selects authors for AuthorsRepository.List. Name matches
+// any author whose name contains it, ignoring ASCII case.
+type AuthorFilter struct {
+	Name   string
+	Limit  int
+	Offset int
+}
+
+type AuthorsRepository interface {
+	Save(*Author) (int, error)
+	FindAuthorById(id int) (*Author, error)
+	// FindAuthorByName returns nil if no author has the name.
+	FindAuthorByName(name string) (*Author, error)
+	// List returns a page of the matching authors, by name, and how many
+	// match in total.
+	List(filter AuthorFilter) ([]*Author, int, error)
+	// Update renames the author and records an index sync intent for the
+	// author's articles in the same transaction (see
+	// IndexSyncOutboxRepository).
+	Update(*Author) error
+}
+
 func NewAuthor(AuthorId int, Name string) *Author {
 	return &Author{
 		ID:        AuthorId,
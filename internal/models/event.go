@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+// SearchEventType is a shopper interaction with a search result.
+type SearchEventType string
+
+const (
+	EventClick     SearchEventType = "click"
+	EventAddToCart SearchEventType = "add_to_cart"
+	EventPurchase  SearchEventType = "purchase"
+)
+
+func (t SearchEventType) Valid() bool {
+	switch t {
+	case EventClick, EventAddToCart, EventPurchase:
+		return true
+	}
+	return false
+}
+
+// SearchEvent records one interaction with a document in a tenant's index.
+// SearchID links it back to the search log entry it came from (when the
+// shopper arrived through search); SessionID is hashed like the search log's.
+type SearchEvent struct {
+	ID         string
+	TenantID   string
+	Type       SearchEventType
+	DocumentID string
+	SearchID   string
+	Query      string
+	SessionID  string
+	CreatedAt  time.Time
+}
+
+// DocumentEventCount is the number of events of one type a document received
+// on one (UTC) day.
+type DocumentEventCount struct {
+	TenantID   string
+	DocumentID string
+	Type       SearchEventType
+	Day        time.Time
+	Count      int64
+}
+
+// DocumentPopularity is the popularity score last written into a tenant's
+// index for one document.
+type DocumentPopularity struct {
+	TenantID   string
+	DocumentID string
+	Score      float64
+	UpdatedAt  time.Time
+}
+
+type SearchEventRepository interface {
+	SaveBatch(events []*SearchEvent) error
+	// DailyCounts aggregates every event since the given time by tenant,
+	// document, type and day.
+	DailyCounts(since time.Time) ([]*DocumentEventCount, error)
+	Prune(olderThan time.Time) (int64, error)
+}
+
+type DocumentPopularityRepository interface {
+	// ListScored returns every document whose stored score is above zero.
+	ListScored() ([]*DocumentPopularity, error)
+	// ListScoredByTenant is ListScored for a single tenant.
+	ListScoredByTenant(tenantID string) ([]*DocumentPopularity, error)
+	// SaveBatch upserts scores; a zero score removes the document's row.
+	SaveBatch(scores []*DocumentPopularity) error
+}
@@ -0,0 +1,83 @@
+package models
+
+import "time"
+
+// IndexSyncKind names what an index sync intent refers to.
+type IndexSyncKind string
+
+const (
+	// IndexSyncArticle re-indexes one article.
+	IndexSyncArticle IndexSyncKind = "article"
+	// IndexSyncTag re-indexes every article carrying one tag or one of its
+	// descendants, whose paths include the tag's label.
+	IndexSyncTag IndexSyncKind = "tag"
+	// IndexSyncAuthor re-indexes every article by one author.
+	IndexSyncAuthor IndexSyncKind = "author"
+	// IndexSyncProject drops the index of one deleted project, named by the
+	// intent's tenant and project; its entity ID is unused.
+	IndexSyncProject IndexSyncKind = "project"
+)
+
+// IndexSyncIntent is an outbox entry recording that an entity changed in
+// SQLite and must be (re-)indexed. It holds the entity's ID only: the
+// entity is read back when the intent is processed, so the index receives
+// its latest state however many times it changed in between. An article
+// intent also records the article's tenant and project, so the documents of
+// an article deleted in between are removed from the right indexes.
+type IndexSyncIntent struct {
+	ID            int64         `json:"id"`
+	Kind          IndexSyncKind `json:"kind"`
+	EntityID      int           `json:"entity_id"`
+	TenantID      string        `json:"tenant_id,omitempty"`
+	ProjectID     string        `json:"project_id,omitempty"`
+	Attempts      int           `json:"attempts"`
+	LastError     string        `json:"last_error,omitempty"`
+	NextAttemptAt time.Time     `json:"next_attempt_at"`
+	CreatedAt     time.Time     `json:"created_at"`
+}
+
+// IndexSyncDeadLetter is an intent that exhausted its attempts.
+type IndexSyncDeadLetter struct {
+	ID        int64         `json:"id"`
+	IntentID  int64         `json:"intent_id"`
+	Kind      IndexSyncKind `json:"kind"`
+	EntityID  int           `json:"entity_id"`
+	TenantID  string        `json:"tenant_id,omitempty"`
+	ProjectID string        `json:"project_id,omitempty"`
+	Attempts  int           `json:"attempts"`
+	LastError string        `json:"last_error"`
+	CreatedAt time.Time     `json:"created_at"`
+	FailedAt  time.Time     `json:"failed_at"`
+}
+
+// IndexSyncStats summarizes the outbox. OldestPendingAt is nil when
+// nothing is pending.
+type IndexSyncStats struct {
+	Pending         int        `json:"pending"`
+	Retrying        int        `json:"retrying"`
+	OldestPendingAt *time.Time `json:"oldest_pending_at"`
+	DeadLetters     int        `json:"dead_letters"`
+}
+
+// IndexSyncOutboxRepository stores index sync intents. Writers that must
+// not lose an intent record it in the transaction changing the entity
+// (see adapters.SQLliteArticleRepository.Save); Enqueue is for the others.
+type IndexSyncOutboxRepository interface {
+	Enqueue(kind IndexSyncKind, entityID int) error
+	// EnqueueTenantArticles records an intent for each of the tenant's
+	// articles, as when its index was emptied.
+	EnqueueTenantArticles(tenantID string) error
+	// ListDue returns intents whose next attempt is due at now, oldest
+	// first.
+	ListDue(now time.Time, limit int) ([]*IndexSyncIntent, error)
+	// Complete removes processed intents.
+	Complete(ids []int64) error
+	// Reschedule saves a failed intent's attempts, last error and next
+	// attempt time.
+	Reschedule(intent *IndexSyncIntent) error
+	// DeadLetter moves an intent to the dead letters, in one transaction.
+	DeadLetter(intent *IndexSyncIntent) error
+	// ListDeadLetters returns dead letters, newest first.
+	ListDeadLetters(limit int) ([]*IndexSyncDeadLetter, error)
+	Stats() (IndexSyncStats, error)
+}
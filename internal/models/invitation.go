@@ -0,0 +1,69 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrInvitationAccepted is returned when accepting an invitation that
+	// has already been used.
+	ErrInvitationAccepted = errors.New("invitation has already been accepted")
+	// ErrAlreadyMember is returned when accepting an invitation to a tenant
+	// the user already belongs to.
+	ErrAlreadyMember = errors.New("user is already a member of the tenant")
+)
+
+// Invitation lets whoever holds its token join TenantID with Role, once,
+// before ExpiresAt, provided they sign in as Email. Only the token's hash
+// is stored (see security.HashToken).
+type Invitation struct {
+	ID         string     `json:"id"`
+	TenantID   string     `json:"tenant_id"`
+	Email      string     `json:"email"`
+	Role       Role       `json:"role"`
+	TokenHash  string     `json:"-"`
+	InvitedBy  string     `json:"invited_by"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func NewInvitation(id, tenantID, email string, role Role, tokenHash, invitedBy string, expiresAt time.Time) *Invitation {
+	return &Invitation{
+		ID:        id,
+		TenantID:  tenantID,
+		Email:     email,
+		Role:      role,
+		TokenHash: tokenHash,
+		InvitedBy: invitedBy,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}
+}
+
+// ExpiredAt reports whether the invitation can no longer be accepted at t.
+func (i *Invitation) ExpiredAt(t time.Time) bool {
+	return !t.Before(i.ExpiresAt)
+}
+
+type InvitationRepository interface {
+	Save(invitation *Invitation) error
+	// FindByID and FindByTokenHash return nil if there is no such
+	// invitation.
+	FindByID(id string) (*Invitation, error)
+	FindByTokenHash(tokenHash string) (*Invitation, error)
+	// ListPendingByTenant returns the tenant's invitations not yet
+	// accepted, expired ones included, newest first.
+	ListPendingByTenant(tenantID string) ([]*Invitation, error)
+	Delete(id string) error
+	// Accept marks the invitation accepted and saves the membership in one
+	// transaction, failing with ErrInvitationAccepted if it was used
+	// meanwhile and ErrAlreadyMember if the user already belongs to the
+	// tenant.
+	Accept(id string, membership *Membership) error
+	// AcceptAsNewUser is Accept for a user registering with the
+	// invitation: the user is saved in the same transaction, so a failed
+	// accept leaves no account behind.
+	AcceptAsNewUser(id string, user *User, membership *Membership) error
+}
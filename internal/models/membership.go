@@ -1,6 +1,13 @@
 package models
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+// ErrLastAdmin is returned when a change would leave a tenant without an
+// admin.
+var ErrLastAdmin = errors.New("tenant must keep at least one admin")
 
 type Role string
 
@@ -10,6 +17,8 @@ const (
 	RoleMember  Role = "member"
 )
 
+// IsValid reports whether r is a built-in role. A tenant's custom roles
+// (see CustomRole) are valid in that tenant too.
 func (r Role) IsValid() bool {
 	switch r {
 	case RoleAdmin, RoleBilling, RoleMember:
@@ -19,11 +28,11 @@ func (r Role) IsValid() bool {
 }
 
 type Membership struct {
-	ID        string
-	UserID    string
-	TenantID  string
-	Role      Role
-	CreatedAt time.Time
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	TenantID  string    `json:"tenant_id"`
+	Role      Role      `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 func NewMembership(id, userID, tenantID string, role Role) *Membership {
@@ -42,6 +51,9 @@ type MembershipRepository interface {
 	FindByUserAndTenant(userID, tenantID string) (*Membership, error)
 	ListByUser(userID string) ([]*Membership, error)
 	ListByTenant(tenantID string) ([]*Membership, error)
+	// UpdateRole and Delete fail with sql.ErrNoRows if there is no such
+	// membership and with ErrLastAdmin if it is its tenant's only admin
+	// and would stop being one.
 	UpdateRole(id string, role Role) error
 	Delete(id string) error
 }
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"errors"
+	"regexp"
+	"time"
+)
+
+// Permission is an action a role may take in its tenant, named
+// "<resource>:<verb>".
+type Permission string
+
+const (
+	PermDocumentsRead  Permission = "documents:read"
+	PermDocumentsWrite Permission = "documents:write"
+	PermProjectsManage Permission = "projects:manage"
+	PermMembersRead    Permission = "members:read"
+	PermMembersManage  Permission = "members:manage"
+	PermBillingUpdate  Permission = "billing:update"
+)
+
+// AllPermissions lists every permission, the ones custom roles choose from.
+var AllPermissions = []Permission{
+	PermDocumentsRead,
+	PermDocumentsWrite,
+	PermProjectsManage,
+	PermMembersRead,
+	PermMembersManage,
+	PermBillingUpdate,
+}
+
+// BuiltinRolePermissions is the permission matrix of the built-in roles.
+// Custom roles (see CustomRole) carry their own permissions.
+var BuiltinRolePermissions = map[Role][]Permission{
+	RoleAdmin: AllPermissions,
+	RoleBilling: {
+		PermDocumentsRead,
+		PermMembersRead,
+		PermBillingUpdate,
+	},
+	RoleMember: {
+		PermDocumentsRead,
+		PermDocumentsWrite,
+		PermProjectsManage,
+		PermMembersRead,
+	},
+}
+
+func (p Permission) IsValid() bool {
+	for _, known := range AllPermissions {
+		if p == known {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrRoleInUse is returned when deleting a custom role that members or
+// pending invitations still hold.
+var ErrRoleInUse = errors.New("role is assigned to members or pending invitations")
+
+var customRoleName = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,31}$`)
+
+// CustomRole is a tenant-defined role, assigned to memberships and
+// invitations by Name like the built-in roles.
+type CustomRole struct {
+	ID          string       `json:"id"`
+	TenantID    string       `json:"tenant_id"`
+	Name        Role         `json:"name"`
+	Permissions []Permission `json:"permissions"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+func NewCustomRole(id, tenantID string, name Role, permissions []Permission) *CustomRole {
+	now := time.Now()
+	return &CustomRole{
+		ID:          id,
+		TenantID:    tenantID,
+		Name:        name,
+		Permissions: permissions,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// IsValidCustomRoleName reports whether name can name a custom role: a
+// lowercase slug of 2 to 32 characters that is not a built-in role.
+func IsValidCustomRoleName(name Role) bool {
+	return customRoleName.MatchString(string(name)) && !name.IsValid()
+}
+
+type CustomRoleRepository interface {
+	Save(role *CustomRole) error
+	// Update replaces the role's permissions.
+	Update(role *CustomRole) error
+	// Delete fails with ErrRoleInUse while the role is assigned.
+	Delete(tenantID, id string) error
+	// FindByID and FindByName return nil when the tenant has no such role.
+	FindByID(tenantID, id string) (*CustomRole, error)
+	FindByName(tenantID string, name Role) (*CustomRole, error)
+	ListByTenant(tenantID string) ([]*CustomRole, error)
+}
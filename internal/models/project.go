@@ -18,11 +18,11 @@ func (t ProjectTier) IsValid() bool {
 }
 
 type Project struct {
-	ID        string
-	TenantID  string
-	Name      string
-	Tier      ProjectTier
-	CreatedAt time.Time
+	ID        string      `json:"id"`
+	TenantID  string      `json:"tenant_id"`
+	Name      string      `json:"name"`
+	Tier      ProjectTier `json:"tier"`
+	CreatedAt time.Time   `json:"created_at"`
 }
 
 func NewProject(id, tenantID, name string, tier ProjectTier) *Project {
@@ -42,5 +42,9 @@ type ProjectRepository interface {
 	CountByTenant(tenantID string) (int, error)
 	UpdateTier(id string, tier ProjectTier) error
 	Update(project *Project) error
-	Delete(id string) error
+	// Delete removes the project and detaches its articles, which stay in
+	// the tenant, recording an index sync intent for each, and one to drop
+	// the project's index, in the same transaction. It returns how many
+	// articles were detached, or sql.ErrNoRows if there is no such project.
+	Delete(id string) (int, error)
 }
@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+// QueryRuleMatch says how a rule's query condition is compared with the
+// shopper's (normalized) query.
+type QueryRuleMatch string
+
+const (
+	MatchExact    QueryRuleMatch = "exact"
+	MatchContains QueryRuleMatch = "contains"
+)
+
+func (m QueryRuleMatch) Valid() bool {
+	return m == MatchExact || m == MatchContains
+}
+
+// QueryRuleConditions decide when a rule applies: the query matches and, if
+// set, the current time is within [StartsAt, EndsAt).
+type QueryRuleConditions struct {
+	Match    QueryRuleMatch `json:"match"`
+	Query    string         `json:"query"`
+	StartsAt *time.Time     `json:"starts_at,omitempty"`
+	EndsAt   *time.Time     `json:"ends_at,omitempty"`
+}
+
+// PinnedDocument forces a document to a 1-based position in the results.
+type PinnedDocument struct {
+	DocumentID string `json:"document_id"`
+	Position   int    `json:"position"`
+}
+
+// QueryRuleConsequences are applied to a matching search. Filter is ANDed
+// into the search's filter; Boost and Bury are filter expressions whose
+// matching hits are moved ahead of, or behind, the rest.
+type QueryRuleConsequences struct {
+	Pins   []PinnedDocument `json:"pins,omitempty"`
+	Hide   []string         `json:"hide,omitempty"`
+	Filter string           `json:"filter,omitempty"`
+	Boost  string           `json:"boost,omitempty"`
+	Bury   string           `json:"bury,omitempty"`
+}
+
+// QueryRule is one tenant merchandising rule.
+type QueryRule struct {
+	ID           string                `json:"id"`
+	TenantID     string                `json:"-"`
+	Description  string                `json:"description"`
+	Enabled      bool                  `json:"enabled"`
+	Conditions   QueryRuleConditions   `json:"conditions"`
+	Consequences QueryRuleConsequences `json:"consequences"`
+	CreatedAt    time.Time             `json:"created_at"`
+	UpdatedAt    time.Time             `json:"updated_at"`
+}
+
+// ActiveAt reports whether the rule is enabled and within its date range.
+func (r *QueryRule) ActiveAt(now time.Time) bool {
+	if !r.Enabled {
+		return false
+	}
+	if r.Conditions.StartsAt != nil && now.Before(*r.Conditions.StartsAt) {
+		return false
+	}
+	if r.Conditions.EndsAt != nil && !now.Before(*r.Conditions.EndsAt) {
+		return false
+	}
+	return true
+}
+
+type QueryRuleRepository interface {
+	Save(rule *QueryRule) error
+	Update(rule *QueryRule) error
+	Delete(tenantID, id string) error
+	// FindByID returns nil when the tenant has no rule with that ID.
+	FindByID(tenantID, id string) (*QueryRule, error)
+	// ListByTenant returns the tenant's rules, oldest first; that order is
+	// also the order in which matching rules are applied.
+	ListByTenant(tenantID string) ([]*QueryRule, error)
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrRefreshTokenReused is returned when rotating a refresh token that has
+// already been rotated or revoked.
+var ErrRefreshTokenReused = errors.New("refresh token has already been used")
+
+// RefreshToken is an opaque, single-use token renewing a user's access
+// token. Each refresh rotates it: the token is marked used and a new one in
+// the same family replaces it, so presenting a used token again means it
+// leaked and the whole family is revoked with its session (see Session).
+// Only the token's hash is stored (see security.HashToken).
+type RefreshToken struct {
+	ID       string
+	FamilyID string
+	UserID   string
+	// TenantID is the tenant the renewed access tokens are bound to, if
+	// any.
+	TenantID  string
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
+
+func NewRefreshToken(id, familyID, userID, tenantID, tokenHash string, expiresAt time.Time) *RefreshToken {
+	return &RefreshToken{
+		ID:        id,
+		FamilyID:  familyID,
+		UserID:    userID,
+		TenantID:  tenantID,
+		TokenHash: tokenHash,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}
+}
+
+type RefreshTokenRepository interface {
+	Save(token *RefreshToken) error
+	// FindByTokenHash returns nil if there is no such token.
+	FindByTokenHash(tokenHash string) (*RefreshToken, error)
+	// Rotate marks the token used and saves next in one transaction,
+	// failing with ErrRefreshTokenReused if the token was used or revoked
+	// meanwhile.
+	Rotate(id string, next *RefreshToken) error
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// ReindexCheckpoint records how far a full reindex got: every article with
+// an ID up to LastArticleID has been handed to the engine. A reindex that
+// finds a checkpoint resumes after it.
+type ReindexCheckpoint struct {
+	IndexName     string    `json:"index_name"`
+	LastArticleID int       `json:"last_article_id"`
+	Indexed       int       `json:"indexed"`
+	StartedAt     time.Time `json:"started_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type ReindexCheckpointRepository interface {
+	// Load returns nil if the index has no checkpoint.
+	Load(indexName string) (*ReindexCheckpoint, error)
+	// Save creates or replaces the index's checkpoint.
+	Save(checkpoint *ReindexCheckpoint) error
+	Delete(indexName string) error
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// SavedSearch is a standing query of one of a tenant's buyers. Documents
+// indexed after it was saved that match it are announced to WebhookURL.
+type SavedSearch struct {
+	ID         string   `json:"id"`
+	TenantID   string   `json:"-"`
+	Name       string   `json:"name"`
+	Query      string   `json:"query"`
+	Filter     string   `json:"filter,omitempty"`
+	Sort       []string `json:"sort,omitempty"`
+	WebhookURL string   `json:"webhook_url"`
+	// Secret signs the webhook deliveries. It is returned only when the
+	// saved search is created.
+	Secret    string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type SavedSearchRepository interface {
+	Save(savedSearch *SavedSearch) error
+	Update(savedSearch *SavedSearch) error
+	// Delete removes the saved search and the matches recorded for it.
+	Delete(tenantID, id string) error
+	// FindByID returns nil when the tenant has no saved search with that ID.
+	FindByID(tenantID, id string) (*SavedSearch, error)
+	// ListByTenant returns the tenant's saved searches, oldest first.
+	ListByTenant(tenantID string) ([]*SavedSearch, error)
+	// UnseenMatches returns those of documentIDs not recorded as matches of
+	// the saved search yet, in the given order.
+	UnseenMatches(savedSearchID string, documentIDs []string) ([]string, error)
+	// RecordMatches remembers that documentIDs matched the saved search.
+	RecordMatches(savedSearchID string, documentIDs []string) error
+}
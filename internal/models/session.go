@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// Session is one login of a user: the refresh token family it started (see
+// RefreshToken), whose ID it shares, and every access token issued in it, so
+// that revoking the session cuts off both. Device, IPAddress and UserAgent
+// describe the client, as last seen.
+type Session struct {
+	ID     string `json:"id"`
+	UserID string `json:"user_id"`
+	// TenantID is the tenant the session's tokens are bound to, if any.
+	TenantID   string     `json:"tenant_id,omitempty"`
+	Device     string     `json:"device"`
+	IPAddress  string     `json:"ip_address"`
+	UserAgent  string     `json:"user_agent"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+func NewSession(id, userID, tenantID, device, ipAddress, userAgent string, expiresAt time.Time) *Session {
+	now := time.Now()
+	return &Session{
+		ID:         id,
+		UserID:     userID,
+		TenantID:   tenantID,
+		Device:     device,
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  expiresAt,
+	}
+}
+
+type SessionRepository interface {
+	Save(session *Session) error
+	// Start saves a new session together with its first refresh token:
+	// either both are saved or neither is.
+	Start(session *Session, refreshToken *RefreshToken) error
+	// FindByID returns nil if there is no such session.
+	FindByID(id string) (*Session, error)
+	// ListActiveByUser returns the user's sessions that are neither revoked
+	// nor expired, most recently seen first.
+	ListActiveByUser(userID string) ([]*Session, error)
+	// Touch records that the session was used from ipAddress at seenAt.
+	Touch(id, ipAddress string, seenAt time.Time) error
+	// Extend moves the session's expiry, as its refresh token is rotated.
+	Extend(id string, expiresAt time.Time) error
+	// RecordAccessToken records the ID (jti) of an access token issued in
+	// the session, so that revoking the session revokes the token.
+	RecordAccessToken(sessionID, jti string, expiresAt time.Time) error
+	// Revoke revokes the session with its refresh and access tokens.
+	Revoke(id string) error
+	// RevokeAllByUser revokes every session of the user, returning how
+	// many it revoked.
+	RevokeAllByUser(userID string) (int, error)
+	// ListRevokedAccessTokens returns the IDs of the revoked access tokens
+	// that have not expired by now.
+	ListRevokedAccessTokens(now time.Time) ([]string, error)
+	// Prune deletes the sessions, refresh tokens and access tokens that
+	// expired by now, returning how many rows it deleted.
+	Prune(now time.Time) (int64, error)
+}
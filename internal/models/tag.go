@@ -1,12 +1,33 @@
 package models
 
-import "time"
+import (
+	"errors"
+	"strings"
+	"time"
+)
 
+var (
+	// ErrTagInUse is returned when deleting a tag that articles still carry
+	// without detaching it.
+	ErrTagInUse = errors.New("tag is in use")
+	// ErrTagCycle is returned when a tag would become its own ancestor.
+	ErrTagCycle = errors.New("tag cannot be moved under itself or its descendants")
+)
+
+// TagPathSeparator joins the labels of a tag's path, as in
+// "Clothing > Dresses > Maxi".
+const TagPathSeparator = " > "
+
+// Tag is a node of the tag taxonomy: a tag without a parent is a root.
+// Path, the labels from the root down to the tag, is only loaded with the
+// tags of articles read for indexing (see ArticleRepository.FindByIDs).
 type Tag struct {
-	ID        int    `json:"id"`
-	Label     string `json:"label"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	ID        int      `json:"id"`
+	Label     string   `json:"label"`
+	ParentID  *int     `json:"parent_id"`
+	Path      []string `json:"path,omitempty"`
+	CreatedAt string   `json:"created_at"`
+	UpdatedAt string   `json:"updated_at"`
 }
 
 func NewTag(label string) *Tag {
@@ -17,6 +38,15 @@ func NewTag(label string) *Tag {
 	}
 }
 
+// PathLabel is the tag's path joined with TagPathSeparator, or its label
+// if the path is not loaded.
+func (t *Tag) PathLabel() string {
+	if len(t.Path) == 0 {
+		return t.Label
+	}
+	return strings.Join(t.Path, TagPathSeparator)
+}
+
 func (t *Tag) Update(label string) {
 	t.Label = label
 	t.UpdatedAt = time.Now().Format(time.RFC3339)
@@ -24,8 +54,29 @@ func (t *Tag) Update(label string) {
 
 type TagsRepository interface {
 	Save(*Tag) (int, error)
+	// Update renames the tag and records an index sync intent for its
+	// articles in the same transaction. It returns sql.ErrNoRows if there
+	// is no such tag.
+	Update(*Tag) error
 	FindById(id int) (*Tag, error)
 	FindByLabel(label string) (*Tag, error)
 	FindByLabels(labels []string) ([]*Tag, error)
 	FindAll() ([]*Tag, error)
+	// Delete removes the tag, detaching it from its articles if detach is
+	// set and failing with ErrTagInUse otherwise; its children move up to
+	// its parent. Merge moves the source tag's articles and children onto
+	// the target and removes the source. Both record index sync intents for
+	// every affected article in the same transaction (see
+	// IndexSyncOutboxRepository), and return how many articles carried the
+	// removed tag.
+	Delete(id int, detach bool) (int, error)
+	Merge(sourceID, targetID int) (int, error)
+	// Move makes the tag a child of parentID, or a root if it is nil,
+	// failing with ErrTagCycle if the parent is in the tag's subtree. It
+	// records an index sync intent for the subtree in the same transaction.
+	Move(id int, parentID *int) error
+	// Ancestors lists the tag's ancestors, root first; Descendants lists
+	// its subtree, the tag excluded, level by level.
+	Ancestors(id int) ([]*Tag, error)
+	Descendants(id int) ([]*Tag, error)
 }
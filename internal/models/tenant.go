@@ -22,4 +22,29 @@ type TenantRepository interface {
 	ListByUserID(userID string) ([]*Tenant, error)
 	Update(tenant *Tenant) error
 	Delete(id string) error
+	// Purge deletes the tenant together with its memberships, projects and
+	// tenant-scoped articles in a single transaction, returning a receipt
+	// with the per-table counts. Purging an unknown tenant is not an error.
+	Purge(id string) (*TenantDeletionReceipt, error)
+}
+
+// TenantDeletionReceipt records what an offboarding removed and when, so a
+// GDPR erasure request can be proven after the tenant's rows are gone. It
+// intentionally holds only counts and identifiers, never tenant content.
+type TenantDeletionReceipt struct {
+	ID                 string    `json:"id"`
+	TenantID           string    `json:"tenant_id"`
+	TenantFound        bool      `json:"tenant_found"`
+	MembershipsDeleted int       `json:"memberships_deleted"`
+	ProjectsDeleted    int       `json:"projects_deleted"`
+	ArticlesDeleted    int       `json:"articles_deleted"`
+	ArticleTagsDeleted int       `json:"article_tags_deleted"`
+	IndexName          string    `json:"index_name"`
+	IndexTaskUID       int64     `json:"index_task_uid"`
+	DeletedAt          time.Time `json:"deleted_at"`
+}
+
+type TenantDeletionReceiptRepository interface {
+	Save(receipt *TenantDeletionReceipt) error
+	ListByTenant(tenantID string) ([]*TenantDeletionReceipt, error)
 }
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// UsageRollup holds one tenant's metered usage of the internal search API
+// within a single hour bucket. Engine latency is kept as a sum plus a call
+// count (and the worst single call) so rollups can be merged losslessly and
+// averages derived afterwards.
+type UsageRollup struct {
+	TenantID           string    `json:"-"`
+	Hour               time.Time `json:"hour"`
+	Searches           int64     `json:"searches"`
+	DocumentsIndexed   int64     `json:"documents_indexed"`
+	DocumentsDeleted   int64     `json:"documents_deleted"`
+	BytesIngested      int64     `json:"bytes_ingested"`
+	EngineCalls        int64     `json:"engine_calls"`
+	EngineErrors       int64     `json:"engine_errors"`
+	EngineLatencyMs    int64     `json:"engine_latency_ms"`
+	EngineLatencyMaxMs int64     `json:"engine_latency_max_ms"`
+}
+
+// Merge adds other's counters into u. Hour and TenantID are left untouched.
+func (u *UsageRollup) Merge(other *UsageRollup) {
+	u.Searches += other.Searches
+	u.DocumentsIndexed += other.DocumentsIndexed
+	u.DocumentsDeleted += other.DocumentsDeleted
+	u.BytesIngested += other.BytesIngested
+	u.EngineCalls += other.EngineCalls
+	u.EngineErrors += other.EngineErrors
+	u.EngineLatencyMs += other.EngineLatencyMs
+	if other.EngineLatencyMaxMs > u.EngineLatencyMaxMs {
+		u.EngineLatencyMaxMs = other.EngineLatencyMaxMs
+	}
+}
+
+type UsageRepository interface {
+	// Increment adds the rollup's counters to the stored (tenant, hour) row,
+	// creating it if needed.
+	Increment(rollup *UsageRollup) error
+	ListByTenant(tenantID string, since time.Time) ([]*UsageRollup, error)
+}
@@ -0,0 +1,11 @@
+package models
+
+// VocabularyRepository stores each tenant's search vocabulary: the terms
+// found in its indexed documents' searchable fields, with how often each
+// was seen.
+type VocabularyRepository interface {
+	// Increment adds counts to the tenant's stored term frequencies.
+	Increment(tenantID string, counts map[string]int64) error
+	Load(tenantID string) (map[string]int64, error)
+	DeleteByTenant(tenantID string) error
+}
@@ -0,0 +1,130 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookEvent is an indexing lifecycle event tenants can subscribe to.
+type WebhookEvent string
+
+const (
+	EventDocumentsIndexed WebhookEvent = "documents.indexed"
+	EventDocumentsFailed  WebhookEvent = "documents.failed"
+	EventIndexReset       WebhookEvent = "index.reset"
+	EventSettingsUpdated  WebhookEvent = "settings.updated"
+)
+
+func (e WebhookEvent) Valid() bool {
+	switch e {
+	case EventDocumentsIndexed, EventDocumentsFailed, EventIndexReset, EventSettingsUpdated:
+		return true
+	}
+	return false
+}
+
+// WebhookSubscription sends a tenant's lifecycle events to URL.
+type WebhookSubscription struct {
+	ID          string         `json:"id"`
+	TenantID    string         `json:"-"`
+	Description string         `json:"description"`
+	URL         string         `json:"url"`
+	Events      []WebhookEvent `json:"events"`
+	Enabled     bool           `json:"enabled"`
+	// Secret signs the deliveries. It is returned only when the
+	// subscription is created.
+	Secret    string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Wants reports whether the subscription is enabled and listens to event.
+func (s *WebhookSubscription) Wants(event WebhookEvent) bool {
+	if !s.Enabled {
+		return false
+	}
+	for _, e := range s.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookSubscriptionRepository interface {
+	Save(subscription *WebhookSubscription) error
+	Update(subscription *WebhookSubscription) error
+	Delete(tenantID, id string) error
+	// FindByID returns nil when the tenant has no subscription with that ID.
+	FindByID(tenantID, id string) (*WebhookSubscription, error)
+	// ListByTenant returns the tenant's subscriptions, oldest first.
+	ListByTenant(tenantID string) ([]*WebhookSubscription, error)
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	DeliveryPending   WebhookDeliveryStatus = "pending"
+	DeliveryDelivered WebhookDeliveryStatus = "delivered"
+	// DeliveryFailed is final: every attempt was used up, and the delivery
+	// was copied to the dead-letter table.
+	DeliveryFailed WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery is one webhook notification and the record of its
+// delivery attempts. SourceID names what the notification is about, e.g.
+// the saved search whose matches it lists.
+type WebhookDelivery struct {
+	ID             string                `json:"id"`
+	TenantID       string                `json:"-"`
+	SourceID       string                `json:"source_id"`
+	Event          string                `json:"event"`
+	URL            string                `json:"url"`
+	Payload        json.RawMessage       `json:"payload"`
+	Secret         string                `json:"-"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	ResponseStatus int                   `json:"response_status,omitempty"`
+	LastError      string                `json:"last_error,omitempty"`
+	NextAttemptAt  time.Time             `json:"next_attempt_at"`
+	CreatedAt      time.Time             `json:"created_at"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty"`
+}
+
+// WebhookDeadLetter is a delivery that used up its attempts, kept until it
+// is redelivered.
+type WebhookDeadLetter struct {
+	ID             string          `json:"id"`
+	DeliveryID     string          `json:"delivery_id"`
+	TenantID       string          `json:"-"`
+	SourceID       string          `json:"source_id"`
+	Event          string          `json:"event"`
+	URL            string          `json:"url"`
+	Payload        json.RawMessage `json:"payload"`
+	Secret         string          `json:"-"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus int             `json:"response_status,omitempty"`
+	LastError      string          `json:"last_error"`
+	FailedAt       time.Time       `json:"failed_at"`
+}
+
+type WebhookDeliveryRepository interface {
+	Save(delivery *WebhookDelivery) error
+	Update(delivery *WebhookDelivery) error
+	// DeadLetter saves a delivery that used up its attempts and copies it
+	// to the dead-letter table, in one transaction.
+	DeadLetter(delivery *WebhookDelivery) error
+	// ListDue returns up to limit pending deliveries whose next attempt is
+	// due at now, oldest first.
+	ListDue(now time.Time, limit int) ([]*WebhookDelivery, error)
+	// ListBySource returns up to limit of the tenant's deliveries for one
+	// source, newest first.
+	ListBySource(tenantID, sourceID string, limit int) ([]*WebhookDelivery, error)
+	// ListDeadLetters returns up to limit of the tenant's dead letters,
+	// newest first.
+	ListDeadLetters(tenantID string, limit int) ([]*WebhookDeadLetter, error)
+	// FindDeadLetter returns nil when the tenant has no dead letter with
+	// that ID.
+	FindDeadLetter(tenantID, id string) (*WebhookDeadLetter, error)
+	DeleteDeadLetter(tenantID, id string) error
+}
@@ -0,0 +1,88 @@
+package popularity
+
+import (
+	"mini-search-platform/internal/models"
+	"mini-search-platform/internal/search"
+	"mini-search-platform/pkg/logging"
+)
+
+// Engine decorates a search.TenantEngine so indexed documents keep their
+// popularity. Indexing replaces a whole document, and the Scorer only
+// writes scores that changed, so a re-seeded document would otherwise lose
+// its score until its demand changes. Each indexed document that has a
+// stored score is given it; the caller's documents are not modified.
+//
+// Failing to load the scores is logged and never fails the write.
+type Engine struct {
+	engine search.TenantEngine
+	scores models.DocumentPopularityRepository
+}
+
+func NewEngine(engine search.TenantEngine, scores models.DocumentPopularityRepository) *Engine {
+	return &Engine{engine: engine, scores: scores}
+}
+
+func (e *Engine) IndexTenantDocuments(tenantID string, documents []search.TenantDocument) error {
+	return e.engine.IndexTenantDocuments(tenantID, e.withScores(tenantID, documents))
+}
+
+// withScores returns documents with their stored scores, copying only the
+// documents that have one.
+func (e *Engine) withScores(tenantID string, documents []search.TenantDocument) []search.TenantDocument {
+	stored, err := e.scores.ListScoredByTenant(tenantID)
+	if err != nil {
+		logging.Error("failed to load popularity scores", "tenant_id", tenantID, "error", err)
+		return documents
+	}
+	if len(stored) == 0 {
+		return documents
+	}
+	scores := make(map[string]float64, len(stored))
+	for _, score := range stored {
+		scores[score.DocumentID] = score.Score
+	}
+
+	scored := make([]search.TenantDocument, len(documents))
+	for i, document := range documents {
+		scored[i] = document
+		id, ok := document["id"]
+		if !ok {
+			continue
+		}
+		score, ok := scores[search.DocumentID(id)]
+		if !ok {
+			continue
+		}
+		copied := make(search.TenantDocument, len(document)+1)
+		for field, value := range document {
+			copied[field] = value
+		}
+		copied[search.PopularityAttribute] = score
+		scored[i] = copied
+	}
+	return scored
+}
+
+func (e *Engine) SearchTenant(tenantID string, query string, options search.SearchOptions) (search.TenantSearchResponse, error) {
+	return e.engine.SearchTenant(tenantID, query, options)
+}
+
+func (e *Engine) DeleteAllTenantDocuments(tenantID string) error {
+	return e.engine.DeleteAllTenantDocuments(tenantID)
+}
+
+func (e *Engine) DeleteTenantIndex(tenantID string) (int64, error) {
+	return e.engine.DeleteTenantIndex(tenantID)
+}
+
+func (e *Engine) PatchTenantDocuments(tenantID string, patches []search.TenantDocument) error {
+	return e.engine.PatchTenantDocuments(tenantID, patches)
+}
+
+func (e *Engine) DeleteTenantDocuments(tenantID string, ids []string) error {
+	return e.engine.DeleteTenantDocuments(tenantID, ids)
+}
+
+func (e *Engine) ListTenantDocuments(tenantID string, offset, limit int) (search.TenantListResponse, error) {
+	return e.engine.ListTenantDocuments(tenantID, offset, limit)
+}
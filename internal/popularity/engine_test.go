@@ -0,0 +1,50 @@
+package popularity
+
+import (
+	"testing"
+
+	"mini-search-platform/internal/search"
+)
+
+type indexingEngine struct {
+	search.TenantEngine
+	indexed []search.TenantDocument
+}
+
+func (e *indexingEngine) IndexTenantDocuments(tenantID string, documents []search.TenantDocument) error {
+	e.indexed = append(e.indexed, documents...)
+	return nil
+}
+
+func TestEngine_ReindexedDocumentsKeepTheirScores(t *testing.T) {
+	scores := &memoryPopularityRepository{scores: map[documentKey]float64{
+		{tenantID: "tenant-a", documentID: "1"}:       2.5,
+		{tenantID: "tenant-a", documentID: "shoe"}:    4,
+		{tenantID: "tenant-a", documentID: "1234567"}: 7,
+		{tenantID: "tenant-b", documentID: "2"}:       9,
+	}}
+	inner := &indexingEngine{}
+	engine := NewEngine(inner, scores)
+
+	documents := []search.TenantDocument{
+		{"id": 1, "title": "Sandals"},
+		{"id": "shoe", "title": "Shoe"},
+		{"id": 2, "title": "Boots"},
+		{"title": "No id"},
+		// As decoded from a JSON batch.
+		{"id": float64(1234567), "title": "Loafers"},
+	}
+	if err := engine.IndexTenantDocuments("tenant-a", documents); err != nil {
+		t.Fatalf("IndexTenantDocuments failed: %v", err)
+	}
+
+	want := []interface{}{2.5, 4.0, nil, nil, 7.0}
+	for i, document := range inner.indexed {
+		if got := document[search.PopularityAttribute]; got != want[i] {
+			t.Errorf("document %v: expected popularity %v, got %v", document["id"], want[i], got)
+		}
+	}
+	if _, ok := documents[0][search.PopularityAttribute]; ok {
+		t.Error("expected the caller's documents left unmodified")
+	}
+}
@@ -0,0 +1,170 @@
+package popularity
+
+import (
+	"math"
+	"time"
+
+	"mini-search-platform/internal/models"
+	"mini-search-platform/internal/search"
+	"mini-search-platform/pkg/logging"
+)
+
+// DefaultWeights value a purchase over an add-to-cart over a click.
+var DefaultWeights = map[models.SearchEventType]float64{
+	models.EventClick:     1,
+	models.EventAddToCart: 3,
+	models.EventPurchase:  5,
+}
+
+// patchBatchSize bounds how many documents go into one engine update.
+const patchBatchSize = 500
+
+// Scorer turns shopper events into a per-document popularity score and
+// writes it into each tenant's index as search.PopularityAttribute.
+//
+// Each event contributes its type's weight, halved for every halfLife of age,
+// so the score follows current demand rather than accumulating forever.
+// Events older than window are ignored and pruned, which lets a document's
+// score decay back to zero; only scores that changed are written.
+type Scorer struct {
+	events  models.SearchEventRepository
+	scores  models.DocumentPopularityRepository
+	engine  search.TenantDocumentPatcher
+	weights map[models.SearchEventType]float64
+
+	halfLife time.Duration
+	window   time.Duration
+	now      func() time.Time
+}
+
+func NewScorer(
+	events models.SearchEventRepository,
+	scores models.DocumentPopularityRepository,
+	engine search.TenantDocumentPatcher,
+	halfLife, window time.Duration,
+) *Scorer {
+	return &Scorer{
+		events:   events,
+		scores:   scores,
+		engine:   engine,
+		weights:  DefaultWeights,
+		halfLife: halfLife,
+		window:   window,
+		now:      time.Now,
+	}
+}
+
+type documentKey struct {
+	tenantID   string
+	documentID string
+}
+
+// compute returns the current score of every document with events in the
+// window, rounded to three decimals. Events age in whole days, so a
+// document's score only changes when it gets new events or a day passes,
+// rather than on every run.
+func (s *Scorer) compute() (map[documentKey]float64, error) {
+	now := s.now().UTC()
+	counts, err := s.events.DailyCounts(now.Add(-s.window))
+	if err != nil {
+		return nil, err
+	}
+
+	today := now.Truncate(24 * time.Hour)
+	scores := make(map[documentKey]float64)
+	for _, count := range counts {
+		age := max(today.Sub(count.Day), 0)
+		decay := math.Pow(0.5, float64(age)/float64(s.halfLife))
+
+		key := documentKey{tenantID: count.TenantID, documentID: count.DocumentID}
+		scores[key] += s.weights[count.Type] * float64(count.Count) * decay
+	}
+
+	for key, score := range scores {
+		scores[key] = math.Round(score*1000) / 1000
+	}
+	return scores, nil
+}
+
+// Run recomputes every score and writes the changed ones to the index and
+// the popularity table, tenant by tenant. A tenant whose index update fails
+// keeps its stored scores, so the next run retries it.
+func (s *Scorer) Run() error {
+	current, err := s.compute()
+	if err != nil {
+		return err
+	}
+
+	stored, err := s.scores.ListScored()
+	if err != nil {
+		return err
+	}
+	previous := make(map[documentKey]float64, len(stored))
+	for _, score := range stored {
+		key := documentKey{tenantID: score.TenantID, documentID: score.DocumentID}
+		previous[key] = score.Score
+		// Documents that fell out of the window decay to zero.
+		if _, ok := current[key]; !ok {
+			current[key] = 0
+		}
+	}
+
+	updatedAt := s.now().UTC()
+	changed := make(map[string][]*models.DocumentPopularity)
+	for key, score := range current {
+		if previous[key] == score {
+			continue
+		}
+		changed[key.tenantID] = append(changed[key.tenantID], &models.DocumentPopularity{
+			TenantID:   key.tenantID,
+			DocumentID: key.documentID,
+			Score:      score,
+			UpdatedAt:  updatedAt,
+		})
+	}
+
+	for tenantID, scores := range changed {
+		if err := s.apply(tenantID, scores); err != nil {
+			logging.Error("failed to update popularity scores", "tenant_id", tenantID, "error", err)
+		}
+	}
+
+	if _, err := s.events.Prune(updatedAt.Add(-s.window)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *Scorer) apply(tenantID string, scores []*models.DocumentPopularity) error {
+	for start := 0; start < len(scores); start += patchBatchSize {
+		batch := scores[start:min(start+patchBatchSize, len(scores))]
+
+		patches := make([]search.TenantDocument, len(batch))
+		for i, score := range batch {
+			patches[i] = search.TenantDocument{
+				"id":                       score.DocumentID,
+				search.PopularityAttribute: score.Score,
+			}
+		}
+
+		if err := s.engine.PatchTenantDocuments(tenantID, patches); err != nil {
+			return err
+		}
+		if err := s.scores.SaveBatch(batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Start recomputes popularity every interval in the background.
+func (s *Scorer) Start(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			if err := s.Run(); err != nil {
+				logging.Error("failed to compute popularity scores", "error", err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,184 @@
+package popularity
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"mini-search-platform/internal/models"
+	"mini-search-platform/internal/search"
+)
+
+type memoryEventRepository struct {
+	counts []*models.DocumentEventCount
+	pruned time.Time
+}
+
+func (r *memoryEventRepository) SaveBatch(events []*models.SearchEvent) error { return nil }
+
+func (r *memoryEventRepository) DailyCounts(since time.Time) ([]*models.DocumentEventCount, error) {
+	var out []*models.DocumentEventCount
+	for _, count := range r.counts {
+		if !count.Day.Before(since.Truncate(24 * time.Hour)) {
+			out = append(out, count)
+		}
+	}
+	return out, nil
+}
+
+func (r *memoryEventRepository) Prune(olderThan time.Time) (int64, error) {
+	r.pruned = olderThan
+	return 0, nil
+}
+
+type memoryPopularityRepository struct {
+	scores map[documentKey]float64
+}
+
+func (r *memoryPopularityRepository) ListScored() ([]*models.DocumentPopularity, error) {
+	var out []*models.DocumentPopularity
+	for key, score := range r.scores {
+		out = append(out, &models.DocumentPopularity{TenantID: key.tenantID, DocumentID: key.documentID, Score: score})
+	}
+	return out, nil
+}
+
+func (r *memoryPopularityRepository) ListScoredByTenant(tenantID string) ([]*models.DocumentPopularity, error) {
+	var out []*models.DocumentPopularity
+	for key, score := range r.scores {
+		if key.tenantID == tenantID {
+			out = append(out, &models.DocumentPopularity{TenantID: key.tenantID, DocumentID: key.documentID, Score: score})
+		}
+	}
+	return out, nil
+}
+
+func (r *memoryPopularityRepository) SaveBatch(scores []*models.DocumentPopularity) error {
+	for _, score := range scores {
+		key := documentKey{tenantID: score.TenantID, documentID: score.DocumentID}
+		if score.Score <= 0 {
+			delete(r.scores, key)
+		} else {
+			r.scores[key] = score.Score
+		}
+	}
+	return nil
+}
+
+type recordingPatcher struct {
+	patches map[string][]search.TenantDocument
+	fail    bool
+}
+
+func (p *recordingPatcher) PatchTenantDocuments(tenantID string, patches []search.TenantDocument) error {
+	if p.fail {
+		return errors.New("engine unavailable")
+	}
+	p.patches[tenantID] = append(p.patches[tenantID], patches...)
+	return nil
+}
+
+func (p *recordingPatcher) scoreOf(tenantID, documentID string) (float64, bool) {
+	for _, patch := range p.patches[tenantID] {
+		if patch["id"] == documentID {
+			return patch[search.PopularityAttribute].(float64), true
+		}
+	}
+	return 0, false
+}
+
+func newTestScorer(now time.Time, counts ...*models.DocumentEventCount) (*Scorer, *recordingPatcher, *memoryPopularityRepository) {
+	patcher := &recordingPatcher{patches: make(map[string][]search.TenantDocument)}
+	scores := &memoryPopularityRepository{scores: make(map[documentKey]float64)}
+
+	scorer := NewScorer(&memoryEventRepository{counts: counts}, scores, patcher, 7*24*time.Hour, 30*24*time.Hour)
+	scorer.now = func() time.Time { return now }
+	return scorer, patcher, scores
+}
+
+func count(tenantID, documentID string, eventType models.SearchEventType, day time.Time, n int64) *models.DocumentEventCount {
+	return &models.DocumentEventCount{TenantID: tenantID, DocumentID: documentID, Type: eventType, Day: day, Count: n}
+}
+
+func TestScorer_WeightsEventTypesAndDecaysByAge(t *testing.T) {
+	now := time.Date(2026, 3, 15, 10, 0, 0, 0, time.UTC)
+	today := now.Truncate(24 * time.Hour)
+
+	scorer, patcher, _ := newTestScorer(now,
+		count("tenant-a", "dress", models.EventClick, today, 2),
+		count("tenant-a", "dress", models.EventPurchase, today, 1),
+		// One half-life ago: worth half.
+		count("tenant-a", "sandals", models.EventAddToCart, today.Add(-7*24*time.Hour), 2),
+		count("tenant-b", "dress", models.EventClick, today, 1),
+	)
+
+	if err := scorer.Run(); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	for _, tc := range []struct {
+		tenantID, documentID string
+		want                 float64
+	}{
+		{"tenant-a", "dress", 2*1 + 5},
+		{"tenant-a", "sandals", 2 * 3 * 0.5},
+		{"tenant-b", "dress", 1},
+	} {
+		got, ok := patcher.scoreOf(tc.tenantID, tc.documentID)
+		if !ok {
+			t.Errorf("%s/%s: no popularity patch written", tc.tenantID, tc.documentID)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%s/%s: expected score %v, got %v", tc.tenantID, tc.documentID, tc.want, got)
+		}
+	}
+}
+
+func TestScorer_WritesOnlyChangedScoresAndDecaysToZero(t *testing.T) {
+	now := time.Date(2026, 3, 15, 10, 0, 0, 0, time.UTC)
+	today := now.Truncate(24 * time.Hour)
+
+	scorer, patcher, scores := newTestScorer(now, count("tenant-a", "dress", models.EventClick, today, 1))
+	scores.scores[documentKey{"tenant-a", "retired"}] = 4
+
+	if err := scorer.Run(); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if got, ok := patcher.scoreOf("tenant-a", "retired"); !ok || got != 0 {
+		t.Errorf("a document with no events left should be reset to 0, got %v (written=%v)", got, ok)
+	}
+	if _, ok := scores.scores[documentKey{"tenant-a", "retired"}]; ok {
+		t.Error("a zeroed score should be removed from the popularity table")
+	}
+
+	patcher.patches = make(map[string][]search.TenantDocument)
+	if err := scorer.Run(); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(patcher.patches) != 0 {
+		t.Errorf("unchanged scores must not be rewritten, got %v", patcher.patches)
+	}
+}
+
+func TestScorer_FailedPatchIsRetriedNextRun(t *testing.T) {
+	now := time.Date(2026, 3, 15, 10, 0, 0, 0, time.UTC)
+
+	scorer, patcher, scores := newTestScorer(now, count("tenant-a", "dress", models.EventClick, now.Truncate(24*time.Hour), 1))
+
+	patcher.fail = true
+	if err := scorer.Run(); err != nil {
+		t.Fatalf("Run should log, not fail, on a tenant's patch error: %v", err)
+	}
+	if len(scores.scores) != 0 {
+		t.Fatal("scores must not be stored when the index update failed")
+	}
+
+	patcher.fail = false
+	if err := scorer.Run(); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if got, ok := patcher.scoreOf("tenant-a", "dress"); !ok || got != 1 {
+		t.Errorf("expected the score to be written on retry, got %v (written=%v)", got, ok)
+	}
+}
@@ -0,0 +1,152 @@
+// Package reindex rebuilds the public articles index from SQLite, for
+// recovering from a wiped index or applying new index settings.
+package reindex
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"mini-search-platform/internal/models"
+	"mini-search-platform/internal/search"
+)
+
+// Articles is implemented by adapters.SQLliteArticleRepository.
+type Articles interface {
+	Count() (int, error)
+	ListAfterID(afterID, limit int) ([]*models.Article, error)
+}
+
+// Engine is implemented by adapters.MeilisearchEngine.
+type Engine interface {
+	IndexArticles(articles []*models.Article) error
+	WaitForArticleTasks(ctx context.Context) error
+	CountArticles() (int64, error)
+}
+
+// Progress is reported after each page.
+type Progress struct {
+	Indexed       int
+	Total         int
+	LastArticleID int
+	Elapsed       time.Duration
+}
+
+// Result summarizes a finished reindex. IndexCount is the number of
+// documents in the index once the engine processed every batch; it equals
+// ArticleCount unless the engine rejected documents or the index holds
+// documents of articles deleted from SQLite.
+type Result struct {
+	Indexed      int
+	ResumedAfter int
+	ArticleCount int
+	IndexCount   int64
+}
+
+func (r Result) CountsMatch() bool {
+	return int64(r.ArticleCount) == r.IndexCount
+}
+
+// Reindexer pages through every article in ID order and hands them to the
+// engine in batches of at most BatchSize. After each page it saves a
+// checkpoint, so a run that is interrupted (or fails) resumes after the
+// last page the engine accepted instead of starting over.
+type Reindexer struct {
+	articles    Articles
+	engine      Engine
+	checkpoints models.ReindexCheckpointRepository
+
+	PageSize   int
+	BatchSize  int
+	OnProgress func(Progress)
+
+	now func() time.Time
+}
+
+func New(articles Articles, engine Engine, checkpoints models.ReindexCheckpointRepository) *Reindexer {
+	return &Reindexer{
+		articles:    articles,
+		engine:      engine,
+		checkpoints: checkpoints,
+		PageSize:    1000,
+		BatchSize:   250,
+		now:         time.Now,
+	}
+}
+
+// Run reindexes every article not yet covered by the checkpoint, then waits
+// for the engine and compares counts. It stops between pages when ctx is
+// done, keeping the checkpoint for the next run; the checkpoint is removed
+// once every page is indexed.
+func (r *Reindexer) Run(ctx context.Context) (Result, error) {
+	var result Result
+
+	checkpoint, err := r.checkpoints.Load(search.ARTICLES_INDEX_NAME)
+	if err != nil {
+		return result, fmt.Errorf("loading checkpoint: %w", err)
+	}
+	if checkpoint == nil {
+		now := r.now().UTC()
+		checkpoint = &models.ReindexCheckpoint{IndexName: search.ARTICLES_INDEX_NAME, StartedAt: now, UpdatedAt: now}
+	}
+	result.ResumedAfter = checkpoint.LastArticleID
+
+	total, err := r.articles.Count()
+	if err != nil {
+		return result, fmt.Errorf("counting articles: %w", err)
+	}
+
+	started := r.now()
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		page, err := r.articles.ListAfterID(checkpoint.LastArticleID, r.PageSize)
+		if err != nil {
+			return result, fmt.Errorf("listing articles after %d: %w", checkpoint.LastArticleID, err)
+		}
+		if len(page) == 0 {
+			break
+		}
+
+		for start := 0; start < len(page); start += r.BatchSize {
+			batch := page[start:min(start+r.BatchSize, len(page))]
+			if err := r.engine.IndexArticles(batch); err != nil {
+				return result, fmt.Errorf("indexing articles %d-%d: %w", batch[0].ID, batch[len(batch)-1].ID, err)
+			}
+		}
+
+		checkpoint.LastArticleID = page[len(page)-1].ID
+		checkpoint.Indexed += len(page)
+		checkpoint.UpdatedAt = r.now().UTC()
+		if err := r.checkpoints.Save(checkpoint); err != nil {
+			return result, fmt.Errorf("saving checkpoint: %w", err)
+		}
+		result.Indexed += len(page)
+
+		if r.OnProgress != nil {
+			r.OnProgress(Progress{
+				Indexed:       checkpoint.Indexed,
+				Total:         total,
+				LastArticleID: checkpoint.LastArticleID,
+				Elapsed:       r.now().Sub(started),
+			})
+		}
+	}
+
+	if err := r.checkpoints.Delete(search.ARTICLES_INDEX_NAME); err != nil {
+		return result, fmt.Errorf("removing checkpoint: %w", err)
+	}
+
+	if err := r.engine.WaitForArticleTasks(ctx); err != nil {
+		return result, fmt.Errorf("waiting for indexing: %w", err)
+	}
+	if result.ArticleCount, err = r.articles.Count(); err != nil {
+		return result, fmt.Errorf("counting articles: %w", err)
+	}
+	if result.IndexCount, err = r.engine.CountArticles(); err != nil {
+		return result, fmt.Errorf("counting indexed articles: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,146 @@
+package reindex
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"mini-search-platform/internal/models"
+)
+
+type memoryArticles struct {
+	ids []int
+}
+
+func (a *memoryArticles) Count() (int, error) { return len(a.ids), nil }
+
+func (a *memoryArticles) ListAfterID(afterID, limit int) ([]*models.Article, error) {
+	var page []*models.Article
+	for _, id := range a.ids {
+		if id > afterID && len(page) < limit {
+			page = append(page, &models.Article{ID: id})
+		}
+	}
+	return page, nil
+}
+
+type recordingEngine struct {
+	batches   [][]int
+	failAfter int // fail every call after this many batches; 0 never fails
+	documents map[int]bool
+}
+
+func (e *recordingEngine) IndexArticles(articles []*models.Article) error {
+	if e.failAfter > 0 && len(e.batches) >= e.failAfter {
+		return errors.New("meilisearch unavailable")
+	}
+	ids := make([]int, len(articles))
+	for i, a := range articles {
+		ids[i] = a.ID
+		e.documents[a.ID] = true
+	}
+	e.batches = append(e.batches, ids)
+	return nil
+}
+
+func (e *recordingEngine) WaitForArticleTasks(ctx context.Context) error { return nil }
+
+func (e *recordingEngine) CountArticles() (int64, error) { return int64(len(e.documents)), nil }
+
+type memoryCheckpoints struct {
+	checkpoints map[string]models.ReindexCheckpoint
+}
+
+func (c *memoryCheckpoints) Load(indexName string) (*models.ReindexCheckpoint, error) {
+	if checkpoint, ok := c.checkpoints[indexName]; ok {
+		return &checkpoint, nil
+	}
+	return nil, nil
+}
+
+func (c *memoryCheckpoints) Save(checkpoint *models.ReindexCheckpoint) error {
+	c.checkpoints[checkpoint.IndexName] = *checkpoint
+	return nil
+}
+
+func (c *memoryCheckpoints) Delete(indexName string) error {
+	delete(c.checkpoints, indexName)
+	return nil
+}
+
+func TestReindexer_ResumesFromCheckpointAfterFailure(t *testing.T) {
+	articles := &memoryArticles{ids: []int{1, 2, 3, 5, 8, 13, 21}}
+	engine := &recordingEngine{failAfter: 3, documents: make(map[int]bool)}
+	checkpoints := &memoryCheckpoints{checkpoints: make(map[string]models.ReindexCheckpoint)}
+
+	r := New(articles, engine, checkpoints)
+	r.PageSize = 3
+	r.BatchSize = 2
+	var progress []Progress
+	r.OnProgress = func(p Progress) { progress = append(progress, p) }
+
+	// Pages [1 2 3] and [5 8 13] are split into batches [1 2] [3] and
+	// [5 8] [13]; the engine fails on [13], so only the first page counts.
+	result, err := r.Run(context.Background())
+	if err == nil {
+		t.Fatal("expected the engine failure to stop the run")
+	}
+	if result.Indexed != 3 || len(progress) != 1 || progress[0].Indexed != 3 || progress[0].Total != 7 {
+		t.Fatalf("expected one page to be indexed before the failure, got %+v, %+v", result, progress)
+	}
+	if checkpoint, _ := checkpoints.Load("articles"); checkpoint == nil || checkpoint.LastArticleID != 3 {
+		t.Fatalf("expected a checkpoint after article 3, got %+v", checkpoint)
+	}
+
+	engine.failAfter = 0
+	engine.batches = nil
+	result, err = r.Run(context.Background())
+	if err != nil {
+		t.Fatalf("resumed Run failed: %v", err)
+	}
+	if result.ResumedAfter != 3 || result.Indexed != 4 {
+		t.Errorf("expected the run to resume after article 3, got %+v", result)
+	}
+	if len(engine.batches) != 3 || engine.batches[0][0] != 5 {
+		t.Errorf("expected only the remaining articles to be sent, got %v", engine.batches)
+	}
+	if !result.CountsMatch() || result.ArticleCount != 7 {
+		t.Errorf("expected matching counts, got %+v", result)
+	}
+	if checkpoint, _ := checkpoints.Load("articles"); checkpoint != nil {
+		t.Errorf("a finished run must remove its checkpoint, got %+v", checkpoint)
+	}
+}
+
+func TestReindexer_ReportsCountMismatch(t *testing.T) {
+	articles := &memoryArticles{ids: []int{1, 2}}
+	// The index still holds a document for a deleted article.
+	engine := &recordingEngine{documents: map[int]bool{99: true}}
+	checkpoints := &memoryCheckpoints{checkpoints: make(map[string]models.ReindexCheckpoint)}
+
+	result, err := New(articles, engine, checkpoints).Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.CountsMatch() || result.ArticleCount != 2 || result.IndexCount != 3 {
+		t.Errorf("expected a count mismatch, got %+v", result)
+	}
+}
+
+func TestReindexer_StopsWhenCanceled(t *testing.T) {
+	articles := &memoryArticles{ids: []int{1, 2, 3, 4}}
+	engine := &recordingEngine{documents: make(map[int]bool)}
+	checkpoints := &memoryCheckpoints{checkpoints: make(map[string]models.ReindexCheckpoint)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := New(articles, engine, checkpoints)
+	r.PageSize = 2
+	r.OnProgress = func(Progress) { cancel() }
+
+	if _, err := r.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the run to stop on cancel, got %v", err)
+	}
+	if checkpoint, _ := checkpoints.Load("articles"); checkpoint == nil || checkpoint.LastArticleID != 2 {
+		t.Errorf("expected the checkpoint to be kept, got %+v", checkpoint)
+	}
+}
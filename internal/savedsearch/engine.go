@@ -0,0 +1,49 @@
+package savedsearch
+
+import (
+	"mini-search-platform/internal/search"
+)
+
+// NotifyingEngine decorates a search.TenantEngine so every successful
+// IndexTenantDocuments call hands the indexed documents to a Notifier.
+// Everything else passes through unchanged.
+type NotifyingEngine struct {
+	engine   search.TenantEngine
+	notifier *Notifier
+}
+
+func NewNotifyingEngine(engine search.TenantEngine, notifier *Notifier) *NotifyingEngine {
+	return &NotifyingEngine{engine: engine, notifier: notifier}
+}
+
+func (e *NotifyingEngine) IndexTenantDocuments(tenantID string, documents []search.TenantDocument) error {
+	if err := e.engine.IndexTenantDocuments(tenantID, documents); err != nil {
+		return err
+	}
+	e.notifier.Enqueue(tenantID, documents)
+	return nil
+}
+
+func (e *NotifyingEngine) SearchTenant(tenantID string, query string, options search.SearchOptions) (search.TenantSearchResponse, error) {
+	return e.engine.SearchTenant(tenantID, query, options)
+}
+
+func (e *NotifyingEngine) DeleteAllTenantDocuments(tenantID string) error {
+	return e.engine.DeleteAllTenantDocuments(tenantID)
+}
+
+func (e *NotifyingEngine) DeleteTenantIndex(tenantID string) (int64, error) {
+	return e.engine.DeleteTenantIndex(tenantID)
+}
+
+func (e *NotifyingEngine) PatchTenantDocuments(tenantID string, patches []search.TenantDocument) error {
+	return e.engine.PatchTenantDocuments(tenantID, patches)
+}
+
+func (e *NotifyingEngine) DeleteTenantDocuments(tenantID string, ids []string) error {
+	return e.engine.DeleteTenantDocuments(tenantID, ids)
+}
+
+func (e *NotifyingEngine) ListTenantDocuments(tenantID string, offset, limit int) (search.TenantListResponse, error) {
+	return e.engine.ListTenantDocuments(tenantID, offset, limit)
+}
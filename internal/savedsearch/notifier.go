@@ -0,0 +1,190 @@
+package savedsearch
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"mini-search-platform/internal/models"
+	"mini-search-platform/internal/search"
+	"mini-search-platform/internal/webhooks"
+	"mini-search-platform/pkg/logging"
+)
+
+// EventMatches is the webhook event announcing new saved search matches.
+const EventMatches = "saved_search.matches"
+
+const (
+	// matchBatchSize bounds both the ids in one matching search's filter and
+	// the documents listed in one webhook delivery.
+	matchBatchSize = 100
+	indexWaitLimit = time.Minute
+)
+
+// Engine is what the notifier needs from the search engine: searching, and
+// waiting until freshly indexed documents are searchable.
+type Engine interface {
+	SearchTenant(tenantID string, query string, options search.SearchOptions) (search.TenantSearchResponse, error)
+	search.TenantTaskWaiter
+}
+
+// MatchesPayload is the body of an EventMatches webhook.
+type MatchesPayload struct {
+	Event       string                  `json:"event"`
+	SavedSearch *models.SavedSearch     `json:"saved_search"`
+	Matches     []search.TenantDocument `json:"matches"`
+	OccurredAt  time.Time               `json:"occurred_at"`
+}
+
+type job struct {
+	tenantID    string
+	documentIDs []string
+}
+
+// Notifier evaluates a tenant's saved searches against the documents it has
+// just indexed and sends a webhook listing the new matches of each. A
+// document is announced the first time it matches a saved search only, so
+// re-indexing (or a reset and full reindex) does not repeat notifications.
+// Matches are recorded as announced once their delivery is queued, so a
+// failure in between announces them again rather than never.
+//
+// Matching runs off the indexing request path: Enqueue queues the indexed
+// IDs and the worker started by Start waits for the engine to make them
+// searchable, then runs each saved search restricted to them. Jobs are
+// dropped, and counted, while the queue is full.
+type Notifier struct {
+	engine     Engine
+	searches   models.SavedSearchRepository
+	dispatcher *webhooks.Dispatcher
+	jobs       chan job
+	dropped    atomic.Int64
+	now        func() time.Time
+}
+
+func NewNotifier(engine Engine, searches models.SavedSearchRepository, dispatcher *webhooks.Dispatcher, queueSize int) *Notifier {
+	return &Notifier{
+		engine:     engine,
+		searches:   searches,
+		dispatcher: dispatcher,
+		jobs:       make(chan job, queueSize),
+		now:        time.Now,
+	}
+}
+
+// Enqueue queues the documents just indexed for the tenant.
+func (n *Notifier) Enqueue(tenantID string, documents []search.TenantDocument) {
+	ids := make([]string, 0, len(documents))
+	for _, doc := range documents {
+		if id, ok := doc["id"]; ok {
+			ids = append(ids, search.DocumentID(id))
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	select {
+	case n.jobs <- job{tenantID: tenantID, documentIDs: ids}:
+	default:
+		dropped := n.dropped.Add(1)
+		logging.Warn("saved search queue is full, dropping indexed documents",
+			"tenant_id", tenantID, "documents", len(ids), "dropped_jobs", dropped)
+	}
+}
+
+// Dropped returns how many jobs were dropped because the queue was full.
+func (n *Notifier) Dropped() int64 {
+	return n.dropped.Load()
+}
+
+// Start runs the matching worker.
+func (n *Notifier) Start() {
+	go func() {
+		for j := range n.jobs {
+			if err := n.process(j); err != nil {
+				logging.Error("failed to evaluate saved searches", "tenant_id", j.tenantID, "error", err)
+			}
+		}
+	}()
+}
+
+func (n *Notifier) process(j job) error {
+	savedSearches, err := n.searches.ListByTenant(j.tenantID)
+	if err != nil {
+		return err
+	}
+	if len(savedSearches) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), indexWaitLimit)
+	defer cancel()
+	if err := n.engine.WaitForTenantTasks(ctx, j.tenantID); err != nil {
+		return fmt.Errorf("waiting for indexing: %w", err)
+	}
+
+	// One saved search failing (e.g. on a filter the engine rejects) must
+	// not hold back the others.
+	for _, savedSearch := range savedSearches {
+		if err := n.notify(savedSearch, j.documentIDs); err != nil {
+			logging.Error("failed to evaluate saved search",
+				"tenant_id", j.tenantID, "saved_search_id", savedSearch.ID, "error", err)
+		}
+	}
+	return nil
+}
+
+func (n *Notifier) notify(savedSearch *models.SavedSearch, documentIDs []string) error {
+	for start := 0; start < len(documentIDs); start += matchBatchSize {
+		batch := documentIDs[start:min(start+matchBatchSize, len(documentIDs))]
+
+		result, err := n.engine.SearchTenant(savedSearch.TenantID, savedSearch.Query, search.SearchOptions{
+			Limit:  len(batch),
+			Filter: search.AndFilters(savedSearch.Filter, search.IDInFilter(batch)),
+			Sort:   savedSearch.Sort,
+		})
+		if err != nil {
+			return err
+		}
+
+		matchedIDs := make([]string, len(result.Hits))
+		for i, hit := range result.Hits {
+			matchedIDs[i] = search.DocumentID(hit["id"])
+		}
+		unseen, err := n.searches.UnseenMatches(savedSearch.ID, matchedIDs)
+		if err != nil {
+			return err
+		}
+		if len(unseen) == 0 {
+			continue
+		}
+
+		isNew := make(map[string]bool, len(unseen))
+		for _, id := range unseen {
+			isNew[id] = true
+		}
+		var matches []search.TenantDocument
+		for i, hit := range result.Hits {
+			if isNew[matchedIDs[i]] {
+				matches = append(matches, hit)
+			}
+		}
+
+		_, err = n.dispatcher.Enqueue(savedSearch.TenantID, savedSearch.ID, EventMatches, savedSearch.WebhookURL, savedSearch.Secret, MatchesPayload{
+			Event:       EventMatches,
+			SavedSearch: savedSearch,
+			Matches:     matches,
+			OccurredAt:  n.now().UTC(),
+		})
+		if err != nil {
+			return err
+		}
+		// Only once the delivery is queued: recording the matches first
+		// would lose them if it were not.
+		if err := n.searches.RecordMatches(savedSearch.ID, unseen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,262 @@
+package savedsearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"mini-search-platform/internal/models"
+	"mini-search-platform/internal/search"
+	"mini-search-platform/internal/webhooks"
+)
+
+// catalogEngine matches documents whose title contains the query, among
+// those whose quoted id appears in the filter (the notifier always filters
+// on the indexed ids).
+type catalogEngine struct {
+	docs    []search.TenantDocument
+	waited  int
+	filters []string
+}
+
+func (e *catalogEngine) SearchTenant(tenantID, query string, options search.SearchOptions) (search.TenantSearchResponse, error) {
+	e.filters = append(e.filters, options.Filter)
+	hits := []search.TenantDocument{}
+	for _, doc := range e.docs {
+		title := strings.ToLower(doc["title"].(string))
+		if strings.Contains(title, query) && strings.Contains(options.Filter, `"`+search.DocumentID(doc["id"])+`"`) {
+			hits = append(hits, doc)
+		}
+	}
+	return search.TenantSearchResponse{Query: query, Hits: hits, Total: len(hits)}, nil
+}
+
+func (e *catalogEngine) WaitForTenantTasks(ctx context.Context, tenantID string) error {
+	e.waited++
+	return nil
+}
+
+type memorySavedSearchRepository struct {
+	searches []*models.SavedSearch
+	matches  map[string]bool
+}
+
+func (r *memorySavedSearchRepository) Save(s *models.SavedSearch) error   { return nil }
+func (r *memorySavedSearchRepository) Update(s *models.SavedSearch) error { return nil }
+func (r *memorySavedSearchRepository) Delete(tenantID, id string) error   { return nil }
+func (r *memorySavedSearchRepository) FindByID(tenantID, id string) (*models.SavedSearch, error) {
+	return nil, nil
+}
+
+func (r *memorySavedSearchRepository) ListByTenant(tenantID string) ([]*models.SavedSearch, error) {
+	var list []*models.SavedSearch
+	for _, s := range r.searches {
+		if s.TenantID == tenantID {
+			list = append(list, s)
+		}
+	}
+	return list, nil
+}
+
+func (r *memorySavedSearchRepository) UnseenMatches(savedSearchID string, documentIDs []string) ([]string, error) {
+	var unseen []string
+	for _, id := range documentIDs {
+		if !r.matches[savedSearchID+"/"+id] {
+			unseen = append(unseen, id)
+		}
+	}
+	return unseen, nil
+}
+
+func (r *memorySavedSearchRepository) RecordMatches(savedSearchID string, documentIDs []string) error {
+	for _, id := range documentIDs {
+		r.matches[savedSearchID+"/"+id] = true
+	}
+	return nil
+}
+
+type memoryDeliveryRepository struct {
+	deliveries []*models.WebhookDelivery
+	failing    bool
+}
+
+func (r *memoryDeliveryRepository) Save(d *models.WebhookDelivery) error {
+	if r.failing {
+		return errors.New("database unavailable")
+	}
+	r.deliveries = append(r.deliveries, d)
+	return nil
+}
+
+func (r *memoryDeliveryRepository) Update(d *models.WebhookDelivery) error { return nil }
+
+func (r *memoryDeliveryRepository) ListDue(now time.Time, limit int) ([]*models.WebhookDelivery, error) {
+	var due []*models.WebhookDelivery
+	for _, d := range r.deliveries {
+		if d.Status == models.DeliveryPending && !d.NextAttemptAt.After(now) {
+			due = append(due, d)
+		}
+	}
+	return due, nil
+}
+
+func (r *memoryDeliveryRepository) ListBySource(tenantID, sourceID string, limit int) ([]*models.WebhookDelivery, error) {
+	return nil, nil
+}
+
+func (r *memoryDeliveryRepository) DeadLetter(d *models.WebhookDelivery) error { return nil }
+
+func (r *memoryDeliveryRepository) ListDeadLetters(tenantID string, limit int) ([]*models.WebhookDeadLetter, error) {
+	return nil, nil
+}
+
+func (r *memoryDeliveryRepository) FindDeadLetter(tenantID, id string) (*models.WebhookDeadLetter, error) {
+	return nil, nil
+}
+
+func (r *memoryDeliveryRepository) DeleteDeadLetter(tenantID, id string) error { return nil }
+
+// receiver is a local webhook endpoint that verifies signatures and keeps
+// the payloads it accepted.
+type receiver struct {
+	t        *testing.T
+	secret   string
+	mu       sync.Mutex
+	payloads []MatchesPayload
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	if err := webhooks.Verify(rc.secret, r.Header.Get(webhooks.SignatureHeader), body, time.Minute, time.Now()); err != nil {
+		rc.t.Errorf("webhook signature did not verify: %v", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var payload MatchesPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		rc.t.Errorf("invalid payload %s: %v", body, err)
+	}
+	rc.mu.Lock()
+	rc.payloads = append(rc.payloads, payload)
+	rc.mu.Unlock()
+}
+
+func matchedIDs(payload MatchesPayload) []string {
+	ids := make([]string, len(payload.Matches))
+	for i, doc := range payload.Matches {
+		ids[i] = search.DocumentID(doc["id"])
+	}
+	return ids
+}
+
+func TestNotifier_SendsOnlyNewMatchesOfEachSavedSearch(t *testing.T) {
+	rc := &receiver{t: t, secret: "whsec_blazer"}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	engine := &catalogEngine{docs: []search.TenantDocument{
+		{"id": float64(1), "title": "Linen blazer"},
+		{"id": float64(2), "title": "Wool blazer"},
+		{"id": float64(3), "title": "Linen shirt"},
+		{"id": float64(12345678), "title": "Linen blazer, navy"},
+	}}
+	searches := &memorySavedSearchRepository{
+		matches: make(map[string]bool),
+		searches: []*models.SavedSearch{
+			{ID: "blazers", TenantID: "tenant-a", Query: "linen blazer", Filter: "price < 200", WebhookURL: server.URL, Secret: "whsec_blazer"},
+			{ID: "coats", TenantID: "tenant-a", Query: "coat", WebhookURL: server.URL, Secret: "whsec_blazer"},
+			{ID: "other-tenant", TenantID: "tenant-b", Query: "linen", WebhookURL: server.URL, Secret: "whsec_blazer"},
+		},
+	}
+	dispatcher := webhooks.NewDispatcher(&memoryDeliveryRepository{}, 5*time.Second, 3, 1)
+	dispatcher.AllowPrivateNetworks()
+	notifier := NewNotifier(engine, searches, dispatcher, 10)
+
+	indexed := []search.TenantDocument{engine.docs[0], engine.docs[1], engine.docs[2]}
+	if err := notifier.process(job{tenantID: "tenant-a", documentIDs: []string{"1", "2", "3"}}); err != nil {
+		t.Fatalf("process failed: %v", err)
+	}
+	if err := dispatcher.DeliverDue(); err != nil {
+		t.Fatalf("DeliverDue failed: %v", err)
+	}
+
+	if engine.waited != 1 {
+		t.Errorf("expected the notifier to wait for indexing once, got %d", engine.waited)
+	}
+	if want := `(price < 200) AND (id IN ["1", "2", "3"])`; engine.filters[0] != want {
+		t.Errorf("expected the saved filter restricted to the indexed ids %q, got %q", want, engine.filters[0])
+	}
+	if len(rc.payloads) != 1 {
+		t.Fatalf("expected one webhook (coats matched nothing), got %d", len(rc.payloads))
+	}
+	first := rc.payloads[0]
+	if first.Event != EventMatches || first.SavedSearch.ID != "blazers" || strings.Join(matchedIDs(first), ",") != "1" {
+		t.Errorf("unexpected payload %+v", first)
+	}
+
+	// Re-indexing a known match together with a new one only announces the
+	// new one.
+	indexed = append(indexed, engine.docs[3])
+	notifier.Enqueue("tenant-a", indexed)
+	notifier.process(<-notifier.jobs)
+	dispatcher.DeliverDue()
+
+	if len(rc.payloads) != 2 {
+		t.Fatalf("expected a second webhook, got %d", len(rc.payloads))
+	}
+	if got := strings.Join(matchedIDs(rc.payloads[1]), ","); got != "12345678" {
+		t.Errorf("expected only the new match, got %s", got)
+	}
+
+	notifier.Enqueue("tenant-a", indexed)
+	notifier.process(<-notifier.jobs)
+	dispatcher.DeliverDue()
+	if len(rc.payloads) != 2 {
+		t.Errorf("re-indexing known matches must not notify again, got %d webhooks", len(rc.payloads))
+	}
+}
+
+func TestNotifier_MatchesAreAnnouncedLaterWhenTheDeliveryFailsToQueue(t *testing.T) {
+	engine := &catalogEngine{docs: []search.TenantDocument{{"id": "1", "title": "Linen blazer"}}}
+	searches := &memorySavedSearchRepository{
+		matches:  make(map[string]bool),
+		searches: []*models.SavedSearch{{ID: "blazers", TenantID: "tenant-a", Query: "linen", WebhookURL: "https://buyer.example.com/hooks"}},
+	}
+	deliveries := &memoryDeliveryRepository{failing: true}
+	notifier := NewNotifier(engine, searches, webhooks.NewDispatcher(deliveries, time.Second, 3, 1), 10)
+
+	if err := notifier.notify(searches.searches[0], []string{"1"}); err == nil {
+		t.Fatal("expected the failed enqueue to be reported")
+	}
+	if len(searches.matches) != 0 {
+		t.Fatalf("expected no match recorded without a delivery, got %v", searches.matches)
+	}
+
+	deliveries.failing = false
+	if err := notifier.notify(searches.searches[0], []string{"1"}); err != nil {
+		t.Fatalf("notify failed: %v", err)
+	}
+	if len(deliveries.deliveries) != 1 || !searches.matches["blazers/1"] {
+		t.Errorf("expected the match announced and recorded on the next run, got %d deliveries and %v", len(deliveries.deliveries), searches.matches)
+	}
+}
+
+func TestNotifier_DropsJobsWhenTheQueueIsFull(t *testing.T) {
+	notifier := NewNotifier(&catalogEngine{}, &memorySavedSearchRepository{}, nil, 1)
+
+	notifier.Enqueue("tenant-a", []search.TenantDocument{{"id": "a"}})
+	notifier.Enqueue("tenant-a", []search.TenantDocument{{"id": "b"}})
+	notifier.Enqueue("tenant-a", []search.TenantDocument{{"title": "no id"}})
+
+	if notifier.Dropped() != 1 || len(notifier.jobs) != 1 {
+		t.Errorf("expected one queued and one dropped job, got %d queued, %d dropped", len(notifier.jobs), notifier.Dropped())
+	}
+}
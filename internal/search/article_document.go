@@ -0,0 +1,84 @@
+package search
+
+import (
+	"strconv"
+	"strings"
+
+	"mini-search-platform/internal/models"
+)
+
+// ArticleDocument is how an article is stored in the articles index and,
+// with another ID (see TenantArticleDocuments), in its tenant's index. Each
+// tag also carries its path as hierarchical facet levels: lvl0 is the
+// root's label, lvl1 the root's and the next label joined with
+// models.TagPathSeparator, and so on down to the tag. The index flattens
+// them into tags.lvl0, tags.lvl1, ..., which can be filtered and faceted
+// on, since tags is filterable.
+func ArticleDocument(article *models.Article) TenantDocument {
+	tags := make([]map[string]interface{}, 0, len(article.Tags))
+	for _, tag := range article.Tags {
+		if tag == nil {
+			continue
+		}
+		path := tag.Path
+		if len(path) == 0 {
+			path = []string{tag.Label}
+		}
+		document := map[string]interface{}{
+			"id":         tag.ID,
+			"label":      tag.Label,
+			"parent_id":  tag.ParentID,
+			"path":       path,
+			"created_at": tag.CreatedAt,
+			"updated_at": tag.UpdatedAt,
+		}
+		for level := range path {
+			document["lvl"+strconv.Itoa(level)] = strings.Join(path[:level+1], models.TagPathSeparator)
+		}
+		tags = append(tags, document)
+	}
+
+	document := TenantDocument{
+		"id":         article.ID,
+		"title":      article.Title,
+		"body":       article.Body,
+		"author":     article.Author,
+		"author_id":  article.AuthorID,
+		"created_at": article.CreatedAt,
+		"tags":       tags,
+	}
+	if article.TenantID != "" {
+		document["tenant_id"] = article.TenantID
+	}
+	if article.ProjectID != "" {
+		document["project_id"] = article.ProjectID
+	}
+	return document
+}
+
+func ArticleDocuments(articles []*models.Article) []TenantDocument {
+	documents := make([]TenantDocument, len(articles))
+	for i, article := range articles {
+		documents[i] = ArticleDocument(article)
+	}
+	return documents
+}
+
+// TenantArticleDocumentID is the ID of an article's document in its
+// tenant's and project's indexes. Those indexes also hold the tenant's
+// catalog documents, whose IDs the tenant chooses, so an article's ID is
+// prefixed to keep article 5 from replacing catalog document "5".
+func TenantArticleDocumentID(articleID int) string {
+	return "article-" + strconv.Itoa(articleID)
+}
+
+// TenantArticleDocuments returns the articles' documents for their tenant's
+// and project's indexes: ArticleDocument's, with TenantArticleDocumentID's
+// IDs.
+func TenantArticleDocuments(articles []*models.Article) []TenantDocument {
+	documents := ArticleDocuments(articles)
+	for i, article := range articles {
+		documents[i]["id"] = TenantArticleDocumentID(article.ID)
+	}
+	return documents
+}
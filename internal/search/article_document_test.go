@@ -0,0 +1,52 @@
+package search
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"mini-search-platform/internal/models"
+)
+
+func TestArticleDocuments_AddTagHierarchyLevels(t *testing.T) {
+	parent := 1
+	documents := ArticleDocuments([]*models.Article{{
+		ID:    7,
+		Title: "Maxi dresses for summer",
+		Tags: []*models.Tag{
+			{ID: 3, Label: "Maxi", ParentID: &parent, Path: []string{"Clothing", "Dresses", "Maxi"}},
+			{ID: 4, Label: "summer"},
+		},
+	}})
+
+	raw, err := json.Marshal(documents)
+	if err != nil {
+		t.Fatalf("failed to marshal documents: %v", err)
+	}
+	var decoded []map[string]interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatalf("failed to unmarshal documents: %v", err)
+	}
+
+	if len(decoded) != 1 || decoded[0]["id"] != float64(7) || decoded[0]["title"] != "Maxi dresses for summer" {
+		t.Fatalf("expected the article's fields, got %v", decoded)
+	}
+	tags := decoded[0]["tags"].([]interface{})
+	maxi := tags[0].(map[string]interface{})
+	for level, want := range []string{"Clothing", "Clothing > Dresses", "Clothing > Dresses > Maxi"} {
+		if got := maxi["lvl"+strconv.Itoa(level)]; got != want {
+			t.Errorf("expected lvl%d %q, got %v", level, want, got)
+		}
+	}
+	if _, ok := maxi["lvl3"]; ok {
+		t.Errorf("expected no level past the tag, got %v", maxi)
+	}
+	if maxi["label"] != "Maxi" || maxi["parent_id"] != float64(1) {
+		t.Errorf("expected the tag's fields, got %v", maxi)
+	}
+
+	summer := tags[1].(map[string]interface{})
+	if summer["lvl0"] != "summer" || summer["path"].([]interface{})[0] != "summer" {
+		t.Errorf("expected a root tag to be its own level 0, got %v", summer)
+	}
+}
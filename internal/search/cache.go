@@ -0,0 +1,285 @@
+package search
+
+import (
+	"container/list"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"mini-search-platform/internal/analytics"
+)
+
+// CacheStats reports one tenant's search cache effectiveness.
+type CacheStats struct {
+	Hits    int64   `json:"hits"`
+	Misses  int64   `json:"misses"`
+	HitRate float64 `json:"hit_rate"`
+	Entries int     `json:"entries"`
+	Bytes   int64   `json:"bytes"`
+}
+
+type cacheEntry struct {
+	key       string
+	tenantID  string
+	response  TenantSearchResponse
+	size      int64
+	expiresAt time.Time
+}
+
+// tenantCacheState is dropped as soon as the tenant has nothing cached, so
+// the cache only holds state for tenants with live entries; its hit and miss
+// counters cover the time since.
+type tenantCacheState struct {
+	// generation is unique to this state and replaced on every
+	// invalidation, so a search that started before a write cannot store
+	// its (now stale) result after it, even in a state created since.
+	generation uint64
+	entries    map[string]*list.Element
+	bytes      int64
+	hits       int64
+	misses     int64
+}
+
+// CachingEngine decorates a TenantEngine with a tenant-scoped search result
+// cache. Entries expire after a TTL, the cache as a whole is bounded by a
+// byte budget (least recently used entries are evicted first), and every
+// write to a tenant's index drops that tenant's entries.
+//
+// Meilisearch applies writes asynchronously, so a search that runs between a
+// write being enqueued and its task finishing can still cache pre-write
+// results; keep the TTL short enough that this window is harmless.
+//
+// Cached responses share their hit maps between callers; callers must treat
+// them as read-only.
+type CachingEngine struct {
+	engine   TenantEngine
+	ttl      time.Duration
+	maxBytes int64
+	now      func() time.Time
+
+	mu          sync.Mutex
+	lru         *list.List
+	bytes       int64
+	tenants     map[string]*tenantCacheState
+	generations uint64
+}
+
+func NewCachingEngine(engine TenantEngine, ttl time.Duration, maxBytes int64) *CachingEngine {
+	return &CachingEngine{
+		engine:   engine,
+		ttl:      ttl,
+		maxBytes: maxBytes,
+		now:      time.Now,
+		lru:      list.New(),
+		tenants:  make(map[string]*tenantCacheState),
+	}
+}
+
+// cacheKey identifies a search by tenant, normalized query and every option
+// that shapes the response.
+func cacheKey(tenantID, query string, options SearchOptions) string {
+	facets := splitFacets(options.Facets)
+	sort.Strings(facets)
+
+	return strings.Join([]string{
+		tenantID,
+		analytics.NormalizeQuery(query),
+		strconv.Itoa(options.Limit),
+		strconv.Itoa(options.Offset),
+		strings.TrimSpace(options.Filter),
+		strings.Join(options.Sort, ","),
+		strings.Join(facets, ","),
+	}, "\x00")
+}
+
+func splitFacets(csv string) []string {
+	var out []string
+	for _, f := range strings.Split(csv, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func (e *CachingEngine) tenant(tenantID string) *tenantCacheState {
+	state, ok := e.tenants[tenantID]
+	if !ok {
+		e.generations++
+		state = &tenantCacheState{generation: e.generations, entries: make(map[string]*list.Element)}
+		e.tenants[tenantID] = state
+	}
+	return state
+}
+
+// releaseLocked drops the tenant's state if it has nothing cached.
+func (e *CachingEngine) releaseLocked(tenantID string) {
+	if state, ok := e.tenants[tenantID]; ok && len(state.entries) == 0 {
+		delete(e.tenants, tenantID)
+	}
+}
+
+func (e *CachingEngine) removeLocked(elem *list.Element) {
+	entry := elem.Value.(*cacheEntry)
+	e.lru.Remove(elem)
+	e.bytes -= entry.size
+
+	if state, ok := e.tenants[entry.tenantID]; ok {
+		delete(state.entries, entry.key)
+		state.bytes -= entry.size
+	}
+	e.releaseLocked(entry.tenantID)
+}
+
+func (e *CachingEngine) SearchTenant(tenantID string, query string, options SearchOptions) (TenantSearchResponse, error) {
+	key := cacheKey(tenantID, query, options)
+
+	e.mu.Lock()
+	if state, ok := e.tenants[tenantID]; ok {
+		if elem, ok := state.entries[key]; ok {
+			entry := elem.Value.(*cacheEntry)
+			if e.now().Before(entry.expiresAt) {
+				e.lru.MoveToFront(elem)
+				state.hits++
+				response := entry.response
+				e.mu.Unlock()
+
+				response.Hits = append([]TenantDocument(nil), response.Hits...)
+				response.Cached = true
+				return response, nil
+			}
+			e.removeLocked(elem)
+		}
+	}
+	state := e.tenant(tenantID)
+	state.misses++
+	generation := state.generation
+	e.mu.Unlock()
+
+	response, err := e.engine.SearchTenant(tenantID, query, options)
+	if err != nil {
+		e.mu.Lock()
+		if state, ok := e.tenants[tenantID]; ok && state.generation == generation {
+			e.releaseLocked(tenantID)
+		}
+		e.mu.Unlock()
+		return response, err
+	}
+
+	e.store(tenantID, key, generation, response)
+	return response, nil
+}
+
+// store caches a response unless the tenant was invalidated while the search
+// was in flight, then evicts least recently used entries until the cache is
+// back within its byte budget.
+func (e *CachingEngine) store(tenantID, key string, generation uint64, response TenantSearchResponse) {
+	size := int64(-1)
+	if raw, err := json.Marshal(response); err == nil {
+		size = int64(len(raw) + len(key))
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	state, ok := e.tenants[tenantID]
+	if !ok || state.generation != generation {
+		return
+	}
+	if size < 0 || size > e.maxBytes {
+		e.releaseLocked(tenantID)
+		return
+	}
+	if elem, ok := state.entries[key]; ok {
+		e.removeLocked(elem)
+	}
+
+	entry := &cacheEntry{
+		key:       key,
+		tenantID:  tenantID,
+		response:  response,
+		size:      size,
+		expiresAt: e.now().Add(e.ttl),
+	}
+	state.entries[key] = e.lru.PushFront(entry)
+	state.bytes += size
+	e.bytes += size
+
+	for e.bytes > e.maxBytes {
+		e.removeLocked(e.lru.Back())
+	}
+}
+
+// InvalidateTenant drops every cached search for the tenant. Index writes
+// made through this engine call it automatically; anything that changes a
+// tenant's index settings or ranking outside of it must call it too.
+func (e *CachingEngine) InvalidateTenant(tenantID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	state, ok := e.tenants[tenantID]
+	if !ok {
+		return
+	}
+	for _, elem := range state.entries {
+		e.removeLocked(elem)
+	}
+	// Searches in flight hold the old generation; whatever state they find
+	// once done, it will not match.
+	delete(e.tenants, tenantID)
+}
+
+// Stats returns the tenant's hit/miss counters and current footprint.
+func (e *CachingEngine) Stats(tenantID string) CacheStats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	stats := CacheStats{}
+	state, ok := e.tenants[tenantID]
+	if !ok {
+		return stats
+	}
+
+	stats.Hits = state.hits
+	stats.Misses = state.misses
+	stats.Entries = len(state.entries)
+	stats.Bytes = state.bytes
+	if total := state.hits + state.misses; total > 0 {
+		stats.HitRate = float64(state.hits) / float64(total)
+	}
+	return stats
+}
+
+// IndexTenantDocuments also covers the index's lazy settings initialization,
+// which happens on a tenant's first write.
+func (e *CachingEngine) IndexTenantDocuments(tenantID string, documents []TenantDocument) error {
+	defer e.InvalidateTenant(tenantID)
+	return e.engine.IndexTenantDocuments(tenantID, documents)
+}
+
+func (e *CachingEngine) DeleteAllTenantDocuments(tenantID string) error {
+	defer e.InvalidateTenant(tenantID)
+	return e.engine.DeleteAllTenantDocuments(tenantID)
+}
+
+func (e *CachingEngine) DeleteTenantIndex(tenantID string) (int64, error) {
+	defer e.InvalidateTenant(tenantID)
+	return e.engine.DeleteTenantIndex(tenantID)
+}
+
+func (e *CachingEngine) PatchTenantDocuments(tenantID string, patches []TenantDocument) error {
+	defer e.InvalidateTenant(tenantID)
+	return e.engine.PatchTenantDocuments(tenantID, patches)
+}
+
+func (e *CachingEngine) DeleteTenantDocuments(tenantID string, ids []string) error {
+	defer e.InvalidateTenant(tenantID)
+	return e.engine.DeleteTenantDocuments(tenantID, ids)
+}
+
+func (e *CachingEngine) ListTenantDocuments(tenantID string, offset, limit int) (TenantListResponse, error) {
+	return e.engine.ListTenantDocuments(tenantID, offset, limit)
+}
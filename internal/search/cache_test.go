@@ -0,0 +1,194 @@
+package search
+
+import (
+	"testing"
+	"time"
+)
+
+type countingEngine struct {
+	searches int
+}
+
+func (e *countingEngine) SearchTenant(tenantID, query string, options SearchOptions) (TenantSearchResponse, error) {
+	e.searches++
+	return TenantSearchResponse{
+		Query: query,
+		Hits:  []TenantDocument{{"id": tenantID + ":" + query}},
+		Total: 1,
+	}, nil
+}
+
+func (e *countingEngine) IndexTenantDocuments(tenantID string, documents []TenantDocument) error {
+	return nil
+}
+
+func (e *countingEngine) DeleteAllTenantDocuments(tenantID string) error { return nil }
+
+func (e *countingEngine) DeleteTenantIndex(tenantID string) (int64, error) { return 0, nil }
+
+func (e *countingEngine) PatchTenantDocuments(tenantID string, patches []TenantDocument) error {
+	return nil
+}
+
+func (e *countingEngine) DeleteTenantDocuments(tenantID string, ids []string) error {
+	return nil
+}
+
+func (e *countingEngine) ListTenantDocuments(tenantID string, offset, limit int) (TenantListResponse, error) {
+	return TenantListResponse{}, nil
+}
+
+func TestCachingEngine_ServesRepeatedNormalizedQueriesFromCache(t *testing.T) {
+	inner := &countingEngine{}
+	cache := NewCachingEngine(inner, time.Minute, 1<<20)
+
+	first, err := cache.SearchTenant("tenant-a", "Summer  Dress", SearchOptions{Limit: 10})
+	if err != nil {
+		t.Fatalf("SearchTenant failed: %v", err)
+	}
+	if first.Cached {
+		t.Error("first search must not be reported as cached")
+	}
+
+	second, err := cache.SearchTenant("tenant-a", "summer dress", SearchOptions{Limit: 10})
+	if err != nil {
+		t.Fatalf("SearchTenant failed: %v", err)
+	}
+	if !second.Cached {
+		t.Error("repeated search should be served from cache")
+	}
+	if inner.searches != 1 {
+		t.Errorf("expected 1 engine search, got %d", inner.searches)
+	}
+
+	if _, err := cache.SearchTenant("tenant-a", "summer dress", SearchOptions{Limit: 20}); err != nil {
+		t.Fatalf("SearchTenant failed: %v", err)
+	}
+	if inner.searches != 2 {
+		t.Errorf("different options must not share an entry; expected 2 engine searches, got %d", inner.searches)
+	}
+
+	stats := cache.Stats("tenant-a")
+	if stats.Hits != 1 || stats.Misses != 2 || stats.Entries != 2 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestCachingEngine_TenantsDoNotShareEntries(t *testing.T) {
+	inner := &countingEngine{}
+	cache := NewCachingEngine(inner, time.Minute, 1<<20)
+
+	cache.SearchTenant("tenant-a", "shoe", SearchOptions{})
+	result, _ := cache.SearchTenant("tenant-b", "shoe", SearchOptions{})
+
+	if result.Cached {
+		t.Fatal("tenant-b must not be served tenant-a's cached result")
+	}
+	if got := result.Hits[0]["id"]; got != "tenant-b:shoe" {
+		t.Fatalf("expected tenant-b's own hit, got %v", got)
+	}
+}
+
+func TestCachingEngine_WritesInvalidateOnlyThatTenant(t *testing.T) {
+	inner := &countingEngine{}
+	cache := NewCachingEngine(inner, time.Minute, 1<<20)
+
+	cache.SearchTenant("tenant-a", "shoe", SearchOptions{})
+	cache.SearchTenant("tenant-b", "shoe", SearchOptions{})
+
+	if err := cache.IndexTenantDocuments("tenant-a", []TenantDocument{{"id": "1"}}); err != nil {
+		t.Fatalf("IndexTenantDocuments failed: %v", err)
+	}
+
+	if result, _ := cache.SearchTenant("tenant-a", "shoe", SearchOptions{}); result.Cached {
+		t.Error("tenant-a's entries should have been invalidated by the write")
+	}
+	if result, _ := cache.SearchTenant("tenant-b", "shoe", SearchOptions{}); !result.Cached {
+		t.Error("tenant-b's entries should survive tenant-a's write")
+	}
+
+	if err := cache.DeleteAllTenantDocuments("tenant-b"); err != nil {
+		t.Fatalf("DeleteAllTenantDocuments failed: %v", err)
+	}
+	if result, _ := cache.SearchTenant("tenant-b", "shoe", SearchOptions{}); result.Cached {
+		t.Error("tenant-b's entries should have been invalidated by the reset")
+	}
+}
+
+func TestCachingEngine_EntriesExpireAfterTTL(t *testing.T) {
+	inner := &countingEngine{}
+	cache := NewCachingEngine(inner, time.Minute, 1<<20)
+
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	cache.SearchTenant("tenant-a", "shoe", SearchOptions{})
+	now = now.Add(2 * time.Minute)
+
+	if result, _ := cache.SearchTenant("tenant-a", "shoe", SearchOptions{}); result.Cached {
+		t.Fatal("expired entry must not be served")
+	}
+}
+
+func TestCachingEngine_StaysWithinByteBudget(t *testing.T) {
+	inner := &countingEngine{}
+	cache := NewCachingEngine(inner, time.Minute, 400)
+
+	queries := []string{"one", "two", "three", "four", "five", "six"}
+	for _, q := range queries {
+		cache.SearchTenant("tenant-a", q, SearchOptions{})
+	}
+
+	stats := cache.Stats("tenant-a")
+	if stats.Bytes > 400 {
+		t.Fatalf("cache exceeded its budget: %d bytes", stats.Bytes)
+	}
+	if stats.Entries == 0 || stats.Entries == len(queries) {
+		t.Fatalf("expected some but not all entries to be evicted, got %d", stats.Entries)
+	}
+
+	// The most recent query survives; the oldest is evicted first.
+	if result, _ := cache.SearchTenant("tenant-a", "six", SearchOptions{}); !result.Cached {
+		t.Error("most recently used entry should still be cached")
+	}
+	if result, _ := cache.SearchTenant("tenant-a", "one", SearchOptions{}); result.Cached {
+		t.Error("least recently used entry should have been evicted")
+	}
+}
+
+// writingEngine writes to the tenant's index through the cache while a
+// search is in flight.
+type writingEngine struct {
+	countingEngine
+	cache *CachingEngine
+}
+
+func (e *writingEngine) SearchTenant(tenantID, query string, options SearchOptions) (TenantSearchResponse, error) {
+	e.cache.InvalidateTenant(tenantID)
+	return e.countingEngine.SearchTenant(tenantID, query, options)
+}
+
+func TestCachingEngine_ForgetsTenantsWithNothingCached(t *testing.T) {
+	inner := &countingEngine{}
+	cache := NewCachingEngine(inner, time.Minute, 1<<20)
+
+	cache.SearchTenant("tenant-a", "shoe", SearchOptions{})
+	cache.SearchTenant("tenant-b", "shoe", SearchOptions{})
+	if len(cache.tenants) != 2 {
+		t.Fatalf("expected state for both tenants, got %d", len(cache.tenants))
+	}
+
+	cache.InvalidateTenant("tenant-a")
+	cache.InvalidateTenant("tenant-c")
+	if _, ok := cache.tenants["tenant-a"]; ok || len(cache.tenants) != 1 {
+		t.Errorf("expected only tenant-b's state left, got %d states", len(cache.tenants))
+	}
+
+	writer := &writingEngine{}
+	racy := NewCachingEngine(writer, time.Minute, 1<<20)
+	writer.cache = racy
+	racy.SearchTenant("tenant-a", "shoe", SearchOptions{})
+	if len(racy.tenants) != 0 {
+		t.Errorf("expected a search overtaken by a write not to be cached, got %d states", len(racy.tenants))
+	}
+}
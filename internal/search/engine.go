@@ -1,6 +1,9 @@
 package search
 
 import (
+	"context"
+	"fmt"
+	"strconv"
 	"strings"
 
 	"mini-search-platform/internal/models"
@@ -10,9 +13,15 @@ var (
 	ARTICLES_INDEX_NAME = "articles"
 )
 
+// PopularityAttribute is the tenant document field holding the popularity
+// score derived from shopper events. It is sortable, and can back a custom
+// ranking rule (see adapters.MeilisearchEngine.EnablePopularityRanking).
+const PopularityAttribute = "popularity"
+
 type SearchEngine interface {
 	Search(q string, options SearchOptions) (SearchResponse, error)
 	IndexArticles(articles []*models.Article) error
+	DeleteArticles(ids []int) error
 }
 
 // TenantDocument is a loosely-typed document used by the internal, tenant-aware
@@ -20,6 +29,16 @@ type SearchEngine interface {
 // catalogs (id, title, brand, category, ...), so we keep the shape generic.
 type TenantDocument = map[string]interface{}
 
+// DocumentID formats a tenant document's id as the engine and the stores
+// keyed by it spell it. JSON numbers decode as float64, which fmt would
+// print in exponent form for large IDs.
+func DocumentID(id interface{}) string {
+	if n, ok := id.(float64); ok {
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	}
+	return fmt.Sprint(id)
+}
+
 // TenantSearchResponse mirrors CONTRACT.md §3's search response shape:
 // { "query", "hits", "total" } plus, when facets were requested,
 // `facetDistribution`, and the effective `limit`/`offset` used for paging.
@@ -30,6 +49,21 @@ type TenantSearchResponse struct {
 	FacetDistribution map[string]map[string]int `json:"facetDistribution,omitempty"`
 	Limit             int                       `json:"limit"`
 	Offset            int                       `json:"offset"`
+	// Cached reports whether the response was served from the search
+	// result cache (see CachingEngine) rather than by the engine.
+	Cached bool `json:"cached"`
+	// SearchID identifies the search in the tenant's analytics log, so
+	// later events (clicks, conversions) can be attributed to it. Empty when
+	// the search was not logged.
+	SearchID string `json:"search_id,omitempty"`
+	// AppliedRules lists the IDs of the merchandising rules that shaped the
+	// response, in the order they were applied.
+	AppliedRules []string `json:"applied_rules,omitempty"`
+	// Suggestion is a spelling correction of Query, offered when the query
+	// returned few hits. When AutoCorrected is set, Hits are the results of
+	// the suggestion rather than of Query.
+	Suggestion    string `json:"suggestion,omitempty"`
+	AutoCorrected bool   `json:"auto_corrected,omitempty"`
 }
 
 // TenantSearchEngine is implemented by search engines that support
@@ -43,6 +77,60 @@ type TenantSearchEngine interface {
 	DeleteAllTenantDocuments(tenantID string) error
 }
 
+// TenantIndexDeleter is implemented by engines that can drop a tenant's
+// isolated index outright, as opposed to truncating it. Used when a tenant is
+// offboarded; the returned task UID identifies the engine-side deletion.
+type TenantIndexDeleter interface {
+	DeleteTenantIndex(tenantID string) (taskUID int64, err error)
+}
+
+// TenantDocumentPatcher is implemented by engines that can merge fields into
+// documents already in a tenant's index, such as derived signals (popularity)
+// that are computed outside the catalog. Patches for documents that are not
+// in the index are skipped rather than creating partial documents.
+type TenantDocumentPatcher interface {
+	PatchTenantDocuments(tenantID string, patches []TenantDocument) error
+}
+
+// TenantDocumentDeleter is implemented by engines that can remove single
+// documents from a tenant's index, such as a deleted article's.
+type TenantDocumentDeleter interface {
+	DeleteTenantDocuments(tenantID string, ids []string) error
+}
+
+// ProjectIndexer is implemented by engines that keep an isolated index per
+// project of a tenant, next to the tenant's own index. A project's index is
+// created on its first write, like a tenant's.
+type ProjectIndexer interface {
+	IndexProjectDocuments(tenantID, projectID string, documents []TenantDocument) error
+	DeleteProjectDocuments(tenantID, projectID string, ids []string) error
+	DeleteProjectIndex(tenantID, projectID string) (taskUID int64, err error)
+}
+
+// ProjectSearcher is implemented by engines that can search a project's
+// index on its own, like a tenant's.
+type ProjectSearcher interface {
+	SearchProject(tenantID, projectID string, query string, options SearchOptions) (TenantSearchResponse, error)
+}
+
+// TenantTaskWaiter is implemented by engines that index asynchronously.
+// WaitForTenantTasks returns once every write accepted for the tenant so far
+// is searchable, or ctx is done.
+type TenantTaskWaiter interface {
+	WaitForTenantTasks(ctx context.Context, tenantID string) error
+}
+
+// TenantEngine bundles every tenant-scoped capability the internal API uses,
+// so decorators (usage metering, caching) can wrap one value and still be
+// handed to each internal handler.
+type TenantEngine interface {
+	TenantSearchEngine
+	TenantDocumentLister
+	TenantIndexDeleter
+	TenantDocumentPatcher
+	TenantDocumentDeleter
+}
+
 // NormalizeTenantID lowercases the org UUID and replaces '-' with '_', per
 // CONTRACT.md §4's index naming rule.
 func NormalizeTenantID(tenantID string) string {
@@ -55,6 +143,13 @@ func TenantIndexName(tenantID string) string {
 	return "tenant_" + NormalizeTenantID(tenantID) + "_articles"
 }
 
+// ProjectIndexName returns the Meilisearch index name for one of a tenant's
+// projects, `tenant_<normalized-org-uuid>_<normalized-project-id>`, the
+// project ID normalized like the tenant's.
+func ProjectIndexName(tenantID, projectID string) string {
+	return "tenant_" + NormalizeTenantID(tenantID) + "_" + NormalizeTenantID(projectID)
+}
+
 type SearchOptions struct {
 	Limit  int      `json:"limit"`
 	Offset int      `json:"offset"`
@@ -81,36 +176,7 @@ type SearchResponse struct {
 	Offset int         `json:"offset"`
 	Limit  int         `json:"limit"`
 	Total  int         `json:"total"`
-}
-
-type IndexSyncManager struct {
-	Engine             SearchEngine
-	ArticlesRepository models.ArticleRepository
-	TagsRepository     models.TagsRepository
-}
-
-func NewIndexSyncManager(engine SearchEngine, articlesRepository models.ArticleRepository, tagsRepository models.TagsRepository) *IndexSyncManager {
-	return &IndexSyncManager{
-		Engine:             engine,
-		ArticlesRepository: articlesRepository,
-		TagsRepository:     tagsRepository,
-	}
-}
-
-func (m *IndexSyncManager) SyncAfterTagsChanged(tagToSync *models.Tag) error {
-	articles, err := m.ArticlesRepository.FindByTag(tagToSync)
-	if err != nil {
-		return err
-	}
-
-	return m.Engine.IndexArticles(articles)
-}
-
-func (m *IndexSyncManager) SyncAfterArticlesChanged(articlesToSync []*models.Article) error {
-	err := m.Engine.IndexArticles(articlesToSync)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	// FacetDistribution counts hits per value of each requested facet,
+	// such as tags.lvl0 and tags.lvl1 for the tag hierarchy.
+	FacetDistribution map[string]map[string]int `json:"facetDistribution,omitempty"`
 }
@@ -0,0 +1,60 @@
+package search
+
+import "strings"
+
+// AndFilters joins Meilisearch filter expressions with AND, skipping empty
+// ones.
+func AndFilters(filters ...string) string {
+	return joinFilters(" AND ", filters)
+}
+
+// OrFilters joins Meilisearch filter expressions with OR, skipping empty
+// ones.
+func OrFilters(filters ...string) string {
+	return joinFilters(" OR ", filters)
+}
+
+// joinFilters parenthesizes each non-empty filter so operator precedence
+// inside a tenant's expression cannot leak out; a lone filter is returned
+// as is.
+func joinFilters(operator string, filters []string) string {
+	var parts []string
+	for _, f := range filters {
+		if f = strings.TrimSpace(f); f != "" {
+			parts = append(parts, f)
+		}
+	}
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	for i, part := range parts {
+		parts[i] = "(" + part + ")"
+	}
+	return strings.Join(parts, operator)
+}
+
+// NotFilter negates a filter expression; the negation of no filter is none.
+func NotFilter(filter string) string {
+	if filter == "" {
+		return ""
+	}
+	return "NOT (" + filter + ")"
+}
+
+// IDInFilter matches documents whose id is one of ids. It requires id to be
+// filterable, which it is on tenant indexes.
+func IDInFilter(ids []string) string {
+	if len(ids) == 0 {
+		return ""
+	}
+	quoted := make([]string, len(ids))
+	for i, id := range ids {
+		quoted[i] = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(id) + `"`
+	}
+	return "id IN [" + strings.Join(quoted, ", ") + "]"
+}
+
+// IDNotInFilter matches documents whose id is none of ids.
+func IDNotInFilter(ids []string) string {
+	return NotFilter(IDInFilter(ids))
+}
@@ -0,0 +1,341 @@
+package search
+
+import (
+	"fmt"
+	"time"
+
+	"mini-search-platform/internal/models"
+	"mini-search-platform/pkg/logging"
+	"mini-search-platform/pkg/retry"
+)
+
+const outboxBatchSize = 100
+
+// IndexSyncManager keeps the articles index in step with SQLite. Changes
+// are recorded as intents in the index sync outbox (see
+// models.IndexSyncOutboxRepository), and the worker started by Start
+// drains it: each due intent's entity is read back and indexed, or its
+// document deleted if the entity is gone. A failed intent is retried with
+// exponential backoff (retry.Delay from baseDelay, capped at maxDelay)
+// until maxAttempts attempts have failed, after which it is
+// dead-lettered. Indexing is therefore eventually consistent, and survives
+// engine outages and restarts.
+//
+// Articles without a tenant go to the shared articles index through Engine;
+// a tenant's articles go to the tenant's isolated index through
+// TenantIndexer, and those in a project to the project's index as well,
+// through ProjectIndexer.
+type IndexSyncManager struct {
+	Engine             SearchEngine
+	TenantIndexer      TenantArticleIndexer
+	ProjectIndexer     ProjectIndexer
+	ArticlesRepository models.ArticleRepository
+	TagsRepository     models.TagsRepository
+	Outbox             models.IndexSyncOutboxRepository
+
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+	now         func() time.Time
+	wake        chan struct{}
+}
+
+// TenantArticleIndexer is the part of a TenantEngine that keeps tenants'
+// articles indexed.
+type TenantArticleIndexer interface {
+	IndexTenantDocuments(tenantID string, documents []TenantDocument) error
+	TenantDocumentDeleter
+}
+
+func NewIndexSyncManager(engine SearchEngine, tenantIndexer TenantArticleIndexer, projectIndexer ProjectIndexer, articlesRepository models.ArticleRepository, tagsRepository models.TagsRepository, outbox models.IndexSyncOutboxRepository, maxAttempts int) *IndexSyncManager {
+	return &IndexSyncManager{
+		Engine:             engine,
+		TenantIndexer:      tenantIndexer,
+		ProjectIndexer:     projectIndexer,
+		ArticlesRepository: articlesRepository,
+		TagsRepository:     tagsRepository,
+		Outbox:             outbox,
+		maxAttempts:        maxAttempts,
+		baseDelay:          5 * time.Second,
+		maxDelay:           10 * time.Minute,
+		now:                time.Now,
+		wake:               make(chan struct{}, 1),
+	}
+}
+
+// SyncAfterTagsChanged re-indexes the articles carrying the tag or one of
+// its descendants, whose documents carry the tag's label in their paths.
+func (m *IndexSyncManager) SyncAfterTagsChanged(tagToSync *models.Tag) error {
+	articles, err := m.ArticlesRepository.FindByTagSubtree(tagToSync.ID)
+	if err != nil {
+		return err
+	}
+
+	return m.SyncAfterArticlesChanged(articles)
+}
+
+// SyncAfterArticlesChanged indexes the shared articles in one engine call,
+// each tenant's articles in one call per tenant and each project's articles
+// in one more call per project.
+func (m *IndexSyncManager) SyncAfterArticlesChanged(articlesToSync []*models.Article) error {
+	if len(articlesToSync) == 0 {
+		return nil
+	}
+
+	var shared []*models.Article
+	var tenants []string
+	byTenant := make(map[string][]*models.Article)
+	for _, article := range articlesToSync {
+		if article.TenantID == "" {
+			shared = append(shared, article)
+			continue
+		}
+		if _, ok := byTenant[article.TenantID]; !ok {
+			tenants = append(tenants, article.TenantID)
+		}
+		byTenant[article.TenantID] = append(byTenant[article.TenantID], article)
+	}
+
+	if len(shared) > 0 {
+		if err := m.Engine.IndexArticles(shared); err != nil {
+			return err
+		}
+	}
+	for _, tenantID := range tenants {
+		articles := byTenant[tenantID]
+		if err := m.TenantIndexer.IndexTenantDocuments(tenantID, TenantArticleDocuments(articles)); err != nil {
+			return err
+		}
+
+		var projects []string
+		byProject := make(map[string][]*models.Article)
+		for _, article := range articles {
+			if article.ProjectID == "" {
+				continue
+			}
+			if _, ok := byProject[article.ProjectID]; !ok {
+				projects = append(projects, article.ProjectID)
+			}
+			byProject[article.ProjectID] = append(byProject[article.ProjectID], article)
+		}
+		for _, projectID := range projects {
+			if err := m.ProjectIndexer.IndexProjectDocuments(tenantID, projectID, TenantArticleDocuments(byProject[projectID])); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// SyncAfterAuthorChanged re-indexes the author's articles, whose documents
+// carry the author's name.
+func (m *IndexSyncManager) SyncAfterAuthorChanged(authorID int) error {
+	articles, err := m.ArticlesRepository.FindByAuthor(authorID)
+	if err != nil {
+		return err
+	}
+
+	return m.SyncAfterArticlesChanged(articles)
+}
+
+// ResyncTenant re-indexes each of the tenant's articles through the outbox,
+// as after the tenant's index was emptied.
+func (m *IndexSyncManager) ResyncTenant(tenantID string) error {
+	if err := m.Outbox.EnqueueTenantArticles(tenantID); err != nil {
+		return err
+	}
+	m.Notify()
+	return nil
+}
+
+// Notify wakes the worker, so intents written alongside a change (see
+// models.ArticleRepository) are processed right away rather than on
+// the next tick.
+func (m *IndexSyncManager) Notify() {
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
+// DrainOutbox processes every intent that is due.
+func (m *IndexSyncManager) DrainOutbox() error {
+	for {
+		due, err := m.Outbox.ListDue(m.now(), outboxBatchSize)
+		if err != nil {
+			return err
+		}
+		if err := m.process(due); err != nil {
+			return err
+		}
+		if len(due) < outboxBatchSize {
+			return nil
+		}
+	}
+}
+
+// process indexes the batch's articles in one engine call, and each tag's
+// or author's articles in one call per tag or author. Each deleted project's
+// index is dropped on its own.
+func (m *IndexSyncManager) process(intents []*models.IndexSyncIntent) error {
+	var articleIntents []*models.IndexSyncIntent
+	var articleIDs []int
+	for _, intent := range intents {
+		switch intent.Kind {
+		case models.IndexSyncArticle:
+			articleIntents = append(articleIntents, intent)
+			articleIDs = append(articleIDs, intent.EntityID)
+		case models.IndexSyncTag:
+			err := m.SyncAfterTagsChanged(&models.Tag{ID: intent.EntityID})
+			if err := m.settle([]*models.IndexSyncIntent{intent}, err); err != nil {
+				return err
+			}
+		case models.IndexSyncAuthor:
+			err := m.SyncAfterAuthorChanged(intent.EntityID)
+			if err := m.settle([]*models.IndexSyncIntent{intent}, err); err != nil {
+				return err
+			}
+		case models.IndexSyncProject:
+			_, err := m.ProjectIndexer.DeleteProjectIndex(intent.TenantID, intent.ProjectID)
+			if err := m.settle([]*models.IndexSyncIntent{intent}, err); err != nil {
+				return err
+			}
+		default:
+			err := fmt.Errorf("unknown index sync kind %q", intent.Kind)
+			if err := m.settle([]*models.IndexSyncIntent{intent}, err); err != nil {
+				return err
+			}
+		}
+	}
+	if len(articleIntents) == 0 {
+		return nil
+	}
+
+	// Articles that are no longer found were deleted, and so are their
+	// documents.
+	articles, err := m.ArticlesRepository.FindByIDs(articleIDs)
+	if err == nil {
+		err = m.SyncAfterArticlesChanged(articles)
+	}
+	if err == nil {
+		err = m.deleteMissingArticles(articleIntents, articles)
+	}
+	return m.settle(articleIntents, err)
+}
+
+type projectKey struct {
+	tenantID, projectID string
+}
+
+// deleteMissingArticles deletes the documents of the intents' articles that
+// were not found, from the indexes of the tenant and project each intent
+// recorded.
+func (m *IndexSyncManager) deleteMissingArticles(intents []*models.IndexSyncIntent, found []*models.Article) error {
+	var tenants []string
+	byTenant := make(map[string][]int)
+	var projects []projectKey
+	byProject := make(map[projectKey][]string)
+	for _, intent := range missingArticleIntents(intents, found) {
+		if _, ok := byTenant[intent.TenantID]; !ok {
+			tenants = append(tenants, intent.TenantID)
+		}
+		byTenant[intent.TenantID] = append(byTenant[intent.TenantID], intent.EntityID)
+
+		if intent.ProjectID != "" {
+			project := projectKey{tenantID: intent.TenantID, projectID: intent.ProjectID}
+			if _, ok := byProject[project]; !ok {
+				projects = append(projects, project)
+			}
+			byProject[project] = append(byProject[project], TenantArticleDocumentID(intent.EntityID))
+		}
+	}
+
+	for _, tenantID := range tenants {
+		ids := byTenant[tenantID]
+		if tenantID == "" {
+			if err := m.Engine.DeleteArticles(ids); err != nil {
+				return err
+			}
+			continue
+		}
+		documentIDs := make([]string, len(ids))
+		for i, id := range ids {
+			documentIDs[i] = TenantArticleDocumentID(id)
+		}
+		if err := m.TenantIndexer.DeleteTenantDocuments(tenantID, documentIDs); err != nil {
+			return err
+		}
+	}
+	for _, project := range projects {
+		if err := m.ProjectIndexer.DeleteProjectDocuments(project.tenantID, project.projectID, byProject[project]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func missingArticleIntents(intents []*models.IndexSyncIntent, found []*models.Article) []*models.IndexSyncIntent {
+	present := make(map[int]bool, len(found))
+	for _, article := range found {
+		present[article.ID] = true
+	}
+	var missing []*models.IndexSyncIntent
+	for _, intent := range intents {
+		if !present[intent.EntityID] {
+			missing = append(missing, intent)
+			present[intent.EntityID] = true
+		}
+	}
+	return missing
+}
+
+// settle completes the intents, or records syncErr on each and schedules
+// its retry or dead-letters it.
+func (m *IndexSyncManager) settle(intents []*models.IndexSyncIntent, syncErr error) error {
+	if syncErr == nil {
+		ids := make([]int64, len(intents))
+		for i, intent := range intents {
+			ids[i] = intent.ID
+		}
+		return m.Outbox.Complete(ids)
+	}
+
+	now := m.now().UTC()
+	for _, intent := range intents {
+		intent.Attempts++
+		intent.LastError = syncErr.Error()
+
+		if intent.Attempts >= m.maxAttempts {
+			logging.Warn("index sync failed permanently",
+				"kind", intent.Kind, "entity_id", intent.EntityID, "attempts", intent.Attempts, "error", syncErr)
+			if err := m.Outbox.DeadLetter(intent); err != nil {
+				return err
+			}
+			continue
+		}
+
+		intent.NextAttemptAt = now.Add(retry.Delay(intent.Attempts, m.baseDelay, m.maxDelay))
+		if err := m.Outbox.Reschedule(intent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Start runs the outbox worker: due intents are processed every interval,
+// and right away after Notify.
+func (m *IndexSyncManager) Start(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for {
+			if err := m.DrainOutbox(); err != nil {
+				logging.Error("failed to drain index sync outbox", "error", err)
+			}
+			select {
+			case <-ticker.C:
+			case <-m.wake:
+			}
+		}
+	}()
+}
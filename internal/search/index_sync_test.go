@@ -0,0 +1,369 @@
+package search
+
+import (
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"mini-search-platform/internal/models"
+)
+
+type flakyArticlesEngine struct {
+	failures int
+	indexed  [][]int
+	deleted  [][]int
+}
+
+func (e *flakyArticlesEngine) Search(q string, options SearchOptions) (SearchResponse, error) {
+	return SearchResponse{}, nil
+}
+
+func (e *flakyArticlesEngine) IndexArticles(articles []*models.Article) error {
+	if e.failures > 0 {
+		e.failures--
+		return errors.New("meilisearch unavailable")
+	}
+	ids := make([]int, len(articles))
+	for i, a := range articles {
+		ids[i] = a.ID
+	}
+	e.indexed = append(e.indexed, ids)
+	return nil
+}
+
+func (e *flakyArticlesEngine) DeleteArticles(ids []int) error {
+	e.deleted = append(e.deleted, ids)
+	return nil
+}
+
+// recordingTenantIndexer records tenant writes by tenant ID and project
+// writes by "tenant/project", failing the first dropFailures index drops.
+type recordingTenantIndexer struct {
+	indexed      map[string][]TenantDocument
+	deleted      map[string][]string
+	dropped      []string
+	dropFailures int
+}
+
+func newRecordingTenantIndexer() *recordingTenantIndexer {
+	return &recordingTenantIndexer{indexed: make(map[string][]TenantDocument), deleted: make(map[string][]string)}
+}
+
+func (i *recordingTenantIndexer) IndexProjectDocuments(tenantID, projectID string, documents []TenantDocument) error {
+	return i.IndexTenantDocuments(tenantID+"/"+projectID, documents)
+}
+
+func (i *recordingTenantIndexer) DeleteProjectDocuments(tenantID, projectID string, ids []string) error {
+	return i.DeleteTenantDocuments(tenantID+"/"+projectID, ids)
+}
+
+func (i *recordingTenantIndexer) DeleteProjectIndex(tenantID, projectID string) (int64, error) {
+	if i.dropFailures > 0 {
+		i.dropFailures--
+		return 0, errors.New("meilisearch unavailable")
+	}
+	i.dropped = append(i.dropped, tenantID+"/"+projectID)
+	return 1, nil
+}
+
+func (i *recordingTenantIndexer) IndexTenantDocuments(tenantID string, documents []TenantDocument) error {
+	i.indexed[tenantID] = append(i.indexed[tenantID], documents...)
+	return nil
+}
+
+func (i *recordingTenantIndexer) DeleteTenantDocuments(tenantID string, ids []string) error {
+	i.deleted[tenantID] = append(i.deleted[tenantID], ids...)
+	return nil
+}
+
+type memoryArticleRepository struct {
+	articles map[int]*models.Article
+	byTag    map[int][]int
+	byAuthor map[int][]int
+}
+
+func (r *memoryArticleRepository) Save(article *models.Article) (int, error) { return article.ID, nil }
+func (r *memoryArticleRepository) Update(article *models.Article) error      { return nil }
+func (r *memoryArticleRepository) Delete(id int) error                       { return nil }
+func (r *memoryArticleRepository) FindByID(id int) (*models.Article, error) {
+	return r.articles[id], nil
+}
+func (r *memoryArticleRepository) List(models.ArticleFilter) ([]*models.Article, int, error) {
+	return nil, 0, nil
+}
+
+func (r *memoryArticleRepository) FindByTag(tenantID string, tag *models.Tag) ([]*models.Article, error) {
+	return r.FindByIDs(r.byTag[tag.ID])
+}
+
+func (r *memoryArticleRepository) FindByTagSubtree(tagID int) ([]*models.Article, error) {
+	return r.FindByIDs(r.byTag[tagID])
+}
+
+func (r *memoryArticleRepository) FindByAuthor(authorID int) ([]*models.Article, error) {
+	return r.FindByIDs(r.byAuthor[authorID])
+}
+
+func (r *memoryArticleRepository) FindByIDs(ids []int) ([]*models.Article, error) {
+	var found []*models.Article
+	for _, id := range ids {
+		if a, ok := r.articles[id]; ok {
+			found = append(found, a)
+		}
+	}
+	return found, nil
+}
+
+type memoryOutbox struct {
+	nextID      int64
+	intents     map[int64]*models.IndexSyncIntent
+	deadLetters []*models.IndexSyncDeadLetter
+	now         func() time.Time
+}
+
+func newMemoryOutbox(now func() time.Time) *memoryOutbox {
+	return &memoryOutbox{intents: make(map[int64]*models.IndexSyncIntent), now: now}
+}
+
+func (o *memoryOutbox) Enqueue(kind models.IndexSyncKind, entityID int) error {
+	return o.enqueueArticle(kind, entityID, "", "")
+}
+
+func (o *memoryOutbox) EnqueueTenantArticles(tenantID string) error {
+	return nil
+}
+
+func (o *memoryOutbox) enqueueArticle(kind models.IndexSyncKind, entityID int, tenantID, projectID string) error {
+	o.nextID++
+	o.intents[o.nextID] = &models.IndexSyncIntent{ID: o.nextID, Kind: kind, EntityID: entityID, TenantID: tenantID, ProjectID: projectID, NextAttemptAt: o.now(), CreatedAt: o.now()}
+	return nil
+}
+
+func (o *memoryOutbox) ListDue(now time.Time, limit int) ([]*models.IndexSyncIntent, error) {
+	var due []*models.IndexSyncIntent
+	for _, intent := range o.intents {
+		if !intent.NextAttemptAt.After(now) {
+			copied := *intent
+			due = append(due, &copied)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].ID < due[j].ID })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+func (o *memoryOutbox) Complete(ids []int64) error {
+	for _, id := range ids {
+		delete(o.intents, id)
+	}
+	return nil
+}
+
+func (o *memoryOutbox) Reschedule(intent *models.IndexSyncIntent) error {
+	copied := *intent
+	o.intents[intent.ID] = &copied
+	return nil
+}
+
+func (o *memoryOutbox) DeadLetter(intent *models.IndexSyncIntent) error {
+	delete(o.intents, intent.ID)
+	o.deadLetters = append(o.deadLetters, &models.IndexSyncDeadLetter{
+		IntentID: intent.ID, Kind: intent.Kind, EntityID: intent.EntityID, Attempts: intent.Attempts, LastError: intent.LastError,
+	})
+	return nil
+}
+
+func (o *memoryOutbox) ListDeadLetters(limit int) ([]*models.IndexSyncDeadLetter, error) {
+	return o.deadLetters, nil
+}
+
+func (o *memoryOutbox) Stats() (models.IndexSyncStats, error) {
+	return models.IndexSyncStats{Pending: len(o.intents), DeadLetters: len(o.deadLetters)}, nil
+}
+
+func newTestIndexSyncManager(engine *flakyArticlesEngine, maxAttempts int) (*IndexSyncManager, *memoryOutbox, *time.Time) {
+	clock := time.Now()
+	now := func() time.Time { return clock }
+	articles := &memoryArticleRepository{
+		articles: map[int]*models.Article{1: {ID: 1}, 2: {ID: 2}, 3: {ID: 3}, 4: {ID: 4, TenantID: "acme", ProjectID: "docs"}},
+		byTag:    map[int][]int{7: {2, 3}},
+		byAuthor: map[int][]int{5: {1, 3}},
+	}
+	outbox := newMemoryOutbox(now)
+	indexer := newRecordingTenantIndexer()
+	m := NewIndexSyncManager(engine, indexer, indexer, articles, nil, outbox, maxAttempts)
+	m.now = now
+	return m, outbox, &clock
+}
+
+func TestIndexSyncManager_RetriesUntilTheEngineRecovers(t *testing.T) {
+	engine := &flakyArticlesEngine{failures: 2}
+	m, outbox, clock := newTestIndexSyncManager(engine, 5)
+
+	outbox.Enqueue(models.IndexSyncArticle, 1)
+	outbox.Enqueue(models.IndexSyncArticle, 2)
+
+	if err := m.DrainOutbox(); err != nil {
+		t.Fatalf("DrainOutbox failed: %v", err)
+	}
+	intent := outbox.intents[1]
+	if intent == nil || intent.Attempts != 1 || intent.LastError != "meilisearch unavailable" {
+		t.Fatalf("expected the intent to be kept for a retry, got %+v", intent)
+	}
+	if want := clock.Add(5 * time.Second); !intent.NextAttemptAt.Equal(want.UTC()) {
+		t.Errorf("expected the retry at %v, got %v", want, intent.NextAttemptAt)
+	}
+
+	// Not due yet: the engine is not called.
+	m.DrainOutbox()
+	if engine.failures != 1 {
+		t.Fatalf("a retry must wait for its backoff")
+	}
+
+	*clock = clock.Add(5 * time.Second)
+	m.DrainOutbox()
+	if intent := outbox.intents[1]; intent == nil || intent.Attempts != 2 || !intent.NextAttemptAt.Equal(clock.Add(10*time.Second).UTC()) {
+		t.Fatalf("expected the delay to double, got %+v", intent)
+	}
+
+	*clock = clock.Add(10 * time.Second)
+	m.DrainOutbox()
+	if len(outbox.intents) != 0 {
+		t.Fatalf("expected the outbox to be drained, got %d intents", len(outbox.intents))
+	}
+	if len(engine.indexed) != 1 || len(engine.indexed[0]) != 2 {
+		t.Errorf("expected both articles to be indexed in one call, got %v", engine.indexed)
+	}
+}
+
+func TestIndexSyncManager_DeadLettersAfterMaxAttempts(t *testing.T) {
+	engine := &flakyArticlesEngine{failures: 100}
+	m, outbox, clock := newTestIndexSyncManager(engine, 3)
+
+	outbox.Enqueue(models.IndexSyncTag, 7)
+	for i := 0; i < 3; i++ {
+		m.DrainOutbox()
+		*clock = clock.Add(time.Hour)
+	}
+
+	if len(outbox.intents) != 0 {
+		t.Fatalf("expected the intent to leave the outbox, got %d intents", len(outbox.intents))
+	}
+	if len(outbox.deadLetters) != 1 {
+		t.Fatalf("expected one dead letter, got %d", len(outbox.deadLetters))
+	}
+	dl := outbox.deadLetters[0]
+	if dl.Kind != models.IndexSyncTag || dl.EntityID != 7 || dl.Attempts != 3 || dl.LastError != "meilisearch unavailable" {
+		t.Errorf("unexpected dead letter %+v", dl)
+	}
+}
+
+func TestIndexSyncManager_TagIntentIndexesTheTagsArticles(t *testing.T) {
+	engine := &flakyArticlesEngine{}
+	m, outbox, _ := newTestIndexSyncManager(engine, 3)
+
+	outbox.Enqueue(models.IndexSyncTag, 7)
+	if err := m.DrainOutbox(); err != nil {
+		t.Fatalf("DrainOutbox failed: %v", err)
+	}
+
+	if len(outbox.intents) != 0 {
+		t.Errorf("expected the outbox to be drained, got %d intents", len(outbox.intents))
+	}
+	if len(engine.indexed) != 1 || len(engine.indexed[0]) != 2 || engine.indexed[0][0] != 2 || engine.indexed[0][1] != 3 {
+		t.Errorf("expected the tag's articles to be indexed, got %v", engine.indexed)
+	}
+}
+
+func TestIndexSyncManager_DeletesDocumentsOfDeletedArticles(t *testing.T) {
+	engine := &flakyArticlesEngine{}
+	m, outbox, _ := newTestIndexSyncManager(engine, 3)
+
+	outbox.Enqueue(models.IndexSyncArticle, 1)
+	outbox.Enqueue(models.IndexSyncArticle, 42) // deleted since
+	outbox.Enqueue(models.IndexSyncArticle, 42)
+	if err := m.DrainOutbox(); err != nil {
+		t.Fatalf("DrainOutbox failed: %v", err)
+	}
+
+	if len(outbox.intents) != 0 {
+		t.Errorf("expected the outbox to be drained, got %d intents", len(outbox.intents))
+	}
+	if len(engine.indexed) != 1 || len(engine.indexed[0]) != 1 || engine.indexed[0][0] != 1 {
+		t.Errorf("expected the remaining article to be indexed, got %v", engine.indexed)
+	}
+	if len(engine.deleted) != 1 || len(engine.deleted[0]) != 1 || engine.deleted[0][0] != 42 {
+		t.Errorf("expected the deleted article's document to be removed once, got %v", engine.deleted)
+	}
+}
+
+func TestIndexSyncManager_AuthorIntentIndexesTheAuthorsArticles(t *testing.T) {
+	engine := &flakyArticlesEngine{}
+	m, outbox, _ := newTestIndexSyncManager(engine, 3)
+
+	outbox.Enqueue(models.IndexSyncAuthor, 5)
+	if err := m.DrainOutbox(); err != nil {
+		t.Fatalf("DrainOutbox failed: %v", err)
+	}
+
+	if len(outbox.intents) != 0 {
+		t.Errorf("expected the outbox to be drained, got %d intents", len(outbox.intents))
+	}
+	if len(engine.indexed) != 1 || len(engine.indexed[0]) != 2 || engine.indexed[0][0] != 1 || engine.indexed[0][1] != 3 {
+		t.Errorf("expected the author's articles to be indexed, got %v", engine.indexed)
+	}
+}
+
+func TestIndexSyncManager_RoutesTenantArticlesToTheirIndexes(t *testing.T) {
+	engine := &flakyArticlesEngine{}
+	m, outbox, _ := newTestIndexSyncManager(engine, 3)
+	tenants := m.TenantIndexer.(*recordingTenantIndexer)
+
+	outbox.Enqueue(models.IndexSyncArticle, 1)
+	outbox.enqueueArticle(models.IndexSyncArticle, 4, "acme", "docs")
+	outbox.enqueueArticle(models.IndexSyncArticle, 9, "acme", "docs") // deleted since
+	if err := m.DrainOutbox(); err != nil {
+		t.Fatalf("DrainOutbox failed: %v", err)
+	}
+
+	if len(engine.indexed) != 1 || len(engine.indexed[0]) != 1 || engine.indexed[0][0] != 1 {
+		t.Errorf("expected only the shared article in the shared index, got %v", engine.indexed)
+	}
+	docs := tenants.indexed["acme"]
+	if len(docs) != 1 || docs[0]["id"] != "article-4" || docs[0]["tenant_id"] != "acme" || docs[0]["project_id"] != "docs" {
+		t.Errorf("expected the tenant's article in its index, got %v", docs)
+	}
+	if len(engine.deleted) != 0 {
+		t.Errorf("expected nothing deleted from the shared index, got %v", engine.deleted)
+	}
+	if deleted := tenants.deleted["acme"]; len(deleted) != 1 || deleted[0] != "article-9" {
+		t.Errorf("expected the deleted article's document to be removed from the tenant's index, got %v", deleted)
+	}
+	if docs := tenants.indexed["acme/docs"]; len(docs) != 1 || docs[0]["id"] != "article-4" {
+		t.Errorf("expected the project's article in the project's index, got %v", docs)
+	}
+	if deleted := tenants.deleted["acme/docs"]; len(deleted) != 1 || deleted[0] != "article-9" {
+		t.Errorf("expected the deleted article's document to be removed from the project's index, got %v", deleted)
+	}
+}
+
+func TestIndexSyncManager_ProjectIntentDropsTheProjectsIndex(t *testing.T) {
+	m, outbox, clock := newTestIndexSyncManager(&flakyArticlesEngine{}, 3)
+	indexer := m.ProjectIndexer.(*recordingTenantIndexer)
+	indexer.dropFailures = 1
+
+	outbox.enqueueArticle(models.IndexSyncProject, 0, "acme", "docs")
+	m.DrainOutbox()
+	if intent := outbox.intents[1]; intent == nil || intent.Attempts != 1 {
+		t.Fatalf("expected the failed drop kept for a retry, got %+v", intent)
+	}
+
+	*clock = clock.Add(time.Hour)
+	m.DrainOutbox()
+	if len(outbox.intents) != 0 || len(indexer.dropped) != 1 || indexer.dropped[0] != "acme/docs" {
+		t.Errorf("expected the project's index dropped on the retry, got %v with %d intents left", indexer.dropped, len(outbox.intents))
+	}
+}
@@ -0,0 +1,30 @@
+package search
+
+import "time"
+
+// TaskKind says what an asynchronous tenant index task was enqueued for.
+type TaskKind string
+
+const (
+	// TaskDocuments adds or replaces documents.
+	TaskDocuments TaskKind = "documents"
+	// TaskReset deletes every document, keeping the index settings.
+	TaskReset TaskKind = "reset"
+	// TaskSettings applies the tenant index settings.
+	TaskSettings TaskKind = "settings"
+)
+
+// TaskOutcome reports a finished asynchronous tenant index task. Error is
+// set when the task failed or was canceled.
+type TaskOutcome struct {
+	TenantID          string
+	TaskUID           int64
+	Kind              TaskKind
+	Succeeded         bool
+	Error             string
+	ReceivedDocuments int64
+	IndexedDocuments  int64
+	DeletedDocuments  int64
+	EnqueuedAt        time.Time
+	FinishedAt        time.Time
+}
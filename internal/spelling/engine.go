@@ -0,0 +1,107 @@
+package spelling
+
+import (
+	"strings"
+
+	"mini-search-platform/internal/search"
+	"mini-search-platform/pkg/logging"
+)
+
+// Engine decorates a search.TenantEngine with "did you mean" suggestions.
+// Indexed documents feed the tenant's Vocabulary; a search returning fewer
+// than minHits hits is answered with a Suggestion built from it. With
+// autoCorrect, the suggestion is searched as well and its results are
+// returned instead when they have more hits, flagged as AutoCorrected.
+//
+// Vocabulary failures are logged and never fail the search or the write
+// they accompany.
+type Engine struct {
+	engine      search.TenantEngine
+	vocabulary  *Vocabulary
+	minHits     int
+	autoCorrect bool
+}
+
+func NewEngine(engine search.TenantEngine, vocabulary *Vocabulary, minHits int, autoCorrect bool) *Engine {
+	return &Engine{engine: engine, vocabulary: vocabulary, minHits: minHits, autoCorrect: autoCorrect}
+}
+
+func (e *Engine) SearchTenant(tenantID string, query string, options search.SearchOptions) (search.TenantSearchResponse, error) {
+	result, err := e.engine.SearchTenant(tenantID, query, options)
+	if err != nil || result.Total >= e.minHits || strings.TrimSpace(query) == "" {
+		return result, err
+	}
+
+	suggestion, err := e.vocabulary.Suggest(tenantID, query)
+	if err != nil {
+		logging.Error("failed to load search vocabulary", "tenant_id", tenantID, "error", err)
+		return result, nil
+	}
+	if suggestion == "" {
+		return result, nil
+	}
+
+	if e.autoCorrect {
+		corrected, err := e.engine.SearchTenant(tenantID, suggestion, options)
+		if err != nil {
+			logging.Warn("auto-corrected search failed", "tenant_id", tenantID, "error", err)
+		} else if corrected.Total > result.Total {
+			corrected.Query = query
+			corrected.Suggestion = suggestion
+			corrected.AutoCorrected = true
+			return corrected, nil
+		}
+	}
+
+	result.Suggestion = suggestion
+	return result, nil
+}
+
+func (e *Engine) IndexTenantDocuments(tenantID string, documents []search.TenantDocument) error {
+	if err := e.engine.IndexTenantDocuments(tenantID, documents); err != nil {
+		return err
+	}
+	if err := e.vocabulary.Add(tenantID, documents); err != nil {
+		logging.Error("failed to update search vocabulary", "tenant_id", tenantID, "error", err)
+	}
+	return nil
+}
+
+func (e *Engine) DeleteAllTenantDocuments(tenantID string) error {
+	if err := e.engine.DeleteAllTenantDocuments(tenantID); err != nil {
+		return err
+	}
+	e.reset(tenantID)
+	return nil
+}
+
+func (e *Engine) DeleteTenantIndex(tenantID string) (int64, error) {
+	deleted, err := e.engine.DeleteTenantIndex(tenantID)
+	if err != nil {
+		return deleted, err
+	}
+	e.reset(tenantID)
+	return deleted, nil
+}
+
+func (e *Engine) reset(tenantID string) {
+	if err := e.vocabulary.Reset(tenantID); err != nil {
+		logging.Error("failed to reset search vocabulary", "tenant_id", tenantID, "error", err)
+	}
+}
+
+// PatchTenantDocuments passes through without touching the vocabulary:
+// patches only carry computed attributes such as popularity.
+func (e *Engine) PatchTenantDocuments(tenantID string, patches []search.TenantDocument) error {
+	return e.engine.PatchTenantDocuments(tenantID, patches)
+}
+
+// DeleteTenantDocuments passes through: the vocabulary keeps the deleted
+// documents' words until the tenant's index is reset.
+func (e *Engine) DeleteTenantDocuments(tenantID string, ids []string) error {
+	return e.engine.DeleteTenantDocuments(tenantID, ids)
+}
+
+func (e *Engine) ListTenantDocuments(tenantID string, offset, limit int) (search.TenantListResponse, error) {
+	return e.engine.ListTenantDocuments(tenantID, offset, limit)
+}
@@ -0,0 +1,212 @@
+package spelling
+
+import (
+	"testing"
+
+	"mini-search-platform/internal/models"
+	"mini-search-platform/internal/search"
+)
+
+type memoryVocabularyRepository struct {
+	terms map[string]map[string]int64
+}
+
+func newMemoryVocabularyRepository() *memoryVocabularyRepository {
+	return &memoryVocabularyRepository{terms: make(map[string]map[string]int64)}
+}
+
+func (r *memoryVocabularyRepository) Increment(tenantID string, counts map[string]int64) error {
+	if r.terms[tenantID] == nil {
+		r.terms[tenantID] = make(map[string]int64)
+	}
+	for term, count := range counts {
+		r.terms[tenantID][term] += count
+	}
+	return nil
+}
+
+func (r *memoryVocabularyRepository) Load(tenantID string) (map[string]int64, error) {
+	terms := make(map[string]int64)
+	for term, count := range r.terms[tenantID] {
+		terms[term] = count
+	}
+	return terms, nil
+}
+
+func (r *memoryVocabularyRepository) DeleteByTenant(tenantID string) error {
+	delete(r.terms, tenantID)
+	return nil
+}
+
+var _ models.VocabularyRepository = (*memoryVocabularyRepository)(nil)
+
+// queryEngine returns a canned total per exact query; unknown queries have
+// no hits.
+type queryEngine struct {
+	search.TenantEngine
+	totals map[string]int
+}
+
+func (e *queryEngine) SearchTenant(tenantID, query string, options search.SearchOptions) (search.TenantSearchResponse, error) {
+	hits := []search.TenantDocument{}
+	for i := 0; i < e.totals[query]; i++ {
+		hits = append(hits, search.TenantDocument{"id": i, "matched": query})
+	}
+	return search.TenantSearchResponse{Query: query, Hits: hits, Total: len(hits)}, nil
+}
+
+func (e *queryEngine) IndexTenantDocuments(tenantID string, documents []search.TenantDocument) error {
+	return nil
+}
+
+func (e *queryEngine) DeleteAllTenantDocuments(tenantID string) error {
+	return nil
+}
+
+func TestVocabulary_SuggestPrefersCloserThenMoreFrequentTerms(t *testing.T) {
+	vocabulary := NewVocabulary(newMemoryVocabularyRepository(), 100)
+	err := vocabulary.Add("tenant-a", []search.TenantDocument{
+		{"title": "Leather sandals", "body": "Summer sandals in leather", "price": 30},
+		{"title": "Leather handbag", "tags": []interface{}{"summer", map[string]interface{}{"name": "Sale"}}},
+		{"title": "Candles"},
+		{"title": "Vandals"},
+	})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	cases := map[string]string{
+		"lether sandels": "leather sandals", // "candles" is two edits away
+		"randals":        "sandals",         // as close as "vandals" but more frequent
+		"summre":         "summer",          // a transposition is one edit
+		"leather":        "",                // nothing to correct
+		"sale bag 2024":  "",                // too short or numeric
+		"iphone":         "",                // nothing close enough
+		"Sumer sale 15":  "summer sale 15",  // other words are kept
+	}
+	for query, want := range cases {
+		got, err := vocabulary.Suggest("tenant-a", query)
+		if err != nil {
+			t.Fatalf("Suggest(%q) failed: %v", query, err)
+		}
+		if got != want {
+			t.Errorf("Suggest(%q) = %q, want %q", query, got, want)
+		}
+	}
+
+	if got, _ := vocabulary.Suggest("tenant-b", "lether"); got != "" {
+		t.Errorf("another tenant's vocabulary must not be used, got %q", got)
+	}
+}
+
+func TestEngine_SuggestsOnlyForLowResultQueries(t *testing.T) {
+	inner := &queryEngine{totals: map[string]int{"lether": 0, "leather": 5, "candle": 1}}
+	engine := NewEngine(inner, NewVocabulary(newMemoryVocabularyRepository(), 100), 2, false)
+	if err := engine.IndexTenantDocuments("tenant-a", []search.TenantDocument{
+		{"title": "leather candle"},
+	}); err != nil {
+		t.Fatalf("IndexTenantDocuments failed: %v", err)
+	}
+
+	result, err := engine.SearchTenant("tenant-a", "lether", search.SearchOptions{Limit: 10})
+	if err != nil {
+		t.Fatalf("SearchTenant failed: %v", err)
+	}
+	if result.Suggestion != "leather" || result.AutoCorrected || result.Total != 0 {
+		t.Errorf("expected a suggestion without auto-correction, got %+v", result)
+	}
+
+	result, _ = engine.SearchTenant("tenant-a", "candel", search.SearchOptions{Limit: 10})
+	if result.Suggestion != "candle" {
+		t.Errorf("expected a suggestion below the hit threshold, got %q", result.Suggestion)
+	}
+
+	result, _ = engine.SearchTenant("tenant-a", "leather", search.SearchOptions{Limit: 10})
+	if result.Suggestion != "" {
+		t.Errorf("a query with enough hits must not get a suggestion, got %q", result.Suggestion)
+	}
+}
+
+func TestEngine_AutoCorrectsWhenTheSuggestionFindsMore(t *testing.T) {
+	inner := &queryEngine{totals: map[string]int{"leather": 3}}
+	engine := NewEngine(inner, NewVocabulary(newMemoryVocabularyRepository(), 100), 1, true)
+	engine.IndexTenantDocuments("tenant-a", []search.TenantDocument{{"title": "Leather bags"}})
+
+	result, err := engine.SearchTenant("tenant-a", "Lether", search.SearchOptions{Limit: 10})
+	if err != nil {
+		t.Fatalf("SearchTenant failed: %v", err)
+	}
+	if !result.AutoCorrected || result.Suggestion != "leather" || result.Total != 3 {
+		t.Fatalf("expected the corrected results, got %+v", result)
+	}
+	if result.Query != "Lether" || result.Hits[0]["matched"] != "leather" {
+		t.Errorf("expected the original query with the suggestion's hits, got %+v", result)
+	}
+
+	// The suggestion finds nothing either: the original results are kept.
+	result, _ = engine.SearchTenant("tenant-a", "bagz", search.SearchOptions{Limit: 10})
+	if result.AutoCorrected || result.Suggestion != "bags" {
+		t.Errorf("expected a plain suggestion, got %+v", result)
+	}
+}
+
+func TestEngine_DeletingDocumentsResetsTheVocabulary(t *testing.T) {
+	repo := newMemoryVocabularyRepository()
+	engine := NewEngine(&queryEngine{}, NewVocabulary(repo, 100), 1, false)
+	engine.IndexTenantDocuments("tenant-a", []search.TenantDocument{{"title": "leather"}})
+
+	if result, _ := engine.SearchTenant("tenant-a", "lether", search.SearchOptions{}); result.Suggestion != "leather" {
+		t.Fatalf("expected a suggestion before the reset, got %q", result.Suggestion)
+	}
+	if err := engine.DeleteAllTenantDocuments("tenant-a"); err != nil {
+		t.Fatalf("DeleteAllTenantDocuments failed: %v", err)
+	}
+	if result, _ := engine.SearchTenant("tenant-a", "lether", search.SearchOptions{}); result.Suggestion != "" {
+		t.Errorf("expected no suggestion after the reset, got %q", result.Suggestion)
+	}
+	if len(repo.terms) != 0 {
+		t.Errorf("expected the stored vocabulary to be deleted, got %v", repo.terms)
+	}
+}
+
+// loadCountingRepository counts the vocabularies loaded per tenant.
+type loadCountingRepository struct {
+	*memoryVocabularyRepository
+	loads map[string]int
+}
+
+func (r *loadCountingRepository) Load(tenantID string) (map[string]int64, error) {
+	r.loads[tenantID]++
+	return r.memoryVocabularyRepository.Load(tenantID)
+}
+
+func TestVocabulary_ForgetsTheLeastRecentlyUsedTenantOverTheCap(t *testing.T) {
+	repo := &loadCountingRepository{memoryVocabularyRepository: newMemoryVocabularyRepository(), loads: make(map[string]int)}
+	vocabulary := NewVocabulary(repo, 2)
+	for _, tenantID := range []string{"tenant-a", "tenant-b", "tenant-c"} {
+		if err := vocabulary.Add(tenantID, []search.TenantDocument{{"title": "leather"}}); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+
+	suggest := func(tenantID string) {
+		t.Helper()
+		if suggestion, err := vocabulary.Suggest(tenantID, "lether"); err != nil || suggestion != "leather" {
+			t.Fatalf("expected a suggestion for %s, got %q (%v)", tenantID, suggestion, err)
+		}
+	}
+	suggest("tenant-a")
+	suggest("tenant-b")
+	suggest("tenant-a")
+	// Over the cap: tenant-b is the least recently used and is forgotten.
+	suggest("tenant-c")
+	suggest("tenant-a")
+	suggest("tenant-b")
+
+	want := map[string]int{"tenant-a": 1, "tenant-b": 2, "tenant-c": 1}
+	for tenantID, loads := range want {
+		if repo.loads[tenantID] != loads {
+			t.Errorf("expected %s loaded %d times, got %d", tenantID, loads, repo.loads[tenantID])
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package spelling
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"mini-search-platform/internal/models"
+	"mini-search-platform/internal/search"
+)
+
+// VocabularyFields are the document fields terms are collected from. They
+// match the tenant index's searchable attributes, so every suggested term
+// can actually be found.
+var VocabularyFields = []string{"title", "body", "author", "tags", "brand", "category"}
+
+const (
+	minTermLength = 2
+	maxTermLength = 32
+)
+
+// Vocabulary holds the terms of each tenant's indexed documents with their
+// frequencies. It is persisted through a models.VocabularyRepository and
+// kept in memory once loaded, for at most maxTenants tenants; the least
+// recently used tenant is forgotten first and reloaded when next needed.
+//
+// Frequencies only grow: re-indexing a document counts its terms again.
+// They rank candidate corrections against each other, so the inflation does
+// not matter, and a full reindex starts over from DeleteAllTenantDocuments.
+type Vocabulary struct {
+	repo       models.VocabularyRepository
+	maxTenants int
+
+	// mu guards tenants and lru only; each tenant's terms have their own
+	// lock, so loading or scanning one tenant does not hold up the others.
+	mu      sync.Mutex
+	tenants map[string]*list.Element
+	lru     *list.List
+}
+
+type tenantVocabulary struct {
+	tenantID string
+
+	mu sync.Mutex
+	// terms is nil until loaded from the repository.
+	terms map[string]int64
+}
+
+func NewVocabulary(repo models.VocabularyRepository, maxTenants int) *Vocabulary {
+	return &Vocabulary{
+		repo:       repo,
+		maxTenants: max(maxTenants, 1),
+		tenants:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+// tenant returns the tenant's entry, marking it most recently used. With
+// create, a missing entry is added, evicting the least recently used one
+// over the cap; otherwise a missing entry is nil.
+func (v *Vocabulary) tenant(tenantID string, create bool) *tenantVocabulary {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if elem, ok := v.tenants[tenantID]; ok {
+		v.lru.MoveToFront(elem)
+		return elem.Value.(*tenantVocabulary)
+	}
+	if !create {
+		return nil
+	}
+
+	entry := &tenantVocabulary{tenantID: tenantID}
+	v.tenants[tenantID] = v.lru.PushFront(entry)
+	for v.lru.Len() > v.maxTenants {
+		oldest := v.lru.Back()
+		v.lru.Remove(oldest)
+		delete(v.tenants, oldest.Value.(*tenantVocabulary).tenantID)
+	}
+	return entry
+}
+
+// Add counts the terms of documents into the tenant's vocabulary.
+func (v *Vocabulary) Add(tenantID string, documents []search.TenantDocument) error {
+	counts := make(map[string]int64)
+	for _, doc := range documents {
+		for _, field := range VocabularyFields {
+			collectTerms(doc[field], counts)
+		}
+	}
+	if len(counts) == 0 {
+		return nil
+	}
+
+	if err := v.repo.Increment(tenantID, counts); err != nil {
+		return err
+	}
+
+	// Tenants not loaded yet will read the new counts from the repository.
+	entry := v.tenant(tenantID, false)
+	if entry == nil {
+		return nil
+	}
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.terms != nil {
+		for term, count := range counts {
+			entry.terms[term] += count
+		}
+	}
+	return nil
+}
+
+// Reset forgets the tenant's vocabulary. The entry is dropped after the
+// repository is cleared, so a search in between cannot reload the old terms
+// into a fresh entry.
+func (v *Vocabulary) Reset(tenantID string) error {
+	err := v.repo.DeleteByTenant(tenantID)
+
+	v.mu.Lock()
+	if elem, ok := v.tenants[tenantID]; ok {
+		v.lru.Remove(elem)
+		delete(v.tenants, tenantID)
+	}
+	v.mu.Unlock()
+
+	return err
+}
+
+// Suggest returns query, lowercased, with every unknown term replaced by the
+// closest known one, or "" if no term needed (or had) a correction. Closeness is
+// the edit distance, allowing one edit for terms of up to five letters and
+// two for longer ones; among equally close terms the most frequent wins.
+func (v *Vocabulary) Suggest(tenantID, query string) (string, error) {
+	tokens := words(query)
+	if len(tokens) == 0 {
+		return "", nil
+	}
+
+	// The tenant's lock is held through the scan because Add updates its
+	// terms; loading them only holds up that tenant's searches.
+	entry := v.tenant(tenantID, true)
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.terms == nil {
+		terms, err := v.repo.Load(tenantID)
+		if err != nil {
+			return "", err
+		}
+		entry.terms = terms
+	}
+
+	corrected := false
+	for i, token := range tokens {
+		if !isTerm(token) || entry.terms[token] > 0 {
+			continue
+		}
+		if best := closest(entry.terms, token); best != "" {
+			tokens[i] = best
+			corrected = true
+		}
+	}
+	if !corrected {
+		return "", nil
+	}
+	return strings.Join(tokens, " "), nil
+}
+
+func closest(terms map[string]int64, token string) string {
+	length := utf8.RuneCountInString(token)
+	maxDistance := 2
+	switch {
+	case length < 3:
+		// Too short to tell a typo from a different word.
+		return ""
+	case length <= 5:
+		maxDistance = 1
+	}
+
+	var best string
+	var bestDistance int
+	var bestFrequency int64
+	for term, frequency := range terms {
+		if abs(utf8.RuneCountInString(term)-length) > maxDistance {
+			continue
+		}
+		d := distance(token, term, maxDistance)
+		if d > maxDistance {
+			continue
+		}
+		// Ties on frequency go to the lexically first term so the choice
+		// does not depend on map order.
+		if best == "" || d < bestDistance || (d == bestDistance &&
+			(frequency > bestFrequency || (frequency == bestFrequency && term < best))) {
+			best, bestDistance, bestFrequency = term, d, frequency
+		}
+	}
+	return best
+}
+
+// distance is the optimal string alignment distance between a and b: the
+// Levenshtein distance with adjacent transpositions counted as one edit.
+// Once every alignment exceeds limit it stops and returns limit+1.
+func distance(a, b string, limit int) int {
+	s, t := []rune(a), []rune(b)
+	prev2 := make([]int, len(t)+1)
+	prev := make([]int, len(t)+1)
+	curr := make([]int, len(t)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(s); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+			rowMin = min(rowMin, curr[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+	return prev[len(t)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// Tokenize lowercases text and splits it into vocabulary terms. Numbers and
+// words outside the vocabulary's length bounds are dropped.
+func Tokenize(text string) []string {
+	var terms []string
+	for _, word := range words(text) {
+		if isTerm(word) {
+			terms = append(terms, word)
+		}
+	}
+	return terms
+}
+
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func isTerm(word string) bool {
+	n := utf8.RuneCountInString(word)
+	return n >= minTermLength && n <= maxTermLength && strings.IndexFunc(word, unicode.IsLetter) >= 0
+}
+
+// collectTerms counts the words of a field value, descending into arrays
+// and objects (e.g. tags stored as [{"name": ...}]).
+func collectTerms(value interface{}, counts map[string]int64) {
+	switch v := value.(type) {
+	case string:
+		for _, term := range Tokenize(v) {
+			counts[term]++
+		}
+	case []interface{}:
+		for _, item := range v {
+			collectTerms(item, counts)
+		}
+	case []string:
+		for _, item := range v {
+			collectTerms(item, counts)
+		}
+	case map[string]interface{}:
+		for _, item := range v {
+			collectTerms(item, counts)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package usage
+
+import (
+	"encoding/json"
+	"time"
+
+	"mini-search-platform/internal/models"
+	"mini-search-platform/internal/search"
+)
+
+// MeteredEngine decorates a search.TenantEngine, recording every call
+// against the calling tenant's usage: searches, documents indexed and
+// deleted, bytes ingested, and engine latency.
+type MeteredEngine struct {
+	engine search.TenantEngine
+	meter  *Meter
+}
+
+func NewMeteredEngine(engine search.TenantEngine, meter *Meter) *MeteredEngine {
+	return &MeteredEngine{engine: engine, meter: meter}
+}
+
+// observe records one engine call's latency and outcome on top of delta.
+func (e *MeteredEngine) observe(tenantID string, start time.Time, err error, delta models.UsageRollup) {
+	elapsed := time.Since(start).Milliseconds()
+
+	delta.EngineCalls = 1
+	delta.EngineLatencyMs = elapsed
+	delta.EngineLatencyMaxMs = elapsed
+	if err != nil {
+		delta.EngineErrors = 1
+	}
+
+	e.meter.Record(tenantID, delta)
+}
+
+// documentCount reports how many documents the tenant's index currently
+// holds, so deletions can be metered by the documents they actually remove.
+// A failed count is metered as zero rather than blocking the deletion.
+func (e *MeteredEngine) documentCount(tenantID string) int64 {
+	page, err := e.engine.ListTenantDocuments(tenantID, 0, 1)
+	if err != nil {
+		return 0
+	}
+	return int64(page.Total)
+}
+
+func (e *MeteredEngine) SearchTenant(tenantID string, query string, options search.SearchOptions) (search.TenantSearchResponse, error) {
+	start := time.Now()
+	result, err := e.engine.SearchTenant(tenantID, query, options)
+	e.observe(tenantID, start, err, models.UsageRollup{Searches: 1})
+	return result, err
+}
+
+func (e *MeteredEngine) IndexTenantDocuments(tenantID string, documents []search.TenantDocument) error {
+	var size int64
+	if raw, err := json.Marshal(documents); err == nil {
+		size = int64(len(raw))
+	}
+
+	start := time.Now()
+	err := e.engine.IndexTenantDocuments(tenantID, documents)
+
+	delta := models.UsageRollup{}
+	if err == nil {
+		delta.DocumentsIndexed = int64(len(documents))
+		delta.BytesIngested = size
+	}
+	e.observe(tenantID, start, err, delta)
+	return err
+}
+
+func (e *MeteredEngine) DeleteAllTenantDocuments(tenantID string) error {
+	count := e.documentCount(tenantID)
+
+	start := time.Now()
+	err := e.engine.DeleteAllTenantDocuments(tenantID)
+
+	delta := models.UsageRollup{}
+	if err == nil {
+		delta.DocumentsDeleted = count
+	}
+	e.observe(tenantID, start, err, delta)
+	return err
+}
+
+// DeleteTenantIndex is not metered: it offboards the tenant, whose usage is
+// purged with the rest of its data through Meter.Forget.
+func (e *MeteredEngine) DeleteTenantIndex(tenantID string) (int64, error) {
+	return e.engine.DeleteTenantIndex(tenantID)
+}
+
+// PatchTenantDocuments is not metered: patches carry platform-derived signals
+// (popularity scores) written by background jobs, not tenant traffic.
+func (e *MeteredEngine) PatchTenantDocuments(tenantID string, patches []search.TenantDocument) error {
+	return e.engine.PatchTenantDocuments(tenantID, patches)
+}
+
+func (e *MeteredEngine) DeleteTenantDocuments(tenantID string, ids []string) error {
+	start := time.Now()
+	err := e.engine.DeleteTenantDocuments(tenantID, ids)
+
+	delta := models.UsageRollup{}
+	if err == nil {
+		delta.DocumentsDeleted = int64(len(ids))
+	}
+	e.observe(tenantID, start, err, delta)
+	return err
+}
+
+func (e *MeteredEngine) ListTenantDocuments(tenantID string, offset, limit int) (search.TenantListResponse, error) {
+	start := time.Now()
+	result, err := e.engine.ListTenantDocuments(tenantID, offset, limit)
+	e.observe(tenantID, start, err, models.UsageRollup{})
+	return result, err
+}
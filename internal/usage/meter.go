@@ -0,0 +1,127 @@
+package usage
+
+import (
+	"sync"
+	"time"
+
+	"mini-search-platform/internal/models"
+	"mini-search-platform/pkg/logging"
+)
+
+type bucketKey struct {
+	tenantID string
+	hour     time.Time
+}
+
+// Meter accumulates per-tenant usage in memory, bucketed by hour, and
+// periodically flushes it into a UsageRepository. Buffering keeps the SQLite
+// write off the request path; reads go through Rollups, which flushes first
+// so callers always see everything recorded so far.
+type Meter struct {
+	repo    models.UsageRepository
+	mu      sync.Mutex
+	pending map[bucketKey]*models.UsageRollup
+	now     func() time.Time
+
+	// flushing is held for a whole flush, so Forget can wait out one in
+	// flight.
+	flushing sync.Mutex
+}
+
+func NewMeter(repo models.UsageRepository) *Meter {
+	return &Meter{
+		repo:    repo,
+		pending: make(map[bucketKey]*models.UsageRollup),
+		now:     time.Now,
+	}
+}
+
+// Record merges delta into the tenant's bucket for the current hour.
+func (m *Meter) Record(tenantID string, delta models.UsageRollup) {
+	hour := m.now().UTC().Truncate(time.Hour)
+	key := bucketKey{tenantID: tenantID, hour: hour}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	bucket, ok := m.pending[key]
+	if !ok {
+		bucket = &models.UsageRollup{TenantID: tenantID, Hour: hour}
+		m.pending[key] = bucket
+	}
+	bucket.Merge(&delta)
+}
+
+// Forget runs purge, which deletes the tenant's stored usage, with flushing
+// held off, then drops the tenant's usage not flushed yet. Neither a flush
+// in flight nor a failed one putting its buckets back can then write the
+// tenant's usage again after the purge. If purge fails, nothing is dropped.
+func (m *Meter) Forget(tenantID string, purge func() error) error {
+	m.flushing.Lock()
+	defer m.flushing.Unlock()
+
+	if err := purge(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key := range m.pending {
+		if key.tenantID == tenantID {
+			delete(m.pending, key)
+		}
+	}
+	return nil
+}
+
+// Flush writes every pending bucket to the repository. Buckets that fail to
+// persist are merged back so the next flush retries them.
+func (m *Meter) Flush() error {
+	m.flushing.Lock()
+	defer m.flushing.Unlock()
+
+	m.mu.Lock()
+	pending := m.pending
+	m.pending = make(map[bucketKey]*models.UsageRollup)
+	m.mu.Unlock()
+
+	var firstErr error
+	for key, rollup := range pending {
+		if err := m.repo.Increment(rollup); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			m.mu.Lock()
+			if bucket, ok := m.pending[key]; ok {
+				bucket.Merge(rollup)
+			} else {
+				m.pending[key] = rollup
+			}
+			m.mu.Unlock()
+		}
+	}
+
+	return firstErr
+}
+
+// Start flushes the meter every interval in the background.
+func (m *Meter) Start(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			if err := m.Flush(); err != nil {
+				logging.Error("failed to flush usage rollups", "error", err)
+			}
+		}
+	}()
+}
+
+// Rollups returns the tenant's hourly rollups since the given time, oldest
+// first, after flushing anything still buffered.
+func (m *Meter) Rollups(tenantID string, since time.Time) ([]*models.UsageRollup, error) {
+	if err := m.Flush(); err != nil {
+		return nil, err
+	}
+	return m.repo.ListByTenant(tenantID, since)
+}
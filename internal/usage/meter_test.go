@@ -0,0 +1,221 @@
+package usage
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"mini-search-platform/internal/models"
+	"mini-search-platform/internal/search"
+)
+
+type memoryUsageRepository struct {
+	rows    map[bucketKey]*models.UsageRollup
+	failing bool
+	// incrementing, when set, is called on entering Increment.
+	incrementing func()
+}
+
+func newMemoryUsageRepository() *memoryUsageRepository {
+	return &memoryUsageRepository{rows: make(map[bucketKey]*models.UsageRollup)}
+}
+
+func (r *memoryUsageRepository) Increment(rollup *models.UsageRollup) error {
+	if r.incrementing != nil {
+		r.incrementing()
+	}
+	if r.failing {
+		return errors.New("database unavailable")
+	}
+	key := bucketKey{tenantID: rollup.TenantID, hour: rollup.Hour}
+	row, ok := r.rows[key]
+	if !ok {
+		row = &models.UsageRollup{TenantID: rollup.TenantID, Hour: rollup.Hour}
+		r.rows[key] = row
+	}
+	row.Merge(rollup)
+	return nil
+}
+
+func (r *memoryUsageRepository) ListByTenant(tenantID string, since time.Time) ([]*models.UsageRollup, error) {
+	var out []*models.UsageRollup
+	for key, row := range r.rows {
+		if key.tenantID == tenantID && !key.hour.Before(since.Truncate(time.Hour)) {
+			out = append(out, row)
+		}
+	}
+	return out, nil
+}
+
+func (r *memoryUsageRepository) purge(tenantID string) error {
+	for key := range r.rows {
+		if key.tenantID == tenantID {
+			delete(r.rows, key)
+		}
+	}
+	return nil
+}
+
+type stubTenantEngine struct {
+	documents int
+}
+
+func (e *stubTenantEngine) SearchTenant(tenantID, query string, options search.SearchOptions) (search.TenantSearchResponse, error) {
+	return search.TenantSearchResponse{Query: query}, nil
+}
+
+func (e *stubTenantEngine) IndexTenantDocuments(tenantID string, documents []search.TenantDocument) error {
+	e.documents += len(documents)
+	return nil
+}
+
+func (e *stubTenantEngine) DeleteAllTenantDocuments(tenantID string) error {
+	e.documents = 0
+	return nil
+}
+
+func (e *stubTenantEngine) DeleteTenantIndex(tenantID string) (int64, error) {
+	e.documents = 0
+	return 1, nil
+}
+
+func (e *stubTenantEngine) PatchTenantDocuments(tenantID string, patches []search.TenantDocument) error {
+	return nil
+}
+
+func (e *stubTenantEngine) DeleteTenantDocuments(tenantID string, ids []string) error {
+	return nil
+}
+
+func (e *stubTenantEngine) ListTenantDocuments(tenantID string, offset, limit int) (search.TenantListResponse, error) {
+	return search.TenantListResponse{Total: e.documents}, nil
+}
+
+func TestMeteredEngine_RecordsPerTenantUsage(t *testing.T) {
+	repo := newMemoryUsageRepository()
+	meter := NewMeter(repo)
+	engine := NewMeteredEngine(&stubTenantEngine{}, meter)
+
+	docs := []search.TenantDocument{{"id": "1"}, {"id": "2"}}
+	if err := engine.IndexTenantDocuments("tenant-a", docs); err != nil {
+		t.Fatalf("IndexTenantDocuments failed: %v", err)
+	}
+	if err := engine.DeleteAllTenantDocuments("tenant-a"); err != nil {
+		t.Fatalf("DeleteAllTenantDocuments failed: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := engine.SearchTenant("tenant-a", "shoe", search.SearchOptions{}); err != nil {
+			t.Fatalf("SearchTenant failed: %v", err)
+		}
+	}
+	if _, err := engine.SearchTenant("tenant-b", "shoe", search.SearchOptions{}); err != nil {
+		t.Fatalf("SearchTenant failed: %v", err)
+	}
+
+	rollups, err := meter.Rollups("tenant-a", time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("Rollups failed: %v", err)
+	}
+	if len(rollups) != 1 {
+		t.Fatalf("expected a single hourly rollup, got %d", len(rollups))
+	}
+
+	got := rollups[0]
+	if got.Searches != 3 {
+		t.Errorf("expected 3 searches, got %d", got.Searches)
+	}
+	if got.DocumentsIndexed != 2 || got.DocumentsDeleted != 2 {
+		t.Errorf("expected 2 indexed and 2 deleted, got %d and %d", got.DocumentsIndexed, got.DocumentsDeleted)
+	}
+	if got.BytesIngested == 0 {
+		t.Error("expected bytes ingested to be recorded")
+	}
+	if got.EngineCalls != 5 {
+		t.Errorf("expected 5 engine calls, got %d", got.EngineCalls)
+	}
+}
+
+func TestMeter_FailedFlushIsRetried(t *testing.T) {
+	repo := newMemoryUsageRepository()
+	meter := NewMeter(repo)
+
+	repo.failing = true
+	meter.Record("tenant-a", models.UsageRollup{Searches: 2})
+	if err := meter.Flush(); err == nil {
+		t.Fatal("expected flush to fail")
+	}
+
+	meter.Record("tenant-a", models.UsageRollup{Searches: 1})
+	repo.failing = false
+
+	rollups, err := meter.Rollups("tenant-a", time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("Rollups failed: %v", err)
+	}
+	if len(rollups) != 1 || rollups[0].Searches != 3 {
+		t.Fatalf("expected the failed bucket to be retried and merged, got %+v", rollups)
+	}
+}
+
+func TestMeter_ForgetDropsTheTenantsUsage(t *testing.T) {
+	repo := newMemoryUsageRepository()
+	meter := NewMeter(repo)
+
+	meter.Record("tenant-a", models.UsageRollup{Searches: 1})
+	if err := meter.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	for _, tenantID := range []string{"tenant-a", "tenant-b"} {
+		meter.Record(tenantID, models.UsageRollup{Searches: 1})
+	}
+	if err := meter.Forget("tenant-a", func() error { return repo.purge("tenant-a") }); err != nil {
+		t.Fatalf("Forget failed: %v", err)
+	}
+
+	if rollups, _ := meter.Rollups("tenant-a", time.Now().Add(-time.Hour)); len(rollups) != 0 {
+		t.Errorf("expected no usage left for the offboarded tenant, got %+v", rollups)
+	}
+	if rollups, _ := meter.Rollups("tenant-b", time.Now().Add(-time.Hour)); len(rollups) != 1 {
+		t.Errorf("expected the other tenant's usage kept, got %+v", rollups)
+	}
+}
+
+func TestMeter_ForgetWaitsForAFlushInFlight(t *testing.T) {
+	repo := newMemoryUsageRepository()
+	meter := NewMeter(repo)
+	entered, release := make(chan struct{}), make(chan struct{})
+	repo.incrementing = func() {
+		close(entered)
+		<-release
+	}
+	// The flush in flight fails, putting the tenant's bucket back.
+	repo.failing = true
+
+	meter.Record("tenant-a", models.UsageRollup{Searches: 1})
+	flushed := make(chan error)
+	go func() { flushed <- meter.Flush() }()
+	<-entered
+
+	forgotten := make(chan error)
+	go func() {
+		forgotten <- meter.Forget("tenant-a", func() error { return repo.purge("tenant-a") })
+	}()
+	select {
+	case <-forgotten:
+		t.Fatal("expected the purge to wait for the flush in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-flushed; err == nil {
+		t.Fatal("expected the flush to fail")
+	}
+	if err := <-forgotten; err != nil {
+		t.Fatalf("Forget failed: %v", err)
+	}
+
+	repo.incrementing, repo.failing = nil, false
+	if rollups, _ := meter.Rollups("tenant-a", time.Now().Add(-time.Hour)); len(rollups) != 0 {
+		t.Errorf("expected the put-back usage dropped with the tenant, got %+v", rollups)
+	}
+}
@@ -0,0 +1,117 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// resolveTimeout bounds the DNS lookup of a webhook URL's host.
+const resolveTimeout = 5 * time.Second
+
+// ErrPrivateDestination is returned for a webhook URL, or a connection,
+// whose address is not on the public internet. Like ValidateURL's other
+// errors, it reads as the continuation of the URL field's name.
+var ErrPrivateDestination = errors.New("must resolve to public addresses only")
+
+// nonPublicNetworks are the ranges net.IP's own predicates miss: "this
+// network", shared address space (carrier-grade NAT, which also holds some
+// cloud metadata services), IETF protocol assignments, documentation and
+// benchmarking ranges, and the IPv6 discard, translation and documentation
+// prefixes.
+var nonPublicNetworks = func() []*net.IPNet {
+	var networks []*net.IPNet
+	for _, cidr := range []string{
+		"0.0.0.0/8",
+		"100.64.0.0/10",
+		"192.0.0.0/24",
+		"192.0.2.0/24",
+		"198.18.0.0/15",
+		"198.51.100.0/24",
+		"203.0.113.0/24",
+		"240.0.0.0/4",
+		"64:ff9b::/96",
+		"100::/64",
+		"2001:db8::/32",
+	} {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}()
+
+// isPublicIP reports whether ip is a public unicast address: not loopback,
+// private (RFC 1918, IPv6 unique local), link-local (which holds the cloud
+// metadata endpoint 169.254.169.254), multicast, unspecified or reserved.
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return false
+	}
+	for _, network := range nonPublicNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateURL checks that raw is an absolute http or https URL whose host
+// resolves to public addresses only, unless the dispatcher may reach
+// private networks. The check is repeated on every connection the
+// dispatcher makes, so a host re-pointed at a private address later is
+// still refused.
+func (d *Dispatcher) ValidateURL(raw string) error {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("must be an absolute http or https URL")
+	}
+	if d.allowPrivate {
+		return nil
+	}
+
+	host := u.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		if !isPublicIP(ip) {
+			return ErrPrivateDestination
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		return errors.New("must have a resolvable host")
+	}
+	for _, addr := range addrs {
+		if !isPublicIP(addr.IP) {
+			return ErrPrivateDestination
+		}
+	}
+	return nil
+}
+
+// checkDial is the dialer's Control hook: it runs after the host is
+// resolved, so it sees the address actually connected to, redirects and
+// DNS changes since ValidateURL included.
+func (d *Dispatcher) checkDial(network, address string, _ syscall.RawConn) error {
+	if d.allowPrivate {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+		return ErrPrivateDestination
+	}
+	return nil
+}
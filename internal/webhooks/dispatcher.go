@@ -0,0 +1,255 @@
+package webhooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"mini-search-platform/internal/models"
+	"mini-search-platform/pkg/logging"
+	"mini-search-platform/pkg/retry"
+
+	"github.com/google/uuid"
+)
+
+const (
+	dueBatchSize = 100
+	// maxErrorLength bounds the response excerpt kept in the delivery log.
+	maxErrorLength = 512
+)
+
+// Dispatcher delivers webhooks through a durable delivery log. Enqueue
+// records a pending delivery; the worker started by Start POSTs the due
+// deliveries, up to workers at a time, each signed with the delivery's
+// secret (see Sign). A 2xx response
+// marks it delivered; anything else schedules a retry with exponential
+// backoff (retry.Delay from baseDelay, capped at maxDelay) until
+// maxAttempts attempts have failed, after which the delivery is marked
+// failed and dead-lettered. Redeliver queues a dead letter again.
+//
+// Deliveries are at least once: a receiver that times out after accepting
+// a delivery gets it again, and should deduplicate on DeliveryHeader.
+//
+// Receivers must be on the public internet: every connection is refused
+// whose address is loopback, private, link-local or otherwise reserved (see
+// ValidateURL), unless AllowPrivateNetworks was called.
+type Dispatcher struct {
+	repo         models.WebhookDeliveryRepository
+	client       *http.Client
+	workers      int
+	maxAttempts  int
+	baseDelay    time.Duration
+	maxDelay     time.Duration
+	allowPrivate bool
+	now          func() time.Time
+	wake         chan struct{}
+}
+
+func NewDispatcher(repo models.WebhookDeliveryRepository, timeout time.Duration, maxAttempts, workers int) *Dispatcher {
+	d := &Dispatcher{
+		repo:        repo,
+		workers:     max(workers, 1),
+		maxAttempts: maxAttempts,
+		baseDelay:   10 * time.Second,
+		maxDelay:    time.Hour,
+		now:         time.Now,
+		wake:        make(chan struct{}, 1),
+	}
+
+	// No proxy, so the dial check sees the receiver's own address.
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{
+		Timeout:   timeout,
+		KeepAlive: 30 * time.Second,
+		Control:   d.checkDial,
+	}).DialContext
+	d.client = &http.Client{Timeout: timeout, Transport: transport}
+	return d
+}
+
+// AllowPrivateNetworks lets webhooks reach private and loopback addresses,
+// for local development against a receiver on the same machine.
+func (d *Dispatcher) AllowPrivateNetworks() {
+	d.allowPrivate = true
+}
+
+// Enqueue records a delivery of payload, as JSON, to url and wakes the
+// worker so it is attempted right away.
+func (d *Dispatcher) Enqueue(tenantID, sourceID, event, url, secret string, payload interface{}) (*models.WebhookDelivery, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	now := d.now().UTC()
+	delivery := &models.WebhookDelivery{
+		ID:            uuid.NewString(),
+		TenantID:      tenantID,
+		SourceID:      sourceID,
+		Event:         event,
+		URL:           url,
+		Payload:       body,
+		Secret:        secret,
+		Status:        models.DeliveryPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+	if err := d.repo.Save(delivery); err != nil {
+		return nil, err
+	}
+
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+	return delivery, nil
+}
+
+// Redeliver queues a fresh delivery of a dead letter, to the URL and with
+// the secret it was first sent with, and removes the dead letter. It
+// returns nil if the tenant has no such dead letter.
+func (d *Dispatcher) Redeliver(tenantID, deadLetterID string) (*models.WebhookDelivery, error) {
+	deadLetter, err := d.repo.FindDeadLetter(tenantID, deadLetterID)
+	if err != nil || deadLetter == nil {
+		return nil, err
+	}
+
+	// Queue before deleting: if the delete fails, the dead letter can be
+	// redelivered twice, but it is never lost.
+	delivery, err := d.Enqueue(tenantID, deadLetter.SourceID, deadLetter.Event, deadLetter.URL, deadLetter.Secret, deadLetter.Payload)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.repo.DeleteDeadLetter(tenantID, deadLetterID); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// DeliverDue attempts every delivery that is due, up to workers at a time.
+// Outcomes are saved one at a time as the attempts finish.
+func (d *Dispatcher) DeliverDue() error {
+	for {
+		due, err := d.repo.ListDue(d.now(), dueBatchSize)
+		if err != nil {
+			return err
+		}
+		if err := d.deliverBatch(due); err != nil {
+			return err
+		}
+		if len(due) < dueBatchSize {
+			return nil
+		}
+	}
+}
+
+// deliverBatch attempts the deliveries on the worker pool and saves each
+// outcome, returning the first error saving one. Every attempt is saved
+// (or fails to be) before it returns, so the next batch never lists a
+// delivery still in flight.
+func (d *Dispatcher) deliverBatch(due []*models.WebhookDelivery) error {
+	jobs := make(chan *models.WebhookDelivery)
+	attempted := make(chan *models.WebhookDelivery)
+
+	var wg sync.WaitGroup
+	for i := 0; i < min(d.workers, len(due)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for delivery := range jobs {
+				d.attempt(delivery)
+				attempted <- delivery
+			}
+		}()
+	}
+	go func() {
+		for _, delivery := range due {
+			jobs <- delivery
+		}
+		close(jobs)
+		wg.Wait()
+		close(attempted)
+	}()
+
+	var firstErr error
+	for delivery := range attempted {
+		save := d.repo.Update
+		if delivery.Status == models.DeliveryFailed {
+			save = d.repo.DeadLetter
+		}
+		if err := save(delivery); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// attempt sends the delivery once and records the outcome on it.
+func (d *Dispatcher) attempt(delivery *models.WebhookDelivery) {
+	delivery.Attempts++
+	status, err := d.send(delivery)
+	delivery.ResponseStatus = status
+
+	now := d.now().UTC()
+	if err == nil {
+		delivery.Status = models.DeliveryDelivered
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+		return
+	}
+
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= d.maxAttempts {
+		delivery.Status = models.DeliveryFailed
+		logging.Warn("webhook delivery failed permanently",
+			"tenant_id", delivery.TenantID, "delivery_id", delivery.ID, "attempts", delivery.Attempts, "error", err)
+		return
+	}
+	delivery.NextAttemptAt = now.Add(retry.Delay(delivery.Attempts, d.baseDelay, d.maxDelay))
+}
+
+func (d *Dispatcher) send(delivery *models.WebhookDelivery) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, delivery.ID)
+	req.Header.Set(SignatureHeader, Sign(delivery.Secret, d.now(), delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		excerpt, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorLength))
+		return resp.StatusCode, fmt.Errorf("receiver responded %d: %s", resp.StatusCode, bytes.TrimSpace(excerpt))
+	}
+	return resp.StatusCode, nil
+}
+
+// Start runs the delivery worker: due deliveries are attempted every
+// interval, and right away after Enqueue.
+func (d *Dispatcher) Start(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+			case <-d.wake:
+			}
+			if err := d.DeliverDue(); err != nil {
+				logging.Error("failed to deliver webhooks", "error", err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,400 @@
+package webhooks
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"mini-search-platform/internal/models"
+)
+
+type memoryDeliveryRepository struct {
+	mu          sync.Mutex
+	deliveries  map[string]*models.WebhookDelivery
+	deadLetters map[string]*models.WebhookDeadLetter
+}
+
+func newMemoryDeliveryRepository() *memoryDeliveryRepository {
+	return &memoryDeliveryRepository{
+		deliveries:  make(map[string]*models.WebhookDelivery),
+		deadLetters: make(map[string]*models.WebhookDeadLetter),
+	}
+}
+
+func (r *memoryDeliveryRepository) Save(delivery *models.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := *delivery
+	r.deliveries[delivery.ID] = &stored
+	return nil
+}
+
+func (r *memoryDeliveryRepository) Update(delivery *models.WebhookDelivery) error {
+	return r.Save(delivery)
+}
+
+func (r *memoryDeliveryRepository) ListDue(now time.Time, limit int) ([]*models.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var due []*models.WebhookDelivery
+	for _, d := range r.deliveries {
+		if d.Status == models.DeliveryPending && !d.NextAttemptAt.After(now) {
+			copied := *d
+			due = append(due, &copied)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextAttemptAt.Before(due[j].NextAttemptAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+func (r *memoryDeliveryRepository) ListBySource(tenantID, sourceID string, limit int) ([]*models.WebhookDelivery, error) {
+	return nil, nil
+}
+
+func (r *memoryDeliveryRepository) DeadLetter(delivery *models.WebhookDelivery) error {
+	r.Save(delivery)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id := "dl-" + delivery.ID
+	r.deadLetters[id] = &models.WebhookDeadLetter{
+		ID:         id,
+		DeliveryID: delivery.ID,
+		TenantID:   delivery.TenantID,
+		SourceID:   delivery.SourceID,
+		Event:      delivery.Event,
+		URL:        delivery.URL,
+		Payload:    delivery.Payload,
+		Secret:     delivery.Secret,
+		Attempts:   delivery.Attempts,
+		LastError:  delivery.LastError,
+	}
+	return nil
+}
+
+func (r *memoryDeliveryRepository) ListDeadLetters(tenantID string, limit int) ([]*models.WebhookDeadLetter, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var list []*models.WebhookDeadLetter
+	for _, dl := range r.deadLetters {
+		if dl.TenantID == tenantID {
+			list = append(list, dl)
+		}
+	}
+	return list, nil
+}
+
+func (r *memoryDeliveryRepository) FindDeadLetter(tenantID, id string) (*models.WebhookDeadLetter, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if dl, ok := r.deadLetters[id]; ok && dl.TenantID == tenantID {
+		return dl, nil
+	}
+	return nil, nil
+}
+
+func (r *memoryDeliveryRepository) DeleteDeadLetter(tenantID, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.deadLetters, id)
+	return nil
+}
+
+func (r *memoryDeliveryRepository) get(id string) models.WebhookDelivery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return *r.deliveries[id]
+}
+
+// receiver is a local webhook endpoint answering with the queued status
+// codes (200 once they run out) and recording what it was sent.
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.requests = append(rc.requests, r)
+	rc.bodies = append(rc.bodies, body)
+	status := http.StatusOK
+	if len(rc.statuses) > 0 {
+		status, rc.statuses = rc.statuses[0], rc.statuses[1:]
+	}
+	w.WriteHeader(status)
+	w.Write([]byte("receiver says hi"))
+}
+
+// fixedClock lets tests step time past retry delays.
+type fixedClock struct{ t time.Time }
+
+func (c *fixedClock) now() time.Time { return c.t }
+
+func newTestDispatcher(repo *memoryDeliveryRepository, maxAttempts int) (*Dispatcher, *fixedClock) {
+	clock := &fixedClock{t: time.Now()}
+	d := NewDispatcher(repo, 5*time.Second, maxAttempts, 4)
+	// Test receivers listen on loopback.
+	d.AllowPrivateNetworks()
+	d.now = clock.now
+	return d, clock
+}
+
+func TestDispatcher_DeliversSignedPayload(t *testing.T) {
+	rc := &receiver{}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	repo := newMemoryDeliveryRepository()
+	d, clock := newTestDispatcher(repo, 3)
+
+	delivery, err := d.Enqueue("tenant-a", "source-1", "test.event", server.URL, "whsec_test", map[string]string{"hello": "world"})
+	if err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	if err := d.DeliverDue(); err != nil {
+		t.Fatalf("DeliverDue failed: %v", err)
+	}
+
+	if len(rc.requests) != 1 {
+		t.Fatalf("expected one request, got %d", len(rc.requests))
+	}
+	req, body := rc.requests[0], rc.bodies[0]
+	if string(body) != `{"hello":"world"}` {
+		t.Errorf("unexpected body %s", body)
+	}
+	if req.Header.Get(EventHeader) != "test.event" || req.Header.Get(DeliveryHeader) != delivery.ID {
+		t.Errorf("unexpected headers %v", req.Header)
+	}
+	if err := Verify("whsec_test", req.Header.Get(SignatureHeader), body, time.Minute, clock.t); err != nil {
+		t.Errorf("signature did not verify: %v", err)
+	}
+	if err := Verify("whsec_other", req.Header.Get(SignatureHeader), body, time.Minute, clock.t); err == nil {
+		t.Error("a signature must not verify with another secret")
+	}
+
+	stored := repo.get(delivery.ID)
+	if stored.Status != models.DeliveryDelivered || stored.Attempts != 1 || stored.ResponseStatus != 200 || stored.DeliveredAt == nil {
+		t.Errorf("expected a delivered log entry, got %+v", stored)
+	}
+}
+
+func TestDispatcher_RetriesWithBackoffThenGivesUp(t *testing.T) {
+	rc := &receiver{statuses: []int{500, 503, 500}}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	repo := newMemoryDeliveryRepository()
+	d, clock := newTestDispatcher(repo, 3)
+
+	delivery, _ := d.Enqueue("tenant-a", "source-1", "test.event", server.URL, "whsec_test", map[string]int{"n": 1})
+	d.DeliverDue()
+
+	stored := repo.get(delivery.ID)
+	if stored.Status != models.DeliveryPending || stored.Attempts != 1 || stored.ResponseStatus != 500 {
+		t.Fatalf("expected a pending retry after the first failure, got %+v", stored)
+	}
+	if want := clock.t.Add(10 * time.Second); !stored.NextAttemptAt.Equal(want.UTC()) {
+		t.Errorf("expected the retry at %v, got %v", want, stored.NextAttemptAt)
+	}
+	if stored.LastError != "receiver responded 500: receiver says hi" {
+		t.Errorf("unexpected last error %q", stored.LastError)
+	}
+
+	// Not due yet: nothing is sent.
+	d.DeliverDue()
+	if len(rc.requests) != 1 {
+		t.Fatalf("a retry must wait for its backoff, got %d requests", len(rc.requests))
+	}
+
+	clock.t = clock.t.Add(10 * time.Second)
+	d.DeliverDue()
+	if stored = repo.get(delivery.ID); stored.Attempts != 2 || !stored.NextAttemptAt.Equal(clock.t.Add(20*time.Second).UTC()) {
+		t.Fatalf("expected the delay to double, got %+v", stored)
+	}
+
+	clock.t = clock.t.Add(20 * time.Second)
+	d.DeliverDue()
+	if stored = repo.get(delivery.ID); stored.Status != models.DeliveryFailed || stored.Attempts != 3 {
+		t.Errorf("expected the delivery to fail after 3 attempts, got %+v", stored)
+	}
+
+	if deadLetters, _ := repo.ListDeadLetters("tenant-a", 10); len(deadLetters) != 1 || deadLetters[0].DeliveryID != delivery.ID {
+		t.Errorf("expected the failed delivery to be dead-lettered, got %+v", deadLetters)
+	}
+
+	clock.t = clock.t.Add(time.Hour)
+	d.DeliverDue()
+	if len(rc.requests) != 3 {
+		t.Errorf("a failed delivery must not be retried, got %d requests", len(rc.requests))
+	}
+}
+
+func TestDispatcher_RedeliversDeadLetter(t *testing.T) {
+	rc := &receiver{statuses: []int{500}}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	repo := newMemoryDeliveryRepository()
+	d, _ := newTestDispatcher(repo, 1)
+
+	first, _ := d.Enqueue("tenant-a", "source-1", "test.event", server.URL, "whsec_test", map[string]int{"n": 1})
+	d.DeliverDue()
+	deadLetters, _ := repo.ListDeadLetters("tenant-a", 10)
+	if len(deadLetters) != 1 {
+		t.Fatalf("expected one dead letter, got %d", len(deadLetters))
+	}
+
+	if missing, err := d.Redeliver("tenant-b", deadLetters[0].ID); err != nil || missing != nil {
+		t.Fatalf("another tenant's dead letter must not be redelivered, got %+v, %v", missing, err)
+	}
+
+	redelivery, err := d.Redeliver("tenant-a", deadLetters[0].ID)
+	if err != nil || redelivery == nil {
+		t.Fatalf("Redeliver failed: %+v, %v", redelivery, err)
+	}
+	if redelivery.ID == first.ID || redelivery.SourceID != "source-1" || string(redelivery.Payload) != `{"n":1}` {
+		t.Errorf("expected a new delivery of the same payload, got %+v", redelivery)
+	}
+	if remaining, _ := repo.ListDeadLetters("tenant-a", 10); len(remaining) != 0 {
+		t.Errorf("expected the dead letter to be removed, got %+v", remaining)
+	}
+
+	d.DeliverDue()
+	if len(rc.requests) != 2 || string(rc.bodies[1]) != `{"n":1}` {
+		t.Fatalf("expected the payload to be sent again, got %d requests", len(rc.requests))
+	}
+	if stored := repo.get(redelivery.ID); stored.Status != models.DeliveryDelivered {
+		t.Errorf("expected the redelivery to succeed, got %+v", stored)
+	}
+}
+
+func TestDispatcher_UnreachableReceiverIsRetried(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	repo := newMemoryDeliveryRepository()
+	d, _ := newTestDispatcher(repo, 3)
+
+	delivery, _ := d.Enqueue("tenant-a", "source-1", "test.event", url, "whsec_test", nil)
+	if err := d.DeliverDue(); err != nil {
+		t.Fatalf("a delivery failure must not fail the worker: %v", err)
+	}
+	if stored := repo.get(delivery.ID); stored.Status != models.DeliveryPending || stored.LastError == "" || stored.ResponseStatus != 0 {
+		t.Errorf("expected a pending retry recording the error, got %+v", stored)
+	}
+}
+
+func TestDispatcher_DeliversConcurrently(t *testing.T) {
+	const deliveries = 4
+	arrived := make(chan struct{}, deliveries)
+	all := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arrived <- struct{}{}
+		// Only answers once every delivery is in flight at the same time.
+		select {
+		case <-all:
+			w.WriteHeader(http.StatusOK)
+		case <-time.After(2 * time.Second):
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	go func() {
+		for i := 0; i < deliveries; i++ {
+			<-arrived
+		}
+		close(all)
+	}()
+
+	repo := newMemoryDeliveryRepository()
+	d, _ := newTestDispatcher(repo, 3)
+	var ids []string
+	for i := 0; i < deliveries; i++ {
+		delivery, _ := d.Enqueue("tenant-a", "source-1", "test.event", server.URL, "whsec_test", nil)
+		ids = append(ids, delivery.ID)
+	}
+	if err := d.DeliverDue(); err != nil {
+		t.Fatalf("DeliverDue failed: %v", err)
+	}
+
+	for _, id := range ids {
+		if stored := repo.get(id); stored.Status != models.DeliveryDelivered {
+			t.Errorf("expected every delivery attempted at once and delivered, got %+v", stored)
+		}
+	}
+}
+
+func TestDispatcher_RefusesPrivateDestinations(t *testing.T) {
+	d := NewDispatcher(newMemoryDeliveryRepository(), 5*time.Second, 3, 1)
+
+	cases := map[string]error{
+		"https://93.184.216.34/hook":          nil,
+		"http://127.0.0.1:8080/hook":          ErrPrivateDestination,
+		"http://localhost/hook":               ErrPrivateDestination,
+		"http://10.1.2.3/hook":                ErrPrivateDestination,
+		"http://172.16.0.1/hook":              ErrPrivateDestination,
+		"http://192.168.1.1/hook":             ErrPrivateDestination,
+		"http://169.254.169.254/latest/meta":  ErrPrivateDestination,
+		"http://100.100.100.200/latest/meta":  ErrPrivateDestination,
+		"http://0.0.0.0/hook":                 ErrPrivateDestination,
+		"http://[::1]/hook":                   ErrPrivateDestination,
+		"http://[fd00:ec2::254]/hook":         ErrPrivateDestination,
+		"http://[::ffff:127.0.0.1]/hook":      ErrPrivateDestination,
+		"http://[fe80::1]/hook":               ErrPrivateDestination,
+		"http://[2606:2800:220:1::248]/hook":  nil,
+		"http://[64:ff9b::a00:1]/hook":        ErrPrivateDestination,
+		"https://203.0.113.7/documentation":   ErrPrivateDestination,
+		"https://198.18.0.1/benchmarking":     ErrPrivateDestination,
+		"https://192.0.0.170/protocol-assign": ErrPrivateDestination,
+	}
+	for raw, want := range cases {
+		if err := d.ValidateURL(raw); err != want {
+			t.Errorf("%s: expected %v, got %v", raw, want, err)
+		}
+	}
+
+	if err := d.ValidateURL("ftp://93.184.216.34/hook"); err == nil {
+		t.Error("expected a non-http URL refused")
+	}
+
+	d.AllowPrivateNetworks()
+	if err := d.ValidateURL("http://127.0.0.1:8080/hook"); err != nil {
+		t.Errorf("expected private destinations allowed, got %v", err)
+	}
+}
+
+func TestDispatcher_RefusesPrivateReceiversWhenConnecting(t *testing.T) {
+	rc := &receiver{}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	repo := newMemoryDeliveryRepository()
+	d := NewDispatcher(repo, 5*time.Second, 3, 1)
+
+	// Enqueued without ValidateURL, like a subscription whose host was
+	// re-pointed at a private address after it was created.
+	delivery, _ := d.Enqueue("tenant-a", "source-1", "test.event", server.URL, "whsec_test", nil)
+	if err := d.DeliverDue(); err != nil {
+		t.Fatalf("DeliverDue failed: %v", err)
+	}
+
+	if len(rc.requests) != 0 {
+		t.Errorf("expected the private receiver never reached, got %d requests", len(rc.requests))
+	}
+	if stored := repo.get(delivery.ID); stored.Status != models.DeliveryPending || !strings.Contains(stored.LastError, ErrPrivateDestination.Error()) {
+		t.Errorf("expected a retry recording the refusal, got %+v", stored)
+	}
+}
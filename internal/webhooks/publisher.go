@@ -0,0 +1,99 @@
+package webhooks
+
+import (
+	"time"
+
+	"mini-search-platform/internal/models"
+	"mini-search-platform/internal/search"
+	"mini-search-platform/pkg/logging"
+
+	"github.com/google/uuid"
+)
+
+// LifecycleEvent is the body of a lifecycle webhook. ID is the same in
+// every delivery of the event, redeliveries included, so receivers can
+// deduplicate on it.
+type LifecycleEvent struct {
+	ID         string              `json:"id"`
+	Event      models.WebhookEvent `json:"event"`
+	TenantID   string              `json:"tenant_id"`
+	OccurredAt time.Time           `json:"occurred_at"`
+	Data       interface{}         `json:"data"`
+}
+
+// Publisher fans a tenant's indexing lifecycle events out to the tenant's
+// subscriptions, one Dispatcher delivery per interested subscription.
+// Failures are logged: a lost event must not fail the indexing that
+// caused it.
+type Publisher struct {
+	subscriptions models.WebhookSubscriptionRepository
+	dispatcher    *Dispatcher
+	now           func() time.Time
+}
+
+func NewPublisher(subscriptions models.WebhookSubscriptionRepository, dispatcher *Dispatcher) *Publisher {
+	return &Publisher{subscriptions: subscriptions, dispatcher: dispatcher, now: time.Now}
+}
+
+func (p *Publisher) Publish(tenantID string, event models.WebhookEvent, data interface{}) {
+	subscriptions, err := p.subscriptions.ListByTenant(tenantID)
+	if err != nil {
+		logging.Error("failed to load webhook subscriptions", "tenant_id", tenantID, "event", event, "error", err)
+		return
+	}
+
+	payload := LifecycleEvent{
+		ID:         uuid.NewString(),
+		Event:      event,
+		TenantID:   tenantID,
+		OccurredAt: p.now().UTC(),
+		Data:       data,
+	}
+	for _, subscription := range subscriptions {
+		if !subscription.Wants(event) {
+			continue
+		}
+		if _, err := p.dispatcher.Enqueue(tenantID, subscription.ID, string(event), subscription.URL, subscription.Secret, payload); err != nil {
+			logging.Error("failed to queue webhook", "tenant_id", tenantID, "subscription_id", subscription.ID, "event", event, "error", err)
+		}
+	}
+}
+
+// HandleTask publishes the event for a finished engine task: its documents
+// were indexed or failed, its index was reset, or its settings were
+// applied. Failed resets and settings updates have no event and are only
+// logged.
+func (p *Publisher) HandleTask(outcome search.TaskOutcome) {
+	switch {
+	case outcome.Kind == search.TaskDocuments && outcome.Succeeded:
+		p.Publish(outcome.TenantID, models.EventDocumentsIndexed, map[string]interface{}{
+			"task_uid":           outcome.TaskUID,
+			"received_documents": outcome.ReceivedDocuments,
+			"indexed_documents":  outcome.IndexedDocuments,
+			"enqueued_at":        outcome.EnqueuedAt,
+			"finished_at":        outcome.FinishedAt,
+		})
+	case outcome.Kind == search.TaskDocuments:
+		p.Publish(outcome.TenantID, models.EventDocumentsFailed, map[string]interface{}{
+			"task_uid":           outcome.TaskUID,
+			"received_documents": outcome.ReceivedDocuments,
+			"error":              outcome.Error,
+			"enqueued_at":        outcome.EnqueuedAt,
+			"finished_at":        outcome.FinishedAt,
+		})
+	case outcome.Kind == search.TaskReset && outcome.Succeeded:
+		p.Publish(outcome.TenantID, models.EventIndexReset, map[string]interface{}{
+			"task_uid":          outcome.TaskUID,
+			"deleted_documents": outcome.DeletedDocuments,
+			"finished_at":       outcome.FinishedAt,
+		})
+	case outcome.Kind == search.TaskSettings && outcome.Succeeded:
+		p.Publish(outcome.TenantID, models.EventSettingsUpdated, map[string]interface{}{
+			"task_uid":    outcome.TaskUID,
+			"finished_at": outcome.FinishedAt,
+		})
+	default:
+		logging.Warn("tenant index task failed",
+			"tenant_id", outcome.TenantID, "task_uid", outcome.TaskUID, "kind", outcome.Kind, "error", outcome.Error)
+	}
+}
@@ -0,0 +1,138 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"mini-search-platform/internal/models"
+	"mini-search-platform/internal/search"
+)
+
+type memorySubscriptionRepository struct {
+	subscriptions []*models.WebhookSubscription
+}
+
+func (r *memorySubscriptionRepository) Save(s *models.WebhookSubscription) error {
+	r.subscriptions = append(r.subscriptions, s)
+	return nil
+}
+
+func (r *memorySubscriptionRepository) Update(s *models.WebhookSubscription) error { return nil }
+
+func (r *memorySubscriptionRepository) Delete(tenantID, id string) error { return nil }
+
+func (r *memorySubscriptionRepository) FindByID(tenantID, id string) (*models.WebhookSubscription, error) {
+	return nil, nil
+}
+
+func (r *memorySubscriptionRepository) ListByTenant(tenantID string) ([]*models.WebhookSubscription, error) {
+	var list []*models.WebhookSubscription
+	for _, s := range r.subscriptions {
+		if s.TenantID == tenantID {
+			list = append(list, s)
+		}
+	}
+	return list, nil
+}
+
+func TestPublisher_HandleTaskNotifiesInterestedSubscriptions(t *testing.T) {
+	rc := &receiver{}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	subscriptions := &memorySubscriptionRepository{}
+	subscriptions.Save(&models.WebhookSubscription{
+		ID: "sub-indexed", TenantID: "tenant-a", URL: server.URL, Secret: "whsec_a", Enabled: true,
+		Events: []models.WebhookEvent{models.EventDocumentsIndexed},
+	})
+	subscriptions.Save(&models.WebhookSubscription{
+		ID: "sub-disabled", TenantID: "tenant-a", URL: server.URL, Secret: "whsec_b", Enabled: false,
+		Events: []models.WebhookEvent{models.EventDocumentsIndexed},
+	})
+	subscriptions.Save(&models.WebhookSubscription{
+		ID: "sub-reset", TenantID: "tenant-a", URL: server.URL, Secret: "whsec_c", Enabled: true,
+		Events: []models.WebhookEvent{models.EventIndexReset},
+	})
+	subscriptions.Save(&models.WebhookSubscription{
+		ID: "sub-other-tenant", TenantID: "tenant-b", URL: server.URL, Secret: "whsec_d", Enabled: true,
+		Events: []models.WebhookEvent{models.EventDocumentsIndexed},
+	})
+
+	repo := newMemoryDeliveryRepository()
+	d, clock := newTestDispatcher(repo, 3)
+	publisher := NewPublisher(subscriptions, d)
+
+	publisher.HandleTask(search.TaskOutcome{
+		TenantID:          "tenant-a",
+		TaskUID:           42,
+		Kind:              search.TaskDocuments,
+		Succeeded:         true,
+		ReceivedDocuments: 3,
+		IndexedDocuments:  3,
+	})
+	// A failed settings update has no event.
+	publisher.HandleTask(search.TaskOutcome{TenantID: "tenant-a", TaskUID: 43, Kind: search.TaskSettings, Error: "boom"})
+	if err := d.DeliverDue(); err != nil {
+		t.Fatalf("DeliverDue failed: %v", err)
+	}
+
+	if len(rc.requests) != 1 {
+		t.Fatalf("expected only the enabled documents.indexed subscription to be called, got %d requests", len(rc.requests))
+	}
+	req, body := rc.requests[0], rc.bodies[0]
+	if req.Header.Get(EventHeader) != string(models.EventDocumentsIndexed) {
+		t.Errorf("unexpected event header %q", req.Header.Get(EventHeader))
+	}
+	if err := Verify("whsec_a", req.Header.Get(SignatureHeader), body, time.Minute, clock.t); err != nil {
+		t.Errorf("signature did not verify with the subscription secret: %v", err)
+	}
+
+	var event struct {
+		LifecycleEvent
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &event); err != nil {
+		t.Fatalf("invalid payload %s: %v", body, err)
+	}
+	if event.ID == "" || event.Event != models.EventDocumentsIndexed || event.TenantID != "tenant-a" {
+		t.Errorf("unexpected envelope %s", body)
+	}
+	if event.Data["task_uid"] != float64(42) || event.Data["indexed_documents"] != float64(3) {
+		t.Errorf("unexpected data %v", event.Data)
+	}
+}
+
+func TestPublisher_HandleTaskMapsOutcomesToEvents(t *testing.T) {
+	cases := []struct {
+		outcome search.TaskOutcome
+		event   models.WebhookEvent
+	}{
+		{search.TaskOutcome{Kind: search.TaskDocuments, Succeeded: true}, models.EventDocumentsIndexed},
+		{search.TaskOutcome{Kind: search.TaskDocuments, Error: "invalid document"}, models.EventDocumentsFailed},
+		{search.TaskOutcome{Kind: search.TaskReset, Succeeded: true}, models.EventIndexReset},
+		{search.TaskOutcome{Kind: search.TaskSettings, Succeeded: true}, models.EventSettingsUpdated},
+	}
+
+	all := []models.WebhookEvent{models.EventDocumentsIndexed, models.EventDocumentsFailed, models.EventIndexReset, models.EventSettingsUpdated}
+	for _, tc := range cases {
+		subscriptions := &memorySubscriptionRepository{}
+		subscriptions.Save(&models.WebhookSubscription{ID: "sub", TenantID: "tenant-a", URL: "http://example.invalid", Enabled: true, Events: all})
+		repo := newMemoryDeliveryRepository()
+		d, _ := newTestDispatcher(repo, 3)
+
+		tc.outcome.TenantID = "tenant-a"
+		NewPublisher(subscriptions, d).HandleTask(tc.outcome)
+
+		if len(repo.deliveries) != 1 {
+			t.Errorf("%+v: expected one delivery, got %d", tc.outcome, len(repo.deliveries))
+			continue
+		}
+		for _, delivery := range repo.deliveries {
+			if delivery.Event != string(tc.event) || delivery.SourceID != "sub" {
+				t.Errorf("%+v: expected a %s delivery for the subscription, got %+v", tc.outcome, tc.event, delivery)
+			}
+		}
+	}
+}
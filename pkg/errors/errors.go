@@ -18,9 +18,10 @@ const (
 	ErrCodeRateLimited  ErrorCode = "RATE_LIMITED"
 
 	// Server errors (5xx)
-	ErrCodeDatabase ErrorCode = "DATABASE_ERROR"
-	ErrCodeSearch   ErrorCode = "SEARCH_ERROR"
-	ErrCodeInternal ErrorCode = "INTERNAL_ERROR"
+	ErrCodeDatabase    ErrorCode = "DATABASE_ERROR"
+	ErrCodeSearch      ErrorCode = "SEARCH_ERROR"
+	ErrCodeInternal    ErrorCode = "INTERNAL_ERROR"
+	ErrCodeUnavailable ErrorCode = "SERVICE_UNAVAILABLE"
 )
 
 // AppError represents a structured application error
@@ -128,3 +129,13 @@ func Internal(message string, err error) *AppError {
 		http.StatusInternalServerError,
 	).WithError(err)
 }
+
+// Unavailable creates a service unavailable error, for a downstream
+// dependency that cannot currently be reached
+func Unavailable(message string, err error) *AppError {
+	return NewAppError(
+		ErrCodeUnavailable,
+		message,
+		http.StatusServiceUnavailable,
+	).WithError(err)
+}
@@ -17,3 +17,19 @@ func WithBackoff(ctx context.Context, operation func() error) error {
 
 	return nil
 }
+
+// Delay returns how long to wait before retry number attempt (1 for the
+// first retry) on an exponential schedule: initial, doubling each time,
+// capped at max. Unlike WithBackoff it keeps no state, so retries persisted
+// across restarts (e.g. webhook deliveries) can be scheduled from their
+// attempt count alone.
+func Delay(attempt int, initial, max time.Duration) time.Duration {
+	delay := initial
+	for i := 1; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		return max
+	}
+	return delay
+}
@@ -12,6 +12,12 @@ const (
 	ContextKeyEmail    = "email"
 	ContextKeyTenantID = "tenant_id"
 	ContextKeyRole     = "role"
+	// ContextKeyPermissions holds the permissions the role grants, as
+	// resolved by middleware.RequireTenant.
+	ContextKeyPermissions = "permissions"
+	// ContextKeySessionID holds the session the access token was issued
+	// in, if any.
+	ContextKeySessionID = "session_id"
 )
 
 var (
@@ -31,6 +37,30 @@ func SetTenantContext(c *gin.Context, tenantID string, role models.Role) {
 	c.Set(ContextKeyRole, string(role))
 }
 
+func SetSessionID(c *gin.Context, sessionID string) {
+	c.Set(ContextKeySessionID, sessionID)
+}
+
+func SetPermissions(c *gin.Context, permissions []models.Permission) {
+	c.Set(ContextKeyPermissions, permissions)
+}
+
+// HasPermission reports whether the user's role in the request's tenant
+// grants permission. Without resolved permissions it grants nothing.
+func HasPermission(c *gin.Context, permission models.Permission) bool {
+	value, exists := c.Get(ContextKeyPermissions)
+	if !exists {
+		return false
+	}
+	permissions, _ := value.([]models.Permission)
+	for _, granted := range permissions {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
 func GetUserID(c *gin.Context) (string, error) {
 	value, exists := c.Get(ContextKeyUserID)
 	if !exists {
@@ -79,6 +109,14 @@ func GetRole(c *gin.Context) (models.Role, error) {
 	return models.Role(roleStr), nil
 }
 
+// GetSessionID returns the session of the request's access token, or ""
+// for tokens issued outside a session.
+func GetSessionID(c *gin.Context) string {
+	sessionID, _ := c.Get(ContextKeySessionID)
+	id, _ := sessionID.(string)
+	return id
+}
+
 func MustGetUserID(c *gin.Context) string {
 	userID, _ := GetUserID(c)
 	return userID
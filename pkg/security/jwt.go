@@ -2,9 +2,11 @@ package security
 
 import (
 	"errors"
+	"mini-search-platform/internal/models"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 )
 
 var (
@@ -16,6 +18,12 @@ type TokenClaims struct {
 	UserID   string `json:"user_id"`
 	Email    string `json:"email"`
 	TenantID string `json:"tenant_id,omitempty"`
+	// Role is the user's role in TenantID when the token was issued, for
+	// clients; the server re-reads the membership on every request.
+	Role models.Role `json:"role,omitempty"`
+	// SessionID is the session (see models.Session) the token was issued
+	// in; revoking the session revokes the token by its ID (the jti).
+	SessionID string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -34,16 +42,29 @@ func NewJWTService(secretKey string, issuer string, ttl time.Duration) *JWTServi
 }
 
 func (j *JWTService) GenerateToken(userID, email string) (string, error) {
-	return j.GenerateTenantToken(userID, email, "")
+	return j.GenerateTenantToken(userID, email, "", "")
 }
 
-func (j *JWTService) GenerateTenantToken(userID, email, tenantID string) (string, error) {
+// GenerateTenantToken issues a token bound to tenantID, in which the user
+// holds role.
+func (j *JWTService) GenerateTenantToken(userID, email, tenantID string, role models.Role) (string, error) {
+	token, _, err := j.GenerateSessionToken("", userID, email, tenantID, role)
+	return token, err
+}
+
+// GenerateSessionToken is GenerateTenantToken for a token issued in
+// sessionID, also returning its claims so the caller can record the token's
+// ID and expiry.
+func (j *JWTService) GenerateSessionToken(sessionID, userID, email, tenantID string, role models.Role) (string, *TokenClaims, error) {
 	now := time.Now()
-	claims := TokenClaims{
-		UserID:   userID,
-		Email:    email,
-		TenantID: tenantID,
+	claims := &TokenClaims{
+		UserID:    userID,
+		Email:     email,
+		TenantID:  tenantID,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
 			Issuer:    j.issuer,
 			Subject:   userID,
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -51,8 +72,11 @@ func (j *JWTService) GenerateTenantToken(userID, email, tenantID string) (string
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(j.secretKey)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(j.secretKey)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, claims, nil
 }
 
 func (j *JWTService) ValidateToken(tokenString string) (*TokenClaims, error) {
@@ -76,23 +100,3 @@ func (j *JWTService) ValidateToken(tokenString string) (*TokenClaims, error) {
 
 	return nil, ErrInvalidToken
 }
-
-func (j *JWTService) RefreshToken(tokenString string) (string, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &TokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, ErrInvalidToken
-		}
-		return j.secretKey, nil
-	})
-
-	if err != nil && !errors.Is(err, jwt.ErrTokenExpired) {
-		return "", ErrInvalidToken
-	}
-
-	claims, ok := token.Claims.(*TokenClaims)
-	if !ok {
-		return "", ErrInvalidToken
-	}
-
-	return j.GenerateTenantToken(claims.UserID, claims.Email, claims.TenantID)
-}
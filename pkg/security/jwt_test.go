@@ -1,6 +1,7 @@
 package security
 
 import (
+	"mini-search-platform/internal/models"
 	"testing"
 	"time"
 
@@ -39,7 +40,7 @@ func TestGenerateToken(t *testing.T) {
 func TestGenerateTenantToken(t *testing.T) {
 	service := NewJWTService("test-secret", "test-issuer", time.Hour)
 
-	token, err := service.GenerateTenantToken("user123", "test@example.com", "tenant456")
+	token, err := service.GenerateTenantToken("user123", "test@example.com", "tenant456", models.RoleBilling)
 	if err != nil {
 		t.Fatalf("GenerateTenantToken failed: %v", err)
 	}
@@ -56,6 +57,9 @@ func TestGenerateTenantToken(t *testing.T) {
 	if claims.TenantID != "tenant456" {
 		t.Errorf("Expected tenant ID 'tenant456', got '%s'", claims.TenantID)
 	}
+	if claims.Role != models.RoleBilling {
+		t.Errorf("Expected role 'billing', got '%s'", claims.Role)
+	}
 }
 
 func TestValidateToken_ValidToken(t *testing.T) {
@@ -175,92 +179,64 @@ func TestValidateToken_WrongAlgorithm(t *testing.T) {
 	}
 }
 
-func TestRefreshToken_ValidToken(t *testing.T) {
+func TestTokenClaims_PreservesAllFields(t *testing.T) {
 	service := NewJWTService("test-secret", "test-issuer", time.Hour)
 
-	originalToken, err := service.GenerateToken("user123", "test@example.com")
-	if err != nil {
-		t.Fatalf("GenerateToken failed: %v", err)
-	}
-
-	time.Sleep(time.Second)
-
-	newToken, err := service.RefreshToken(originalToken)
+	token, err := service.GenerateTenantToken("user123", "test@example.com", "tenant456", models.RoleBilling)
 	if err != nil {
-		t.Fatalf("RefreshToken failed: %v", err)
-	}
-
-	if newToken == "" {
-		t.Error("Refreshed token should not be empty")
-	}
-
-	if newToken == originalToken {
-		t.Error("Refreshed token should be different from original")
+		t.Fatalf("GenerateTenantToken failed: %v", err)
 	}
 
-	claims, err := service.ValidateToken(newToken)
+	claims, err := service.ValidateToken(token)
 	if err != nil {
-		t.Fatalf("ValidateToken failed on refreshed token: %v", err)
+		t.Fatalf("ValidateToken failed: %v", err)
 	}
 
 	if claims.UserID != "user123" {
-		t.Errorf("Expected user ID 'user123', got '%s'", claims.UserID)
+		t.Errorf("UserID not preserved: expected 'user123', got '%s'", claims.UserID)
 	}
-}
-
-func TestRefreshToken_ExpiredToken(t *testing.T) {
-	service := NewJWTService("test-secret", "test-issuer", -time.Hour)
 
-	expiredToken, err := service.GenerateToken("user123", "test@example.com")
-	if err != nil {
-		t.Fatalf("GenerateToken failed: %v", err)
+	if claims.Email != "test@example.com" {
+		t.Errorf("Email not preserved: expected 'test@example.com', got '%s'", claims.Email)
 	}
 
-	newToken, err := service.RefreshToken(expiredToken)
-	if err != nil {
-		t.Fatalf("RefreshToken should work with expired token: %v", err)
+	if claims.TenantID != "tenant456" {
+		t.Errorf("TenantID not preserved: expected 'tenant456', got '%s'", claims.TenantID)
 	}
 
-	if newToken == "" {
-		t.Error("Refreshed token should not be empty")
+	if claims.Subject != "user123" {
+		t.Errorf("Subject not preserved: expected 'user123', got '%s'", claims.Subject)
 	}
 }
 
-func TestRefreshToken_InvalidToken(t *testing.T) {
+func TestGenerateSessionToken_GivesEachTokenAnID(t *testing.T) {
 	service := NewJWTService("test-secret", "test-issuer", time.Hour)
 
-	_, err := service.RefreshToken("invalid.token.here")
-	if err == nil {
-		t.Error("RefreshToken should fail with invalid token")
+	first, issued, err := service.GenerateSessionToken("session1", "user123", "test@example.com", "tenant456", models.RoleAdmin)
+	if err != nil {
+		t.Fatalf("GenerateSessionToken failed: %v", err)
 	}
-}
-
-func TestTokenClaims_PreservesAllFields(t *testing.T) {
-	service := NewJWTService("test-secret", "test-issuer", time.Hour)
-
-	token, err := service.GenerateTenantToken("user123", "test@example.com", "tenant456")
+	second, _, err := service.GenerateSessionToken("session1", "user123", "test@example.com", "tenant456", models.RoleAdmin)
 	if err != nil {
-		t.Fatalf("GenerateTenantToken failed: %v", err)
+		t.Fatalf("GenerateSessionToken failed: %v", err)
 	}
 
-	claims, err := service.ValidateToken(token)
+	claims, err := service.ValidateToken(first)
 	if err != nil {
 		t.Fatalf("ValidateToken failed: %v", err)
 	}
-
-	if claims.UserID != "user123" {
-		t.Errorf("UserID not preserved: expected 'user123', got '%s'", claims.UserID)
+	if claims.ID == "" || claims.ID != issued.ID {
+		t.Errorf("expected the jti %q returned with the token, got %q", issued.ID, claims.ID)
 	}
-
-	if claims.Email != "test@example.com" {
-		t.Errorf("Email not preserved: expected 'test@example.com', got '%s'", claims.Email)
+	if claims.SessionID != "session1" {
+		t.Errorf("SessionID not preserved: expected 'session1', got '%s'", claims.SessionID)
 	}
 
-	if claims.TenantID != "tenant456" {
-		t.Errorf("TenantID not preserved: expected 'tenant456', got '%s'", claims.TenantID)
+	other, err := service.ValidateToken(second)
+	if err != nil {
+		t.Fatalf("ValidateToken failed: %v", err)
 	}
-
-	if claims.Subject != "user123" {
-		t.Errorf("Subject not preserved: expected 'user123', got '%s'", claims.Subject)
+	if other.ID == claims.ID {
+		t.Errorf("expected distinct jtis, both were %q", claims.ID)
 	}
 }
@@ -0,0 +1,27 @@
+package security
+
+import (
+	"time"
+
+	"mini-search-platform/internal/models"
+	"mini-search-platform/pkg/logging"
+)
+
+// StartSessionPruning deletes the expired sessions, refresh tokens and
+// access tokens every interval. They are refused once expired anyway; this
+// only keeps the tables from growing with every login and refresh.
+func StartSessionPruning(sessions models.SessionRepository, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			pruned, err := sessions.Prune(time.Now())
+			if err != nil {
+				logging.Error("failed to prune sessions", "error", err)
+				continue
+			}
+			if pruned > 0 {
+				logging.Info("pruned sessions", "rows", pruned)
+			}
+		}
+	}()
+}
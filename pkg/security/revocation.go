@@ -0,0 +1,59 @@
+package security
+
+import (
+	"mini-search-platform/internal/models"
+	"sync"
+	"time"
+)
+
+// RevocationList caches the IDs (jti) of the revoked access tokens that have
+// not expired yet, so authenticating a request does not query the database.
+// The cache is reloaded once it is older than its TTL, so a session revoked
+// through another server takes effect there within the TTL; Invalidate makes
+// a revocation through this server take effect at once.
+type RevocationList struct {
+	sessions models.SessionRepository
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu       sync.Mutex
+	revoked  map[string]struct{}
+	loadedAt time.Time
+}
+
+func NewRevocationList(sessions models.SessionRepository, ttl time.Duration) *RevocationList {
+	return &RevocationList{
+		sessions: sessions,
+		ttl:      ttl,
+		now:      time.Now,
+	}
+}
+
+// IsRevoked reports whether the access token with ID jti was revoked.
+func (l *RevocationList) IsRevoked(jti string) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if l.revoked == nil || now.Sub(l.loadedAt) >= l.ttl {
+		jtis, err := l.sessions.ListRevokedAccessTokens(now)
+		if err != nil {
+			return false, err
+		}
+		l.revoked = make(map[string]struct{}, len(jtis))
+		for _, id := range jtis {
+			l.revoked[id] = struct{}{}
+		}
+		l.loadedAt = now
+	}
+
+	_, revoked := l.revoked[jti]
+	return revoked, nil
+}
+
+// Invalidate drops the cache, so the next IsRevoked reloads it.
+func (l *RevocationList) Invalidate() {
+	l.mu.Lock()
+	l.revoked = nil
+	l.mu.Unlock()
+}
@@ -0,0 +1,60 @@
+package security
+
+import (
+	"testing"
+	"time"
+
+	"mini-search-platform/internal/models"
+)
+
+// revokedTokens is a SessionRepository listing a fixed set of revoked
+// tokens and counting the loads.
+type revokedTokens struct {
+	models.SessionRepository
+	jtis  []string
+	loads int
+}
+
+func (r *revokedTokens) ListRevokedAccessTokens(now time.Time) ([]string, error) {
+	r.loads++
+	return r.jtis, nil
+}
+
+func TestRevocationList_ReloadsOnceStaleOrInvalidated(t *testing.T) {
+	repo := &revokedTokens{jtis: []string{"revoked"}}
+	list := NewRevocationList(repo, time.Minute)
+	now := time.Now()
+	list.now = func() time.Time { return now }
+
+	for _, jti := range []string{"revoked", "other"} {
+		revoked, err := list.IsRevoked(jti)
+		if err != nil {
+			t.Fatalf("IsRevoked failed: %v", err)
+		}
+		if revoked != (jti == "revoked") {
+			t.Errorf("IsRevoked(%q) = %v", jti, revoked)
+		}
+	}
+	if repo.loads != 1 {
+		t.Errorf("expected one load while fresh, got %d", repo.loads)
+	}
+
+	// Revoked through another server: seen once the cache is stale.
+	repo.jtis = append(repo.jtis, "other")
+	if revoked, _ := list.IsRevoked("other"); revoked {
+		t.Error("expected the cached list until it is stale")
+	}
+	now = now.Add(time.Minute)
+	if revoked, _ := list.IsRevoked("other"); !revoked {
+		t.Error("expected a stale list to be reloaded")
+	}
+
+	repo.jtis = append(repo.jtis, "third")
+	list.Invalidate()
+	if revoked, _ := list.IsRevoked("third"); !revoked {
+		t.Error("expected an invalidated list to be reloaded")
+	}
+	if repo.loads != 3 {
+		t.Errorf("expected three loads, got %d", repo.loads)
+	}
+}